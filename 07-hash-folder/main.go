@@ -1,67 +1,95 @@
+// Command 07-hash-folder writes a checksum manifest of every file below a
+// folder, or verifies a folder against a manifest:
+//
+//	go run ./07-hash-folder -folder release -o release.sha256
+//	go run ./07-hash-folder -folder release -verify release.sha256
+//
+// Verify prints the added, removed, modified and unreadable files and exits
+// with status 1 if there is any, so it can check the integrity of a release.
 package main
 
 import (
-	"crypto/md5"
-	"crypto/sha1"
-	"crypto/sha256"
 	"flag"
-	"fmt"
-	"hash"
-	"io"
 	"log"
 	"os"
-	"path/filepath"
+	"runtime"
 )
 
 func main() {
-	folder := flag.String("folder", "", "")
+	folder := flag.String("folder", "", "folder to hash recursively")
+	algorithm := flag.String("algo", "", "hash algorithm: md5, sha1, sha256 or sha512 (default sha256, or the one of the manifest to verify)")
+	format := flag.String("format", formatSum, "manifest format: sha256sum, bsd or json")
+	output := flag.String("o", "", "file to write the manifest to instead of stdout")
+	verify := flag.String("verify", "", "manifest to verify the folder against")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of files hashed in parallel")
 	flag.Parse()
 
-	allFiles, err := os.ReadDir(*folder)
-	if err != nil {
-		fmt.Println("Cannot read this file path")
+	log.SetFlags(0)
+	if *folder == "" || *workers < 1 {
+		flag.Usage()
+		os.Exit(2)
 	}
-
-	for _, file := range allFiles {
-		if !file.IsDir() {
-			filepath := filepath.Join(*folder, file.Name())
-			md5Hash, err := calculateHash(filepath, md5.New)
-			if err != nil {
-				log.Println("Error calculating MD5 hash for", file, err)
-			} else {
-				fmt.Printf("MD5 hash of %s: %s\n", file, md5Hash)
-			}
-			sha1Hash, err := calculateHash(filepath, sha1.New)
-			if err != nil {
-				log.Println("Error calculating SHA-1 hash for", file, err)
-			} else {
-				fmt.Printf("SHA-1 hash of %s: %s\n", file, sha1Hash)
-			}
-			sh256Hash, err := calculateHash(filepath, sha256.New)
-			if err != nil {
-				log.Println("Error calculating SHA-256 hash for", file, err)
-			} else {
-				fmt.Printf("SHA-256 hash of %s: %s\n", file, sh256Hash)
-			}
+	if *verify != "" {
+		ok, err := verifyFolder(*folder, *verify, *algorithm, *workers, os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !ok {
+			os.Exit(1)
 		}
+		return
 	}
-	if err != nil {
-		log.Fatal(err)
+	if !writeFolder(*folder, *algorithm, *format, *output, *workers) {
+		os.Exit(1)
 	}
 }
 
-func calculateHash(filePath string, hashFunc func() hash.Hash) (string, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return "", err
+// writeFolder writes the manifest of folder to output, or stdout when empty.
+// It reports whether every file was hashed.
+func writeFolder(folder, algorithm, format, output string, workers int) bool {
+	if algorithm == "" {
+		algorithm = "sha256"
+	}
+	if _, ok := hashFuncs[algorithm]; !ok {
+		log.Fatalf("unknown algorithm %q", algorithm)
+	}
+	switch format {
+	case formatSum, formatBSD, formatJSON:
+	default:
+		log.Fatalf("unknown format %q", format)
 	}
-	defer file.Close()
 
-	h := hashFunc()
-	if _, err := io.Copy(h, file); err != nil {
-		return "", err
+	// The manifest is not part of the folder it describes
+	var skip []string
+	if output != "" {
+		skip = append(skip, output)
+	}
+	entries, err := hashFolder(folder, algorithm, workers, skip)
+	if err != nil {
+		log.Fatal(err)
+	}
+	hashed := true
+	for _, entry := range entries {
+		if entry.Err != nil {
+			log.Printf("cannot hash %s: %v", entry.Path, entry.Err)
+			hashed = false
+		}
 	}
 
-	hashBytes := h.Sum(nil)
-	return fmt.Sprintf("%x", hashBytes), nil
+	if output == "" {
+		err = writeManifest(os.Stdout, format, algorithm, entries)
+	} else {
+		var file *os.File
+		file, err = os.Create(output)
+		if err == nil {
+			err = writeManifest(file, format, algorithm, entries)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	return hashed
 }
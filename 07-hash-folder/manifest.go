@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Manifest formats
+const (
+	// formatSum is the format of sha256sum and the other GNU coreutils:
+	// "<hash>  <path>"
+	formatSum = "sha256sum"
+	// formatBSD is the tagged format of BSD md5 and of sha256sum --tag:
+	// "SHA256 (<path>) = <hash>"
+	formatBSD = "bsd"
+	// formatJSON also records the size and modification time of each file
+	formatJSON = "json"
+)
+
+var hashFuncs = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// bsdTags are the names of the algorithms in BSD-style lines
+var bsdTags = map[string]string{
+	"md5":    "MD5",
+	"sha1":   "SHA1",
+	"sha256": "SHA256",
+	"sha512": "SHA512",
+}
+
+// hexLengths are the algorithms by length of their hex digest, to recognise
+// the algorithm of a sha256sum-style manifest
+var hexLengths = map[int]string{
+	32:  "md5",
+	40:  "sha1",
+	64:  "sha256",
+	128: "sha512",
+}
+
+// fileEntry is a file of a manifest
+type fileEntry struct {
+	// Path is relative to the folder and slash-separated
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Hash       string    `json:"hash"`
+	// Err is set if the file could not be read
+	Err       error `json:"-"`
+	algorithm string
+}
+
+type jsonManifest struct {
+	Algorithm string      `json:"algorithm"`
+	Files     []fileEntry `json:"files"`
+}
+
+// hashFolder hashes every regular file below folder with algorithm, except
+// the files in skip. Symbolic links are not followed. Files that cannot be
+// read are returned with Err set, the entries are sorted by path.
+func hashFolder(folder, algorithm string, workers int, skip []string) ([]fileEntry, error) {
+	entries, err := listFiles(folder, skip)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].algorithm = algorithm
+	}
+	hashFiles(folder, entries, workers)
+	return entries, nil
+}
+
+// listFiles returns the regular files below folder, except the files in
+// skip, sorted by path. A directory that cannot be read is returned with Err
+// set.
+func listFiles(folder string, skip []string) ([]fileEntry, error) {
+	skipped := make(map[string]bool)
+	for _, path := range skip {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		skipped[abs] = true
+	}
+
+	var entries []fileEntry
+	err := filepath.WalkDir(folder, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == folder {
+				return err
+			}
+			entries = append(entries, fileEntry{Path: relPath(folder, path), Err: err})
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if abs, err := filepath.Abs(path); err == nil && skipped[abs] {
+			return nil
+		}
+		entries = append(entries, fileEntry{Path: relPath(folder, path)})
+		return nil
+	})
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, err
+}
+
+// relPath returns the slash-separated path of path relative to folder
+func relPath(folder, path string) string {
+	rel, err := filepath.Rel(folder, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// hashFiles hashes the entries below folder with their algorithm, workers
+// files at a time. Entries with Err set are skipped.
+func hashFiles(folder string, entries []fileEntry, workers int) {
+	jobs := make(chan *fileEntry)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entry := range jobs {
+				path := filepath.Join(folder, filepath.FromSlash(entry.Path))
+				entry.Size, entry.ModifiedAt, entry.Hash, entry.Err = hashFile(path, hashFuncs[entry.algorithm])
+			}
+		}()
+	}
+	for i := range entries {
+		if entries[i].Err == nil {
+			jobs <- &entries[i]
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+func hashFile(path string, hashFunc func() hash.Hash) (int64, time.Time, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, time.Time{}, "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, time.Time{}, "", err
+	}
+	h := hashFunc()
+	size, err := io.Copy(h, file)
+	if err != nil {
+		return 0, time.Time{}, "", err
+	}
+	return size, info.ModTime().UTC(), fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// writeManifest writes the entries without Err to w in format
+func writeManifest(w io.Writer, format, algorithm string, entries []fileEntry) error {
+	var hashed []fileEntry
+	for _, entry := range entries {
+		if entry.Err == nil {
+			hashed = append(hashed, entry)
+		}
+	}
+
+	if format == formatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(jsonManifest{Algorithm: algorithm, Files: hashed})
+	}
+	buf := bufio.NewWriter(w)
+	for _, entry := range hashed {
+		path, escaped := escapePath(entry.Path)
+		if escaped {
+			buf.WriteByte('\\')
+		}
+		if format == formatBSD {
+			fmt.Fprintf(buf, "%s (%s) = %s\n", bsdTags[algorithm], path, entry.Hash)
+		} else {
+			fmt.Fprintf(buf, "%s  %s\n", entry.Hash, path)
+		}
+	}
+	return buf.Flush()
+}
+
+// pathEscaper escapes paths like GNU coreutils, a line with an escaped path
+// starts with a backslash
+var (
+	pathEscaper   = strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\r", "\\r")
+	pathUnescaper = strings.NewReplacer("\\\\", "\\", "\\n", "\n", "\\r", "\r")
+)
+
+func escapePath(path string) (string, bool) {
+	if !strings.ContainsAny(path, "\\\n\r") {
+		return path, false
+	}
+	return pathEscaper.Replace(path), true
+}
+
+var (
+	bsdLine = regexp.MustCompile(`^([A-Z0-9-]+) \((.*)\) = ([0-9a-fA-F]+)$`)
+	sumLine = regexp.MustCompile(`^([0-9a-fA-F]+) [ *](.*)$`)
+)
+
+// readManifest reads a manifest in any format. The algorithm of a
+// sha256sum-style line is algorithm, or guessed from the length of the
+// hash when empty.
+func readManifest(path, algorithm string) ([]fileEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var manifest jsonManifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+		}
+		if _, ok := hashFuncs[manifest.Algorithm]; !ok {
+			return nil, fmt.Errorf("invalid manifest %s: unknown algorithm %q", path, manifest.Algorithm)
+		}
+		for i := range manifest.Files {
+			manifest.Files[i].algorithm = manifest.Algorithm
+		}
+		return manifest.Files, nil
+	}
+
+	var entries []fileEntry
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" {
+			continue
+		}
+		escaped := strings.HasPrefix(line, "\\")
+		if escaped {
+			line = line[1:]
+		}
+
+		var entry fileEntry
+		if match := bsdLine.FindStringSubmatch(line); match != nil {
+			for name, tag := range bsdTags {
+				if tag == match[1] {
+					entry.algorithm = name
+				}
+			}
+			entry.Path, entry.Hash = match[2], match[3]
+		} else if match := sumLine.FindStringSubmatch(line); match != nil {
+			entry.algorithm = algorithm
+			if entry.algorithm == "" {
+				entry.algorithm = hexLengths[len(match[1])]
+			}
+			entry.Hash, entry.Path = match[1], match[2]
+		} else {
+			return nil, fmt.Errorf("invalid manifest %s: line %d is not a checksum line", path, i+1)
+		}
+		if _, ok := hashFuncs[entry.algorithm]; !ok {
+			return nil, fmt.Errorf("invalid manifest %s: unknown algorithm on line %d", path, i+1)
+		}
+		if escaped {
+			entry.Path = pathUnescaper.Replace(entry.Path)
+		}
+		entry.Hash = strings.ToLower(entry.Hash)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTree creates the files of contents below dir, keyed by slash-separated
+// path
+func writeTree(t *testing.T, dir string, contents map[string]string) {
+	t.Helper()
+	for path, content := range contents {
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// testTree has names that need escaping in the line formats
+var testTree = map[string]string{
+	"a.txt":             "hello\n",
+	"dir/b c.txt":       "",
+	"dir/sub/été.txt":   "unicode",
+	`back\slash.txt`:    "backslash",
+	"new\nline.txt":     "newline",
+	"(paren) = x.txt":   "looks like a BSD line",
+	"star *suffix.txt":  "star",
+	"dir/carriage\r.go": "carriage return",
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	folder := t.TempDir()
+	writeTree(t, folder, testTree)
+
+	for _, format := range []string{formatSum, formatBSD, formatJSON} {
+		for algorithm := range hashFuncs {
+			entries, err := hashFolder(folder, algorithm, 2, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(testTree) {
+				t.Fatalf("hashed %d files, want %d", len(entries), len(testTree))
+			}
+			manifest := filepath.Join(t.TempDir(), "manifest")
+			file, err := os.Create(manifest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := writeManifest(file, format, algorithm, entries); err != nil {
+				t.Fatal(err)
+			}
+			file.Close()
+
+			// The algorithm of the line formats is read from the tag or
+			// guessed from the length of the hashes
+			read, err := readManifest(manifest, "")
+			if err != nil {
+				t.Fatalf("%s %s: %v", format, algorithm, err)
+			}
+			if len(read) != len(entries) {
+				t.Fatalf("%s %s: read %d files, want %d", format, algorithm, len(read), len(entries))
+			}
+			for i, entry := range read {
+				written := entries[i]
+				if entry.Path != written.Path || entry.Hash != written.Hash || entry.algorithm != algorithm {
+					t.Errorf("%s %s: read %q %s %s, want %q %s %s", format, algorithm,
+						entry.Path, entry.algorithm, entry.Hash, written.Path, algorithm, written.Hash)
+				}
+				if format == formatJSON && (entry.Size != written.Size || !entry.ModifiedAt.Equal(written.ModifiedAt)) {
+					t.Errorf("json: read %q of %d bytes modified at %v, want %d bytes at %v",
+						entry.Path, entry.Size, entry.ModifiedAt, written.Size, written.ModifiedAt)
+				}
+			}
+		}
+	}
+}
+
+func TestWriteManifestLines(t *testing.T) {
+	entries := []fileEntry{
+		{Path: "a.txt", Hash: "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"},
+		{Path: `dir/back\slash`, Hash: "00"},
+		{Path: "new\nline", Hash: "11"},
+		{Path: "unreadable", Err: os.ErrPermission},
+	}
+	for format, want := range map[string]string{
+		formatSum: "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03  a.txt\n" +
+			"\\00  dir/back\\\\slash\n" +
+			"\\11  new\\nline\n",
+		formatBSD: "SHA256 (a.txt) = 5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03\n" +
+			"\\SHA256 (dir/back\\\\slash) = 00\n" +
+			"\\SHA256 (new\\nline) = 11\n",
+	} {
+		var manifest strings.Builder
+		if err := writeManifest(&manifest, format, "sha256", entries); err != nil {
+			t.Fatal(err)
+		}
+		if manifest.String() != want {
+			t.Errorf("%s manifest:\n%s\nwant:\n%s", format, manifest.String(), want)
+		}
+	}
+}
+
+// TestManifestSha256sum checks the manifest with sha256sum -c of GNU
+// coreutils, escaped names included
+func TestManifestSha256sum(t *testing.T) {
+	sha256sum, err := exec.LookPath("sha256sum")
+	if err != nil {
+		t.Skip("sha256sum is not installed")
+	}
+	folder := t.TempDir()
+	writeTree(t, folder, testTree)
+	entries, err := hashFolder(folder, "sha256", 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []string{formatSum, formatBSD} {
+		var manifest strings.Builder
+		if err := writeManifest(&manifest, format, "sha256", entries); err != nil {
+			t.Fatal(err)
+		}
+		cmd := exec.Command(sha256sum, "--check", "--strict", "-")
+		cmd.Dir = folder
+		cmd.Stdin = strings.NewReader(manifest.String())
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("sha256sum --check of the %s manifest: %v\n%s", format, err, output)
+		}
+	}
+}
+
+// TestReadManifestLines reads lines written by sha256sum, md5sum --tag and
+// BSD md5
+func TestReadManifestLines(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		manifest  string
+		algorithm string
+		want      []fileEntry
+	}{
+		{
+			name: "sha256sum",
+			manifest: "5891B5B522D5DF086D0FF0B110FBD9D21BB4FC7163AF34D08286A2E846F6BE03  a.txt\n" +
+				"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855 *bin/b c.exe\n" +
+				"\\e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  new\\nline\\\\x\n",
+			want: []fileEntry{
+				{Path: "a.txt", Hash: "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03", algorithm: "sha256"},
+				{Path: "bin/b c.exe", Hash: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", algorithm: "sha256"},
+				{Path: "new\nline\\x", Hash: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", algorithm: "sha256"},
+			},
+		},
+		{
+			name:     "md5sum with CRLF",
+			manifest: "d41d8cd98f00b204e9800998ecf8427e  empty\r\n\r\n",
+			want:     []fileEntry{{Path: "empty", Hash: "d41d8cd98f00b204e9800998ecf8427e", algorithm: "md5"}},
+		},
+		{
+			name:      "sum with the algorithm given",
+			manifest:  "0123  x\n",
+			algorithm: "sha1",
+			want:      []fileEntry{{Path: "x", Hash: "0123", algorithm: "sha1"}},
+		},
+		{
+			name: "bsd",
+			manifest: "MD5 (a (1).txt) = d41d8cd98f00b204e9800998ecf8427e\n" +
+				"SHA1 (b) = da39a3ee5e6b4b0d3255bfef95601890afd80709\n" +
+				"\\SHA512 (c\\\\d) = 00ff\n",
+			want: []fileEntry{
+				{Path: "a (1).txt", Hash: "d41d8cd98f00b204e9800998ecf8427e", algorithm: "md5"},
+				{Path: "b", Hash: "da39a3ee5e6b4b0d3255bfef95601890afd80709", algorithm: "sha1"},
+				{Path: `c\d`, Hash: "00ff", algorithm: "sha512"},
+			},
+		},
+		{
+			name:     "json",
+			manifest: `{"algorithm": "sha1", "files": [{"path": "a\\b\nc", "size": 3, "modified_at": "2024-01-02T03:04:05Z", "hash": "abc"}]}`,
+			want: []fileEntry{{
+				Path:       "a\\b\nc",
+				Size:       3,
+				ModifiedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				Hash:       "abc",
+				algorithm:  "sha1",
+			}},
+		},
+	} {
+		manifest := filepath.Join(t.TempDir(), "manifest")
+		if err := os.WriteFile(manifest, []byte(test.manifest), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		entries, err := readManifest(manifest, test.algorithm)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(entries, test.want) {
+			t.Errorf("%s: read %+v, want %+v", test.name, entries, test.want)
+		}
+	}
+}
+
+func TestReadManifestErrors(t *testing.T) {
+	for name, manifest := range map[string]string{
+		"not a checksum line":    "5891b5b5 a.txt\n",
+		"unknown hash length":    "0123  x\n",
+		"unknown bsd tag":        "CRC32 (x) = 0123\n",
+		"unknown json algorithm": `{"algorithm": "crc32", "files": []}`,
+		"invalid json":           `{"algorithm": `,
+	} {
+		path := filepath.Join(t.TempDir(), "manifest")
+		if err := os.WriteFile(path, []byte(manifest), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if entries, err := readManifest(path, ""); err == nil {
+			t.Errorf("%s: read %+v, want an error", name, entries)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Statuses of the files that do not match the manifest
+const (
+	statusAdded      = "ADDED"
+	statusRemoved    = "REMOVED"
+	statusModified   = "MODIFIED"
+	statusUnreadable = "UNREADABLE"
+)
+
+// mismatch is a file that does not match the manifest
+type mismatch struct {
+	status string
+	path   string
+	err    error
+}
+
+// verifyFolder compares the files below folder to the manifest at
+// manifestPath and writes a line to w for every file that is added, removed,
+// modified or unreadable, followed by a summary. It reports whether the
+// folder matches the manifest.
+func verifyFolder(folder, manifestPath, algorithm string, workers int, w io.Writer) (bool, error) {
+	if algorithm != "" {
+		if _, ok := hashFuncs[algorithm]; !ok {
+			return false, fmt.Errorf("unknown algorithm %q", algorithm)
+		}
+	}
+	expected, err := readManifest(manifestPath, algorithm)
+	if err != nil {
+		return false, err
+	}
+	files, err := listFiles(folder, []string{manifestPath})
+	if err != nil {
+		return false, err
+	}
+
+	want := make(map[string]fileEntry, len(expected))
+	for _, entry := range expected {
+		want[entry.Path] = entry
+	}
+	var mismatches []mismatch
+	// Only the files of the manifest are hashed, with its algorithm
+	var checked []fileEntry
+	for _, file := range files {
+		entry, ok := want[file.Path]
+		switch {
+		case file.Err != nil:
+			mismatches = append(mismatches, mismatch{status: statusUnreadable, path: file.Path, err: file.Err})
+		case !ok:
+			mismatches = append(mismatches, mismatch{status: statusAdded, path: file.Path})
+		default:
+			checked = append(checked, fileEntry{Path: file.Path, algorithm: entry.algorithm})
+		}
+	}
+	present := make(map[string]bool, len(files))
+	for _, file := range files {
+		present[file.Path] = true
+	}
+	for _, entry := range expected {
+		if !present[entry.Path] {
+			mismatches = append(mismatches, mismatch{status: statusRemoved, path: entry.Path})
+		}
+	}
+
+	hashFiles(folder, checked, workers)
+	matched := 0
+	for _, file := range checked {
+		switch {
+		case file.Err != nil:
+			mismatches = append(mismatches, mismatch{status: statusUnreadable, path: file.Path, err: file.Err})
+		case file.Hash != want[file.Path].Hash:
+			mismatches = append(mismatches, mismatch{status: statusModified, path: file.Path})
+		default:
+			matched++
+		}
+	}
+
+	sort.Slice(mismatches, func(i, j int) bool { return mismatches[i].path < mismatches[j].path })
+	counts := make(map[string]int)
+	for _, m := range mismatches {
+		counts[m.status]++
+		if m.err != nil {
+			fmt.Fprintf(w, "%s %s: %v\n", m.status, m.path, m.err)
+		} else {
+			fmt.Fprintf(w, "%s %s\n", m.status, m.path)
+		}
+	}
+	fmt.Fprintf(w, "%d ok, %d added, %d removed, %d modified, %d unreadable\n",
+		matched, counts[statusAdded], counts[statusRemoved], counts[statusModified], counts[statusUnreadable])
+	return len(mismatches) == 0, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFolderManifest writes a sha256sum manifest of folder into the folder
+// and returns its path
+func writeFolderManifest(t *testing.T, folder string) string {
+	t.Helper()
+	manifest := filepath.Join(folder, "SHA256SUMS")
+	entries, err := hashFolder(folder, "sha256", 2, []string{manifest})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := writeManifest(file, formatSum, "sha256", entries); err != nil {
+		t.Fatal(err)
+	}
+	return manifest
+}
+
+func TestVerifyFolder(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		change func(t *testing.T, folder string)
+		output string
+	}{
+		{
+			name:   "unchanged",
+			change: func(t *testing.T, folder string) {},
+			output: "8 ok, 0 added, 0 removed, 0 modified, 0 unreadable\n",
+		},
+		{
+			name: "added",
+			change: func(t *testing.T, folder string) {
+				writeTree(t, folder, map[string]string{"dir/new.txt": "new"})
+			},
+			output: "ADDED dir/new.txt\n" +
+				"8 ok, 1 added, 0 removed, 0 modified, 0 unreadable\n",
+		},
+		{
+			name: "removed",
+			change: func(t *testing.T, folder string) {
+				if err := os.Remove(filepath.Join(folder, "dir", "b c.txt")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			output: "REMOVED dir/b c.txt\n" +
+				"7 ok, 0 added, 1 removed, 0 modified, 0 unreadable\n",
+		},
+		{
+			name: "modified",
+			change: func(t *testing.T, folder string) {
+				writeTree(t, folder, map[string]string{"a.txt": "hello!\n", "new\nline.txt": "changed"})
+			},
+			output: "MODIFIED a.txt\n" +
+				"MODIFIED new\nline.txt\n" +
+				"6 ok, 0 added, 0 removed, 2 modified, 0 unreadable\n",
+		},
+		{
+			name: "unreadable",
+			change: func(t *testing.T, folder string) {
+				if os.Geteuid() == 0 {
+					t.Skip("root reads files without permission")
+				}
+				path := filepath.Join(folder, "a.txt")
+				if err := os.Chmod(path, 0); err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { os.Chmod(path, 0o644) })
+			},
+			output: "UNREADABLE a.txt: open a.txt: permission denied\n" +
+				"7 ok, 0 added, 0 removed, 0 modified, 1 unreadable\n",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			folder := t.TempDir()
+			writeTree(t, folder, testTree)
+			manifest := writeFolderManifest(t, folder)
+			test.change(t, folder)
+
+			var output strings.Builder
+			ok, err := verifyFolder(folder, manifest, "", 2, &output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := test.name == "unchanged"; ok != want {
+				t.Errorf("verifyFolder returned %v, want %v", ok, want)
+			}
+			// The error of an unreadable file names its full path
+			got := strings.ReplaceAll(output.String(), folder+string(filepath.Separator), "")
+			if got != test.output {
+				t.Errorf("verifyFolder wrote:\n%s\nwant:\n%s", got, test.output)
+			}
+		})
+	}
+}
+
+func TestVerifyFolderErrors(t *testing.T) {
+	folder := t.TempDir()
+	writeTree(t, folder, testTree)
+	manifest := writeFolderManifest(t, folder)
+
+	if _, err := verifyFolder(folder, manifest, "crc32", 2, &strings.Builder{}); err == nil {
+		t.Error("verifyFolder with an unknown algorithm succeeded")
+	}
+	if _, err := verifyFolder(folder, filepath.Join(folder, "missing"), "", 2, &strings.Builder{}); err == nil {
+		t.Error("verifyFolder without a manifest succeeded")
+	}
+}
@@ -0,0 +1,176 @@
+// Package filequery parses the file search language of the q parameter into
+// an AST and compiles it into a parameterised condition on the file table.
+//
+// A query is made of terms separated by spaces, all of which have to match.
+// Terms are combined with | or OR, negated with ! or NOT and grouped with
+// parentheses. A term is a word or a "quoted phrase" searched in the name of
+// the file, or in its path if it contains a path separator, optionally with *
+// and ? wildcards matching the whole name. A term can also be a filter:
+//
+//	name:report          word or phrase in the name
+//	path:/srv/share      word or phrase in the path
+//	ext:docx;xlsx        one of the extensions
+//	size:>10mb           size compared with >, >=, <, <= or =, a range
+//	                     1mb..10mb or empty, tiny, small, medium, large, huge,
+//	                     gigantic
+//	modified:lastweek    modification time, dm: for short, compared like size
+//	created:2024-01      creation time, dc: for short
+//	accessed:today       access time, da: for short
+//	meta.author:"Jane"   document or media metadata, the whole value
+//	                     matched with case
+//
+// Dates are a day 2024-01-15, a month 2024-01, a year 2024 or one of today,
+// yesterday, thisweek, lastweek, thismonth, lastmonth, thisyear and lastyear.
+//
+//	ext:docx size:>10mb modified:lastweek path:/srv/share "quarterly report" !tmp
+package filequery
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Field is a column of the file table searched by a term
+type Field string
+
+const (
+	FieldName       Field = "name"
+	FieldPath       Field = "path"
+	FieldExtension  Field = "ext"
+	FieldSize       Field = "size"
+	FieldModifiedAt Field = "modified"
+	FieldCreatedAt  Field = "created"
+	FieldAccessedAt Field = "accessed"
+)
+
+// Node is a node of a parsed query
+type Node interface {
+	// Pos is the byte offset of the node in the query
+	Pos() int
+	// String returns the node in a syntax close to the query, bounds are
+	// resolved to numbers and times
+	String() string
+}
+
+// And matches the files matched by all its nodes, an empty And matches every
+// file
+type And struct {
+	Offset int
+	Nodes  []Node
+}
+
+// Or matches the files matched by any of its nodes
+type Or struct {
+	Offset int
+	Nodes  []Node
+}
+
+// Not matches the files not matched by its node
+type Not struct {
+	Offset int
+	Node   Node
+}
+
+// Text matches a name or a path containing Value regardless of case. A value
+// with wildcards has to match the whole name or path.
+type Text struct {
+	Offset int
+	Field  Field
+	Value  string
+}
+
+// Extension matches the files with one of the extensions, given in lower case
+// without the leading dot
+type Extension struct {
+	Offset     int
+	Extensions []string
+}
+
+// Size matches the files with a size in [Min, Max]
+type Size struct {
+	Offset int
+	Min    int64
+	Max    int64
+}
+
+// Meta matches the files whose metadata has Value at Key, such as the author
+// of a document or the camera of a photo
+type Meta struct {
+	Offset int
+	Key    string
+	Value  string
+}
+
+// Time matches the files with a time of Field in [After, Before), a zero
+// bound is not checked
+type Time struct {
+	Offset int
+	Field  Field
+	After  time.Time
+	Before time.Time
+}
+
+func (node *And) Pos() int       { return node.Offset }
+func (node *Or) Pos() int        { return node.Offset }
+func (node *Not) Pos() int       { return node.Offset }
+func (node *Text) Pos() int      { return node.Offset }
+func (node *Extension) Pos() int { return node.Offset }
+func (node *Size) Pos() int      { return node.Offset }
+func (node *Time) Pos() int      { return node.Offset }
+func (node *Meta) Pos() int      { return node.Offset }
+
+func (node *And) String() string {
+	parts := make([]string, len(node.Nodes))
+	for i, child := range node.Nodes {
+		parts[i] = child.String()
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+func (node *Or) String() string {
+	parts := make([]string, len(node.Nodes))
+	for i, child := range node.Nodes {
+		parts[i] = child.String()
+	}
+	return "(" + strings.Join(parts, " | ") + ")"
+}
+
+func (node *Not) String() string {
+	return "!" + node.Node.String()
+}
+
+func (node *Text) String() string {
+	return fmt.Sprintf("%s:%q", node.Field, node.Value)
+}
+
+func (node *Extension) String() string {
+	return "ext:" + strings.Join(node.Extensions, ";")
+}
+
+func (node *Size) String() string {
+	if node.Max == math.MaxInt64 {
+		return fmt.Sprintf("size:>=%d", node.Min)
+	}
+	return fmt.Sprintf("size:%d..%d", node.Min, node.Max)
+}
+
+func (node *Time) String() string {
+	format := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%s:%s..%s", node.Field, format(node.After), format(node.Before))
+}
+
+func (node *Meta) String() string {
+	return fmt.Sprintf("meta.%s:%q", node.Key, node.Value)
+}
+
+// hasWildcards reports whether a text value has * or ? wildcards
+func hasWildcards(value string) bool {
+	return strings.ContainsAny(value, "*?")
+}
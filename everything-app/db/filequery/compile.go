@@ -0,0 +1,164 @@
+package filequery
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect is the SQL of a database a query is compiled for
+type Dialect struct {
+	// Placeholder returns the placeholder of the nth argument, counted from 1
+	Placeholder func(n int) string
+	// ILike is the LIKE operator ignoring case
+	ILike string
+	// NoLimit is the LIMIT of a query returning every row
+	NoLimit string
+	// Time converts a time argument to the time stored by the database
+	Time func(t time.Time) interface{}
+	// Metadata is the condition of the metadata column containing the JSON
+	// object of the placeholder
+	Metadata func(placeholder string) string
+}
+
+var (
+	Postgres = Dialect{
+		Placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+		ILike:       "ILIKE",
+		NoLimit:     "ALL",
+		Time:        func(t time.Time) interface{} { return t },
+		Metadata:    func(placeholder string) string { return "metadata @> CAST(" + placeholder + " AS jsonb)" },
+	}
+	// SQLite stores the times in UTC and its LIKE ignores the case of ASCII
+	// letters only
+	SQLite = Dialect{
+		Placeholder: func(int) string { return "?" },
+		ILike:       "LIKE",
+		NoLimit:     "-1",
+		Time:        func(t time.Time) interface{} { return t.UTC() },
+		// metadata_contains is registered by the SQLite store
+		Metadata: func(placeholder string) string { return "metadata_contains(metadata, " + placeholder + ")" },
+	}
+)
+
+// Builder writes a parameterised SQL query
+type Builder struct {
+	strings.Builder
+	Dialect Dialect
+	Args    []interface{}
+}
+
+// Arg adds an argument to the query and writes its placeholder
+func (builder *Builder) Arg(value interface{}) {
+	if t, ok := value.(time.Time); ok {
+		value = builder.Dialect.Time(t)
+	}
+	builder.Args = append(builder.Args, value)
+	builder.WriteString(builder.Dialect.Placeholder(len(builder.Args)))
+}
+
+// columns are the columns of the file table searched by the fields
+var columns = map[Field]string{
+	FieldName:       "name",
+	FieldPath:       "path",
+	FieldExtension:  "extension",
+	FieldSize:       "size",
+	FieldModifiedAt: "modified_at",
+	FieldCreatedAt:  "created_at",
+	FieldAccessedAt: "accessed_at",
+}
+
+// Compile writes the condition of node on the columns of the file table
+func Compile(builder *Builder, node Node) {
+	switch node := node.(type) {
+	case *And:
+		compileList(builder, node.Nodes, " AND ", "1 = 1")
+	case *Or:
+		compileList(builder, node.Nodes, " OR ", "1 = 0")
+	case *Not:
+		builder.WriteString("NOT (")
+		Compile(builder, node.Node)
+		builder.WriteString(")")
+	case *Text:
+		builder.WriteString(columns[node.Field] + " " + builder.Dialect.ILike + " ")
+		builder.Arg(likePattern(node.Value))
+		builder.WriteString(` ESCAPE '\'`)
+	case *Extension:
+		builder.WriteString("lower(extension) IN (")
+		for i, extension := range node.Extensions {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+			builder.Arg("." + extension)
+		}
+		builder.WriteString(")")
+	case *Size:
+		builder.WriteString("(size BETWEEN ")
+		builder.Arg(node.Min)
+		builder.WriteString(" AND ")
+		builder.Arg(node.Max)
+		builder.WriteString(")")
+	case *Time:
+		column := columns[node.Field]
+		builder.WriteString("(")
+		switch {
+		case !node.After.IsZero() && !node.Before.IsZero():
+			builder.WriteString(column + " >= ")
+			builder.Arg(node.After)
+			builder.WriteString(" AND " + column + " < ")
+			builder.Arg(node.Before)
+		case !node.After.IsZero():
+			builder.WriteString(column + " >= ")
+			builder.Arg(node.After)
+		case !node.Before.IsZero():
+			builder.WriteString(column + " < ")
+			builder.Arg(node.Before)
+		default:
+			builder.WriteString("1 = 1")
+		}
+		builder.WriteString(")")
+	case *Meta:
+		object, _ := json.Marshal(map[string]string{node.Key: node.Value})
+		builder.Args = append(builder.Args, string(object))
+		builder.WriteString(builder.Dialect.Metadata(builder.Dialect.Placeholder(len(builder.Args))))
+	}
+}
+
+func compileList(builder *Builder, nodes []Node, separator, empty string) {
+	if len(nodes) == 0 {
+		builder.WriteString(empty)
+		return
+	}
+	builder.WriteString("(")
+	for i, node := range nodes {
+		if i > 0 {
+			builder.WriteString(separator)
+		}
+		Compile(builder, node)
+	}
+	builder.WriteString(")")
+}
+
+// likePattern returns the LIKE pattern escaped with \ of a text value. A
+// value with wildcards matches the whole column, other values any part.
+func likePattern(value string) string {
+	var pattern strings.Builder
+	for _, r := range value {
+		switch r {
+		case '%', '_', '\\':
+			pattern.WriteRune('\\')
+			pattern.WriteRune(r)
+		case '*':
+			pattern.WriteRune('%')
+		case '?':
+			pattern.WriteRune('_')
+		default:
+			pattern.WriteRune(r)
+		}
+	}
+	if hasWildcards(value) {
+		return pattern.String()
+	}
+	return "%" + pattern.String() + "%"
+}
@@ -0,0 +1,182 @@
+package filequery
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompile(t *testing.T) {
+	for _, test := range []struct {
+		query    string
+		postgres string
+		sqlite   string
+		args     []interface{}
+	}{
+		{
+			``,
+			`1 = 1`,
+			`1 = 1`,
+			nil,
+		},
+		{
+			`report`,
+			`name ILIKE $1 ESCAPE '\'`,
+			`name LIKE ? ESCAPE '\'`,
+			[]interface{}{"%report%"},
+		},
+		{
+			`path:"/srv/50%_off\x"`,
+			`path ILIKE $1 ESCAPE '\'`,
+			`path LIKE ? ESCAPE '\'`,
+			[]interface{}{`%/srv/50\%\_off\\x%`},
+		},
+		{
+			`report-??.*`,
+			`name ILIKE $1 ESCAPE '\'`,
+			`name LIKE ? ESCAPE '\'`,
+			[]interface{}{"report-__.%"},
+		},
+		{
+			`ext:docx;XLSX size:>1kb`,
+			`(lower(extension) IN ($1, $2) AND (size BETWEEN $3 AND $4))`,
+			`(lower(extension) IN (?, ?) AND (size BETWEEN ? AND ?))`,
+			[]interface{}{".docx", ".xlsx", int64(1025), int64(math.MaxInt64)},
+		},
+		{
+			`!a | (b c)`,
+			`(NOT (name ILIKE $1 ESCAPE '\') OR (name ILIKE $2 ESCAPE '\' AND name ILIKE $3 ESCAPE '\'))`,
+			`(NOT (name LIKE ? ESCAPE '\') OR (name LIKE ? ESCAPE '\' AND name LIKE ? ESCAPE '\'))`,
+			[]interface{}{"%a%", "%b%", "%c%"},
+		},
+		{
+			`meta.author:"Jane Doe" !meta.camera:X100`,
+			`(metadata @> CAST($1 AS jsonb) AND NOT (metadata @> CAST($2 AS jsonb)))`,
+			`(metadata_contains(metadata, ?) AND NOT (metadata_contains(metadata, ?)))`,
+			[]interface{}{`{"author":"Jane Doe"}`, `{"camera":"X100"}`},
+		},
+		{
+			`dc:>2023 da:<2024-03-01`,
+			`((created_at >= $1) AND (accessed_at < $2))`,
+			`((created_at >= ?) AND (accessed_at < ?))`,
+			[]interface{}{
+				time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	} {
+		node, err := Parse(test.query, testNow)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", test.query, err)
+		}
+		for _, dialect := range []struct {
+			name    string
+			dialect Dialect
+			sql     string
+		}{
+			{"Postgres", Postgres, test.postgres},
+			{"SQLite", SQLite, test.sqlite},
+		} {
+			builder := &Builder{Dialect: dialect.dialect}
+			Compile(builder, node)
+			if got := builder.String(); got != dialect.sql {
+				t.Errorf("%s: Compile(%q) = %s, want %s", dialect.name, test.query, got, dialect.sql)
+			}
+			if !reflect.DeepEqual(builder.Args, test.args) {
+				t.Errorf("%s: Compile(%q) args = %#v, want %#v", dialect.name, test.query, builder.Args, test.args)
+			}
+		}
+	}
+}
+
+// TestCompileTimes checks that SQLite, which compares times stored in UTC
+// as text, gets the bounds in UTC
+func TestCompileTimes(t *testing.T) {
+	paris := time.FixedZone("CET", 3600)
+	node, err := Parse(`modified:2024-03-01`, testNow.In(paris))
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := time.Date(2024, 3, 1, 0, 0, 0, 0, paris)
+	before := time.Date(2024, 3, 2, 0, 0, 0, 0, paris)
+
+	postgres := &Builder{Dialect: Postgres}
+	Compile(postgres, node)
+	if want := `(modified_at >= $1 AND modified_at < $2)`; postgres.String() != want {
+		t.Errorf("Postgres: Compile = %s, want %s", postgres.String(), want)
+	}
+	if want := []interface{}{after, before}; !reflect.DeepEqual(postgres.Args, want) {
+		t.Errorf("Postgres: args = %v, want %v", postgres.Args, want)
+	}
+
+	sqlite := &Builder{Dialect: SQLite}
+	Compile(sqlite, node)
+	if want := `(modified_at >= ? AND modified_at < ?)`; sqlite.String() != want {
+		t.Errorf("SQLite: Compile = %s, want %s", sqlite.String(), want)
+	}
+	if want := []interface{}{after.UTC(), before.UTC()}; !reflect.DeepEqual(sqlite.Args, want) {
+		t.Errorf("SQLite: args = %v, want %v", sqlite.Args, want)
+	}
+}
+
+// TestCompileContinuesArgs checks that the placeholders of a condition
+// follow the arguments already in the builder
+func TestCompileContinuesArgs(t *testing.T) {
+	node, err := Parse(`a b`, testNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder := &Builder{Dialect: Postgres}
+	builder.Arg("first")
+	builder.WriteString(" AND ")
+	Compile(builder, node)
+	if want := `$1 AND (name ILIKE $2 ESCAPE '\' AND name ILIKE $3 ESCAPE '\')`; builder.String() != want {
+		t.Errorf("Compile = %s, want %s", builder.String(), want)
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	file := &File{
+		Name:       "Report-01.DOCX",
+		Extension:  ".DOCX",
+		Path:       "/srv/share/Report-01.DOCX",
+		Size:       2048,
+		ModifiedAt: time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC),
+		CreatedAt:  time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+		AccessedAt: time.Date(2024, 3, 14, 9, 0, 0, 0, time.UTC),
+		Metadata:   map[string]string{"author": "Jane Doe", "page_count": "3"},
+	}
+	for query, want := range map[string]bool{
+		``:                       true,
+		`report`:                 true,
+		`REPORT-??.docx`:         true,
+		`report-?.docx`:          false,
+		`path:/srv/share`:        true,
+		`/srv/other`:             false,
+		`ext:docx`:               true,
+		`ext:xlsx;pdf`:           false,
+		`size:>1kb`:              true,
+		`size:small`:             false,
+		`dm:thisweek`:            true,
+		`dm:lastweek`:            false,
+		`dc:2023`:                true,
+		`da:<today`:              false,
+		`!report`:                false,
+		`tmp | report`:           true,
+		`(tmp | report) !ext:go`: true,
+		`meta.author:"Jane Doe"`: true,
+		`meta.author:Jane`:       false,
+		`meta.page_count:3`:      true,
+		`meta.camera:X100`:       false,
+		`!meta.camera:X100`:      true,
+	} {
+		node, err := Parse(query, testNow)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", query, err)
+		}
+		if got := Matcher(node)(file); got != want {
+			t.Errorf("Matcher(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package filequery
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// File holds the columns of a file matched in memory
+type File struct {
+	Name       string
+	Extension  string
+	Path       string
+	Size       int64
+	CreatedAt  time.Time
+	ModifiedAt time.Time
+	AccessedAt time.Time
+	Metadata   map[string]string
+}
+
+// Matcher returns the condition of node for stores matching files in memory,
+// it matches the files selected by the compiled condition
+func Matcher(node Node) func(file *File) bool {
+	switch node := node.(type) {
+	case *And:
+		matchers := make([]func(*File) bool, len(node.Nodes))
+		for i, child := range node.Nodes {
+			matchers[i] = Matcher(child)
+		}
+		return func(file *File) bool {
+			for _, match := range matchers {
+				if !match(file) {
+					return false
+				}
+			}
+			return true
+		}
+	case *Or:
+		matchers := make([]func(*File) bool, len(node.Nodes))
+		for i, child := range node.Nodes {
+			matchers[i] = Matcher(child)
+		}
+		return func(file *File) bool {
+			for _, match := range matchers {
+				if match(file) {
+					return true
+				}
+			}
+			return false
+		}
+	case *Not:
+		match := Matcher(node.Node)
+		return func(file *File) bool { return !match(file) }
+	case *Text:
+		value := func(file *File) string { return file.Name }
+		if node.Field == FieldPath {
+			value = func(file *File) string { return file.Path }
+		}
+		if hasWildcards(node.Value) {
+			pattern := wildcardPattern(node.Value)
+			return func(file *File) bool { return pattern.MatchString(value(file)) }
+		}
+		text := strings.ToLower(node.Value)
+		return func(file *File) bool { return strings.Contains(strings.ToLower(value(file)), text) }
+	case *Extension:
+		return func(file *File) bool {
+			extension := strings.ToLower(file.Extension)
+			for _, candidate := range node.Extensions {
+				if extension == "."+candidate {
+					return true
+				}
+			}
+			return false
+		}
+	case *Size:
+		return func(file *File) bool { return file.Size >= node.Min && file.Size <= node.Max }
+	case *Time:
+		return func(file *File) bool {
+			t := file.ModifiedAt
+			switch node.Field {
+			case FieldCreatedAt:
+				t = file.CreatedAt
+			case FieldAccessedAt:
+				t = file.AccessedAt
+			}
+			return (node.After.IsZero() || !t.Before(node.After)) && (node.Before.IsZero() || t.Before(node.Before))
+		}
+	case *Meta:
+		return func(file *File) bool {
+			value, ok := file.Metadata[node.Key]
+			return ok && value == node.Value
+		}
+	}
+	return func(*File) bool { return false }
+}
+
+// wildcardPattern returns the regular expression of a value with wildcards,
+// matching the whole text regardless of case
+func wildcardPattern(value string) *regexp.Regexp {
+	var pattern strings.Builder
+	pattern.WriteString("(?is)^")
+	for _, r := range value {
+		switch r {
+		case '*':
+			pattern.WriteString(".*")
+		case '?':
+			pattern.WriteString(".")
+		default:
+			pattern.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	pattern.WriteString("$")
+	return regexp.MustCompile(pattern.String())
+}
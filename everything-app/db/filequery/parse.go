@@ -0,0 +1,518 @@
+package filequery
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Error is an invalid query, Pos is the byte offset and Column the column
+// counted in characters from 1 of the error in the query
+type Error struct {
+	Pos    int
+	Column int
+	Msg    string
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", err.Column, err.Msg)
+}
+
+// Parse parses a query, see the package documentation for the syntax. Dates
+// are resolved in the location of now, relatively to now for today and the
+// other named dates. An empty query is an empty And.
+func Parse(query string, now time.Time) (Node, error) {
+	parser := parser{query: query, now: now}
+	if err := parser.scan(); err != nil {
+		return nil, err
+	}
+	if parser.peek().kind == tokenEOF {
+		return &And{}, nil
+	}
+	node, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != tokenEOF {
+		return nil, parser.errorf(token.pos, "unexpected %q", token.text)
+	}
+	return node, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenTerm
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind tokenKind
+	pos  int
+	text string
+}
+
+type parser struct {
+	query  string
+	now    time.Time
+	tokens []token
+	next   int
+}
+
+func (parser *parser) errorf(pos int, format string, args ...interface{}) error {
+	return &Error{
+		Pos:    pos,
+		Column: utf8.RuneCountInString(parser.query[:pos]) + 1,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+// scan splits the query into tokens. A term runs up to a space, a
+// parenthesis or a | that is not quoted.
+func (parser *parser) scan() error {
+	query := parser.query
+	for i := 0; i < len(query); {
+		r, size := utf8.DecodeRuneInString(query[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			parser.tokens = append(parser.tokens, token{kind: tokenOpen, pos: i, text: "("})
+			i++
+		case r == ')':
+			parser.tokens = append(parser.tokens, token{kind: tokenClose, pos: i, text: ")"})
+			i++
+		case r == '|':
+			parser.tokens = append(parser.tokens, token{kind: tokenOr, pos: i, text: "|"})
+			i++
+		case r == '!':
+			parser.tokens = append(parser.tokens, token{kind: tokenNot, pos: i, text: "!"})
+			i++
+		default:
+			end := i
+			quote := -1
+			for end < len(query) {
+				r, size := utf8.DecodeRuneInString(query[end:])
+				if r == '"' {
+					if quote < 0 {
+						quote = end
+					} else {
+						quote = -1
+					}
+				} else if quote < 0 && (unicode.IsSpace(r) || r == '(' || r == ')' || r == '|') {
+					break
+				}
+				end += size
+			}
+			if quote >= 0 {
+				return parser.errorf(quote, "unterminated quote")
+			}
+			kind := tokenTerm
+			switch query[i:end] {
+			case "OR":
+				kind = tokenOr
+			case "NOT":
+				kind = tokenNot
+			}
+			parser.tokens = append(parser.tokens, token{kind: kind, pos: i, text: query[i:end]})
+			i = end
+		}
+	}
+	parser.tokens = append(parser.tokens, token{kind: tokenEOF, pos: len(query), text: "end of query"})
+	return nil
+}
+
+func (parser *parser) peek() token {
+	return parser.tokens[parser.next]
+}
+
+func (parser *parser) advance() token {
+	token := parser.tokens[parser.next]
+	if token.kind != tokenEOF {
+		parser.next++
+	}
+	return token
+}
+
+func (parser *parser) parseOr() (Node, error) {
+	first, err := parser.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	nodes := []Node{first}
+	for parser.peek().kind == tokenOr {
+		parser.advance()
+		node, err := parser.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 1 {
+		return first, nil
+	}
+	return &Or{Offset: first.Pos(), Nodes: nodes}, nil
+}
+
+func (parser *parser) parseAnd() (Node, error) {
+	var nodes []Node
+	for {
+		switch parser.peek().kind {
+		case tokenTerm, tokenNot, tokenOpen:
+			node, err := parser.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, node)
+			continue
+		}
+		break
+	}
+	switch len(nodes) {
+	case 0:
+		token := parser.peek()
+		return nil, parser.errorf(token.pos, "expected a term, found %s", describe(token))
+	case 1:
+		return nodes[0], nil
+	}
+	return &And{Offset: nodes[0].Pos(), Nodes: nodes}, nil
+}
+
+func (parser *parser) parseUnary() (Node, error) {
+	token := parser.advance()
+	switch token.kind {
+	case tokenNot:
+		switch next := parser.peek(); next.kind {
+		case tokenTerm, tokenNot, tokenOpen:
+		default:
+			return nil, parser.errorf(next.pos, "expected a term after %s, found %s", token.text, describe(next))
+		}
+		node, err := parser.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Offset: token.pos, Node: node}, nil
+	case tokenOpen:
+		node, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if parser.peek().kind != tokenClose {
+			return nil, parser.errorf(token.pos, "unclosed (")
+		}
+		parser.advance()
+		return node, nil
+	}
+	return parser.parseTerm(token)
+}
+
+func describe(token token) string {
+	if token.kind == tokenEOF {
+		return token.text
+	}
+	return strconv.Quote(token.text)
+}
+
+// unquote removes the quotes of a term
+func unquote(text string) string {
+	return strings.ReplaceAll(text, `"`, "")
+}
+
+// parseTerm parses a word or a filter. A filter starts with letters, or
+// meta. and a metadata key, and a colon. A single letter is the drive of a
+// Windows path and a quoted colon is searched.
+func (parser *parser) parseTerm(token token) (Node, error) {
+	field, value, pos := "", token.text, token.pos
+	if colon := strings.IndexByte(token.text, ':'); colon > 1 && isFilterName(token.text[:colon]) {
+		field, value, pos = token.text[:colon], token.text[colon+1:], token.pos+colon+1
+	}
+	if key, ok := strings.CutPrefix(field, "meta."); ok {
+		value = unquote(value)
+		if value == "" {
+			return nil, parser.errorf(pos, "missing value of %s:", field)
+		}
+		return &Meta{Offset: token.pos, Key: key, Value: value}, nil
+	}
+	switch strings.ToLower(field) {
+	case "":
+		value = unquote(value)
+		if value == "" {
+			return nil, parser.errorf(token.pos, "empty term")
+		}
+		if strings.ContainsAny(value, `/\`) {
+			return &Text{Offset: token.pos, Field: FieldPath, Value: value}, nil
+		}
+		return &Text{Offset: token.pos, Field: FieldName, Value: value}, nil
+	case "name":
+		return parser.parseText(token.pos, FieldName, value, pos)
+	case "path":
+		return parser.parseText(token.pos, FieldPath, value, pos)
+	case "ext":
+		return parser.parseExtension(token.pos, unquote(value), pos)
+	case "size":
+		return parser.parseSize(token.pos, unquote(value), pos)
+	case "modified", "dm":
+		return parser.parseTime(token.pos, FieldModifiedAt, unquote(value), pos)
+	case "created", "dc":
+		return parser.parseTime(token.pos, FieldCreatedAt, unquote(value), pos)
+	case "accessed", "da":
+		return parser.parseTime(token.pos, FieldAccessedAt, unquote(value), pos)
+	}
+	return nil, parser.errorf(token.pos, "unknown filter %q", field+":")
+}
+
+// isFilterName reports whether name is the name of a filter, letters or
+// meta. and a key of letters, digits and underscores
+func isFilterName(name string) bool {
+	if key, ok := strings.CutPrefix(name, "meta."); ok {
+		return key != "" && strings.IndexFunc(key, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+		}) < 0
+	}
+	return strings.IndexFunc(name, func(r rune) bool { return !unicode.IsLetter(r) }) < 0
+}
+
+func (parser *parser) parseText(offset int, field Field, value string, pos int) (Node, error) {
+	value = unquote(value)
+	if value == "" {
+		return nil, parser.errorf(pos, "missing value of %s:", field)
+	}
+	return &Text{Offset: offset, Field: field, Value: value}, nil
+}
+
+func (parser *parser) parseExtension(offset int, value string, pos int) (Node, error) {
+	if value == "" {
+		return nil, parser.errorf(pos, "missing value of ext:")
+	}
+	node := &Extension{Offset: offset}
+	for _, item := range strings.Split(value, ";") {
+		extension := strings.ToLower(strings.TrimPrefix(item, "."))
+		if extension == "" {
+			return nil, parser.errorf(pos, "empty extension")
+		}
+		node.Extensions = append(node.Extensions, extension)
+		pos += len(item) + 1
+	}
+	return node, nil
+}
+
+// comparison splits a value into a comparison operator and its operands, a
+// range a..b has the operator ".." and two operands
+type comparison struct {
+	op       string
+	operands []string
+	pos      []int
+}
+
+func splitComparison(value string, pos int) comparison {
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, op) {
+			return comparison{op: op, operands: []string{value[len(op):]}, pos: []int{pos + len(op)}}
+		}
+	}
+	if dots := strings.Index(value, ".."); dots >= 0 {
+		return comparison{
+			op:       "..",
+			operands: []string{value[:dots], value[dots+2:]},
+			pos:      []int{pos, pos + dots + 2},
+		}
+	}
+	return comparison{op: "=", operands: []string{value}, pos: []int{pos}}
+}
+
+// sizeUnits are the units of sizes, in powers of 1024
+var sizeUnits = map[string]float64{
+	"":   1,
+	"b":  1,
+	"kb": 1 << 10,
+	"mb": 1 << 20,
+	"gb": 1 << 30,
+	"tb": 1 << 40,
+}
+
+// sizeNames are the named size ranges
+var sizeNames = map[string][2]int64{
+	"empty":    {0, 0},
+	"tiny":     {1, 10 << 10},
+	"small":    {10<<10 + 1, 100 << 10},
+	"medium":   {100<<10 + 1, 1 << 20},
+	"large":    {1<<20 + 1, 16 << 20},
+	"huge":     {16<<20 + 1, 128 << 20},
+	"gigantic": {128<<20 + 1, math.MaxInt64},
+}
+
+// SizeNames are the named sizes from the smallest to the largest
+var SizeNames = []string{"empty", "tiny", "small", "medium", "large", "huge", "gigantic"}
+
+// SizeName returns the named size of size, negative sizes are empty
+func SizeName(size int64) string {
+	for _, name := range SizeNames {
+		if size <= sizeNames[name][1] {
+			return name
+		}
+	}
+	return SizeNames[len(SizeNames)-1]
+}
+
+// parseSizeValue parses a size with a unit or a named size into the range
+// of sizes it stands for
+func (parser *parser) parseSizeValue(value string, pos int) (int64, int64, error) {
+	value = strings.ToLower(value)
+	if value == "" {
+		return 0, 0, parser.errorf(pos, "missing size")
+	}
+	if bounds, ok := sizeNames[value]; ok {
+		return bounds[0], bounds[1], nil
+	}
+	digits := strings.IndexFunc(value, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if digits < 0 {
+		digits = len(value)
+	}
+	number, err := strconv.ParseFloat(value[:digits], 64)
+	if err != nil {
+		return 0, 0, parser.errorf(pos, "invalid size %q", value)
+	}
+	unit, ok := sizeUnits[value[digits:]]
+	if !ok {
+		return 0, 0, parser.errorf(pos+digits, "unknown size unit %q, expected b, kb, mb, gb or tb", value[digits:])
+	}
+	size := number * unit
+	if size >= math.MaxInt64 {
+		return 0, 0, parser.errorf(pos, "size %q is too large", value)
+	}
+	return int64(math.Round(size)), int64(math.Round(size)), nil
+}
+
+func (parser *parser) parseSize(offset int, value string, pos int) (Node, error) {
+	if value == "" {
+		return nil, parser.errorf(pos, "missing value of size:")
+	}
+	comparison := splitComparison(value, pos)
+	min, max, err := parser.parseSizeValue(comparison.operands[0], comparison.pos[0])
+	if err != nil {
+		return nil, err
+	}
+	node := &Size{Offset: offset, Min: 0, Max: math.MaxInt64}
+	switch comparison.op {
+	case ">":
+		if max == math.MaxInt64 {
+			return nil, parser.errorf(comparison.pos[0], "no size is larger than %q", comparison.operands[0])
+		}
+		node.Min = max + 1
+	case ">=":
+		node.Min = min
+	case "<":
+		if min == 0 {
+			return nil, parser.errorf(comparison.pos[0], "no size is smaller than %q", comparison.operands[0])
+		}
+		node.Max = min - 1
+	case "<=":
+		node.Max = max
+	case "=":
+		node.Min, node.Max = min, max
+	case "..":
+		_, last, err := parser.parseSizeValue(comparison.operands[1], comparison.pos[1])
+		if err != nil {
+			return nil, err
+		}
+		if last < min {
+			return nil, parser.errorf(comparison.pos[1], "size range ends before it starts")
+		}
+		node.Min, node.Max = min, last
+	}
+	return node, nil
+}
+
+// parseDate parses a date into the period [start, end) it stands for
+func (parser *parser) parseDate(value string, pos int) (time.Time, time.Time, error) {
+	if value == "" {
+		return time.Time{}, time.Time{}, parser.errorf(pos, "missing date")
+	}
+	now := parser.now
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	// Weeks start on monday
+	week := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	year := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(value) {
+	case "today":
+		return today, today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), today, nil
+	case "thisweek":
+		return week, week.AddDate(0, 0, 7), nil
+	case "lastweek":
+		return week.AddDate(0, 0, -7), week, nil
+	case "thismonth":
+		return month, month.AddDate(0, 1, 0), nil
+	case "lastmonth":
+		return month.AddDate(0, -1, 0), month, nil
+	case "thisyear":
+		return year, year.AddDate(1, 0, 0), nil
+	case "lastyear":
+		return year.AddDate(-1, 0, 0), year, nil
+	}
+	for _, layout := range []struct {
+		format string
+		name   string
+		years  int
+		months int
+		days   int
+	}{
+		{"2006-01-02", "YYYY-MM-DD", 0, 0, 1},
+		{"2006-01", "YYYY-MM", 0, 1, 0},
+		{"2006", "YYYY", 1, 0, 0},
+	} {
+		if len(value) != len(layout.format) {
+			continue
+		}
+		start, err := time.ParseInLocation(layout.format, value, now.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, parser.errorf(pos, "invalid date %q, expected %s", value, layout.name)
+		}
+		return start, start.AddDate(layout.years, layout.months, layout.days), nil
+	}
+	return time.Time{}, time.Time{}, parser.errorf(pos, "invalid date %q, expected YYYY-MM-DD, YYYY-MM, YYYY or a named date like lastweek", value)
+}
+
+func (parser *parser) parseTime(offset int, field Field, value string, pos int) (Node, error) {
+	if value == "" {
+		return nil, parser.errorf(pos, "missing value of %s:", field)
+	}
+	comparison := splitComparison(value, pos)
+	start, end, err := parser.parseDate(comparison.operands[0], comparison.pos[0])
+	if err != nil {
+		return nil, err
+	}
+	node := &Time{Offset: offset, Field: field}
+	switch comparison.op {
+	case ">":
+		node.After = end
+	case ">=":
+		node.After = start
+	case "<":
+		node.Before = start
+	case "<=":
+		node.Before = end
+	case "=":
+		node.After, node.Before = start, end
+	case "..":
+		_, last, err := parser.parseDate(comparison.operands[1], comparison.pos[1])
+		if err != nil {
+			return nil, err
+		}
+		if !last.After(start) {
+			return nil, parser.errorf(comparison.pos[1], "date range ends before it starts")
+		}
+		node.After, node.Before = start, last
+	}
+	return node, nil
+}
@@ -0,0 +1,164 @@
+package filequery
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// testNow is a thursday, its week starts on monday 2024-03-11
+var testNow = time.Date(2024, 3, 14, 15, 4, 5, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	for _, test := range []struct {
+		query string
+		want  string
+	}{
+		{``, `()`},
+		{`report`, `name:"report"`},
+		{`"quarterly report"`, `name:"quarterly report"`},
+		{`quarterly" "report`, `name:"quarterly report"`},
+		{`/srv/share`, `path:"/srv/share"`},
+		{`C:\Users`, `path:"C:\\Users"`},
+		{`"a:b"`, `name:"a:b"`},
+		{`name:"a b"`, `name:"a b"`},
+		{`NAME:report`, `name:"report"`},
+		{`path:/srv`, `path:"/srv"`},
+		{`*.go`, `name:"*.go"`},
+
+		// Negation, OR and grouping
+		{`!tmp`, `!name:"tmp"`},
+		{`NOT tmp`, `!name:"tmp"`},
+		{`!!tmp`, `!!name:"tmp"`},
+		{`a b`, `(name:"a" name:"b")`},
+		{`a | b`, `(name:"a" | name:"b")`},
+		{`a OR b`, `(name:"a" | name:"b")`},
+		{`a|b`, `(name:"a" | name:"b")`},
+		{`a | b c`, `(name:"a" | (name:"b" name:"c"))`},
+		{`(a | b) c`, `((name:"a" | name:"b") name:"c")`},
+		{`!(a b)`, `!(name:"a" name:"b")`},
+		{`"a | b"`, `name:"a | b"`},
+		{`or`, `name:"or"`},
+
+		{`ext:docx`, `ext:docx`},
+		{`ext:.DOCX;xlsx`, `ext:docx;xlsx`},
+
+		// Sizes are in powers of 1024
+		{`size:100`, `size:100..100`},
+		{`size:100b`, `size:100..100`},
+		{`size:>10mb`, `size:>=10485761`},
+		{`size:>=1kb`, `size:>=1024`},
+		{`size:<1KB`, `size:0..1023`},
+		{`size:<=1.5kb`, `size:0..1536`},
+		{`size:=2gb`, `size:2147483648..2147483648`},
+		{`size:1tb`, `size:1099511627776..1099511627776`},
+		{`size:1mb..2mb`, `size:1048576..2097152`},
+		{`size:empty`, `size:0..0`},
+		{`size:tiny`, `size:1..10240`},
+		{`size:>huge`, `size:>=134217729`},
+		{`size:gigantic`, `size:>=134217729`},
+
+		// Dates are periods
+		{`modified:2024-03-01`, `modified:2024-03-01T00:00:00Z..2024-03-02T00:00:00Z`},
+		{`modified:2024-01`, `modified:2024-01-01T00:00:00Z..2024-02-01T00:00:00Z`},
+		{`modified:2023`, `modified:2023-01-01T00:00:00Z..2024-01-01T00:00:00Z`},
+		{`dm:today`, `modified:2024-03-14T00:00:00Z..2024-03-15T00:00:00Z`},
+		{`dm:yesterday`, `modified:2024-03-13T00:00:00Z..2024-03-14T00:00:00Z`},
+		{`dm:thisweek`, `modified:2024-03-11T00:00:00Z..2024-03-18T00:00:00Z`},
+		{`dm:lastweek`, `modified:2024-03-04T00:00:00Z..2024-03-11T00:00:00Z`},
+		{`dm:thismonth`, `modified:2024-03-01T00:00:00Z..2024-04-01T00:00:00Z`},
+		{`dm:lastmonth`, `modified:2024-02-01T00:00:00Z..2024-03-01T00:00:00Z`},
+		{`dm:thisyear`, `modified:2024-01-01T00:00:00Z..2025-01-01T00:00:00Z`},
+		{`dm:LastYear`, `modified:2023-01-01T00:00:00Z..2024-01-01T00:00:00Z`},
+		{`dc:>2023`, `created:2024-01-01T00:00:00Z..`},
+		{`created:>=2023`, `created:2023-01-01T00:00:00Z..`},
+		{`da:<2024-03-01`, `accessed:..2024-03-01T00:00:00Z`},
+		{`accessed:<=2024-03`, `accessed:..2024-04-01T00:00:00Z`},
+		{`modified:2024-01..2024-02`, `modified:2024-01-01T00:00:00Z..2024-03-01T00:00:00Z`},
+
+		{`meta.author:"Jane Doe"`, `meta.author:"Jane Doe"`},
+		{`meta.gps_latitude:48.858`, `meta.gps_latitude:"48.858"`},
+		{`!meta.camera:X100 | meta.page_count:3`, `(!meta.camera:"X100" | meta.page_count:"3")`},
+		// Keys are case sensitive and the value is matched whole
+		{`meta.Author:jane*`, `meta.Author:"jane*"`},
+		{`meta.:x`, `name:"meta.:x"`},
+		{`meta.a-b:x`, `name:"meta.a-b:x"`},
+
+		{
+			`ext:docx size:>10mb modified:lastweek path:/srv/share "quarterly report" !tmp`,
+			`(ext:docx size:>=10485761 modified:2024-03-04T00:00:00Z..2024-03-11T00:00:00Z path:"/srv/share" name:"quarterly report" !name:"tmp")`,
+		},
+	} {
+		node, err := Parse(test.query, testNow)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.query, err)
+			continue
+		}
+		if got := node.String(); got != test.want {
+			t.Errorf("Parse(%q) = %s, want %s", test.query, got, test.want)
+		}
+	}
+}
+
+func TestParseDateLocation(t *testing.T) {
+	paris := time.FixedZone("CET", 3600)
+	node, err := Parse(`modified:2024-03-01`, testNow.In(paris))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `modified:2024-03-01T00:00:00+01:00..2024-03-02T00:00:00+01:00`
+	if got := node.String(); got != want {
+		t.Errorf("Parse in CET = %s, want %s", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, test := range []struct {
+		query  string
+		column int
+		msg    string
+	}{
+		{`"abc`, 1, `unterminated quote`},
+		{`a "b`, 3, `unterminated quote`},
+		{`(a`, 1, `unclosed (`},
+		{`a (b | (c)`, 3, `unclosed (`},
+		{`a)`, 2, `unexpected ")"`},
+		{`a |`, 4, `expected a term, found end of query`},
+		{`| a`, 1, `expected a term, found "|"`},
+		{`()`, 2, `expected a term, found ")"`},
+		{`!`, 2, `expected a term after !, found end of query`},
+		{`NOT | a`, 5, `expected a term after NOT, found "|"`},
+		{`""`, 1, `empty term`},
+		{`foo:bar`, 1, `unknown filter "foo:"`},
+		{`name:""`, 6, `missing value of name:`},
+		{`ext:`, 5, `missing value of ext:`},
+		{`ext:a;;b`, 7, `empty extension`},
+		{`size:`, 6, `missing value of size:`},
+		{`size:>`, 7, `missing size`},
+		{`size:abc`, 6, `invalid size "abc"`},
+		{`size:10xb`, 8, `unknown size unit "xb", expected b, kb, mb, gb or tb`},
+		{`size:>gigantic`, 7, `no size is larger than "gigantic"`},
+		{`size:<empty`, 7, `no size is smaller than "empty"`},
+		{`size:2mb..1mb`, 11, `size range ends before it starts`},
+		{`size:99999999tb`, 6, `size "99999999tb" is too large`},
+		{`modified:`, 10, `missing value of modified:`},
+		{`modified:2024-13`, 10, `invalid date "2024-13", expected YYYY-MM`},
+		{`modified:tomorrow`, 10, `invalid date "tomorrow", expected YYYY-MM-DD, YYYY-MM, YYYY or a named date like lastweek`},
+		{`modified:2024-02..2024-01`, 19, `date range ends before it starts`},
+		{`meta.author:`, 13, `missing value of meta.author:`},
+		{`meta.author:""`, 13, `missing value of meta.author:`},
+		// Columns are counted in characters
+		{`été (`, 6, `expected a term, found end of query`},
+	} {
+		_, err := Parse(test.query, testNow)
+		var parseErr *Error
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Parse(%q) returned %v, want an *Error", test.query, err)
+			continue
+		}
+		if parseErr.Column != test.column || parseErr.Msg != test.msg {
+			t.Errorf("Parse(%q) failed at column %d with %q, want column %d with %q",
+				test.query, parseErr.Column, parseErr.Msg, test.column, test.msg)
+		}
+	}
+}
@@ -0,0 +1,443 @@
+package memdb
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"training/db/filequery"
+	db "training/db/sqlc"
+)
+
+// fileTable holds the files by path with secondary indexes on extension,
+// size and modification time
+type fileTable struct {
+	files       map[string]*db.File
+	nextID      int32
+	byExtension map[string]map[string]struct{}
+	bySize      sortedIndex
+	byModified  sortedIndex
+}
+
+func newFileTable() fileTable {
+	return fileTable{
+		files:       make(map[string]*db.File),
+		byExtension: make(map[string]map[string]struct{}),
+	}
+}
+
+// insert adds a file, an existing file at the same path is replaced if
+// update is set. sql.ErrNoRows is returned like ON CONFLICT DO NOTHING does.
+func (table *fileTable) insert(arg db.UpsertFileParams, update bool) (db.File, error) {
+	old, exists := table.files[arg.Path]
+	if exists && !update {
+		return db.File{}, sql.ErrNoRows
+	}
+	file := &db.File{
+		Name:        arg.Name,
+		Extension:   arg.Extension,
+		Size:        arg.Size,
+		Path:        arg.Path,
+		CreatedAt:   arg.CreatedAt,
+		ModifiedAt:  arg.ModifiedAt,
+		AccessedAt:  arg.AccessedAt,
+		Attributes:  arg.Attributes,
+		Content:     arg.Content,
+		PartialHash: arg.PartialHash,
+		ContentHash: arg.ContentHash,
+		Metadata:    arg.Metadata,
+	}
+	if exists {
+		file.ID = old.ID
+		table.unindex(old)
+	} else {
+		table.nextID++
+		file.ID = table.nextID
+	}
+	table.files[file.Path] = file
+	table.index(file)
+	return *file, nil
+}
+
+// updateHashes sets the hashes of the file at arg.Path and returns the
+// number of updated files
+func (table *fileTable) updateHashes(arg db.UpdateFileHashesParams) int64 {
+	file, ok := table.files[arg.Path]
+	if !ok {
+		return 0
+	}
+	file.PartialHash = arg.PartialHash
+	file.ContentHash = arg.ContentHash
+	return 1
+}
+
+func (table *fileTable) delete(path string) int64 {
+	file, ok := table.files[path]
+	if !ok {
+		return 0
+	}
+	table.unindex(file)
+	delete(table.files, path)
+	return 1
+}
+
+func (table *fileTable) index(file *db.File) {
+	paths, ok := table.byExtension[file.Extension]
+	if !ok {
+		paths = make(map[string]struct{})
+		table.byExtension[file.Extension] = paths
+	}
+	paths[file.Path] = struct{}{}
+	table.bySize.add(file.Size, file.Path)
+	table.byModified.add(timeKey(file.ModifiedAt), file.Path)
+}
+
+func (table *fileTable) unindex(file *db.File) {
+	paths := table.byExtension[file.Extension]
+	delete(paths, file.Path)
+	if len(paths) == 0 {
+		delete(table.byExtension, file.Extension)
+	}
+	table.bySize.remove(file.Size, file.Path)
+	table.byModified.remove(timeKey(file.ModifiedAt), file.Path)
+}
+
+// candidates returns the paths of the smallest index selection that can
+// match arg, every candidate still has to be checked against all filters
+func (table *fileTable) candidates(arg db.GetFilesParams) []string {
+	if arg.Extension != "" {
+		paths := make([]string, 0, len(table.byExtension[arg.Extension]))
+		for path := range table.byExtension[arg.Extension] {
+			paths = append(paths, path)
+		}
+		return paths
+	}
+	entries := table.bySize.between(arg.SizeMin, arg.SizeMax)
+	if byModified := table.byModified.between(timeKey(arg.ModifiedAfter), timeKey(arg.ModifiedBefore)); len(byModified) < len(entries) {
+		entries = byModified
+	}
+	paths := make([]string, len(entries))
+	for i, entry := range entries {
+		paths[i] = entry.path
+	}
+	return paths
+}
+
+// timeKey returns t in nanoseconds for the modification time index, times
+// outside of the range of int64 nanoseconds are clamped
+func timeKey(t time.Time) int64 {
+	switch {
+	case t.Before(minIndexTime):
+		return math.MinInt64
+	case t.After(maxIndexTime):
+		return math.MaxInt64
+	}
+	return t.UnixNano()
+}
+
+var (
+	minIndexTime = time.Unix(0, math.MinInt64)
+	maxIndexTime = time.Unix(0, math.MaxInt64)
+)
+
+func matchFile(file *db.File, arg db.GetFilesParams) bool {
+	// An empty extension matches every file
+	if arg.Extension != "" && file.Extension != arg.Extension {
+		return false
+	}
+	if file.Size < arg.SizeMin || file.Size > arg.SizeMax {
+		return false
+	}
+	if file.CreatedAt.Before(arg.CreatedAfter) || file.CreatedAt.After(arg.CreatedBefore) ||
+		file.ModifiedAt.Before(arg.ModifiedAfter) || file.ModifiedAt.After(arg.ModifiedBefore) ||
+		file.AccessedAt.Before(arg.AccessedAfter) || file.AccessedAt.After(arg.AccessedBefore) {
+		return false
+	}
+	if arg.Metadata != "" && !db.MetadataContains(string(file.Metadata), arg.Metadata) {
+		return false
+	}
+	return true
+}
+
+// containsFold is ILIKE '%' || substr || '%'
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// nameFilter returns the filename search of arg, it reports whether a file
+// matches and its similarity for a fuzzy search
+func nameFilter(arg db.GetFilesParams) (func(file *db.File) (float32, bool), error) {
+	name := arg.Name
+	if name == "" {
+		return func(*db.File) (float32, bool) { return 0, true }, nil
+	}
+	switch arg.NameMatch {
+	case db.MatchSubstring:
+		return func(file *db.File) (float32, bool) { return 0, containsFold(file.Name, name) }, nil
+	case db.MatchExact:
+		return func(file *db.File) (float32, bool) { return 0, file.Name == name }, nil
+	case db.MatchRegex:
+		// ~* matches regardless of case
+		pattern, err := regexp.Compile("(?i)" + name)
+		if err != nil {
+			return nil, err
+		}
+		return func(file *db.File) (float32, bool) { return 0, pattern.MatchString(file.Name) }, nil
+	case db.MatchFuzzy:
+		return func(file *db.File) (float32, bool) {
+			similarity := max(db.WordSimilarity(name, file.Name), db.WordSimilarity(name, file.Path))
+			return similarity, similarity >= arg.Threshold
+		}, nil
+	}
+	return func(*db.File) (float32, bool) { return 0, false }, nil
+}
+
+// searchFiles returns the files matching the filters of arg unsorted. The
+// content search is a db.TextQuery matched without stemming.
+func (store *Store) searchFiles(arg db.GetFilesParams) ([]db.GetFilesRow, error) {
+	matchName, err := nameFilter(arg)
+	if err != nil {
+		return nil, err
+	}
+	var query *db.TextQuery
+	if arg.Content != "" {
+		if query, err = db.ParseTextQuery(arg.Content); err != nil {
+			return nil, err
+		}
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	rows := []db.GetFilesRow{}
+	for _, path := range store.files.candidates(arg) {
+		file := store.files.files[path]
+		if !matchFile(file, arg) {
+			continue
+		}
+		similarity, ok := matchName(file)
+		if !ok {
+			continue
+		}
+		row := db.GetFilesRow{
+			Path:       file.Path,
+			Name:       file.Name,
+			Extension:  file.Extension,
+			Size:       file.Size,
+			CreatedAt:  file.CreatedAt,
+			ModifiedAt: file.ModifiedAt,
+			AccessedAt: file.AccessedAt,
+			Attributes: file.Attributes,
+			Metadata:   file.Metadata,
+			Similarity: similarity,
+		}
+		if query != nil {
+			if row.Rank, row.Snippet, ok = query.Search(file.Content); !ok {
+				continue
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// compareFiles compares the sort keys of a and b in sortOrder, files with
+// the same key are compared by path ascending
+func compareFiles(a, b *db.GetFilesRow, sortBy, sortOrder string) int {
+	order := 0
+	switch sortBy {
+	case db.SortByName:
+		order = strings.Compare(a.Name, b.Name)
+	case db.SortBySize:
+		order = cmp.Compare(a.Size, b.Size)
+	case db.SortByModifiedAt:
+		order = a.ModifiedAt.Compare(b.ModifiedAt)
+	case db.SortByCreatedAt:
+		order = a.CreatedAt.Compare(b.CreatedAt)
+	case db.SortByPath:
+		order = strings.Compare(a.Path, b.Path)
+	case db.SortByRelevance:
+		if order = cmp.Compare(a.Rank, b.Rank); order == 0 {
+			order = cmp.Compare(a.Similarity, b.Similarity)
+		}
+	}
+	if sortOrder == db.SortDesc {
+		order = -order
+	}
+	if order == 0 {
+		order = strings.Compare(a.Path, b.Path)
+	}
+	return order
+}
+
+// GetFiles returns the files matching arg sorted by arg.SortBy and
+// arg.SortOrder. The page starts after the file at arg.AfterPath if it is
+// set. A limit of 0 returns all files.
+func (store *Store) GetFiles(ctx context.Context, arg db.GetFilesParams) ([]db.GetFilesRow, error) {
+	rows, err := store.searchFiles(arg)
+	if err != nil {
+		return nil, err
+	}
+	return pageFiles(rows, arg), nil
+}
+
+// pageFiles sorts rows and returns the page of arg
+func pageFiles(rows []db.GetFilesRow, arg db.GetFilesParams) []db.GetFilesRow {
+	sort.Slice(rows, func(i, j int) bool {
+		return compareFiles(&rows[i], &rows[j], arg.SortBy, arg.SortOrder) < 0
+	})
+
+	if arg.AfterPath != "" {
+		after := db.GetFilesRow{
+			Path:       arg.AfterPath,
+			Name:       arg.AfterText,
+			Size:       arg.AfterSize,
+			ModifiedAt: arg.AfterTime,
+			CreatedAt:  arg.AfterTime,
+			Rank:       arg.AfterRank,
+			Similarity: arg.AfterSimilarity,
+		}
+		start := sort.Search(len(rows), func(i int) bool {
+			return compareFiles(&rows[i], &after, arg.SortBy, arg.SortOrder) > 0
+		})
+		rows = rows[start:]
+	}
+
+	offset := min(int(max(arg.PageOffset, 0)), len(rows))
+	rows = rows[offset:]
+	if limit := int(arg.PageLimit); limit > 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// CountFiles counts the files of GetFiles up to arg.CountLimit
+func (store *Store) CountFiles(ctx context.Context, arg db.CountFilesParams) (int64, error) {
+	rows, err := store.searchFiles(db.GetFilesParams{
+		Content:        arg.Content,
+		NameMatch:      arg.NameMatch,
+		Name:           arg.Name,
+		Threshold:      arg.Threshold,
+		Extension:      arg.Extension,
+		SizeMin:        arg.SizeMin,
+		SizeMax:        arg.SizeMax,
+		CreatedAfter:   arg.CreatedAfter,
+		CreatedBefore:  arg.CreatedBefore,
+		ModifiedAfter:  arg.ModifiedAfter,
+		ModifiedBefore: arg.ModifiedBefore,
+		AccessedAfter:  arg.AccessedAfter,
+		AccessedBefore: arg.AccessedBefore,
+		Metadata:       arg.Metadata,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return min(int64(len(rows)), arg.CountLimit), nil
+}
+
+// queryFiles returns the files matching a filequery query unsorted
+func (store *Store) queryFiles(query filequery.Node) []db.GetFilesRow {
+	match := filequery.Matcher(query)
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	rows := []db.GetFilesRow{}
+	for _, file := range store.files.files {
+		if !match(&filequery.File{
+			Name:       file.Name,
+			Extension:  file.Extension,
+			Path:       file.Path,
+			Size:       file.Size,
+			CreatedAt:  file.CreatedAt,
+			ModifiedAt: file.ModifiedAt,
+			AccessedAt: file.AccessedAt,
+			Metadata:   db.MetadataValues(file.Metadata),
+		}) {
+			continue
+		}
+		rows = append(rows, db.GetFilesRow{
+			Path:       file.Path,
+			Name:       file.Name,
+			Extension:  file.Extension,
+			Size:       file.Size,
+			CreatedAt:  file.CreatedAt,
+			ModifiedAt: file.ModifiedAt,
+			AccessedAt: file.AccessedAt,
+			Attributes: file.Attributes,
+			Metadata:   file.Metadata,
+		})
+	}
+	return rows
+}
+
+// QueryFiles returns the files matching a filequery query sorted and paged
+// like GetFiles
+func (store *Store) QueryFiles(ctx context.Context, arg db.QueryFilesParams) ([]db.GetFilesRow, error) {
+	return pageFiles(store.queryFiles(arg.Query), db.GetFilesParams{
+		SortBy:     arg.SortBy,
+		SortOrder:  arg.SortOrder,
+		AfterPath:  arg.AfterPath,
+		AfterText:  arg.AfterText,
+		AfterSize:  arg.AfterSize,
+		AfterTime:  arg.AfterTime,
+		PageOffset: arg.PageOffset,
+		PageLimit:  arg.PageLimit,
+	}), nil
+}
+
+// CountQueryFiles counts the files of QueryFiles up to limit
+func (store *Store) CountQueryFiles(ctx context.Context, query filequery.Node, limit int64) (int64, error) {
+	return min(int64(len(store.queryFiles(query))), limit), nil
+}
+
+// InsertFile stores a new file, sql.ErrNoRows is returned if the path is already stored
+func (store *Store) InsertFile(ctx context.Context, arg db.InsertFileParams) (db.File, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.files.insert(db.UpsertFileParams(arg), false)
+}
+
+// UpsertFile stores a new file or replaces the file stored at the same path
+func (store *Store) UpsertFile(ctx context.Context, arg db.UpsertFileParams) (db.File, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.files.insert(arg, true)
+}
+
+// UpdateFileHashes stores the hashes computed for the duplicate search
+func (store *Store) UpdateFileHashes(ctx context.Context, arg db.UpdateFileHashesParams) (int64, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.files.updateHashes(arg), nil
+}
+
+// DeleteFileByPath deletes the file stored at path and returns the number of deleted files
+func (store *Store) DeleteFileByPath(ctx context.Context, path string) (int64, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.files.delete(path), nil
+}
+
+// ListFilesByPrefix returns the files whose path starts with prefix ordered by path
+func (store *Store) ListFilesByPrefix(ctx context.Context, prefix string) ([]db.File, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	files := []db.File{}
+	for path, file := range store.files.files {
+		if strings.HasPrefix(path, prefix) {
+			files = append(files, *file)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
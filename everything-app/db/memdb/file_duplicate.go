@@ -0,0 +1,99 @@
+package memdb
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	db "training/db/sqlc"
+)
+
+// duplicateKey identifies a group of identical files
+type duplicateKey struct {
+	hash string
+	size int64
+}
+
+// duplicateGroup is a group of files with the same content hash and size
+type duplicateGroup struct {
+	duplicateKey
+	files []*db.File
+}
+
+func (group *duplicateGroup) wasted() int64 {
+	return int64(len(group.files)-1) * group.size
+}
+
+// GetDuplicateFiles returns the files of the arg.GroupLimit groups of
+// duplicate files wasting the most bytes
+func (store *Store) GetDuplicateFiles(ctx context.Context, arg db.GetDuplicateFilesParams) ([]db.GetDuplicateFilesRow, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	groups := store.files.duplicates(arg.Prefix, arg.MinSize)
+	if len(groups) > int(max(arg.GroupLimit, 0)) {
+		groups = groups[:max(arg.GroupLimit, 0)]
+	}
+	rows := []db.GetDuplicateFilesRow{}
+	for _, group := range groups {
+		slices.SortFunc(group.files, func(a, b *db.File) int { return cmp.Compare(a.Path, b.Path) })
+		for _, file := range group.files {
+			rows = append(rows, db.GetDuplicateFilesRow{
+				Path:        file.Path,
+				Name:        file.Name,
+				ModifiedAt:  file.ModifiedAt,
+				ContentHash: group.hash,
+				Size:        group.size,
+				FileCount:   int64(len(group.files)),
+				Wasted:      group.wasted(),
+			})
+		}
+	}
+	return rows, nil
+}
+
+// GetDuplicateTotals counts all the groups of duplicate files
+func (store *Store) GetDuplicateTotals(ctx context.Context, arg db.GetDuplicateTotalsParams) (db.GetDuplicateTotalsRow, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var totals db.GetDuplicateTotalsRow
+	for _, group := range store.files.duplicates(arg.Prefix, arg.MinSize) {
+		totals.GroupCount++
+		totals.FileCount += int64(len(group.files))
+		totals.Wasted += group.wasted()
+	}
+	return totals, nil
+}
+
+// duplicates returns the groups of at least two hashed files below prefix of
+// at least minSize bytes, ordered by wasted bytes then hash
+func (table *fileTable) duplicates(prefix string, minSize int64) []*duplicateGroup {
+	byKey := make(map[duplicateKey]*duplicateGroup)
+	for path, file := range table.files {
+		if file.ContentHash == "" || file.Size < minSize || !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		key := duplicateKey{hash: file.ContentHash, size: file.Size}
+		group, ok := byKey[key]
+		if !ok {
+			group = &duplicateGroup{duplicateKey: key}
+			byKey[key] = group
+		}
+		group.files = append(group.files, file)
+	}
+
+	groups := []*duplicateGroup{}
+	for _, group := range byKey {
+		if len(group.files) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	slices.SortFunc(groups, func(a, b *duplicateGroup) int {
+		if c := cmp.Compare(b.wasted(), a.wasted()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.hash, b.hash)
+	})
+	return groups
+}
@@ -0,0 +1,138 @@
+package memdb
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"training/db/filequery"
+	db "training/db/sqlc"
+)
+
+// GetFileFacets counts the files of GetFiles by facet
+func (store *Store) GetFileFacets(ctx context.Context, arg db.GetFileFacetsParams) ([]db.GetFileFacetsRow, error) {
+	rows, err := store.searchFiles(db.GetFilesParams{
+		Content:        arg.Content,
+		NameMatch:      arg.NameMatch,
+		Name:           arg.Name,
+		Threshold:      arg.Threshold,
+		Extension:      arg.Extension,
+		SizeMin:        arg.SizeMin,
+		SizeMax:        arg.SizeMax,
+		CreatedAfter:   arg.CreatedAfter,
+		CreatedBefore:  arg.CreatedBefore,
+		ModifiedAfter:  arg.ModifiedAfter,
+		ModifiedBefore: arg.ModifiedBefore,
+		AccessedAfter:  arg.AccessedAfter,
+		AccessedBefore: arg.AccessedBefore,
+		Metadata:       arg.Metadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fileFacets(rows, arg.TopLimit), nil
+}
+
+// GetLargestFiles returns the arg.TopLimit largest files of GetFiles
+func (store *Store) GetLargestFiles(ctx context.Context, arg db.GetLargestFilesParams) ([]db.GetLargestFilesRow, error) {
+	rows, err := store.searchFiles(db.GetFilesParams{
+		Content:        arg.Content,
+		NameMatch:      arg.NameMatch,
+		Name:           arg.Name,
+		Threshold:      arg.Threshold,
+		Extension:      arg.Extension,
+		SizeMin:        arg.SizeMin,
+		SizeMax:        arg.SizeMax,
+		CreatedAfter:   arg.CreatedAfter,
+		CreatedBefore:  arg.CreatedBefore,
+		ModifiedAfter:  arg.ModifiedAfter,
+		ModifiedBefore: arg.ModifiedBefore,
+		AccessedAfter:  arg.AccessedAfter,
+		AccessedBefore: arg.AccessedBefore,
+		Metadata:       arg.Metadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return largestFiles(rows, arg.TopLimit), nil
+}
+
+// QueryFileFacets counts the files matching a filequery query by facet
+func (store *Store) QueryFileFacets(ctx context.Context, query filequery.Node, topLimit int32) ([]db.GetFileFacetsRow, error) {
+	return fileFacets(store.queryFiles(query), topLimit), nil
+}
+
+// QueryLargestFiles returns the topLimit largest files matching a filequery
+// query
+func (store *Store) QueryLargestFiles(ctx context.Context, query filequery.Node, topLimit int32) ([]db.GetLargestFilesRow, error) {
+	return largestFiles(store.queryFiles(query), topLimit), nil
+}
+
+// fileFacets groups rows like GetFileFacets does
+func fileFacets(rows []db.GetFilesRow, topLimit int32) []db.GetFileFacetsRow {
+	groups := map[[2]string]*db.GetFileFacetsRow{}
+	add := func(facet, value string, size int64) {
+		group, ok := groups[[2]string{facet, value}]
+		if !ok {
+			group = &db.GetFileFacetsRow{Facet: facet, Value: value}
+			groups[[2]string{facet, value}] = group
+		}
+		group.Files++
+		group.Size += size
+	}
+	for _, row := range rows {
+		add(db.FacetExtension, row.Extension, row.Size)
+		add(db.FacetDirectory, db.TopDirectory(row.Path), row.Size)
+		add(db.FacetSize, filequery.SizeName(row.Size), row.Size)
+		add(db.FacetMonth, db.UTCMonth(row.ModifiedAt), row.Size)
+		add(db.FacetLargestDirectory, db.ParentDirectory(row.Path), row.Size)
+	}
+
+	facets := make([]db.GetFileFacetsRow, 0, len(groups))
+	for _, group := range groups {
+		facets = append(facets, *group)
+	}
+	slices.SortFunc(facets, func(a, b db.GetFileFacetsRow) int {
+		if c := cmp.Compare(a.Facet, b.Facet); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.Size, a.Size); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Value, b.Value)
+	})
+	// Only the topLimit largest directories are kept
+	result := facets[:0]
+	var directories int32
+	for _, facet := range facets {
+		if facet.Facet == db.FacetLargestDirectory {
+			if directories >= topLimit {
+				continue
+			}
+			directories++
+		}
+		result = append(result, facet)
+	}
+	return result
+}
+
+// largestFiles returns the topLimit largest files of rows, files of the same
+// size are ordered by path
+func largestFiles(rows []db.GetFilesRow, topLimit int32) []db.GetLargestFilesRow {
+	slices.SortFunc(rows, func(a, b db.GetFilesRow) int {
+		if c := cmp.Compare(b.Size, a.Size); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Path, b.Path)
+	})
+	files := []db.GetLargestFilesRow{}
+	for _, row := range rows[:min(len(rows), max(int(topLimit), 0))] {
+		files = append(files, db.GetLargestFilesRow{
+			Path:       row.Path,
+			Name:       row.Name,
+			Extension:  row.Extension,
+			Size:       row.Size,
+			ModifiedAt: row.ModifiedAt,
+		})
+	}
+	return files
+}
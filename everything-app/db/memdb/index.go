@@ -0,0 +1,48 @@
+package memdb
+
+import (
+	"sort"
+)
+
+type indexEntry struct {
+	key  int64
+	path string
+}
+
+// sortedIndex keeps paths ordered by an integer key for range queries
+type sortedIndex struct {
+	entries []indexEntry
+}
+
+func (index *sortedIndex) search(entry indexEntry) int {
+	return sort.Search(len(index.entries), func(i int) bool {
+		e := index.entries[i]
+		return e.key > entry.key || (e.key == entry.key && e.path >= entry.path)
+	})
+}
+
+func (index *sortedIndex) add(key int64, path string) {
+	entry := indexEntry{key: key, path: path}
+	i := index.search(entry)
+	index.entries = append(index.entries, indexEntry{})
+	copy(index.entries[i+1:], index.entries[i:])
+	index.entries[i] = entry
+}
+
+func (index *sortedIndex) remove(key int64, path string) {
+	entry := indexEntry{key: key, path: path}
+	i := index.search(entry)
+	if i < len(index.entries) && index.entries[i] == entry {
+		index.entries = append(index.entries[:i], index.entries[i+1:]...)
+	}
+}
+
+// between returns the entries with min <= key <= max
+func (index *sortedIndex) between(min, max int64) []indexEntry {
+	start := sort.Search(len(index.entries), func(i int) bool { return index.entries[i].key >= min })
+	end := sort.Search(len(index.entries), func(i int) bool { return index.entries[i].key > max })
+	if start >= end {
+		return nil
+	}
+	return index.entries[start:end]
+}
@@ -0,0 +1,261 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	db "training/db/sqlc"
+)
+
+// savedSearchTable holds the saved_search and notification tables by id,
+// and the single row of watch_resume_token
+type savedSearchTable struct {
+	searches           map[int32]*db.SavedSearch
+	nextSearchID       int32
+	notifications      map[int32]*db.Notification
+	nextNotificationID int32
+	resumeToken        *db.WatchResumeToken
+}
+
+func newSavedSearchTable() savedSearchTable {
+	return savedSearchTable{
+		searches:      make(map[int32]*db.SavedSearch),
+		notifications: make(map[int32]*db.Notification),
+	}
+}
+
+// nameTaken reports whether owner has another search named name
+func (table *savedSearchTable) nameTaken(id int32, owner, name string) bool {
+	for _, search := range table.searches {
+		if search.ID != id && search.Owner == owner && search.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedSearches returns the searches accepted by keep sorted by less
+func (table *savedSearchTable) sortedSearches(keep func(*db.SavedSearch) bool, less func(a, b *db.SavedSearch) bool) []db.SavedSearch {
+	var matches []*db.SavedSearch
+	for _, search := range table.searches {
+		if keep(search) {
+			matches = append(matches, search)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return less(matches[i], matches[j]) })
+	searches := make([]db.SavedSearch, len(matches))
+	for i, search := range matches {
+		searches[i] = *search
+	}
+	return searches
+}
+
+// foreignKeyViolation returns the error reported for a missing referenced row
+func foreignKeyViolation(constraint string) error {
+	return fmt.Errorf("%w: insert or update violates foreign key constraint %q", db.ErrForeignKeyViolation, constraint)
+}
+
+// CreateSavedSearch stores a new search of an existing user, the name is
+// unique for the user
+func (store *Store) CreateSavedSearch(ctx context.Context, arg db.CreateSavedSearchParams) (db.SavedSearch, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, ok := store.users.users[arg.Owner]; !ok {
+		return db.SavedSearch{}, foreignKeyViolation("saved_search_owner_fkey")
+	}
+	if store.searches.nameTaken(0, arg.Owner, arg.Name) {
+		return db.SavedSearch{}, uniqueViolation("saved_search_owner_name_key")
+	}
+	store.searches.nextSearchID++
+	search := &db.SavedSearch{
+		ID:        store.searches.nextSearchID,
+		Owner:     arg.Owner,
+		Name:      arg.Name,
+		Query:     arg.Query,
+		Watch:     arg.Watch,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.CreatedAt,
+	}
+	store.searches.searches[search.ID] = search
+	return *search, nil
+}
+
+// GetSavedSearch returns the search with the given id
+func (store *Store) GetSavedSearch(ctx context.Context, id int32) (db.SavedSearch, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	search, ok := store.searches.searches[id]
+	if !ok {
+		return db.SavedSearch{}, sql.ErrNoRows
+	}
+	return *search, nil
+}
+
+// ListSavedSearches returns the searches of owner ordered by name
+func (store *Store) ListSavedSearches(ctx context.Context, owner string) ([]db.SavedSearch, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return store.searches.sortedSearches(
+		func(search *db.SavedSearch) bool { return search.Owner == owner },
+		func(a, b *db.SavedSearch) bool { return a.Name < b.Name },
+	), nil
+}
+
+// ListWatchedSearches returns the watched searches ordered by id
+func (store *Store) ListWatchedSearches(ctx context.Context) ([]db.SavedSearch, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return store.searches.sortedSearches(
+		func(search *db.SavedSearch) bool { return search.Watch },
+		func(a, b *db.SavedSearch) bool { return a.ID < b.ID },
+	), nil
+}
+
+// UpdateSavedSearch replaces the name, query and watch of a search
+func (store *Store) UpdateSavedSearch(ctx context.Context, arg db.UpdateSavedSearchParams) (db.SavedSearch, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	search, ok := store.searches.searches[arg.ID]
+	if !ok {
+		return db.SavedSearch{}, sql.ErrNoRows
+	}
+	if store.searches.nameTaken(search.ID, search.Owner, arg.Name) {
+		return db.SavedSearch{}, uniqueViolation("saved_search_owner_name_key")
+	}
+	search.Name = arg.Name
+	search.Query = arg.Query
+	search.Watch = arg.Watch
+	search.UpdatedAt = arg.UpdatedAt
+	return *search, nil
+}
+
+// DeleteSavedSearch deletes a search and its notifications, it returns the
+// number of deleted searches
+func (store *Store) DeleteSavedSearch(ctx context.Context, id int32) (int64, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, ok := store.searches.searches[id]; !ok {
+		return 0, nil
+	}
+	delete(store.searches.searches, id)
+	for notificationID, notification := range store.searches.notifications {
+		if notification.SavedSearchID == id {
+			delete(store.searches.notifications, notificationID)
+		}
+	}
+	return 1, nil
+}
+
+// CreateNotification stores a notification of an existing search, due at
+// its creation
+func (store *Store) CreateNotification(ctx context.Context, arg db.CreateNotificationParams) (db.Notification, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, ok := store.searches.searches[arg.SavedSearchID]; !ok {
+		return db.Notification{}, foreignKeyViolation("notification_saved_search_id_fkey")
+	}
+	store.searches.nextNotificationID++
+	notification := &db.Notification{
+		ID:            store.searches.nextNotificationID,
+		SavedSearchID: arg.SavedSearchID,
+		Path:          arg.Path,
+		ChangeType:    arg.ChangeType,
+		CreatedAt:     arg.CreatedAt,
+		NextAttemptAt: arg.CreatedAt,
+	}
+	store.searches.notifications[notification.ID] = notification
+	return *notification, nil
+}
+
+// ListNotifications returns the newest notifications of a search first
+func (store *Store) ListNotifications(ctx context.Context, arg db.ListNotificationsParams) ([]db.Notification, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	notifications := []db.Notification{}
+	for _, notification := range store.searches.notifications {
+		if notification.SavedSearchID == arg.SavedSearchID {
+			notifications = append(notifications, *notification)
+		}
+	}
+	sort.Slice(notifications, func(i, j int) bool { return notifications[i].ID > notifications[j].ID })
+	if limit := int(arg.Limit); limit >= 0 && limit < len(notifications) {
+		notifications = notifications[:limit]
+	}
+	return notifications, nil
+}
+
+// ListDueNotifications returns the undelivered notifications with attempts
+// left that are due at arg.Now, the longest due first
+func (store *Store) ListDueNotifications(ctx context.Context, arg db.ListDueNotificationsParams) ([]db.ListDueNotificationsRow, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	rows := []db.ListDueNotificationsRow{}
+	for _, notification := range store.searches.notifications {
+		if !notification.DeliveredAt.IsZero() || notification.Attempts >= arg.MaxAttempts || notification.NextAttemptAt.After(arg.Now) {
+			continue
+		}
+		search := store.searches.searches[notification.SavedSearchID]
+		rows = append(rows, db.ListDueNotificationsRow{
+			Notification: *notification,
+			Owner:        search.Owner,
+			Name:         search.Name,
+			Query:        search.Query,
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Notification.NextAttemptAt.Before(rows[j].Notification.NextAttemptAt)
+	})
+	if limit := int(arg.DueLimit); limit >= 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows, nil
+}
+
+// UpdateNotificationAttempt records an attempt to deliver a notification
+func (store *Store) UpdateNotificationAttempt(ctx context.Context, arg db.UpdateNotificationAttemptParams) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if notification, ok := store.searches.notifications[arg.ID]; ok {
+		notification.Attempts++
+		notification.NextAttemptAt = arg.NextAttemptAt
+		notification.LastError = arg.LastError
+		notification.DeliveredAt = arg.DeliveredAt
+	}
+	return nil
+}
+
+// GetWatchResumeToken returns the saved resume token, sql.ErrNoRows if none
+// was saved
+func (store *Store) GetWatchResumeToken(ctx context.Context) (string, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	if store.searches.resumeToken == nil {
+		return "", sql.ErrNoRows
+	}
+	return store.searches.resumeToken.Token, nil
+}
+
+// SetWatchResumeToken saves the resume token, replacing the previous one
+func (store *Store) SetWatchResumeToken(ctx context.Context, arg db.SetWatchResumeTokenParams) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.searches.resumeToken = &db.WatchResumeToken{
+		ID:        1,
+		Token:     arg.Token,
+		UpdatedAt: arg.UpdatedAt,
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Package memdb implements db.Store in memory, so the indexer and the search
+// API run and can be tested without a database
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	db "training/db/sqlc"
+)
+
+// Store keeps the file, users and saved search tables in maps. Queries
+// behave like their Postgres versions in db/query.
+type Store struct {
+	mutex    sync.RWMutex
+	files    fileTable
+	users    userTable
+	searches savedSearchTable
+}
+
+var _ db.Store = (*Store)(nil)
+
+// NewStore returns an empty Store
+func NewStore() *Store {
+	return &Store{
+		files:    newFileTable(),
+		users:    newUserTable(),
+		searches: newSavedSearchTable(),
+	}
+}
+
+// FileBatchTx applies ops in order. The store is locked while the batch is
+// applied, so other queries see all of its writes or none.
+func (store *Store) FileBatchTx(ctx context.Context, ops []db.FileOp) (db.FileBatchTxResult, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	var result db.FileBatchTxResult
+	for i, op := range ops {
+		switch {
+		case op.Insert != nil:
+			if _, err := store.files.insert(db.UpsertFileParams(*op.Insert), false); errors.Is(err, sql.ErrNoRows) {
+				result.Existing = append(result.Existing, i)
+			}
+		case op.Upsert != nil:
+			store.files.insert(*op.Upsert, true)
+		case op.Hashes != nil:
+			store.files.updateHashes(*op.Hashes)
+		default:
+			result.Deleted += store.files.delete(op.Delete)
+		}
+	}
+	return result, nil
+}
+
+// uniqueViolation returns the error reported for a duplicate key
+func uniqueViolation(constraint string) error {
+	return fmt.Errorf("%w: duplicate key value violates unique constraint %q", db.ErrUniqueViolation, constraint)
+}
+
+func now() time.Time {
+	return time.Now().UTC()
+}
@@ -0,0 +1,166 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	db "training/db/sqlc"
+)
+
+// userTable holds the users by username, the primary key of the users table
+type userTable struct {
+	users  map[string]*db.User
+	nextID int32
+}
+
+func newUserTable() userTable {
+	return userTable{
+		users: make(map[string]*db.User),
+	}
+}
+
+func (table *userTable) byID(id int32) (*db.User, bool) {
+	for _, user := range table.users {
+		if user.ID == id {
+			return user, true
+		}
+	}
+	return nil, false
+}
+
+// CreateUser stores a new user, a unique violation is returned if the username is taken
+func (store *Store) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, exists := store.users.users[arg.Username]; exists {
+		return db.User{}, uniqueViolation("users_pkey")
+	}
+	store.users.nextID++
+	user := &db.User{
+		ID:           store.users.nextID,
+		Email:        arg.Email,
+		Username:     arg.Username,
+		Password:     arg.Password,
+		PasswordHash: arg.PasswordHash,
+		Phone:        arg.Phone,
+		Fullname:     arg.Fullname,
+		Avatar:       arg.Avatar,
+		State:        arg.State,
+		Role:         arg.Role,
+		CreatedAt:    arg.CreatedAt,
+		UpdateAt:     arg.UpdateAt,
+	}
+	store.users.users[user.Username] = user
+	return *user, nil
+}
+
+// DeleteUser sets the state of the user with the given id
+func (store *Store) DeleteUser(ctx context.Context, arg db.DeleteUserParams) (db.User, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	user, ok := store.users.byID(arg.ID)
+	if !ok {
+		return db.User{}, sql.ErrNoRows
+	}
+	user.State = arg.State
+	return *user, nil
+}
+
+// GetUserById returns the user with the given id
+func (store *Store) GetUserById(ctx context.Context, id int32) (db.User, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	user, ok := store.users.byID(id)
+	if !ok {
+		return db.User{}, sql.ErrNoRows
+	}
+	return *user, nil
+}
+
+// GetUserByUsername returns the user with the given username
+func (store *Store) GetUserByUsername(ctx context.Context, username string) (db.User, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	user, ok := store.users.users[username]
+	if !ok {
+		return db.User{}, sql.ErrNoRows
+	}
+	return *user, nil
+}
+
+// GetUsersAsc returns a page of the users matching the search term and state
+func (store *Store) GetUsersAsc(ctx context.Context, arg db.GetUsersAscParams) ([]db.User, error) {
+	return store.getUsers(arg.Column1, arg.State, arg.Column3, arg.Limit, arg.Offset, false), nil
+}
+
+// GetUsersDesc is GetUsersAsc in descending order
+func (store *Store) GetUsersDesc(ctx context.Context, arg db.GetUsersDescParams) ([]db.User, error) {
+	return store.getUsers(arg.Column1, arg.State, arg.Column3, arg.Limit, arg.Offset, true), nil
+}
+
+func (store *Store) getUsers(search sql.NullString, state int64, orderBy interface{}, limit, offset int32, desc bool) []db.User {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	users := []db.User{}
+	for _, user := range store.users.users {
+		if user.State != state {
+			continue
+		}
+		if search.Valid && !containsFold(user.Username, search.String) && !containsFold(user.Fullname, search.String) {
+			continue
+		}
+		users = append(users, *user)
+	}
+
+	var column string
+	switch v := orderBy.(type) {
+	case string:
+		column = v
+	case sql.NullString:
+		column = v.String
+	}
+	less := func(a, b db.User) bool {
+		switch column {
+		case "username":
+			return a.Username < b.Username
+		case "fullname":
+			return a.Fullname < b.Fullname
+		}
+		return a.ID < b.ID
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if desc {
+			return less(users[j], users[i])
+		}
+		return less(users[i], users[j])
+	})
+
+	start := min(int(max(offset, 0)), len(users))
+	end := min(start+int(max(limit, 0)), len(users))
+	return users[start:end]
+}
+
+// UpdateUser overwrites the profile of the user with the given username
+func (store *Store) UpdateUser(ctx context.Context, arg db.UpdateUserParams) (db.User, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	user, ok := store.users.users[arg.Username]
+	if !ok {
+		return db.User{}, sql.ErrNoRows
+	}
+	user.Fullname = arg.Fullname
+	user.Email = arg.Email
+	user.Phone = arg.Phone
+	user.Password = arg.Password
+	user.Avatar = arg.Avatar
+	user.Role = arg.Role
+	user.PasswordHash = arg.PasswordHash
+	user.UpdateAt = now()
+	return *user, nil
+}
@@ -0,0 +1,5 @@
+ALTER TABLE "file" DROP CONSTRAINT IF EXISTS "file_path_key";
+ALTER TABLE "file" ALTER COLUMN "path" TYPE VARCHAR(255);
+ALTER TABLE "file" ALTER COLUMN "extension" TYPE VARCHAR(10);
+ALTER TABLE "file" ALTER COLUMN "name" TYPE VARCHAR(50);
+ALTER TABLE "file" ADD CONSTRAINT "file_name_key" UNIQUE ("name");
@@ -0,0 +1,6 @@
+-- Files are identified by their path, many files share a name
+ALTER TABLE "file" DROP CONSTRAINT IF EXISTS "file_name_key";
+ALTER TABLE "file" ALTER COLUMN "name" TYPE VARCHAR(255);
+ALTER TABLE "file" ALTER COLUMN "extension" TYPE VARCHAR(255);
+ALTER TABLE "file" ALTER COLUMN "path" TYPE VARCHAR(4096);
+ALTER TABLE "file" ADD CONSTRAINT "file_path_key" UNIQUE ("path");
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "file_content_search_idx";
@@ -0,0 +1,3 @@
+-- Full-text index of the file content, GetFiles matches and ranks content
+-- searches with the same to_tsvector expression so the index is used
+CREATE INDEX "file_content_search_idx" ON "file" USING GIN (to_tsvector('english', "content"));
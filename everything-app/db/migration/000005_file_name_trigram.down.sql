@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "file_path_trgm_idx";
+DROP INDEX IF EXISTS "file_name_trgm_idx";
@@ -0,0 +1,4 @@
+-- Trigram indexes for the substring, regex and fuzzy filename searches
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX "file_name_trgm_idx" ON "file" USING GIN ("name" gin_trgm_ops);
+CREATE INDEX "file_path_trgm_idx" ON "file" USING GIN ("path" gin_trgm_ops);
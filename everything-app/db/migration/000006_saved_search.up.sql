@@ -0,0 +1,30 @@
+-- Named searches of the users, a watched search notifies its owner of the
+-- files created or modified that match its query
+CREATE TABLE "saved_search" (
+  "id" SERIAL PRIMARY KEY NOT NULL,
+  "owner" varchar(30) NOT NULL REFERENCES "users" ("username") ON DELETE CASCADE,
+  "name" varchar(100) NOT NULL,
+  "query" text NOT NULL,
+  "watch" boolean NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  UNIQUE ("owner", "name")
+);
+
+-- A match of a watched search, delivered to the webhook until it succeeds
+-- or runs out of attempts. delivered_at is the zero time until delivered.
+CREATE TABLE "notification" (
+  "id" SERIAL PRIMARY KEY NOT NULL,
+  "saved_search_id" integer NOT NULL REFERENCES "saved_search" ("id") ON DELETE CASCADE,
+  "path" text NOT NULL,
+  "change_type" varchar(20) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "attempts" integer NOT NULL DEFAULT 0,
+  "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+  "last_error" text NOT NULL DEFAULT '',
+  "delivered_at" timestamptz NOT NULL DEFAULT ('0001-01-01 00:00:00Z')
+);
+
+CREATE INDEX "notification_saved_search_id_idx" ON "notification" ("saved_search_id");
+CREATE INDEX "notification_pending_idx" ON "notification" ("next_attempt_at")
+  WHERE "delivered_at" = '0001-01-01 00:00:00Z';
@@ -0,0 +1,4 @@
+DROP FUNCTION IF EXISTS utc_month(timestamptz);
+DROP FUNCTION IF EXISTS size_name(bigint);
+DROP FUNCTION IF EXISTS parent_directory(text);
+DROP FUNCTION IF EXISTS top_directory(text);
@@ -0,0 +1,26 @@
+-- Keys of the file facets of GetFileFacets, the SQLite store registers the
+-- same functions. Paths are absolute with / separators.
+CREATE FUNCTION top_directory(path text) RETURNS text
+  LANGUAGE sql IMMUTABLE STRICT
+  AS $$ SELECT CASE WHEN strpos(substr($1, 2), '/') = 0 THEN '/' ELSE substr($1, 1, strpos(substr($1, 2), '/')) END $$;
+
+CREATE FUNCTION parent_directory(path text) RETURNS text
+  LANGUAGE sql IMMUTABLE STRICT
+  AS $$ SELECT CASE WHEN strpos($1, '/') = 0 THEN $1 ELSE COALESCE(NULLIF(regexp_replace($1, '/[^/]*$', ''), ''), '/') END $$;
+
+-- The named sizes of the size: filter of the q query language
+CREATE FUNCTION size_name(size bigint) RETURNS text
+  LANGUAGE sql IMMUTABLE STRICT
+  AS $$ SELECT CASE
+    WHEN $1 <= 0 THEN 'empty'
+    WHEN $1 <= 10240 THEN 'tiny'
+    WHEN $1 <= 102400 THEN 'small'
+    WHEN $1 <= 1048576 THEN 'medium'
+    WHEN $1 <= 16777216 THEN 'large'
+    WHEN $1 <= 134217728 THEN 'huge'
+    ELSE 'gigantic'
+  END $$;
+
+CREATE FUNCTION utc_month(at timestamptz) RETURNS text
+  LANGUAGE sql STABLE STRICT
+  AS $$ SELECT to_char($1 AT TIME ZONE 'UTC', 'YYYY-MM') $$;
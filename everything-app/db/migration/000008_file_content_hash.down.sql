@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "file_content_hash_idx";
+ALTER TABLE "file" DROP COLUMN IF EXISTS "content_hash";
+ALTER TABLE "file" DROP COLUMN IF EXISTS "partial_hash";
@@ -0,0 +1,7 @@
+-- SHA-256 hashes of the file bytes for the duplicate search, empty until
+-- computed. The indexer hashes the first and last bytes of the files whose
+-- size is shared with another file, and all bytes of the files whose
+-- partial hash is shared too.
+ALTER TABLE "file" ADD COLUMN "partial_hash" varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE "file" ADD COLUMN "content_hash" varchar(64) NOT NULL DEFAULT '';
+CREATE INDEX "file_content_hash_idx" ON "file" ("content_hash") WHERE "content_hash" <> '';
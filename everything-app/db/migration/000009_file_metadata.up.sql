@@ -0,0 +1,3 @@
+-- Document and media properties read by the content extractors, such as
+-- the title, author and page count, as a JSON object of strings
+ALTER TABLE "file" ADD COLUMN "metadata" jsonb NOT NULL DEFAULT '{}';
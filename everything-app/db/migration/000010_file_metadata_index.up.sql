@@ -0,0 +1,3 @@
+-- The meta.<key>=value filters of the file search are containment queries,
+-- metadata @> '{"author": "..."}'
+CREATE INDEX "file_metadata_idx" ON "file" USING GIN ("metadata" jsonb_path_ops);
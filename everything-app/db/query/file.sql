@@ -1,31 +1,174 @@
 -- name: GetFiles :many
-SELECT 
-    path
-FROM 
-    file
-WHERE 
-    -- Filename fuzzy search (using LIKE for approximate matching)
-    (name ILIKE '%' || $1 || '%' OR $1 IS NULL)
-    
-    -- File extension exact match
-    AND (extension = $2 OR $2 IS NULL)
-    -- File size range search
-    AND (size >= $3 OR $3 IS NULL)
-    AND (size <= $4 OR $4 IS NULL)
-    -- File created_at range search
-    AND (created_at >= $5 OR $5 IS NULL)
-    AND (created_at <= $6 OR $6 IS NULL)
-    -- File modified_At range search
-    AND (modified_at >= $7 OR $7 IS NULL)
-    AND (modified_at <= $8 OR $8 IS NULL)
-    -- File accessed_at range search
-    AND (accessed_at >= $9 OR $9 IS NULL)
-    AND (accessed_at <= $10 OR $10 IS NULL)
-    -- -- File content search
-    AND (content ILIKE '%' || $11 || '%' OR $11 IS NULL)
-OFFSET $12
--- If limit is not provided, return all results
-LIMIT CASE WHEN $13 = 0 THEN NULL ELSE $12 END;
+-- name is searched according to name_match: substring, exact, regex or fuzzy.
+-- Fuzzy matches the name or the path with a trigram word similarity of at
+-- least threshold, pg_trgm.word_similarity_threshold has to be threshold as
+-- well for the trigram indexes to be used. content is a to_tsquery expression.
+-- Empty searches are skipped. Content hits are ranked by relevance and come
+-- with a highlighted snippet of the content.
+-- Files are sorted by sort_by in sort_order, then by path ascending. A page
+-- after a file starts after after_path and the sort key of the file, given
+-- in the after argument of its type. If limit is 0, return all results
+SELECT
+    path,
+    name,
+    extension,
+    size,
+    created_at,
+    modified_at,
+    accessed_at,
+    attributes,
+    metadata,
+    rank,
+    similarity,
+    CAST(CASE WHEN sqlc.arg(content)::text = '' THEN ''
+        ELSE ts_headline('english', content, to_tsquery('english', sqlc.arg(content)), 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5')
+    END AS text) AS snippet
+FROM (
+    SELECT
+        *
+    FROM (
+        SELECT
+            path,
+            name,
+            extension,
+            size,
+            created_at,
+            modified_at,
+            accessed_at,
+            attributes,
+            metadata,
+            content,
+            CAST(CASE WHEN sqlc.arg(content)::text = '' THEN 0
+                ELSE ts_rank_cd(to_tsvector('english', content), to_tsquery('english', sqlc.arg(content)), 32)
+            END AS real) AS rank,
+            CAST(CASE WHEN sqlc.arg(name_match)::text = 'fuzzy' AND sqlc.arg(name)::text <> ''
+                THEN GREATEST(word_similarity(sqlc.arg(name), name), word_similarity(sqlc.arg(name), path))
+                ELSE 0
+            END AS real) AS similarity
+        FROM
+            file
+        WHERE
+            -- Filename search
+            (sqlc.arg(name)::text = ''
+                OR (sqlc.arg(name_match)::text = 'substring' AND name ILIKE '%' || sqlc.arg(name) || '%')
+                OR (sqlc.arg(name_match)::text = 'exact' AND name = sqlc.arg(name))
+                OR (sqlc.arg(name_match)::text = 'regex' AND name ~* sqlc.arg(name))
+                OR (sqlc.arg(name_match)::text = 'fuzzy' AND (sqlc.arg(name) <% name OR sqlc.arg(name) <% path)
+                    AND GREATEST(word_similarity(sqlc.arg(name), name), word_similarity(sqlc.arg(name), path)) >= sqlc.arg(threshold)::real))
+            -- File extension exact match
+            AND (sqlc.arg(extension)::text = '' OR extension = sqlc.arg(extension))
+            -- File size range search
+            AND size >= sqlc.arg(size_min)
+            AND size <= sqlc.arg(size_max)
+            -- File created_at range search
+            AND created_at >= sqlc.arg(created_after)
+            AND created_at <= sqlc.arg(created_before)
+            -- File modified_At range search
+            AND modified_at >= sqlc.arg(modified_after)
+            AND modified_at <= sqlc.arg(modified_before)
+            -- File accessed_at range search
+            AND accessed_at >= sqlc.arg(accessed_after)
+            AND accessed_at <= sqlc.arg(accessed_before)
+            -- File content search through file_content_search_idx
+            AND (sqlc.arg(content)::text = '' OR to_tsvector('english', content) @@ to_tsquery('english', sqlc.arg(content)))
+            -- Metadata values through file_metadata_idx
+            AND (sqlc.arg(metadata)::text = '' OR metadata @> CAST(sqlc.arg(metadata)::text AS jsonb))
+    ) AS matches
+    WHERE
+        sqlc.arg(after_path)::text = ''
+        OR (sqlc.arg(sort_order)::text = 'asc' AND CASE sqlc.arg(sort_by)::text
+            WHEN 'name' THEN (name > sqlc.arg(after_text)::text OR (name = sqlc.arg(after_text)::text AND path > sqlc.arg(after_path)::text))
+            WHEN 'size' THEN (size > sqlc.arg(after_size)::bigint OR (size = sqlc.arg(after_size)::bigint AND path > sqlc.arg(after_path)::text))
+            WHEN 'modified_at' THEN (modified_at > sqlc.arg(after_time)::timestamptz OR (modified_at = sqlc.arg(after_time)::timestamptz AND path > sqlc.arg(after_path)::text))
+            WHEN 'created_at' THEN (created_at > sqlc.arg(after_time)::timestamptz OR (created_at = sqlc.arg(after_time)::timestamptz AND path > sqlc.arg(after_path)::text))
+            WHEN 'path' THEN path > sqlc.arg(after_path)::text
+            WHEN 'relevance' THEN (rank > sqlc.arg(after_rank)::real OR (rank = sqlc.arg(after_rank)::real
+                AND (similarity > sqlc.arg(after_similarity)::real OR (similarity = sqlc.arg(after_similarity)::real AND path > sqlc.arg(after_path)::text))))
+            ELSE path > sqlc.arg(after_path)::text
+        END)
+        OR (sqlc.arg(sort_order)::text = 'desc' AND CASE sqlc.arg(sort_by)::text
+            WHEN 'name' THEN (name < sqlc.arg(after_text)::text OR (name = sqlc.arg(after_text)::text AND path > sqlc.arg(after_path)::text))
+            WHEN 'size' THEN (size < sqlc.arg(after_size)::bigint OR (size = sqlc.arg(after_size)::bigint AND path > sqlc.arg(after_path)::text))
+            WHEN 'modified_at' THEN (modified_at < sqlc.arg(after_time)::timestamptz OR (modified_at = sqlc.arg(after_time)::timestamptz AND path > sqlc.arg(after_path)::text))
+            WHEN 'created_at' THEN (created_at < sqlc.arg(after_time)::timestamptz OR (created_at = sqlc.arg(after_time)::timestamptz AND path > sqlc.arg(after_path)::text))
+            WHEN 'path' THEN path < sqlc.arg(after_path)::text
+            WHEN 'relevance' THEN (rank < sqlc.arg(after_rank)::real OR (rank = sqlc.arg(after_rank)::real
+                AND (similarity < sqlc.arg(after_similarity)::real OR (similarity = sqlc.arg(after_similarity)::real AND path > sqlc.arg(after_path)::text))))
+            ELSE path > sqlc.arg(after_path)::text
+        END)
+    ORDER BY
+        CASE WHEN sqlc.arg(sort_by)::text = 'name' AND sqlc.arg(sort_order)::text = 'asc' THEN name END ASC,
+        CASE WHEN sqlc.arg(sort_by)::text = 'name' AND sqlc.arg(sort_order)::text = 'desc' THEN name END DESC,
+        CASE WHEN sqlc.arg(sort_by)::text = 'size' AND sqlc.arg(sort_order)::text = 'asc' THEN size END ASC,
+        CASE WHEN sqlc.arg(sort_by)::text = 'size' AND sqlc.arg(sort_order)::text = 'desc' THEN size END DESC,
+        CASE WHEN sqlc.arg(sort_by)::text = 'modified_at' AND sqlc.arg(sort_order)::text = 'asc' THEN modified_at END ASC,
+        CASE WHEN sqlc.arg(sort_by)::text = 'modified_at' AND sqlc.arg(sort_order)::text = 'desc' THEN modified_at END DESC,
+        CASE WHEN sqlc.arg(sort_by)::text = 'created_at' AND sqlc.arg(sort_order)::text = 'asc' THEN created_at END ASC,
+        CASE WHEN sqlc.arg(sort_by)::text = 'created_at' AND sqlc.arg(sort_order)::text = 'desc' THEN created_at END DESC,
+        CASE WHEN sqlc.arg(sort_by)::text = 'path' AND sqlc.arg(sort_order)::text = 'asc' THEN path END ASC,
+        CASE WHEN sqlc.arg(sort_by)::text = 'path' AND sqlc.arg(sort_order)::text = 'desc' THEN path END DESC,
+        CASE WHEN sqlc.arg(sort_by)::text = 'relevance' AND sqlc.arg(sort_order)::text = 'asc' THEN rank END ASC,
+        CASE WHEN sqlc.arg(sort_by)::text = 'relevance' AND sqlc.arg(sort_order)::text = 'desc' THEN rank END DESC,
+        CASE WHEN sqlc.arg(sort_by)::text = 'relevance' AND sqlc.arg(sort_order)::text = 'asc' THEN similarity END ASC,
+        CASE WHEN sqlc.arg(sort_by)::text = 'relevance' AND sqlc.arg(sort_order)::text = 'desc' THEN similarity END DESC,
+        path
+    OFFSET sqlc.arg(page_offset)
+    LIMIT CASE WHEN sqlc.arg(page_limit)::int = 0 THEN NULL ELSE sqlc.arg(page_limit)::int END
+) AS hits
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by)::text = 'name' AND sqlc.arg(sort_order)::text = 'asc' THEN name END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'name' AND sqlc.arg(sort_order)::text = 'desc' THEN name END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'size' AND sqlc.arg(sort_order)::text = 'asc' THEN size END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'size' AND sqlc.arg(sort_order)::text = 'desc' THEN size END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'modified_at' AND sqlc.arg(sort_order)::text = 'asc' THEN modified_at END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'modified_at' AND sqlc.arg(sort_order)::text = 'desc' THEN modified_at END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'created_at' AND sqlc.arg(sort_order)::text = 'asc' THEN created_at END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'created_at' AND sqlc.arg(sort_order)::text = 'desc' THEN created_at END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'path' AND sqlc.arg(sort_order)::text = 'asc' THEN path END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'path' AND sqlc.arg(sort_order)::text = 'desc' THEN path END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'relevance' AND sqlc.arg(sort_order)::text = 'asc' THEN rank END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'relevance' AND sqlc.arg(sort_order)::text = 'desc' THEN rank END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'relevance' AND sqlc.arg(sort_order)::text = 'asc' THEN similarity END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'relevance' AND sqlc.arg(sort_order)::text = 'desc' THEN similarity END DESC,
+    path;
+
+-- name: CountFiles :one
+-- The files of GetFiles are counted up to count_limit
+SELECT
+    count(*)
+FROM (
+    SELECT
+        1
+    FROM
+        file
+    WHERE
+        -- Filename search
+        (sqlc.arg(name)::text = ''
+            OR (sqlc.arg(name_match)::text = 'substring' AND name ILIKE '%' || sqlc.arg(name) || '%')
+            OR (sqlc.arg(name_match)::text = 'exact' AND name = sqlc.arg(name))
+            OR (sqlc.arg(name_match)::text = 'regex' AND name ~* sqlc.arg(name))
+            OR (sqlc.arg(name_match)::text = 'fuzzy' AND (sqlc.arg(name) <% name OR sqlc.arg(name) <% path)
+                AND GREATEST(word_similarity(sqlc.arg(name), name), word_similarity(sqlc.arg(name), path)) >= sqlc.arg(threshold)::real))
+        -- File extension exact match
+        AND (sqlc.arg(extension)::text = '' OR extension = sqlc.arg(extension))
+        -- File size range search
+        AND size >= sqlc.arg(size_min)
+        AND size <= sqlc.arg(size_max)
+        -- File created_at range search
+        AND created_at >= sqlc.arg(created_after)
+        AND created_at <= sqlc.arg(created_before)
+        -- File modified_At range search
+        AND modified_at >= sqlc.arg(modified_after)
+        AND modified_at <= sqlc.arg(modified_before)
+        -- File accessed_at range search
+        AND accessed_at >= sqlc.arg(accessed_after)
+        AND accessed_at <= sqlc.arg(accessed_before)
+        -- File content search through file_content_search_idx
+        AND (sqlc.arg(content)::text = '' OR to_tsvector('english', content) @@ to_tsquery('english', sqlc.arg(content)))
+        -- Metadata values through file_metadata_idx
+        AND (sqlc.arg(metadata)::text = '' OR metadata @> CAST(sqlc.arg(metadata)::text AS jsonb))
+    LIMIT sqlc.arg(count_limit)::bigint
+) AS matches;
 
 -- name: InsertFile :one
 INSERT INTO file (
@@ -37,7 +180,62 @@ INSERT INTO file (
   content,
   created_at,
   modified_at,
-  accessed_at
+  accessed_at,
+  partial_hash,
+  content_hash,
+  metadata
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8, $9
-) RETURNING *;
\ No newline at end of file
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+-- No row is returned if the path is already stored
+ON CONFLICT (path) DO NOTHING
+RETURNING *;
+
+-- name: UpsertFile :one
+INSERT INTO file (
+  name,
+  extension,
+  size,
+  path,
+  attributes,
+  content,
+  created_at,
+  modified_at,
+  accessed_at,
+  partial_hash,
+  content_hash,
+  metadata
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+ON CONFLICT (path) DO UPDATE SET
+  name = EXCLUDED.name,
+  extension = EXCLUDED.extension,
+  size = EXCLUDED.size,
+  attributes = EXCLUDED.attributes,
+  content = EXCLUDED.content,
+  created_at = EXCLUDED.created_at,
+  modified_at = EXCLUDED.modified_at,
+  accessed_at = EXCLUDED.accessed_at,
+  partial_hash = EXCLUDED.partial_hash,
+  content_hash = EXCLUDED.content_hash,
+  metadata = EXCLUDED.metadata
+RETURNING *;
+
+-- name: UpdateFileHashes :execrows
+-- Stores the hashes computed for the duplicate search
+UPDATE file
+SET
+  partial_hash = $2,
+  content_hash = $3
+WHERE path = $1;
+
+-- name: DeleteFileByPath :execrows
+DELETE FROM file
+WHERE path = $1;
+
+-- name: ListFilesByPrefix :many
+SELECT *
+FROM file
+WHERE starts_with(path, sqlc.arg(prefix)::text)
+ORDER BY path;
\ No newline at end of file
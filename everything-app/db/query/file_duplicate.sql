@@ -0,0 +1,67 @@
+-- name: GetDuplicateFiles :many
+-- The files of the group_limit groups of files with the same content hash
+-- below prefix that waste the most bytes, only files of at least min_size
+-- bytes are grouped. A group wastes the size of all of its files but one.
+WITH duplicates AS (
+    SELECT
+        file.content_hash,
+        file.size,
+        count(*) AS file_count,
+        CAST((count(*) - 1) * file.size AS bigint) AS wasted
+    FROM
+        file
+    WHERE
+        file.content_hash <> ''
+        AND file.size >= sqlc.arg(min_size)
+        AND starts_with(file.path, sqlc.arg(prefix)::text)
+    GROUP BY
+        file.content_hash,
+        file.size
+    HAVING
+        count(*) > 1
+    ORDER BY
+        wasted DESC,
+        content_hash
+    LIMIT sqlc.arg(group_limit)
+)
+SELECT
+    file.path,
+    file.name,
+    file.modified_at,
+    duplicates.content_hash,
+    duplicates.size,
+    duplicates.file_count,
+    duplicates.wasted
+FROM
+    file
+    JOIN duplicates ON file.content_hash = duplicates.content_hash AND file.size = duplicates.size
+WHERE
+    starts_with(file.path, sqlc.arg(prefix)::text)
+ORDER BY
+    duplicates.wasted DESC,
+    duplicates.content_hash,
+    file.path;
+
+-- name: GetDuplicateTotals :one
+-- The number of groups of GetDuplicateFiles, of their files and of the
+-- bytes they waste
+SELECT
+    count(*) AS group_count,
+    CAST(COALESCE(sum(file_count), 0) AS bigint) AS file_count,
+    CAST(COALESCE(sum(wasted), 0) AS bigint) AS wasted
+FROM (
+    SELECT
+        count(*) AS file_count,
+        (count(*) - 1) * size AS wasted
+    FROM
+        file
+    WHERE
+        content_hash <> ''
+        AND size >= sqlc.arg(min_size)
+        AND starts_with(path, sqlc.arg(prefix)::text)
+    GROUP BY
+        content_hash,
+        size
+    HAVING
+        count(*) > 1
+) AS duplicates;
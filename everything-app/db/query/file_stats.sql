@@ -0,0 +1,110 @@
+-- name: GetFileFacets :many
+-- The number and the total size of the files matching the filters of
+-- GetFiles by extension, top-level directory, named size and modification
+-- month in UTC, and the top_limit directories with the largest total size
+-- of the matching files they directly contain. The facets are ordered by
+-- name, then by size descending.
+WITH matches AS (
+    SELECT
+        path,
+        extension,
+        size,
+        modified_at
+    FROM
+        file
+    WHERE
+            -- Filename search
+            (sqlc.arg(name)::text = ''
+                OR (sqlc.arg(name_match)::text = 'substring' AND name ILIKE '%' || sqlc.arg(name) || '%')
+                OR (sqlc.arg(name_match)::text = 'exact' AND name = sqlc.arg(name))
+                OR (sqlc.arg(name_match)::text = 'regex' AND name ~* sqlc.arg(name))
+                OR (sqlc.arg(name_match)::text = 'fuzzy' AND (sqlc.arg(name) <% name OR sqlc.arg(name) <% path)
+                    AND GREATEST(word_similarity(sqlc.arg(name), name), word_similarity(sqlc.arg(name), path)) >= sqlc.arg(threshold)::real))
+            -- File extension exact match
+            AND (sqlc.arg(extension)::text = '' OR extension = sqlc.arg(extension))
+            -- File size range search
+            AND size >= sqlc.arg(size_min)
+            AND size <= sqlc.arg(size_max)
+            -- File created_at range search
+            AND created_at >= sqlc.arg(created_after)
+            AND created_at <= sqlc.arg(created_before)
+            -- File modified_At range search
+            AND modified_at >= sqlc.arg(modified_after)
+            AND modified_at <= sqlc.arg(modified_before)
+            -- File accessed_at range search
+            AND accessed_at >= sqlc.arg(accessed_after)
+            AND accessed_at <= sqlc.arg(accessed_before)
+            -- File content search through file_content_search_idx
+            AND (sqlc.arg(content)::text = '' OR to_tsvector('english', content) @@ to_tsquery('english', sqlc.arg(content)))
+            -- Metadata values through file_metadata_idx
+            AND (sqlc.arg(metadata)::text = '' OR metadata @> CAST(sqlc.arg(metadata)::text AS jsonb))
+)
+SELECT
+    CAST('extension' AS text) AS facet,
+    CAST(extension AS text) AS value,
+    count(*) AS files,
+    CAST(sum(size) AS bigint) AS size
+FROM matches
+GROUP BY extension
+UNION ALL
+SELECT 'directory', top_directory(path), count(*), CAST(sum(size) AS bigint)
+FROM matches
+GROUP BY top_directory(path)
+UNION ALL
+SELECT 'size', size_name(size), count(*), CAST(sum(size) AS bigint)
+FROM matches
+GROUP BY size_name(size)
+UNION ALL
+SELECT 'month', utc_month(modified_at), count(*), CAST(sum(size) AS bigint)
+FROM matches
+GROUP BY utc_month(modified_at)
+UNION ALL
+SELECT facet, value, files, size FROM (
+    SELECT 'largest_directory' AS facet, parent_directory(path) AS value, count(*) AS files, CAST(sum(size) AS bigint) AS size
+    FROM matches
+    GROUP BY parent_directory(path)
+    ORDER BY size DESC, value
+    LIMIT sqlc.arg(top_limit)
+) AS largest_directories
+ORDER BY facet, size DESC, value;
+
+-- name: GetLargestFiles :many
+-- The top_limit largest files matching the filters of GetFiles
+SELECT
+    path,
+    name,
+    extension,
+    size,
+    modified_at
+FROM
+    file
+WHERE
+    -- Filename search
+    (sqlc.arg(name)::text = ''
+        OR (sqlc.arg(name_match)::text = 'substring' AND name ILIKE '%' || sqlc.arg(name) || '%')
+        OR (sqlc.arg(name_match)::text = 'exact' AND name = sqlc.arg(name))
+        OR (sqlc.arg(name_match)::text = 'regex' AND name ~* sqlc.arg(name))
+        OR (sqlc.arg(name_match)::text = 'fuzzy' AND (sqlc.arg(name) <% name OR sqlc.arg(name) <% path)
+            AND GREATEST(word_similarity(sqlc.arg(name), name), word_similarity(sqlc.arg(name), path)) >= sqlc.arg(threshold)::real))
+    -- File extension exact match
+    AND (sqlc.arg(extension)::text = '' OR extension = sqlc.arg(extension))
+    -- File size range search
+    AND size >= sqlc.arg(size_min)
+    AND size <= sqlc.arg(size_max)
+    -- File created_at range search
+    AND created_at >= sqlc.arg(created_after)
+    AND created_at <= sqlc.arg(created_before)
+    -- File modified_At range search
+    AND modified_at >= sqlc.arg(modified_after)
+    AND modified_at <= sqlc.arg(modified_before)
+    -- File accessed_at range search
+    AND accessed_at >= sqlc.arg(accessed_after)
+    AND accessed_at <= sqlc.arg(accessed_before)
+    -- File content search through file_content_search_idx
+    AND (sqlc.arg(content)::text = '' OR to_tsvector('english', content) @@ to_tsquery('english', sqlc.arg(content)))
+    -- Metadata values through file_metadata_idx
+    AND (sqlc.arg(metadata)::text = '' OR metadata @> CAST(sqlc.arg(metadata)::text AS jsonb))
+ORDER BY
+    size DESC,
+    path
+LIMIT sqlc.arg(top_limit);
@@ -0,0 +1,35 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// ErrUniqueViolation is wrapped by the errors of stores that are not
+// Postgres when a write violates a unique constraint
+var ErrUniqueViolation = errors.New("unique violation")
+
+// ErrForeignKeyViolation is wrapped by the errors of stores that are not
+// Postgres when a write references a missing row
+var ErrForeignKeyViolation = errors.New("foreign key violation")
+
+// IsUniqueViolation reports whether err is a unique constraint violation,
+// whichever driver returned it
+func IsUniqueViolation(err error) bool {
+	if errors.Is(err, ErrUniqueViolation) {
+		return true
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation"
+}
+
+// IsForeignKeyViolation reports whether err is a foreign key violation,
+// whichever driver returned it
+func IsForeignKeyViolation(err error) bool {
+	if errors.Is(err, ErrForeignKeyViolation) {
+		return true
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Name() == "foreign_key_violation"
+}
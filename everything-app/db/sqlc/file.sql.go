@@ -7,83 +7,331 @@ package db
 
 import (
 	"context"
-	"database/sql"
+	"encoding/json"
 	"time"
 )
 
+const countFiles = `-- name: CountFiles :one
+SELECT
+    count(*)
+FROM (
+    SELECT
+        1
+    FROM
+        file
+    WHERE
+        -- Filename search
+        ($1::text = ''
+            OR ($2::text = 'substring' AND name ILIKE '%' || $1 || '%')
+            OR ($2::text = 'exact' AND name = $1)
+            OR ($2::text = 'regex' AND name ~* $1)
+            OR ($2::text = 'fuzzy' AND ($1 <% name OR $1 <% path)
+                AND GREATEST(word_similarity($1, name), word_similarity($1, path)) >= $3::real))
+        -- File extension exact match
+        AND ($4::text = '' OR extension = $4)
+        -- File size range search
+        AND size >= $5
+        AND size <= $6
+        -- File created_at range search
+        AND created_at >= $7
+        AND created_at <= $8
+        -- File modified_At range search
+        AND modified_at >= $9
+        AND modified_at <= $10
+        -- File accessed_at range search
+        AND accessed_at >= $11
+        AND accessed_at <= $12
+        -- File content search through file_content_search_idx
+        AND ($13::text = '' OR to_tsvector('english', content) @@ to_tsquery('english', $13))
+        -- Metadata values through file_metadata_idx
+        AND ($14::text = '' OR metadata @> CAST($14::text AS jsonb))
+    LIMIT $15::bigint
+) AS matches
+`
+
+type CountFilesParams struct {
+	Name           string    `json:"name"`
+	NameMatch      string    `json:"name_match"`
+	Threshold      float32   `json:"threshold"`
+	Extension      string    `json:"extension"`
+	SizeMin        int64     `json:"size_min"`
+	SizeMax        int64     `json:"size_max"`
+	CreatedAfter   time.Time `json:"created_after"`
+	CreatedBefore  time.Time `json:"created_before"`
+	ModifiedAfter  time.Time `json:"modified_after"`
+	ModifiedBefore time.Time `json:"modified_before"`
+	AccessedAfter  time.Time `json:"accessed_after"`
+	AccessedBefore time.Time `json:"accessed_before"`
+	Content        string    `json:"content"`
+	Metadata       string    `json:"metadata"`
+	CountLimit     int64     `json:"count_limit"`
+}
+
+// The files of GetFiles are counted up to count_limit
+func (q *Queries) CountFiles(ctx context.Context, arg CountFilesParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFiles,
+		arg.Name,
+		arg.NameMatch,
+		arg.Threshold,
+		arg.Extension,
+		arg.SizeMin,
+		arg.SizeMax,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.ModifiedAfter,
+		arg.ModifiedBefore,
+		arg.AccessedAfter,
+		arg.AccessedBefore,
+		arg.Content,
+		arg.Metadata,
+		arg.CountLimit,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteFileByPath = `-- name: DeleteFileByPath :execrows
+DELETE FROM file
+WHERE path = $1
+`
+
+func (q *Queries) DeleteFileByPath(ctx context.Context, path string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFileByPath, path)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getFiles = `-- name: GetFiles :many
-SELECT 
+SELECT
+    path,
+    name,
+    extension,
+    size,
+    created_at,
+    modified_at,
+    accessed_at,
+    attributes,
+    metadata,
+    rank,
+    similarity,
+    CAST(CASE WHEN $1::text = '' THEN ''
+        ELSE ts_headline('english', content, to_tsquery('english', $1), 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5')
+    END AS text) AS snippet
+FROM (
+    SELECT
+        path, name, extension, size, created_at, modified_at, accessed_at, attributes, metadata, content, rank, similarity
+    FROM (
+        SELECT
+            path,
+            name,
+            extension,
+            size,
+            created_at,
+            modified_at,
+            accessed_at,
+            attributes,
+            metadata,
+            content,
+            CAST(CASE WHEN $1::text = '' THEN 0
+                ELSE ts_rank_cd(to_tsvector('english', content), to_tsquery('english', $1), 32)
+            END AS real) AS rank,
+            CAST(CASE WHEN $2::text = 'fuzzy' AND $3::text <> ''
+                THEN GREATEST(word_similarity($3, name), word_similarity($3, path))
+                ELSE 0
+            END AS real) AS similarity
+        FROM
+            file
+        WHERE
+            -- Filename search
+            ($3::text = ''
+                OR ($2::text = 'substring' AND name ILIKE '%' || $3 || '%')
+                OR ($2::text = 'exact' AND name = $3)
+                OR ($2::text = 'regex' AND name ~* $3)
+                OR ($2::text = 'fuzzy' AND ($3 <% name OR $3 <% path)
+                    AND GREATEST(word_similarity($3, name), word_similarity($3, path)) >= $4::real))
+            -- File extension exact match
+            AND ($5::text = '' OR extension = $5)
+            -- File size range search
+            AND size >= $6
+            AND size <= $7
+            -- File created_at range search
+            AND created_at >= $8
+            AND created_at <= $9
+            -- File modified_At range search
+            AND modified_at >= $10
+            AND modified_at <= $11
+            -- File accessed_at range search
+            AND accessed_at >= $12
+            AND accessed_at <= $13
+            -- File content search through file_content_search_idx
+            AND ($1::text = '' OR to_tsvector('english', content) @@ to_tsquery('english', $1))
+            -- Metadata values through file_metadata_idx
+            AND ($14::text = '' OR metadata @> CAST($14::text AS jsonb))
+    ) AS matches
+    WHERE
+        $15::text = ''
+        OR ($16::text = 'asc' AND CASE $17::text
+            WHEN 'name' THEN (name > $18::text OR (name = $18::text AND path > $15::text))
+            WHEN 'size' THEN (size > $19::bigint OR (size = $19::bigint AND path > $15::text))
+            WHEN 'modified_at' THEN (modified_at > $20::timestamptz OR (modified_at = $20::timestamptz AND path > $15::text))
+            WHEN 'created_at' THEN (created_at > $20::timestamptz OR (created_at = $20::timestamptz AND path > $15::text))
+            WHEN 'path' THEN path > $15::text
+            WHEN 'relevance' THEN (rank > $21::real OR (rank = $21::real
+                AND (similarity > $22::real OR (similarity = $22::real AND path > $15::text))))
+            ELSE path > $15::text
+        END)
+        OR ($16::text = 'desc' AND CASE $17::text
+            WHEN 'name' THEN (name < $18::text OR (name = $18::text AND path > $15::text))
+            WHEN 'size' THEN (size < $19::bigint OR (size = $19::bigint AND path > $15::text))
+            WHEN 'modified_at' THEN (modified_at < $20::timestamptz OR (modified_at = $20::timestamptz AND path > $15::text))
+            WHEN 'created_at' THEN (created_at < $20::timestamptz OR (created_at = $20::timestamptz AND path > $15::text))
+            WHEN 'path' THEN path < $15::text
+            WHEN 'relevance' THEN (rank < $21::real OR (rank = $21::real
+                AND (similarity < $22::real OR (similarity = $22::real AND path > $15::text))))
+            ELSE path > $15::text
+        END)
+    ORDER BY
+        CASE WHEN $17::text = 'name' AND $16::text = 'asc' THEN name END ASC,
+        CASE WHEN $17::text = 'name' AND $16::text = 'desc' THEN name END DESC,
+        CASE WHEN $17::text = 'size' AND $16::text = 'asc' THEN size END ASC,
+        CASE WHEN $17::text = 'size' AND $16::text = 'desc' THEN size END DESC,
+        CASE WHEN $17::text = 'modified_at' AND $16::text = 'asc' THEN modified_at END ASC,
+        CASE WHEN $17::text = 'modified_at' AND $16::text = 'desc' THEN modified_at END DESC,
+        CASE WHEN $17::text = 'created_at' AND $16::text = 'asc' THEN created_at END ASC,
+        CASE WHEN $17::text = 'created_at' AND $16::text = 'desc' THEN created_at END DESC,
+        CASE WHEN $17::text = 'path' AND $16::text = 'asc' THEN path END ASC,
+        CASE WHEN $17::text = 'path' AND $16::text = 'desc' THEN path END DESC,
+        CASE WHEN $17::text = 'relevance' AND $16::text = 'asc' THEN rank END ASC,
+        CASE WHEN $17::text = 'relevance' AND $16::text = 'desc' THEN rank END DESC,
+        CASE WHEN $17::text = 'relevance' AND $16::text = 'asc' THEN similarity END ASC,
+        CASE WHEN $17::text = 'relevance' AND $16::text = 'desc' THEN similarity END DESC,
+        path
+    OFFSET $23
+    LIMIT CASE WHEN $24::int = 0 THEN NULL ELSE $24::int END
+) AS hits
+ORDER BY
+    CASE WHEN $17::text = 'name' AND $16::text = 'asc' THEN name END ASC,
+    CASE WHEN $17::text = 'name' AND $16::text = 'desc' THEN name END DESC,
+    CASE WHEN $17::text = 'size' AND $16::text = 'asc' THEN size END ASC,
+    CASE WHEN $17::text = 'size' AND $16::text = 'desc' THEN size END DESC,
+    CASE WHEN $17::text = 'modified_at' AND $16::text = 'asc' THEN modified_at END ASC,
+    CASE WHEN $17::text = 'modified_at' AND $16::text = 'desc' THEN modified_at END DESC,
+    CASE WHEN $17::text = 'created_at' AND $16::text = 'asc' THEN created_at END ASC,
+    CASE WHEN $17::text = 'created_at' AND $16::text = 'desc' THEN created_at END DESC,
+    CASE WHEN $17::text = 'path' AND $16::text = 'asc' THEN path END ASC,
+    CASE WHEN $17::text = 'path' AND $16::text = 'desc' THEN path END DESC,
+    CASE WHEN $17::text = 'relevance' AND $16::text = 'asc' THEN rank END ASC,
+    CASE WHEN $17::text = 'relevance' AND $16::text = 'desc' THEN rank END DESC,
+    CASE WHEN $17::text = 'relevance' AND $16::text = 'asc' THEN similarity END ASC,
+    CASE WHEN $17::text = 'relevance' AND $16::text = 'desc' THEN similarity END DESC,
     path
-FROM 
-    file
-WHERE 
-    -- Filename fuzzy search (using LIKE for approximate matching)
-    (name ILIKE '%' || $1 || '%' OR $1 IS NULL)
-    
-    -- File extension exact match
-    AND (extension = $2 OR $2 IS NULL)
-    -- File size range search
-    AND (size >= $3 OR $3 IS NULL)
-    AND (size <= $4 OR $4 IS NULL)
-    -- File created_at range search
-    AND (created_at >= $5 OR $5 IS NULL)
-    AND (created_at <= $6 OR $6 IS NULL)
-    -- File modified_At range search
-    AND (modified_at >= $7 OR $7 IS NULL)
-    AND (modified_at <= $8 OR $8 IS NULL)
-    -- File accessed_at range search
-    AND (accessed_at >= $9 OR $9 IS NULL)
-    AND (accessed_at <= $10 OR $10 IS NULL)
-    -- -- File content search
-    AND (content ILIKE '%' || $11 || '%' OR $11 IS NULL)
-OFFSET $12
-LIMIT CASE WHEN $13 = 0 THEN NULL ELSE $12 END
 `
 
 type GetFilesParams struct {
-	Column1      sql.NullString `json:"column_1"`
-	Extension    string         `json:"extension"`
-	Size         int64          `json:"size"`
-	Size_2       int64          `json:"size_2"`
-	CreatedAt    time.Time      `json:"created_at"`
-	CreatedAt_2  time.Time      `json:"created_at_2"`
-	ModifiedAt   time.Time      `json:"modified_at"`
-	ModifiedAt_2 time.Time      `json:"modified_at_2"`
-	AccessedAt   time.Time      `json:"accessed_at"`
-	AccessedAt_2 time.Time      `json:"accessed_at_2"`
-	Column11     sql.NullString `json:"column_11"`
-	Offset       int32          `json:"offset"`
-	Column13     interface{}    `json:"column_13"`
+	Content         string    `json:"content"`
+	NameMatch       string    `json:"name_match"`
+	Name            string    `json:"name"`
+	Threshold       float32   `json:"threshold"`
+	Extension       string    `json:"extension"`
+	SizeMin         int64     `json:"size_min"`
+	SizeMax         int64     `json:"size_max"`
+	CreatedAfter    time.Time `json:"created_after"`
+	CreatedBefore   time.Time `json:"created_before"`
+	ModifiedAfter   time.Time `json:"modified_after"`
+	ModifiedBefore  time.Time `json:"modified_before"`
+	AccessedAfter   time.Time `json:"accessed_after"`
+	AccessedBefore  time.Time `json:"accessed_before"`
+	Metadata        string    `json:"metadata"`
+	AfterPath       string    `json:"after_path"`
+	SortOrder       string    `json:"sort_order"`
+	SortBy          string    `json:"sort_by"`
+	AfterText       string    `json:"after_text"`
+	AfterSize       int64     `json:"after_size"`
+	AfterTime       time.Time `json:"after_time"`
+	AfterRank       float32   `json:"after_rank"`
+	AfterSimilarity float32   `json:"after_similarity"`
+	PageOffset      int32     `json:"page_offset"`
+	PageLimit       int32     `json:"page_limit"`
+}
+
+type GetFilesRow struct {
+	Path       string          `json:"path"`
+	Name       string          `json:"name"`
+	Extension  string          `json:"extension"`
+	Size       int64           `json:"size"`
+	CreatedAt  time.Time       `json:"created_at"`
+	ModifiedAt time.Time       `json:"modified_at"`
+	AccessedAt time.Time       `json:"accessed_at"`
+	Attributes string          `json:"attributes"`
+	Metadata   json.RawMessage `json:"metadata"`
+	Rank       float32         `json:"rank"`
+	Similarity float32         `json:"similarity"`
+	Snippet    string          `json:"snippet"`
 }
 
-// If limit is not provided, return all results
-func (q *Queries) GetFiles(ctx context.Context, arg GetFilesParams) ([]string, error) {
+// name is searched according to name_match: substring, exact, regex or fuzzy.
+// Fuzzy matches the name or the path with a trigram word similarity of at
+// least threshold, pg_trgm.word_similarity_threshold has to be threshold as
+// well for the trigram indexes to be used. content is a to_tsquery expression.
+// Empty searches are skipped. Content hits are ranked by relevance and come
+// with a highlighted snippet of the content.
+// Files are sorted by sort_by in sort_order, then by path ascending. A page
+// after a file starts after after_path and the sort key of the file, given
+// in the after argument of its type. If limit is 0, return all results
+func (q *Queries) GetFiles(ctx context.Context, arg GetFilesParams) ([]GetFilesRow, error) {
 	rows, err := q.db.QueryContext(ctx, getFiles,
-		arg.Column1,
+		arg.Content,
+		arg.NameMatch,
+		arg.Name,
+		arg.Threshold,
 		arg.Extension,
-		arg.Size,
-		arg.Size_2,
-		arg.CreatedAt,
-		arg.CreatedAt_2,
-		arg.ModifiedAt,
-		arg.ModifiedAt_2,
-		arg.AccessedAt,
-		arg.AccessedAt_2,
-		arg.Column11,
-		arg.Offset,
-		arg.Column13,
+		arg.SizeMin,
+		arg.SizeMax,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.ModifiedAfter,
+		arg.ModifiedBefore,
+		arg.AccessedAfter,
+		arg.AccessedBefore,
+		arg.Metadata,
+		arg.AfterPath,
+		arg.SortOrder,
+		arg.SortBy,
+		arg.AfterText,
+		arg.AfterSize,
+		arg.AfterTime,
+		arg.AfterRank,
+		arg.AfterSimilarity,
+		arg.PageOffset,
+		arg.PageLimit,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []string{}
+	items := []GetFilesRow{}
 	for rows.Next() {
-		var path string
-		if err := rows.Scan(&path); err != nil {
+		var i GetFilesRow
+		if err := rows.Scan(
+			&i.Path,
+			&i.Name,
+			&i.Extension,
+			&i.Size,
+			&i.CreatedAt,
+			&i.ModifiedAt,
+			&i.AccessedAt,
+			&i.Attributes,
+			&i.Metadata,
+			&i.Rank,
+			&i.Similarity,
+			&i.Snippet,
+		); err != nil {
 			return nil, err
 		}
-		items = append(items, path)
+		items = append(items, i)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -104,24 +352,33 @@ INSERT INTO file (
   content,
   created_at,
   modified_at,
-  accessed_at
+  accessed_at,
+  partial_hash,
+  content_hash,
+  metadata
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8, $9
-) RETURNING id, name, extension, size, path, created_at, modified_at, accessed_at, attributes, content
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+ON CONFLICT (path) DO NOTHING
+RETURNING id, name, extension, size, path, created_at, modified_at, accessed_at, attributes, content, partial_hash, content_hash, metadata
 `
 
 type InsertFileParams struct {
-	Name       string    `json:"name"`
-	Extension  string    `json:"extension"`
-	Size       int64     `json:"size"`
-	Path       string    `json:"path"`
-	Attributes string    `json:"attributes"`
-	Content    string    `json:"content"`
-	CreatedAt  time.Time `json:"created_at"`
-	ModifiedAt time.Time `json:"modified_at"`
-	AccessedAt time.Time `json:"accessed_at"`
+	Name        string          `json:"name"`
+	Extension   string          `json:"extension"`
+	Size        int64           `json:"size"`
+	Path        string          `json:"path"`
+	Attributes  string          `json:"attributes"`
+	Content     string          `json:"content"`
+	CreatedAt   time.Time       `json:"created_at"`
+	ModifiedAt  time.Time       `json:"modified_at"`
+	AccessedAt  time.Time       `json:"accessed_at"`
+	PartialHash string          `json:"partial_hash"`
+	ContentHash string          `json:"content_hash"`
+	Metadata    json.RawMessage `json:"metadata"`
 }
 
+// No row is returned if the path is already stored
 func (q *Queries) InsertFile(ctx context.Context, arg InsertFileParams) (File, error) {
 	row := q.db.QueryRowContext(ctx, insertFile,
 		arg.Name,
@@ -133,6 +390,157 @@ func (q *Queries) InsertFile(ctx context.Context, arg InsertFileParams) (File, e
 		arg.CreatedAt,
 		arg.ModifiedAt,
 		arg.AccessedAt,
+		arg.PartialHash,
+		arg.ContentHash,
+		arg.Metadata,
+	)
+	var i File
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Extension,
+		&i.Size,
+		&i.Path,
+		&i.CreatedAt,
+		&i.ModifiedAt,
+		&i.AccessedAt,
+		&i.Attributes,
+		&i.Content,
+		&i.PartialHash,
+		&i.ContentHash,
+		&i.Metadata,
+	)
+	return i, err
+}
+
+const listFilesByPrefix = `-- name: ListFilesByPrefix :many
+SELECT id, name, extension, size, path, created_at, modified_at, accessed_at, attributes, content, partial_hash, content_hash, metadata
+FROM file
+WHERE starts_with(path, $1::text)
+ORDER BY path
+`
+
+func (q *Queries) ListFilesByPrefix(ctx context.Context, prefix string) ([]File, error) {
+	rows, err := q.db.QueryContext(ctx, listFilesByPrefix, prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []File{}
+	for rows.Next() {
+		var i File
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Extension,
+			&i.Size,
+			&i.Path,
+			&i.CreatedAt,
+			&i.ModifiedAt,
+			&i.AccessedAt,
+			&i.Attributes,
+			&i.Content,
+			&i.PartialHash,
+			&i.ContentHash,
+			&i.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateFileHashes = `-- name: UpdateFileHashes :execrows
+UPDATE file
+SET
+  partial_hash = $2,
+  content_hash = $3
+WHERE path = $1
+`
+
+type UpdateFileHashesParams struct {
+	Path        string `json:"path"`
+	PartialHash string `json:"partial_hash"`
+	ContentHash string `json:"content_hash"`
+}
+
+// Stores the hashes computed for the duplicate search
+func (q *Queries) UpdateFileHashes(ctx context.Context, arg UpdateFileHashesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateFileHashes, arg.Path, arg.PartialHash, arg.ContentHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const upsertFile = `-- name: UpsertFile :one
+INSERT INTO file (
+  name,
+  extension,
+  size,
+  path,
+  attributes,
+  content,
+  created_at,
+  modified_at,
+  accessed_at,
+  partial_hash,
+  content_hash,
+  metadata
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+ON CONFLICT (path) DO UPDATE SET
+  name = EXCLUDED.name,
+  extension = EXCLUDED.extension,
+  size = EXCLUDED.size,
+  attributes = EXCLUDED.attributes,
+  content = EXCLUDED.content,
+  created_at = EXCLUDED.created_at,
+  modified_at = EXCLUDED.modified_at,
+  accessed_at = EXCLUDED.accessed_at,
+  partial_hash = EXCLUDED.partial_hash,
+  content_hash = EXCLUDED.content_hash,
+  metadata = EXCLUDED.metadata
+RETURNING id, name, extension, size, path, created_at, modified_at, accessed_at, attributes, content, partial_hash, content_hash, metadata
+`
+
+type UpsertFileParams struct {
+	Name        string          `json:"name"`
+	Extension   string          `json:"extension"`
+	Size        int64           `json:"size"`
+	Path        string          `json:"path"`
+	Attributes  string          `json:"attributes"`
+	Content     string          `json:"content"`
+	CreatedAt   time.Time       `json:"created_at"`
+	ModifiedAt  time.Time       `json:"modified_at"`
+	AccessedAt  time.Time       `json:"accessed_at"`
+	PartialHash string          `json:"partial_hash"`
+	ContentHash string          `json:"content_hash"`
+	Metadata    json.RawMessage `json:"metadata"`
+}
+
+func (q *Queries) UpsertFile(ctx context.Context, arg UpsertFileParams) (File, error) {
+	row := q.db.QueryRowContext(ctx, upsertFile,
+		arg.Name,
+		arg.Extension,
+		arg.Size,
+		arg.Path,
+		arg.Attributes,
+		arg.Content,
+		arg.CreatedAt,
+		arg.ModifiedAt,
+		arg.AccessedAt,
+		arg.PartialHash,
+		arg.ContentHash,
+		arg.Metadata,
 	)
 	var i File
 	err := row.Scan(
@@ -146,6 +554,9 @@ func (q *Queries) InsertFile(ctx context.Context, arg InsertFileParams) (File, e
 		&i.AccessedAt,
 		&i.Attributes,
 		&i.Content,
+		&i.PartialHash,
+		&i.ContentHash,
+		&i.Metadata,
 	)
 	return i, err
 }
@@ -0,0 +1,27 @@
+package db
+
+// Filename match modes of GetFilesParams.NameMatch
+const (
+	MatchSubstring = "substring"
+	MatchExact     = "exact"
+	MatchRegex     = "regex"
+	MatchFuzzy     = "fuzzy"
+)
+
+// Sort keys of GetFilesParams.SortBy, files with the same key are sorted by
+// path ascending. Relevance is the rank of the content search, then the
+// similarity of the filename search.
+const (
+	SortByName       = "name"
+	SortBySize       = "size"
+	SortByModifiedAt = "modified_at"
+	SortByCreatedAt  = "created_at"
+	SortByPath       = "path"
+	SortByRelevance  = "relevance"
+)
+
+// Sort orders of GetFilesParams.SortOrder
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
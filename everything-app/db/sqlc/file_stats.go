@@ -0,0 +1,190 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"time"
+	"training/db/filequery"
+)
+
+// Facets of GetFileFacetsRow.Facet
+const (
+	FacetExtension        = "extension"
+	FacetDirectory        = "directory"
+	FacetSize             = "size"
+	FacetMonth            = "month"
+	FacetLargestDirectory = "largest_directory"
+)
+
+// TopDirectory returns the top-level directory of path, the top_directory
+// function of the facet queries
+func TopDirectory(path string) string {
+	if path == "" {
+		return "/"
+	}
+	i := strings.IndexByte(path[1:], '/')
+	if i < 0 {
+		return "/"
+	}
+	return path[:i+1]
+}
+
+// ParentDirectory returns the directory of path, the parent_directory
+// function of the facet queries
+func ParentDirectory(path string) string {
+	i := strings.LastIndexByte(path, '/')
+	switch {
+	case i < 0:
+		return path
+	case i == 0:
+		return "/"
+	}
+	return path[:i]
+}
+
+// UTCMonth returns the month of t in UTC as YYYY-MM, the utc_month function
+// of the facet queries
+func UTCMonth(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// GetFileFacets counts the files of GetFiles by facet, see GetFiles for
+// arg.Content
+func (store *SQLStore) GetFileFacets(ctx context.Context, arg GetFileFacetsParams) ([]GetFileFacetsRow, error) {
+	content, err := tsQuery(arg.Content)
+	if err != nil {
+		return nil, err
+	}
+	arg.Content = content
+
+	var rows []GetFileFacetsRow
+	err = store.searchTx(ctx, arg.Name, arg.NameMatch, arg.Threshold, func(q *Queries) error {
+		rows, err = q.GetFileFacets(ctx, arg)
+		return err
+	})
+	return rows, err
+}
+
+// GetLargestFiles returns the largest files of GetFiles, see GetFiles for
+// arg.Content
+func (store *SQLStore) GetLargestFiles(ctx context.Context, arg GetLargestFilesParams) ([]GetLargestFilesRow, error) {
+	content, err := tsQuery(arg.Content)
+	if err != nil {
+		return nil, err
+	}
+	arg.Content = content
+
+	var rows []GetLargestFilesRow
+	err = store.searchTx(ctx, arg.Name, arg.NameMatch, arg.Threshold, func(q *Queries) error {
+		rows, err = q.GetLargestFiles(ctx, arg)
+		return err
+	})
+	return rows, err
+}
+
+// fileFacetsSQL is the query of GetFileFacets without its filters, split at
+// the filters and at the top limit
+var fileFacetsSQL = [3]string{
+	"WITH matches AS (SELECT path, extension, size, modified_at FROM file WHERE ",
+	`)
+SELECT CAST('extension' AS TEXT) AS facet, CAST(extension AS TEXT) AS value, count(*) AS files, CAST(sum(size) AS BIGINT) AS size
+FROM matches GROUP BY extension
+UNION ALL
+SELECT 'directory', top_directory(path), count(*), CAST(sum(size) AS BIGINT)
+FROM matches GROUP BY top_directory(path)
+UNION ALL
+SELECT 'size', size_name(size), count(*), CAST(sum(size) AS BIGINT)
+FROM matches GROUP BY size_name(size)
+UNION ALL
+SELECT 'month', utc_month(modified_at), count(*), CAST(sum(size) AS BIGINT)
+FROM matches GROUP BY utc_month(modified_at)
+UNION ALL
+SELECT facet, value, files, size FROM (
+    SELECT 'largest_directory' AS facet, parent_directory(path) AS value, count(*) AS files, CAST(sum(size) AS BIGINT) AS size
+    FROM matches GROUP BY parent_directory(path)
+    ORDER BY size DESC, value
+    LIMIT `,
+	`
+) AS largest_directories
+ORDER BY facet, size DESC, value`,
+}
+
+// QueryFileFacetsOn runs GetFileFacets for the files matching a filequery
+// query on a database of dialect
+func QueryFileFacetsOn(ctx context.Context, conn DBTX, dialect filequery.Dialect, query filequery.Node, topLimit int32) ([]GetFileFacetsRow, error) {
+	builder := &filequery.Builder{Dialect: dialect}
+	builder.WriteString(fileFacetsSQL[0])
+	filequery.Compile(builder, query)
+	builder.WriteString(fileFacetsSQL[1])
+	builder.Arg(int64(topLimit))
+	builder.WriteString(fileFacetsSQL[2])
+
+	rows, err := conn.QueryContext(ctx, builder.String(), builder.Args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetFileFacetsRow{}
+	for rows.Next() {
+		var i GetFileFacetsRow
+		if err := rows.Scan(&i.Facet, &i.Value, &i.Files, &i.Size); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// QueryLargestFilesOn runs GetLargestFiles for the files matching a
+// filequery query on a database of dialect
+func QueryLargestFilesOn(ctx context.Context, conn DBTX, dialect filequery.Dialect, query filequery.Node, topLimit int32) ([]GetLargestFilesRow, error) {
+	builder := &filequery.Builder{Dialect: dialect}
+	builder.WriteString("SELECT path, name, extension, size, modified_at FROM file WHERE ")
+	filequery.Compile(builder, query)
+	builder.WriteString(" ORDER BY size DESC, path LIMIT ")
+	builder.Arg(int64(topLimit))
+
+	rows, err := conn.QueryContext(ctx, builder.String(), builder.Args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetLargestFilesRow{}
+	for rows.Next() {
+		var i GetLargestFilesRow
+		if err := rows.Scan(
+			&i.Path,
+			&i.Name,
+			&i.Extension,
+			&i.Size,
+			&i.ModifiedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// QueryFileFacets counts the files matching a filequery query by facet
+func (store *SQLStore) QueryFileFacets(ctx context.Context, query filequery.Node, topLimit int32) ([]GetFileFacetsRow, error) {
+	return QueryFileFacetsOn(ctx, store.db, filequery.Postgres, query, topLimit)
+}
+
+// QueryLargestFiles returns the topLimit largest files matching a filequery
+// query
+func (store *SQLStore) QueryLargestFiles(ctx context.Context, query filequery.Node, topLimit int32) ([]GetLargestFilesRow, error) {
+	return QueryLargestFilesOn(ctx, store.db, filequery.Postgres, query, topLimit)
+}
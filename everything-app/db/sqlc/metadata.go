@@ -0,0 +1,51 @@
+package db
+
+import (
+	"encoding/json"
+)
+
+// MetadataContains reports whether the metadata object of a file holds
+// every value of filter, a JSON object of strings. It is the jsonb @> of the
+// metadata filter for stores without jsonb.
+func MetadataContains(metadata, filter string) bool {
+	var values map[string]string
+	if err := json.Unmarshal([]byte(filter), &values); err != nil {
+		return false
+	}
+	if len(values) == 0 {
+		return true
+	}
+	object := MetadataValues(json.RawMessage(metadata))
+	for key, value := range values {
+		if text, ok := object[key]; !ok || text != value {
+			return false
+		}
+	}
+	return true
+}
+
+// MetadataFilter encodes the values of a metadata filter, the argument of
+// GetFilesParams.Metadata. No values give an empty filter.
+func MetadataFilter(values map[string]string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	filter, _ := json.Marshal(values)
+	return string(filter)
+}
+
+// MetadataValues decodes the metadata of a file, values that are not text are
+// left out
+func MetadataValues(metadata json.RawMessage) map[string]string {
+	var object map[string]any
+	if err := json.Unmarshal(metadata, &object); err != nil {
+		return nil
+	}
+	values := make(map[string]string, len(object))
+	for key, value := range object {
+		if text, ok := value.(string); ok {
+			values[key] = text
+		}
+	}
+	return values
+}
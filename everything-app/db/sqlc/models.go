@@ -5,20 +5,46 @@
 package db
 
 import (
+	"encoding/json"
 	"time"
 )
 
 type File struct {
-	ID         int32     `json:"id"`
-	Name       string    `json:"name"`
-	Extension  string    `json:"extension"`
-	Size       int64     `json:"size"`
-	Path       string    `json:"path"`
-	CreatedAt  time.Time `json:"created_at"`
-	ModifiedAt time.Time `json:"modified_at"`
-	AccessedAt time.Time `json:"accessed_at"`
-	Attributes string    `json:"attributes"`
-	Content    string    `json:"content"`
+	ID          int32           `json:"id"`
+	Name        string          `json:"name"`
+	Extension   string          `json:"extension"`
+	Size        int64           `json:"size"`
+	Path        string          `json:"path"`
+	CreatedAt   time.Time       `json:"created_at"`
+	ModifiedAt  time.Time       `json:"modified_at"`
+	AccessedAt  time.Time       `json:"accessed_at"`
+	Attributes  string          `json:"attributes"`
+	Content     string          `json:"content"`
+	PartialHash string          `json:"partial_hash"`
+	ContentHash string          `json:"content_hash"`
+	Metadata    json.RawMessage `json:"metadata"`
+}
+
+type Notification struct {
+	ID            int32     `json:"id"`
+	SavedSearchID int32     `json:"saved_search_id"`
+	Path          string    `json:"path"`
+	ChangeType    string    `json:"change_type"`
+	CreatedAt     time.Time `json:"created_at"`
+	Attempts      int32     `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     string    `json:"last_error"`
+	DeliveredAt   time.Time `json:"delivered_at"`
+}
+
+type SavedSearch struct {
+	ID        int32     `json:"id"`
+	Owner     string    `json:"owner"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	Watch     bool      `json:"watch"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type User struct {
@@ -35,3 +61,9 @@ type User struct {
 	CreatedAt    time.Time `json:"created_at"`
 	UpdateAt     time.Time `json:"update_at"`
 }
+
+type WatchResumeToken struct {
+	ID        int32     `json:"id"`
+	Token     string    `json:"token"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
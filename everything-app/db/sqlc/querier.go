@@ -9,16 +9,63 @@ import (
 )
 
 type Querier interface {
+	// The files of GetFiles are counted up to count_limit
+	CountFiles(ctx context.Context, arg CountFilesParams) (int64, error)
+	CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error)
+	CreateSavedSearch(ctx context.Context, arg CreateSavedSearchParams) (SavedSearch, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DeleteFileByPath(ctx context.Context, path string) (int64, error)
+	DeleteSavedSearch(ctx context.Context, id int32) (int64, error)
 	DeleteUser(ctx context.Context, arg DeleteUserParams) (User, error)
-	// If limit is not provided, return all results
-	GetFiles(ctx context.Context, arg GetFilesParams) ([]string, error)
+	// The files of the group_limit groups of files with the same content hash
+	// below prefix that waste the most bytes, only files of at least min_size
+	// bytes are grouped. A group wastes the size of all of its files but one.
+	GetDuplicateFiles(ctx context.Context, arg GetDuplicateFilesParams) ([]GetDuplicateFilesRow, error)
+	// The number of groups of GetDuplicateFiles, of their files and of the
+	// bytes they waste
+	GetDuplicateTotals(ctx context.Context, arg GetDuplicateTotalsParams) (GetDuplicateTotalsRow, error)
+	// The number and the total size of the files matching the filters of
+	// GetFiles by extension, top-level directory, named size and modification
+	// month in UTC, and the top_limit directories with the largest total size
+	// of the matching files they directly contain. The facets are ordered by
+	// name, then by size descending.
+	GetFileFacets(ctx context.Context, arg GetFileFacetsParams) ([]GetFileFacetsRow, error)
+	// name is searched according to name_match: substring, exact, regex or fuzzy.
+	// Fuzzy matches the name or the path with a trigram word similarity of at
+	// least threshold, pg_trgm.word_similarity_threshold has to be threshold as
+	// well for the trigram indexes to be used. content is a to_tsquery expression.
+	// Empty searches are skipped. Content hits are ranked by relevance and come
+	// with a highlighted snippet of the content.
+	// Files are sorted by sort_by in sort_order, then by path ascending. A page
+	// after a file starts after after_path and the sort key of the file, given
+	// in the after argument of its type. If limit is 0, return all results
+	GetFiles(ctx context.Context, arg GetFilesParams) ([]GetFilesRow, error)
+	// The top_limit largest files matching the filters of GetFiles
+	GetLargestFiles(ctx context.Context, arg GetLargestFilesParams) ([]GetLargestFilesRow, error)
+	GetSavedSearch(ctx context.Context, id int32) (SavedSearch, error)
 	GetUserById(ctx context.Context, id int32) (User, error)
 	GetUserByUsername(ctx context.Context, username string) (User, error)
 	GetUsersAsc(ctx context.Context, arg GetUsersAscParams) ([]User, error)
 	GetUsersDesc(ctx context.Context, arg GetUsersDescParams) ([]User, error)
+	GetWatchResumeToken(ctx context.Context) (string, error)
+	// No row is returned if the path is already stored
 	InsertFile(ctx context.Context, arg InsertFileParams) (File, error)
+	// Notifications waiting for an attempt due at now, with their saved search
+	ListDueNotifications(ctx context.Context, arg ListDueNotificationsParams) ([]ListDueNotificationsRow, error)
+	ListFilesByPrefix(ctx context.Context, prefix string) ([]File, error)
+	// The newest notifications of a saved search first
+	ListNotifications(ctx context.Context, arg ListNotificationsParams) ([]Notification, error)
+	ListSavedSearches(ctx context.Context, owner string) ([]SavedSearch, error)
+	ListWatchedSearches(ctx context.Context) ([]SavedSearch, error)
+	SetWatchResumeToken(ctx context.Context, arg SetWatchResumeTokenParams) error
+	// Stores the hashes computed for the duplicate search
+	UpdateFileHashes(ctx context.Context, arg UpdateFileHashesParams) (int64, error)
+	// Records an attempt to deliver a notification, delivered_at is the zero
+	// time if it failed
+	UpdateNotificationAttempt(ctx context.Context, arg UpdateNotificationAttemptParams) error
+	UpdateSavedSearch(ctx context.Context, arg UpdateSavedSearchParams) (SavedSearch, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpsertFile(ctx context.Context, arg UpsertFileParams) (File, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+	"time"
+	"training/db/filequery"
+)
+
+// QueryFilesParams is a file search by a parsed filequery query, sorted and
+// paged like GetFiles. Files have no relevance, sorting by relevance sorts
+// by path.
+type QueryFilesParams struct {
+	Query      filequery.Node
+	SortBy     string
+	SortOrder  string
+	AfterPath  string
+	AfterText  string
+	AfterSize  int64
+	AfterTime  time.Time
+	PageOffset int32
+	PageLimit  int32
+}
+
+// sortColumns are the columns of the sort keys of QueryFiles
+var sortColumns = map[string]string{
+	SortByName:       "name",
+	SortBySize:       "size",
+	SortByModifiedAt: "modified_at",
+	SortByCreatedAt:  "created_at",
+	SortByPath:       "path",
+}
+
+// queryFilesSQL returns the query of QueryFiles compiled for dialect, it
+// selects the columns of a GetFilesRow without rank and similarity
+func queryFilesSQL(arg QueryFilesParams, dialect filequery.Dialect) (string, []interface{}) {
+	builder := &filequery.Builder{Dialect: dialect}
+	builder.WriteString("SELECT path, name, extension, size, created_at, modified_at, accessed_at, attributes, metadata FROM file WHERE ")
+	filequery.Compile(builder, arg.Query)
+
+	column, ok := sortColumns[arg.SortBy]
+	direction, op := "ASC", ">"
+	if ok && arg.SortOrder == SortDesc {
+		direction, op = "DESC", "<"
+	}
+	if arg.AfterPath != "" {
+		// The page starts after the sort key and the path of the cursor
+		builder.WriteString(" AND ")
+		switch column {
+		case "", "path":
+			builder.WriteString("path " + op + " ")
+			builder.Arg(arg.AfterPath)
+		default:
+			var after interface{}
+			switch arg.SortBy {
+			case SortByName:
+				after = arg.AfterText
+			case SortBySize:
+				after = arg.AfterSize
+			default:
+				after = arg.AfterTime
+			}
+			builder.WriteString("(" + column + " " + op + " ")
+			builder.Arg(after)
+			builder.WriteString(" OR (" + column + " = ")
+			builder.Arg(after)
+			builder.WriteString(" AND path > ")
+			builder.Arg(arg.AfterPath)
+			builder.WriteString("))")
+		}
+	}
+
+	builder.WriteString(" ORDER BY ")
+	if column != "" && column != "path" {
+		builder.WriteString(column + " " + direction + ", path ASC")
+	} else {
+		builder.WriteString("path " + direction)
+	}
+	builder.WriteString(" LIMIT ")
+	if arg.PageLimit > 0 {
+		builder.Arg(int64(arg.PageLimit))
+	} else {
+		builder.WriteString(dialect.NoLimit)
+	}
+	builder.WriteString(" OFFSET ")
+	builder.Arg(int64(max(arg.PageOffset, 0)))
+	return builder.String(), builder.Args
+}
+
+// countQueryFilesSQL returns the query counting the files of QueryFiles up
+// to limit
+func countQueryFilesSQL(query filequery.Node, limit int64, dialect filequery.Dialect) (string, []interface{}) {
+	builder := &filequery.Builder{Dialect: dialect}
+	builder.WriteString("SELECT count(*) FROM (SELECT 1 FROM file WHERE ")
+	filequery.Compile(builder, query)
+	builder.WriteString(" LIMIT ")
+	builder.Arg(limit)
+	builder.WriteString(") AS matches")
+	return builder.String(), builder.Args
+}
+
+// QueryFilesOn runs QueryFiles on a database of dialect, for the stores on
+// SQL databases
+func QueryFilesOn(ctx context.Context, conn DBTX, dialect filequery.Dialect, arg QueryFilesParams) ([]GetFilesRow, error) {
+	query, args := queryFilesSQL(arg, dialect)
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetFilesRow{}
+	for rows.Next() {
+		var i GetFilesRow
+		// SQLite returns the metadata as text, which only scans into bytes
+		var metadata []byte
+		if err := rows.Scan(
+			&i.Path,
+			&i.Name,
+			&i.Extension,
+			&i.Size,
+			&i.CreatedAt,
+			&i.ModifiedAt,
+			&i.AccessedAt,
+			&i.Attributes,
+			&metadata,
+		); err != nil {
+			return nil, err
+		}
+		i.Metadata = metadata
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// CountQueryFilesOn runs CountQueryFiles on a database of dialect
+func CountQueryFilesOn(ctx context.Context, conn DBTX, dialect filequery.Dialect, query filequery.Node, limit int64) (int64, error) {
+	sql, args := countQueryFilesSQL(query, limit, dialect)
+	var count int64
+	err := conn.QueryRowContext(ctx, sql, args...).Scan(&count)
+	return count, err
+}
+
+// QueryFiles returns the files matching a filequery query
+func (store *SQLStore) QueryFiles(ctx context.Context, arg QueryFilesParams) ([]GetFilesRow, error) {
+	return QueryFilesOn(ctx, store.db, filequery.Postgres, arg)
+}
+
+// CountQueryFiles counts the files matching a filequery query up to limit
+func (store *SQLStore) CountQueryFiles(ctx context.Context, query filequery.Node, limit int64) (int64, error) {
+	return CountQueryFilesOn(ctx, store.db, filequery.Postgres, query, limit)
+}
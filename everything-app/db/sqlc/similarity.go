@@ -0,0 +1,40 @@
+package db
+
+// WordSimilarity is the pg_trgm word_similarity of query and text for stores
+// without pg_trgm: the greatest similarity of the trigrams of query and of the
+// trigrams of any run of as many consecutive words of text. It is in [0, 1].
+func WordSimilarity(query, text string) float32 {
+	queryWords := textWords(query)
+	queryTrigrams := trigrams(queryWords)
+	if len(queryTrigrams) == 0 {
+		return 0
+	}
+
+	words := textWords(text)
+	var best float32
+	for i := range words {
+		extent := trigrams(words[i:min(i+len(queryWords), len(words))])
+		common := 0
+		for trigram := range queryTrigrams {
+			if extent[trigram] {
+				common++
+			}
+		}
+		similarity := float32(common) / float32(len(queryTrigrams)+len(extent)-common)
+		best = max(best, similarity)
+	}
+	return best
+}
+
+// trigrams returns the trigrams of words padded like pg_trgm does, with two
+// spaces before and one after each word
+func trigrams(words []string) map[string]bool {
+	result := make(map[string]bool)
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			result[string(padded[i:i+3])] = true
+		}
+	}
+	return result
+}
@@ -3,11 +3,19 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
+	"training/db/filequery"
 )
 
 type Store interface {
 	Querier
+	FileBatchTx(ctx context.Context, ops []FileOp) (FileBatchTxResult, error)
+	QueryFiles(ctx context.Context, arg QueryFilesParams) ([]GetFilesRow, error)
+	CountQueryFiles(ctx context.Context, query filequery.Node, limit int64) (int64, error)
+	QueryFileFacets(ctx context.Context, query filequery.Node, topLimit int32) ([]GetFileFacetsRow, error)
+	QueryLargestFiles(ctx context.Context, query filequery.Node, topLimit int32) ([]GetLargestFilesRow, error)
 }
 
 // SQLStore provides all functions to execute SQL queries and transactions
@@ -41,3 +49,118 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 	}
 	return tx.Commit()
 }
+
+// FileOp is one write of a FileBatchTx, exactly one of the fields is set
+type FileOp struct {
+	Insert *InsertFileParams
+	Upsert *UpsertFileParams
+	Hashes *UpdateFileHashesParams
+	// Delete is the path of the file to delete
+	Delete string
+}
+
+// FileBatchTxResult contains the result of a FileBatchTx
+type FileBatchTxResult struct {
+	// Existing holds the indexes of the inserts skipped because the path was already stored
+	Existing []int
+	// Deleted is the number of deleted rows
+	Deleted int64
+}
+
+// FileBatchTx applies ops in order in a single database transaction.
+// An insert of a path that is already stored does not abort the transaction,
+// it is reported in the result.
+func (store *SQLStore) FileBatchTx(ctx context.Context, ops []FileOp) (FileBatchTxResult, error) {
+	var result FileBatchTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		result = FileBatchTxResult{}
+		for i, op := range ops {
+			var err error
+			switch {
+			case op.Insert != nil:
+				_, err = q.InsertFile(ctx, *op.Insert)
+				if errors.Is(err, sql.ErrNoRows) {
+					result.Existing = append(result.Existing, i)
+					err = nil
+				}
+			case op.Upsert != nil:
+				_, err = q.UpsertFile(ctx, *op.Upsert)
+			case op.Hashes != nil:
+				_, err = q.UpdateFileHashes(ctx, *op.Hashes)
+			default:
+				var deleted int64
+				deleted, err = q.DeleteFileByPath(ctx, op.Delete)
+				result.Deleted += deleted
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// GetFiles searches the content with arg.Content parsed as a TextQuery, the
+// generated query takes a to_tsquery expression
+func (store *SQLStore) GetFiles(ctx context.Context, arg GetFilesParams) ([]GetFilesRow, error) {
+	content, err := tsQuery(arg.Content)
+	if err != nil {
+		return nil, err
+	}
+	arg.Content = content
+
+	var rows []GetFilesRow
+	err = store.searchTx(ctx, arg.Name, arg.NameMatch, arg.Threshold, func(q *Queries) error {
+		rows, err = q.GetFiles(ctx, arg)
+		return err
+	})
+	return rows, err
+}
+
+// CountFiles counts the files of GetFiles, see GetFiles for arg.Content
+func (store *SQLStore) CountFiles(ctx context.Context, arg CountFilesParams) (int64, error) {
+	content, err := tsQuery(arg.Content)
+	if err != nil {
+		return 0, err
+	}
+	arg.Content = content
+
+	var count int64
+	err = store.searchTx(ctx, arg.Name, arg.NameMatch, arg.Threshold, func(q *Queries) error {
+		count, err = q.CountFiles(ctx, arg)
+		return err
+	})
+	return count, err
+}
+
+// searchTx runs a file search. A fuzzy filename search runs in a transaction
+// that sets the word similarity threshold of pg_trgm to threshold, so the
+// trigram indexes select the same files as the query.
+func (store *SQLStore) searchTx(ctx context.Context, name, match string, threshold float32, fn func(*Queries) error) error {
+	if name == "" || match != MatchFuzzy {
+		return fn(store.Queries)
+	}
+	return store.execTx(ctx, func(q *Queries) error {
+		_, err := q.db.ExecContext(ctx, "SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)",
+			strconv.FormatFloat(float64(threshold), 'f', -1, 32))
+		if err != nil {
+			return err
+		}
+		return fn(q)
+	})
+}
+
+// tsQuery returns content, a TextQuery, as a to_tsquery expression
+func tsQuery(content string) (string, error) {
+	if content == "" {
+		return "", nil
+	}
+	query, err := ParseTextQuery(content)
+	if err != nil {
+		return "", err
+	}
+	return query.TsQuery(), nil
+}
@@ -0,0 +1,506 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// TextQuery is a parsed content search. A query is made of words and "quoted
+// phrases", a trailing * turns the last word of a term into a prefix. Terms
+// are combined with AND, which is implicit between terms, and OR. A term is
+// excluded with - or NOT and parentheses group terms.
+//
+//	"annual report" 2023 -draft
+//	(invoice OR receipt) pay*
+//
+// Excluded terms have to be combined with a term that is not excluded, so a
+// query never matches every file.
+type TextQuery struct {
+	root textNode
+}
+
+type textNode interface{}
+
+type textTerm struct {
+	text   string
+	words  []string
+	prefix bool
+}
+
+type textNot struct {
+	node textNode
+}
+
+type textAnd []textNode
+
+type textOr []textNode
+
+// ErrTextQuery is wrapped by the errors of ParseTextQuery
+var ErrTextQuery = errors.New("invalid text query")
+
+// ParseTextQuery parses a content search, see TextQuery for the syntax
+func ParseTextQuery(query string) (*TextQuery, error) {
+	tokens, err := scanTextQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	parser := textParser{tokens: tokens}
+	root, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != textEOF {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrTextQuery, token.text)
+	}
+	if err := checkExclusions(root); err != nil {
+		return nil, err
+	}
+	return &TextQuery{root: root}, nil
+}
+
+type textTokenKind int
+
+const (
+	textEOF textTokenKind = iota
+	textWord
+	textPhrase
+	textAndOp
+	textOrOp
+	textNotOp
+	textOpen
+	textClose
+)
+
+type textToken struct {
+	kind   textTokenKind
+	text   string
+	prefix bool
+}
+
+func scanTextQuery(query string) ([]textToken, error) {
+	var tokens []textToken
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, textToken{kind: textOpen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, textToken{kind: textClose, text: ")"})
+			i++
+		case r == '|':
+			tokens = append(tokens, textToken{kind: textOrOp, text: "|"})
+			i++
+		case r == '&':
+			tokens = append(tokens, textToken{kind: textAndOp, text: "&"})
+			i++
+		case r == '-' || r == '!':
+			tokens = append(tokens, textToken{kind: textNotOp, text: string(r)})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("%w: unterminated phrase %q", ErrTextQuery, string(runes[i:]))
+			}
+			token := textToken{kind: textPhrase, text: string(runes[i+1 : end])}
+			i = end + 1
+			if i < len(runes) && runes[i] == '*' {
+				token.prefix = true
+				i++
+			}
+			tokens = append(tokens, token)
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(`()"|&`, runes[end]) {
+				end++
+			}
+			text := string(runes[i:end])
+			i = end
+			switch text {
+			case "AND":
+				tokens = append(tokens, textToken{kind: textAndOp, text: text})
+			case "OR":
+				tokens = append(tokens, textToken{kind: textOrOp, text: text})
+			case "NOT":
+				tokens = append(tokens, textToken{kind: textNotOp, text: text})
+			default:
+				trimmed := strings.TrimRight(text, "*")
+				tokens = append(tokens, textToken{kind: textWord, text: trimmed, prefix: trimmed != text})
+			}
+		}
+	}
+	return append(tokens, textToken{kind: textEOF}), nil
+}
+
+type textParser struct {
+	tokens []textToken
+	next   int
+}
+
+func (parser *textParser) peek() textToken {
+	return parser.tokens[parser.next]
+}
+
+func (parser *textParser) take() textToken {
+	token := parser.tokens[parser.next]
+	if token.kind != textEOF {
+		parser.next++
+	}
+	return token
+}
+
+func (parser *textParser) parseOr() (textNode, error) {
+	var operands textOr
+	for {
+		operand, err := parser.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+		if parser.peek().kind != textOrOp {
+			break
+		}
+		parser.take()
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return operands, nil
+}
+
+func (parser *textParser) parseAnd() (textNode, error) {
+	var operands textAnd
+	for {
+		switch parser.peek().kind {
+		case textEOF, textClose, textOrOp:
+			if len(operands) == 0 {
+				return nil, fmt.Errorf("%w: expected a term", ErrTextQuery)
+			}
+			if len(operands) == 1 {
+				return operands[0], nil
+			}
+			return operands, nil
+		case textAndOp:
+			if len(operands) == 0 {
+				return nil, fmt.Errorf("%w: expected a term before %q", ErrTextQuery, parser.peek().text)
+			}
+			parser.take()
+		}
+		operand, err := parser.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+}
+
+func (parser *textParser) parseUnary() (textNode, error) {
+	if parser.peek().kind != textNotOp {
+		return parser.parsePrimary()
+	}
+	parser.take()
+	if parser.peek().kind == textNotOp {
+		return nil, fmt.Errorf("%w: a term cannot be excluded twice", ErrTextQuery)
+	}
+	node, err := parser.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	return textNot{node: node}, nil
+}
+
+func (parser *textParser) parsePrimary() (textNode, error) {
+	token := parser.take()
+	switch token.kind {
+	case textOpen:
+		node, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if parser.take().kind != textClose {
+			return nil, fmt.Errorf("%w: missing )", ErrTextQuery)
+		}
+		return node, nil
+	case textWord, textPhrase:
+		words := textWords(token.text)
+		if len(words) == 0 {
+			return nil, fmt.Errorf("%w: %q has no words", ErrTextQuery, token.text)
+		}
+		return textTerm{text: token.text, words: words, prefix: token.prefix}, nil
+	case textEOF:
+		return nil, fmt.Errorf("%w: expected a term", ErrTextQuery)
+	}
+	return nil, fmt.Errorf("%w: unexpected %q", ErrTextQuery, token.text)
+}
+
+// checkExclusions makes sure every excluded term is an operand of an AND
+// with a term that is not excluded
+func checkExclusions(node textNode) error {
+	switch node := node.(type) {
+	case textNot:
+		return fmt.Errorf("%w: excluded terms need a term to match", ErrTextQuery)
+	case textAnd:
+		included := false
+		for _, operand := range node {
+			if not, ok := operand.(textNot); ok {
+				operand = not.node
+			} else {
+				included = true
+			}
+			if err := checkExclusions(operand); err != nil {
+				return err
+			}
+		}
+		if !included {
+			return fmt.Errorf("%w: excluded terms need a term to match", ErrTextQuery)
+		}
+	case textOr:
+		for _, operand := range node {
+			if err := checkExclusions(operand); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// textWords splits text in lower case words of letters and digits
+func textWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+var tsQueryQuoter = strings.NewReplacer(`\`, `\\`, `'`, `''`)
+
+// TsQuery returns the query in the syntax of Postgres to_tsquery
+func (query *TextQuery) TsQuery() string {
+	var tsQuery strings.Builder
+	writeTsQuery(&tsQuery, query.root)
+	return tsQuery.String()
+}
+
+func writeTsQuery(tsQuery *strings.Builder, node textNode) {
+	switch node := node.(type) {
+	case textTerm:
+		for i, part := range tsQueryParts(node) {
+			if i > 0 {
+				tsQuery.WriteString(" <-> ")
+			}
+			tsQuery.WriteString("'" + tsQueryQuoter.Replace(part) + "'")
+		}
+		if node.prefix {
+			tsQuery.WriteString(":*")
+		}
+	case textNot:
+		tsQuery.WriteString("!")
+		writeTsQueryOperand(tsQuery, node.node)
+	case textAnd:
+		for i, operand := range node {
+			if i > 0 {
+				tsQuery.WriteString(" & ")
+			}
+			writeTsQueryOperand(tsQuery, operand)
+		}
+	case textOr:
+		for i, operand := range node {
+			if i > 0 {
+				tsQuery.WriteString(" | ")
+			}
+			writeTsQueryOperand(tsQuery, operand)
+		}
+	}
+}
+
+func writeTsQueryOperand(tsQuery *strings.Builder, node textNode) {
+	if term, ok := node.(textTerm); ok && len(tsQueryParts(term)) == 1 {
+		writeTsQuery(tsQuery, node)
+		return
+	}
+	tsQuery.WriteString("(")
+	writeTsQuery(tsQuery, node)
+	tsQuery.WriteString(")")
+}
+
+// tsQueryParts returns the parts of a term that are parsed by Postgres and
+// joined in a phrase, the parts without words would be dropped as stop words
+func tsQueryParts(term textTerm) []string {
+	var parts []string
+	for _, part := range strings.Fields(term.text) {
+		if len(textWords(part)) > 0 {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// MatchQuery returns the query in the syntax of SQLite FTS5 MATCH
+func (query *TextQuery) MatchQuery() string {
+	var match strings.Builder
+	writeMatchQuery(&match, query.root)
+	return match.String()
+}
+
+func writeMatchQuery(match *strings.Builder, node textNode) {
+	switch node := node.(type) {
+	case textTerm:
+		match.WriteString(`"` + strings.Join(node.words, " ") + `"`)
+		if node.prefix {
+			match.WriteString("*")
+		}
+	case textAnd:
+		// FTS5 only has a binary NOT, the excluded terms are removed from
+		// the AND of the other terms
+		var included, excluded []textNode
+		for _, operand := range node {
+			if not, ok := operand.(textNot); ok {
+				excluded = append(excluded, not.node)
+			} else {
+				included = append(included, operand)
+			}
+		}
+		match.WriteString(strings.Repeat("(", len(excluded)))
+		for i, operand := range included {
+			if i > 0 {
+				match.WriteString(" AND ")
+			}
+			writeMatchOperand(match, operand)
+		}
+		for _, operand := range excluded {
+			match.WriteString(" NOT ")
+			writeMatchOperand(match, operand)
+			match.WriteString(")")
+		}
+	case textOr:
+		for i, operand := range node {
+			if i > 0 {
+				match.WriteString(" OR ")
+			}
+			writeMatchOperand(match, operand)
+		}
+	}
+}
+
+func writeMatchOperand(match *strings.Builder, node textNode) {
+	if _, ok := node.(textTerm); ok {
+		writeMatchQuery(match, node)
+		return
+	}
+	match.WriteString("(")
+	writeMatchQuery(match, node)
+	match.WriteString(")")
+}
+
+// snippetWords is the number of words around the first hit in a snippet
+const snippetWords = 20
+
+type wordSpan struct {
+	word       string
+	start, end int
+}
+
+type textHit struct {
+	first, last int
+}
+
+// Search matches text against the query without stemming, for stores that
+// have no full-text index. The rank grows with the number of hits and is in
+// [0, 1) like ts_rank_cd with normalization 32. The snippet highlights the
+// hits around the first one with <mark>.
+func (query *TextQuery) Search(text string) (rank float32, snippet string, ok bool) {
+	var words []wordSpan
+	start := -1
+	for i, r := range text + " " {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			words = append(words, wordSpan{word: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+
+	ok, hits := searchNode(query.root, words)
+	if !ok {
+		return 0, "", false
+	}
+	if len(hits) == 0 {
+		return 0, "", true
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].first < hits[j].first })
+	rank = float32(len(hits)) / float32(len(hits)+1)
+
+	first := max(hits[0].first-snippetWords/2, 0)
+	last := min(first+snippetWords, len(words)) - 1
+	var builder strings.Builder
+	position := words[first].start
+	for _, hit := range hits {
+		if hit.first < first || hit.last > last || words[hit.first].start < position {
+			continue
+		}
+		builder.WriteString(text[position:words[hit.first].start])
+		builder.WriteString("<mark>" + text[words[hit.first].start:words[hit.last].end] + "</mark>")
+		position = words[hit.last].end
+	}
+	builder.WriteString(text[position:words[last].end])
+	return rank, builder.String(), true
+}
+
+func searchNode(node textNode, words []wordSpan) (bool, []textHit) {
+	switch node := node.(type) {
+	case textTerm:
+		var hits []textHit
+		for i := 0; i+len(node.words) <= len(words); i++ {
+			if matchTerm(node, words[i:i+len(node.words)]) {
+				hits = append(hits, textHit{first: i, last: i + len(node.words) - 1})
+			}
+		}
+		return len(hits) > 0, hits
+	case textNot:
+		ok, _ := searchNode(node.node, words)
+		return !ok, nil
+	case textAnd:
+		var hits []textHit
+		for _, operand := range node {
+			ok, operandHits := searchNode(operand, words)
+			if !ok {
+				return false, nil
+			}
+			hits = append(hits, operandHits...)
+		}
+		return true, hits
+	case textOr:
+		matched := false
+		var hits []textHit
+		for _, operand := range node {
+			if ok, operandHits := searchNode(operand, words); ok {
+				matched = true
+				hits = append(hits, operandHits...)
+			}
+		}
+		return matched, hits
+	}
+	return false, nil
+}
+
+func matchTerm(term textTerm, words []wordSpan) bool {
+	for i, word := range term.words {
+		if i == len(term.words)-1 && term.prefix {
+			return strings.HasPrefix(words[i].word, word)
+		}
+		if words[i].word != word {
+			return false
+		}
+	}
+	return true
+}
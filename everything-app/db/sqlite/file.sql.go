@@ -0,0 +1,751 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: file.sql
+
+package sqlite
+
+import (
+	"context"
+	"time"
+)
+
+const countFiles = `-- name: CountFiles :one
+SELECT
+    count(*)
+FROM (
+    SELECT
+        1
+    FROM
+        file
+    WHERE
+        (CAST(?1 AS TEXT) = '' OR file.id IN (
+            SELECT rowid FROM file_text WHERE file_text.content MATCH CAST(?1 AS TEXT)
+        ))
+        AND -- Filename search
+        (CAST(?2 AS TEXT) = ''
+            -- LIKE ignores case like ILIKE and uses the trigram index
+            OR (CAST(?3 AS TEXT) = 'substring' AND file.id IN (
+                SELECT rowid FROM file_fts WHERE file_fts.name LIKE '%' || CAST(?2 AS TEXT) || '%'
+            ))
+            OR (CAST(?3 AS TEXT) = 'exact' AND file.name = CAST(?2 AS TEXT))
+            OR (CAST(?3 AS TEXT) = 'regex' AND file.name REGEXP CAST(?2 AS TEXT))
+            OR (CAST(?3 AS TEXT) = 'fuzzy' AND max(
+                word_similarity(CAST(?2 AS TEXT), file.name),
+                word_similarity(CAST(?2 AS TEXT), file.path)
+            ) >= CAST(?4 AS REAL)))
+        -- File extension exact match
+        AND (CAST(?5 AS TEXT) = '' OR file.extension = CAST(?5 AS TEXT))
+        -- File size range search
+        AND file.size >= ?6
+        AND file.size <= ?7
+        -- File created_at range search
+        AND file.created_at >= ?8
+        AND file.created_at <= ?9
+        -- File modified_At range search
+        AND file.modified_at >= ?10
+        AND file.modified_at <= ?11
+        -- File accessed_at range search
+        AND file.accessed_at >= ?12
+        AND file.accessed_at <= ?13
+        -- Metadata values, metadata_contains is registered by the store
+        AND (CAST(?14 AS TEXT) = '' OR metadata_contains(file.metadata, CAST(?14 AS TEXT)))
+    LIMIT ?15
+) AS matches
+`
+
+type CountFilesParams struct {
+	Query          string    `json:"query"`
+	Name           string    `json:"name"`
+	NameMatch      string    `json:"name_match"`
+	Threshold      float64   `json:"threshold"`
+	Extension      string    `json:"extension"`
+	SizeMin        int64     `json:"size_min"`
+	SizeMax        int64     `json:"size_max"`
+	CreatedAfter   time.Time `json:"created_after"`
+	CreatedBefore  time.Time `json:"created_before"`
+	ModifiedAfter  time.Time `json:"modified_after"`
+	ModifiedBefore time.Time `json:"modified_before"`
+	AccessedAfter  time.Time `json:"accessed_after"`
+	AccessedBefore time.Time `json:"accessed_before"`
+	Metadata       string    `json:"metadata"`
+	CountLimit     int64     `json:"count_limit"`
+}
+
+// The files of GetFiles and SearchFiles are counted up to count_limit, query
+// is not matched if it is empty
+func (q *Queries) CountFiles(ctx context.Context, arg CountFilesParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFiles,
+		arg.Query,
+		arg.Name,
+		arg.NameMatch,
+		arg.Threshold,
+		arg.Extension,
+		arg.SizeMin,
+		arg.SizeMax,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.ModifiedAfter,
+		arg.ModifiedBefore,
+		arg.AccessedAfter,
+		arg.AccessedBefore,
+		arg.Metadata,
+		arg.CountLimit,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteFileByPath = `-- name: DeleteFileByPath :execrows
+DELETE FROM file
+WHERE path = ?
+`
+
+func (q *Queries) DeleteFileByPath(ctx context.Context, path string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFileByPath, path)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getFiles = `-- name: GetFiles :many
+SELECT
+    matches.path,
+    matches.name,
+    matches.extension,
+    matches.size,
+    matches.created_at,
+    matches.modified_at,
+    matches.accessed_at,
+    matches.attributes,
+    matches.metadata,
+    matches.similarity
+FROM (
+    SELECT
+        file.path,
+        file.name,
+        file.extension,
+        file.size,
+        file.created_at,
+        file.modified_at,
+        file.accessed_at,
+        file.attributes,
+        file.metadata,
+        0 AS relevance,
+        CAST(CASE WHEN CAST(?1 AS TEXT) = 'fuzzy' AND CAST(?2 AS TEXT) <> '' THEN max(
+            word_similarity(CAST(?2 AS TEXT), file.name),
+            word_similarity(CAST(?2 AS TEXT), file.path)
+        ) ELSE 0 END AS REAL) AS similarity
+    FROM
+        file
+    WHERE
+        -- Filename search
+        (CAST(?2 AS TEXT) = ''
+            -- LIKE ignores case like ILIKE and uses the trigram index
+            OR (CAST(?1 AS TEXT) = 'substring' AND file.id IN (
+                SELECT rowid FROM file_fts WHERE file_fts.name LIKE '%' || CAST(?2 AS TEXT) || '%'
+            ))
+            OR (CAST(?1 AS TEXT) = 'exact' AND file.name = CAST(?2 AS TEXT))
+            OR (CAST(?1 AS TEXT) = 'regex' AND file.name REGEXP CAST(?2 AS TEXT))
+            OR (CAST(?1 AS TEXT) = 'fuzzy' AND max(
+                word_similarity(CAST(?2 AS TEXT), file.name),
+                word_similarity(CAST(?2 AS TEXT), file.path)
+            ) >= CAST(?3 AS REAL)))
+        -- File extension exact match
+        AND (CAST(?4 AS TEXT) = '' OR file.extension = CAST(?4 AS TEXT))
+        -- File size range search
+        AND file.size >= ?5
+        AND file.size <= ?6
+        -- File created_at range search
+        AND file.created_at >= ?7
+        AND file.created_at <= ?8
+        -- File modified_At range search
+        AND file.modified_at >= ?9
+        AND file.modified_at <= ?10
+        -- File accessed_at range search
+        AND file.accessed_at >= ?11
+        AND file.accessed_at <= ?12
+        -- Metadata values, metadata_contains is registered by the store
+        AND (CAST(?13 AS TEXT) = '' OR metadata_contains(file.metadata, CAST(?13 AS TEXT)))
+) AS matches,
+    (SELECT CAST(?14 AS TEXT) AS sort_by, CAST(?15 AS TEXT) AS sort_order) AS params
+WHERE
+    CAST(?16 AS TEXT) = ''
+    OR (params.sort_order = 'asc' AND CASE params.sort_by
+        WHEN 'name' THEN (name > CAST(?17 AS TEXT) OR (name = CAST(?17 AS TEXT) AND path > CAST(?16 AS TEXT)))
+        WHEN 'size' THEN (size > CAST(?18 AS INTEGER) OR (size = CAST(?18 AS INTEGER) AND path > CAST(?16 AS TEXT)))
+        WHEN 'modified_at' THEN (modified_at > ?19 OR (modified_at = ?19 AND path > CAST(?16 AS TEXT)))
+        WHEN 'created_at' THEN (created_at > ?19 OR (created_at = ?19 AND path > CAST(?16 AS TEXT)))
+        WHEN 'path' THEN path > CAST(?16 AS TEXT)
+        WHEN 'relevance' THEN (relevance > CAST(?20 AS REAL) OR (relevance = CAST(?20 AS REAL)
+            AND (similarity > CAST(?21 AS REAL) OR (similarity = CAST(?21 AS REAL) AND path > CAST(?16 AS TEXT)))))
+        ELSE path > CAST(?16 AS TEXT)
+    END)
+    OR (params.sort_order = 'desc' AND CASE params.sort_by
+        WHEN 'name' THEN (name < CAST(?17 AS TEXT) OR (name = CAST(?17 AS TEXT) AND path > CAST(?16 AS TEXT)))
+        WHEN 'size' THEN (size < CAST(?18 AS INTEGER) OR (size = CAST(?18 AS INTEGER) AND path > CAST(?16 AS TEXT)))
+        WHEN 'modified_at' THEN (modified_at < ?19 OR (modified_at = ?19 AND path > CAST(?16 AS TEXT)))
+        WHEN 'created_at' THEN (created_at < ?19 OR (created_at = ?19 AND path > CAST(?16 AS TEXT)))
+        WHEN 'path' THEN path < CAST(?16 AS TEXT)
+        WHEN 'relevance' THEN (relevance < CAST(?20 AS REAL) OR (relevance = CAST(?20 AS REAL)
+            AND (similarity < CAST(?21 AS REAL) OR (similarity = CAST(?21 AS REAL) AND path > CAST(?16 AS TEXT)))))
+        ELSE path > CAST(?16 AS TEXT)
+    END)
+ORDER BY
+    CASE WHEN params.sort_by = 'name' AND params.sort_order = 'asc' THEN name END ASC,
+    CASE WHEN params.sort_by = 'name' AND params.sort_order = 'desc' THEN name END DESC,
+    CASE WHEN params.sort_by = 'size' AND params.sort_order = 'asc' THEN size END ASC,
+    CASE WHEN params.sort_by = 'size' AND params.sort_order = 'desc' THEN size END DESC,
+    CASE WHEN params.sort_by = 'modified_at' AND params.sort_order = 'asc' THEN modified_at END ASC,
+    CASE WHEN params.sort_by = 'modified_at' AND params.sort_order = 'desc' THEN modified_at END DESC,
+    CASE WHEN params.sort_by = 'created_at' AND params.sort_order = 'asc' THEN created_at END ASC,
+    CASE WHEN params.sort_by = 'created_at' AND params.sort_order = 'desc' THEN created_at END DESC,
+    CASE WHEN params.sort_by = 'path' AND params.sort_order = 'asc' THEN path END ASC,
+    CASE WHEN params.sort_by = 'path' AND params.sort_order = 'desc' THEN path END DESC,
+    CASE WHEN params.sort_by = 'relevance' AND params.sort_order = 'asc' THEN relevance END ASC,
+    CASE WHEN params.sort_by = 'relevance' AND params.sort_order = 'desc' THEN relevance END DESC,
+    CASE WHEN params.sort_by = 'relevance' AND params.sort_order = 'asc' THEN similarity END ASC,
+    CASE WHEN params.sort_by = 'relevance' AND params.sort_order = 'desc' THEN similarity END DESC,
+    path
+LIMIT CASE WHEN CAST(?23 AS INTEGER) = 0 THEN -1 ELSE CAST(?23 AS INTEGER) END
+OFFSET ?22
+`
+
+type GetFilesParams struct {
+	NameMatch       string    `json:"name_match"`
+	Name            string    `json:"name"`
+	Threshold       float64   `json:"threshold"`
+	Extension       string    `json:"extension"`
+	SizeMin         int64     `json:"size_min"`
+	SizeMax         int64     `json:"size_max"`
+	CreatedAfter    time.Time `json:"created_after"`
+	CreatedBefore   time.Time `json:"created_before"`
+	ModifiedAfter   time.Time `json:"modified_after"`
+	ModifiedBefore  time.Time `json:"modified_before"`
+	AccessedAfter   time.Time `json:"accessed_after"`
+	AccessedBefore  time.Time `json:"accessed_before"`
+	Metadata        string    `json:"metadata"`
+	SortBy          string    `json:"sort_by"`
+	SortOrder       string    `json:"sort_order"`
+	AfterPath       string    `json:"after_path"`
+	AfterText       string    `json:"after_text"`
+	AfterSize       int64     `json:"after_size"`
+	AfterTime       time.Time `json:"after_time"`
+	AfterRank       float64   `json:"after_rank"`
+	AfterSimilarity float64   `json:"after_similarity"`
+	PageOffset      int64     `json:"page_offset"`
+	PageLimit       int64     `json:"page_limit"`
+}
+
+type GetFilesRow struct {
+	Path       string    `json:"path"`
+	Name       string    `json:"name"`
+	Extension  string    `json:"extension"`
+	Size       int64     `json:"size"`
+	CreatedAt  time.Time `json:"created_at"`
+	ModifiedAt time.Time `json:"modified_at"`
+	AccessedAt time.Time `json:"accessed_at"`
+	Attributes string    `json:"attributes"`
+	Metadata   string    `json:"metadata"`
+	Similarity float64   `json:"similarity"`
+}
+
+// name is searched according to name_match like on Postgres, REGEXP and
+// word_similarity are functions registered by the store. An empty name is
+// not searched. The order column is selected in params, sqlc does not type
+// parameters in ORDER BY. Files are sorted and paged like on Postgres.
+// If limit is 0, return all results
+func (q *Queries) GetFiles(ctx context.Context, arg GetFilesParams) ([]GetFilesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFiles,
+		arg.NameMatch,
+		arg.Name,
+		arg.Threshold,
+		arg.Extension,
+		arg.SizeMin,
+		arg.SizeMax,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.ModifiedAfter,
+		arg.ModifiedBefore,
+		arg.AccessedAfter,
+		arg.AccessedBefore,
+		arg.Metadata,
+		arg.SortBy,
+		arg.SortOrder,
+		arg.AfterPath,
+		arg.AfterText,
+		arg.AfterSize,
+		arg.AfterTime,
+		arg.AfterRank,
+		arg.AfterSimilarity,
+		arg.PageOffset,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetFilesRow{}
+	for rows.Next() {
+		var i GetFilesRow
+		if err := rows.Scan(
+			&i.Path,
+			&i.Name,
+			&i.Extension,
+			&i.Size,
+			&i.CreatedAt,
+			&i.ModifiedAt,
+			&i.AccessedAt,
+			&i.Attributes,
+			&i.Metadata,
+			&i.Similarity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertFile = `-- name: InsertFile :one
+INSERT INTO file (
+  name,
+  extension,
+  size,
+  path,
+  attributes,
+  content,
+  created_at,
+  modified_at,
+  accessed_at,
+  partial_hash,
+  content_hash,
+  metadata
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT (path) DO NOTHING
+RETURNING id, name, extension, size, path, created_at, modified_at, accessed_at, attributes, content, partial_hash, content_hash, metadata
+`
+
+type InsertFileParams struct {
+	Name        string    `json:"name"`
+	Extension   string    `json:"extension"`
+	Size        int64     `json:"size"`
+	Path        string    `json:"path"`
+	Attributes  string    `json:"attributes"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+	ModifiedAt  time.Time `json:"modified_at"`
+	AccessedAt  time.Time `json:"accessed_at"`
+	PartialHash string    `json:"partial_hash"`
+	ContentHash string    `json:"content_hash"`
+	Metadata    string    `json:"metadata"`
+}
+
+// No row is returned if the path is already stored
+func (q *Queries) InsertFile(ctx context.Context, arg InsertFileParams) (File, error) {
+	row := q.db.QueryRowContext(ctx, insertFile,
+		arg.Name,
+		arg.Extension,
+		arg.Size,
+		arg.Path,
+		arg.Attributes,
+		arg.Content,
+		arg.CreatedAt,
+		arg.ModifiedAt,
+		arg.AccessedAt,
+		arg.PartialHash,
+		arg.ContentHash,
+		arg.Metadata,
+	)
+	var i File
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Extension,
+		&i.Size,
+		&i.Path,
+		&i.CreatedAt,
+		&i.ModifiedAt,
+		&i.AccessedAt,
+		&i.Attributes,
+		&i.Content,
+		&i.PartialHash,
+		&i.ContentHash,
+		&i.Metadata,
+	)
+	return i, err
+}
+
+const listFilesByPrefix = `-- name: ListFilesByPrefix :many
+SELECT id, name, extension, size, path, created_at, modified_at, accessed_at, attributes, content, partial_hash, content_hash, metadata
+FROM file
+WHERE substr(path, 1, length(CAST(?1 AS TEXT))) = CAST(?1 AS TEXT)
+ORDER BY path
+`
+
+func (q *Queries) ListFilesByPrefix(ctx context.Context, prefix string) ([]File, error) {
+	rows, err := q.db.QueryContext(ctx, listFilesByPrefix, prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []File{}
+	for rows.Next() {
+		var i File
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Extension,
+			&i.Size,
+			&i.Path,
+			&i.CreatedAt,
+			&i.ModifiedAt,
+			&i.AccessedAt,
+			&i.Attributes,
+			&i.Content,
+			&i.PartialHash,
+			&i.ContentHash,
+			&i.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchFiles = `-- name: SearchFiles :many
+SELECT
+    matches.path,
+    matches.name,
+    matches.extension,
+    matches.size,
+    matches.created_at,
+    matches.modified_at,
+    matches.accessed_at,
+    matches.attributes,
+    matches.metadata,
+    matches.relevance,
+    matches.similarity,
+    matches.snippet
+FROM (
+    SELECT
+        file.path,
+        file.name,
+        file.extension,
+        file.size,
+        file.created_at,
+        file.modified_at,
+        file.accessed_at,
+        file.attributes,
+        file.metadata,
+        CAST(to_real(-bm25(file_text) / (1 - bm25(file_text))) AS REAL) AS relevance,
+        CAST(CASE WHEN CAST(?1 AS TEXT) = 'fuzzy' AND CAST(?2 AS TEXT) <> '' THEN max(
+            word_similarity(CAST(?2 AS TEXT), file.name),
+            word_similarity(CAST(?2 AS TEXT), file.path)
+        ) ELSE 0 END AS REAL) AS similarity,
+        CAST(snippet(file_text, 0, '<mark>', '</mark>', '', 20) AS TEXT) AS snippet
+    FROM
+        file_text
+        JOIN file ON file.id = file_text.rowid
+    WHERE
+        file_text.content MATCH ?3
+        AND -- Filename search
+        (CAST(?2 AS TEXT) = ''
+            -- LIKE ignores case like ILIKE and uses the trigram index
+            OR (CAST(?1 AS TEXT) = 'substring' AND file.id IN (
+                SELECT rowid FROM file_fts WHERE file_fts.name LIKE '%' || CAST(?2 AS TEXT) || '%'
+            ))
+            OR (CAST(?1 AS TEXT) = 'exact' AND file.name = CAST(?2 AS TEXT))
+            OR (CAST(?1 AS TEXT) = 'regex' AND file.name REGEXP CAST(?2 AS TEXT))
+            OR (CAST(?1 AS TEXT) = 'fuzzy' AND max(
+                word_similarity(CAST(?2 AS TEXT), file.name),
+                word_similarity(CAST(?2 AS TEXT), file.path)
+            ) >= CAST(?4 AS REAL)))
+        -- File extension exact match
+        AND (CAST(?5 AS TEXT) = '' OR file.extension = CAST(?5 AS TEXT))
+        -- File size range search
+        AND file.size >= ?6
+        AND file.size <= ?7
+        -- File created_at range search
+        AND file.created_at >= ?8
+        AND file.created_at <= ?9
+        -- File modified_At range search
+        AND file.modified_at >= ?10
+        AND file.modified_at <= ?11
+        -- File accessed_at range search
+        AND file.accessed_at >= ?12
+        AND file.accessed_at <= ?13
+        -- Metadata values, metadata_contains is registered by the store
+        AND (CAST(?14 AS TEXT) = '' OR metadata_contains(file.metadata, CAST(?14 AS TEXT)))
+) AS matches,
+    (SELECT CAST(?15 AS TEXT) AS sort_by, CAST(?16 AS TEXT) AS sort_order) AS params
+WHERE
+    CAST(?17 AS TEXT) = ''
+    OR (params.sort_order = 'asc' AND CASE params.sort_by
+        WHEN 'name' THEN (name > CAST(?18 AS TEXT) OR (name = CAST(?18 AS TEXT) AND path > CAST(?17 AS TEXT)))
+        WHEN 'size' THEN (size > CAST(?19 AS INTEGER) OR (size = CAST(?19 AS INTEGER) AND path > CAST(?17 AS TEXT)))
+        WHEN 'modified_at' THEN (modified_at > ?20 OR (modified_at = ?20 AND path > CAST(?17 AS TEXT)))
+        WHEN 'created_at' THEN (created_at > ?20 OR (created_at = ?20 AND path > CAST(?17 AS TEXT)))
+        WHEN 'path' THEN path > CAST(?17 AS TEXT)
+        WHEN 'relevance' THEN (relevance > CAST(?21 AS REAL) OR (relevance = CAST(?21 AS REAL)
+            AND (similarity > CAST(?22 AS REAL) OR (similarity = CAST(?22 AS REAL) AND path > CAST(?17 AS TEXT)))))
+        ELSE path > CAST(?17 AS TEXT)
+    END)
+    OR (params.sort_order = 'desc' AND CASE params.sort_by
+        WHEN 'name' THEN (name < CAST(?18 AS TEXT) OR (name = CAST(?18 AS TEXT) AND path > CAST(?17 AS TEXT)))
+        WHEN 'size' THEN (size < CAST(?19 AS INTEGER) OR (size = CAST(?19 AS INTEGER) AND path > CAST(?17 AS TEXT)))
+        WHEN 'modified_at' THEN (modified_at < ?20 OR (modified_at = ?20 AND path > CAST(?17 AS TEXT)))
+        WHEN 'created_at' THEN (created_at < ?20 OR (created_at = ?20 AND path > CAST(?17 AS TEXT)))
+        WHEN 'path' THEN path < CAST(?17 AS TEXT)
+        WHEN 'relevance' THEN (relevance < CAST(?21 AS REAL) OR (relevance = CAST(?21 AS REAL)
+            AND (similarity < CAST(?22 AS REAL) OR (similarity = CAST(?22 AS REAL) AND path > CAST(?17 AS TEXT)))))
+        ELSE path > CAST(?17 AS TEXT)
+    END)
+ORDER BY
+    CASE WHEN params.sort_by = 'name' AND params.sort_order = 'asc' THEN name END ASC,
+    CASE WHEN params.sort_by = 'name' AND params.sort_order = 'desc' THEN name END DESC,
+    CASE WHEN params.sort_by = 'size' AND params.sort_order = 'asc' THEN size END ASC,
+    CASE WHEN params.sort_by = 'size' AND params.sort_order = 'desc' THEN size END DESC,
+    CASE WHEN params.sort_by = 'modified_at' AND params.sort_order = 'asc' THEN modified_at END ASC,
+    CASE WHEN params.sort_by = 'modified_at' AND params.sort_order = 'desc' THEN modified_at END DESC,
+    CASE WHEN params.sort_by = 'created_at' AND params.sort_order = 'asc' THEN created_at END ASC,
+    CASE WHEN params.sort_by = 'created_at' AND params.sort_order = 'desc' THEN created_at END DESC,
+    CASE WHEN params.sort_by = 'path' AND params.sort_order = 'asc' THEN path END ASC,
+    CASE WHEN params.sort_by = 'path' AND params.sort_order = 'desc' THEN path END DESC,
+    CASE WHEN params.sort_by = 'relevance' AND params.sort_order = 'asc' THEN relevance END ASC,
+    CASE WHEN params.sort_by = 'relevance' AND params.sort_order = 'desc' THEN relevance END DESC,
+    CASE WHEN params.sort_by = 'relevance' AND params.sort_order = 'asc' THEN similarity END ASC,
+    CASE WHEN params.sort_by = 'relevance' AND params.sort_order = 'desc' THEN similarity END DESC,
+    path
+LIMIT CASE WHEN CAST(?24 AS INTEGER) = 0 THEN -1 ELSE CAST(?24 AS INTEGER) END
+OFFSET ?23
+`
+
+type SearchFilesParams struct {
+	NameMatch       string    `json:"name_match"`
+	Name            string    `json:"name"`
+	Query           string    `json:"query"`
+	Threshold       float64   `json:"threshold"`
+	Extension       string    `json:"extension"`
+	SizeMin         int64     `json:"size_min"`
+	SizeMax         int64     `json:"size_max"`
+	CreatedAfter    time.Time `json:"created_after"`
+	CreatedBefore   time.Time `json:"created_before"`
+	ModifiedAfter   time.Time `json:"modified_after"`
+	ModifiedBefore  time.Time `json:"modified_before"`
+	AccessedAfter   time.Time `json:"accessed_after"`
+	AccessedBefore  time.Time `json:"accessed_before"`
+	Metadata        string    `json:"metadata"`
+	SortBy          string    `json:"sort_by"`
+	SortOrder       string    `json:"sort_order"`
+	AfterPath       string    `json:"after_path"`
+	AfterText       string    `json:"after_text"`
+	AfterSize       int64     `json:"after_size"`
+	AfterTime       time.Time `json:"after_time"`
+	AfterRank       float64   `json:"after_rank"`
+	AfterSimilarity float64   `json:"after_similarity"`
+	PageOffset      int64     `json:"page_offset"`
+	PageLimit       int64     `json:"page_limit"`
+}
+
+type SearchFilesRow struct {
+	Path       string    `json:"path"`
+	Name       string    `json:"name"`
+	Extension  string    `json:"extension"`
+	Size       int64     `json:"size"`
+	CreatedAt  time.Time `json:"created_at"`
+	ModifiedAt time.Time `json:"modified_at"`
+	AccessedAt time.Time `json:"accessed_at"`
+	Attributes string    `json:"attributes"`
+	Metadata   string    `json:"metadata"`
+	Relevance  float64   `json:"relevance"`
+	Similarity float64   `json:"similarity"`
+	Snippet    string    `json:"snippet"`
+}
+
+// query is an FTS5 MATCH expression. The bm25 score is scaled to [0, 1) like
+// ts_rank_cd with normalization 32 on Postgres and rounded by to_real to a
+// real, so it compares equal to the rank of a cursor. name is searched like
+// in GetFiles and the files are sorted and paged like in GetFiles.
+// If limit is 0, return all results
+func (q *Queries) SearchFiles(ctx context.Context, arg SearchFilesParams) ([]SearchFilesRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchFiles,
+		arg.NameMatch,
+		arg.Name,
+		arg.Query,
+		arg.Threshold,
+		arg.Extension,
+		arg.SizeMin,
+		arg.SizeMax,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.ModifiedAfter,
+		arg.ModifiedBefore,
+		arg.AccessedAfter,
+		arg.AccessedBefore,
+		arg.Metadata,
+		arg.SortBy,
+		arg.SortOrder,
+		arg.AfterPath,
+		arg.AfterText,
+		arg.AfterSize,
+		arg.AfterTime,
+		arg.AfterRank,
+		arg.AfterSimilarity,
+		arg.PageOffset,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchFilesRow{}
+	for rows.Next() {
+		var i SearchFilesRow
+		if err := rows.Scan(
+			&i.Path,
+			&i.Name,
+			&i.Extension,
+			&i.Size,
+			&i.CreatedAt,
+			&i.ModifiedAt,
+			&i.AccessedAt,
+			&i.Attributes,
+			&i.Metadata,
+			&i.Relevance,
+			&i.Similarity,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateFileHashes = `-- name: UpdateFileHashes :execrows
+UPDATE file
+SET
+  partial_hash = ?2,
+  content_hash = ?3
+WHERE path = ?1
+`
+
+type UpdateFileHashesParams struct {
+	Path        string `json:"path"`
+	PartialHash string `json:"partial_hash"`
+	ContentHash string `json:"content_hash"`
+}
+
+// Stores the hashes computed for the duplicate search
+func (q *Queries) UpdateFileHashes(ctx context.Context, arg UpdateFileHashesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateFileHashes, arg.Path, arg.PartialHash, arg.ContentHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const upsertFile = `-- name: UpsertFile :one
+INSERT INTO file (
+  name,
+  extension,
+  size,
+  path,
+  attributes,
+  content,
+  created_at,
+  modified_at,
+  accessed_at,
+  partial_hash,
+  content_hash,
+  metadata
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT (path) DO UPDATE SET
+  name = excluded.name,
+  extension = excluded.extension,
+  size = excluded.size,
+  attributes = excluded.attributes,
+  content = excluded.content,
+  created_at = excluded.created_at,
+  modified_at = excluded.modified_at,
+  accessed_at = excluded.accessed_at,
+  partial_hash = excluded.partial_hash,
+  content_hash = excluded.content_hash,
+  metadata = excluded.metadata
+RETURNING id, name, extension, size, path, created_at, modified_at, accessed_at, attributes, content, partial_hash, content_hash, metadata
+`
+
+type UpsertFileParams struct {
+	Name        string    `json:"name"`
+	Extension   string    `json:"extension"`
+	Size        int64     `json:"size"`
+	Path        string    `json:"path"`
+	Attributes  string    `json:"attributes"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+	ModifiedAt  time.Time `json:"modified_at"`
+	AccessedAt  time.Time `json:"accessed_at"`
+	PartialHash string    `json:"partial_hash"`
+	ContentHash string    `json:"content_hash"`
+	Metadata    string    `json:"metadata"`
+}
+
+func (q *Queries) UpsertFile(ctx context.Context, arg UpsertFileParams) (File, error) {
+	row := q.db.QueryRowContext(ctx, upsertFile,
+		arg.Name,
+		arg.Extension,
+		arg.Size,
+		arg.Path,
+		arg.Attributes,
+		arg.Content,
+		arg.CreatedAt,
+		arg.ModifiedAt,
+		arg.AccessedAt,
+		arg.PartialHash,
+		arg.ContentHash,
+		arg.Metadata,
+	)
+	var i File
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Extension,
+		&i.Size,
+		&i.Path,
+		&i.CreatedAt,
+		&i.ModifiedAt,
+		&i.AccessedAt,
+		&i.Attributes,
+		&i.Content,
+		&i.PartialHash,
+		&i.ContentHash,
+		&i.Metadata,
+	)
+	return i, err
+}
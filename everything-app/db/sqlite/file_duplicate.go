@@ -0,0 +1,27 @@
+package sqlite
+
+import (
+	"context"
+	db "training/db/sqlc"
+)
+
+func (store *Store) GetDuplicateFiles(ctx context.Context, arg db.GetDuplicateFilesParams) ([]db.GetDuplicateFilesRow, error) {
+	rows, err := store.queries.GetDuplicateFiles(ctx, GetDuplicateFilesParams{
+		Prefix:     arg.Prefix,
+		MinSize:    arg.MinSize,
+		GroupLimit: int64(arg.GroupLimit),
+	})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	result := make([]db.GetDuplicateFilesRow, len(rows))
+	for i, row := range rows {
+		result[i] = db.GetDuplicateFilesRow(row)
+	}
+	return result, nil
+}
+
+func (store *Store) GetDuplicateTotals(ctx context.Context, arg db.GetDuplicateTotalsParams) (db.GetDuplicateTotalsRow, error) {
+	totals, err := store.queries.GetDuplicateTotals(ctx, GetDuplicateTotalsParams(arg))
+	return db.GetDuplicateTotalsRow(totals), mapError(err)
+}
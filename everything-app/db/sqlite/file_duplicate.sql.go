@@ -0,0 +1,146 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: file_duplicate.sql
+
+package sqlite
+
+import (
+	"context"
+	"time"
+)
+
+const getDuplicateFiles = `-- name: GetDuplicateFiles :many
+WITH duplicates AS (
+    SELECT
+        file.content_hash,
+        file.size,
+        count(*) AS file_count,
+        CAST((count(*) - 1) * file.size AS INTEGER) AS wasted
+    FROM
+        file
+    WHERE
+        file.content_hash <> ''
+        AND file.size >= ?2
+        AND substr(file.path, 1, length(CAST(?1 AS TEXT))) = CAST(?1 AS TEXT)
+    GROUP BY
+        file.content_hash,
+        file.size
+    HAVING
+        count(*) > 1
+    ORDER BY
+        wasted DESC,
+        content_hash
+    LIMIT ?3
+)
+SELECT
+    file.path,
+    file.name,
+    file.modified_at,
+    duplicates.content_hash,
+    duplicates.size,
+    duplicates.file_count,
+    duplicates.wasted
+FROM
+    file
+    JOIN duplicates ON file.content_hash = duplicates.content_hash AND file.size = duplicates.size
+WHERE
+    substr(file.path, 1, length(CAST(?1 AS TEXT))) = CAST(?1 AS TEXT)
+ORDER BY
+    duplicates.wasted DESC,
+    duplicates.content_hash,
+    file.path
+`
+
+type GetDuplicateFilesParams struct {
+	Prefix     string `json:"prefix"`
+	MinSize    int64  `json:"min_size"`
+	GroupLimit int64  `json:"group_limit"`
+}
+
+type GetDuplicateFilesRow struct {
+	Path        string    `json:"path"`
+	Name        string    `json:"name"`
+	ModifiedAt  time.Time `json:"modified_at"`
+	ContentHash string    `json:"content_hash"`
+	Size        int64     `json:"size"`
+	FileCount   int64     `json:"file_count"`
+	Wasted      int64     `json:"wasted"`
+}
+
+// The files of the group_limit groups of files with the same content hash
+// below prefix that waste the most bytes, only files of at least min_size
+// bytes are grouped. A group wastes the size of all of its files but one.
+func (q *Queries) GetDuplicateFiles(ctx context.Context, arg GetDuplicateFilesParams) ([]GetDuplicateFilesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDuplicateFiles, arg.Prefix, arg.MinSize, arg.GroupLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetDuplicateFilesRow{}
+	for rows.Next() {
+		var i GetDuplicateFilesRow
+		if err := rows.Scan(
+			&i.Path,
+			&i.Name,
+			&i.ModifiedAt,
+			&i.ContentHash,
+			&i.Size,
+			&i.FileCount,
+			&i.Wasted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDuplicateTotals = `-- name: GetDuplicateTotals :one
+SELECT
+    count(*) AS group_count,
+    CAST(COALESCE(sum(file_count), 0) AS INTEGER) AS file_count,
+    CAST(COALESCE(sum(wasted), 0) AS INTEGER) AS wasted
+FROM (
+    SELECT
+        count(*) AS file_count,
+        (count(*) - 1) * size AS wasted
+    FROM
+        file
+    WHERE
+        content_hash <> ''
+        AND size >= ?1
+        AND substr(path, 1, length(CAST(?2 AS TEXT))) = CAST(?2 AS TEXT)
+    GROUP BY
+        content_hash,
+        size
+    HAVING
+        count(*) > 1
+) AS duplicates
+`
+
+type GetDuplicateTotalsParams struct {
+	MinSize int64  `json:"min_size"`
+	Prefix  string `json:"prefix"`
+}
+
+type GetDuplicateTotalsRow struct {
+	GroupCount int64 `json:"group_count"`
+	FileCount  int64 `json:"file_count"`
+	Wasted     int64 `json:"wasted"`
+}
+
+// The number of groups of GetDuplicateFiles, of their files and of the
+// bytes they waste
+func (q *Queries) GetDuplicateTotals(ctx context.Context, arg GetDuplicateTotalsParams) (GetDuplicateTotalsRow, error) {
+	row := q.db.QueryRowContext(ctx, getDuplicateTotals, arg.MinSize, arg.Prefix)
+	var i GetDuplicateTotalsRow
+	err := row.Scan(&i.GroupCount, &i.FileCount, &i.Wasted)
+	return i, err
+}
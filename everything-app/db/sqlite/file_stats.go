@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+	"training/db/filequery"
+	db "training/db/sqlc"
+)
+
+func (store *Store) GetFileFacets(ctx context.Context, arg db.GetFileFacetsParams) ([]db.GetFileFacetsRow, error) {
+	match, err := matchQuery(arg.Content)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.queries.GetFileFacets(ctx, GetFileFacetsParams{
+		Query:          match,
+		Name:           arg.Name,
+		NameMatch:      arg.NameMatch,
+		Threshold:      float64(arg.Threshold),
+		Extension:      arg.Extension,
+		SizeMin:        arg.SizeMin,
+		SizeMax:        arg.SizeMax,
+		CreatedAfter:   arg.CreatedAfter.UTC(),
+		CreatedBefore:  arg.CreatedBefore.UTC(),
+		ModifiedAfter:  arg.ModifiedAfter.UTC(),
+		ModifiedBefore: arg.ModifiedBefore.UTC(),
+		AccessedAfter:  arg.AccessedAfter.UTC(),
+		AccessedBefore: arg.AccessedBefore.UTC(),
+		Metadata:       arg.Metadata,
+		TopLimit:       int64(arg.TopLimit),
+	})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	result := make([]db.GetFileFacetsRow, len(rows))
+	for i, row := range rows {
+		result[i] = db.GetFileFacetsRow(row)
+	}
+	return result, nil
+}
+
+func (store *Store) GetLargestFiles(ctx context.Context, arg db.GetLargestFilesParams) ([]db.GetLargestFilesRow, error) {
+	match, err := matchQuery(arg.Content)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.queries.GetLargestFiles(ctx, GetLargestFilesParams{
+		Query:          match,
+		Name:           arg.Name,
+		NameMatch:      arg.NameMatch,
+		Threshold:      float64(arg.Threshold),
+		Extension:      arg.Extension,
+		SizeMin:        arg.SizeMin,
+		SizeMax:        arg.SizeMax,
+		CreatedAfter:   arg.CreatedAfter.UTC(),
+		CreatedBefore:  arg.CreatedBefore.UTC(),
+		ModifiedAfter:  arg.ModifiedAfter.UTC(),
+		ModifiedBefore: arg.ModifiedBefore.UTC(),
+		AccessedAfter:  arg.AccessedAfter.UTC(),
+		AccessedBefore: arg.AccessedBefore.UTC(),
+		Metadata:       arg.Metadata,
+		TopLimit:       int64(arg.TopLimit),
+	})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	result := make([]db.GetLargestFilesRow, len(rows))
+	for i, row := range rows {
+		result[i] = db.GetLargestFilesRow(row)
+	}
+	return result, nil
+}
+
+// QueryFileFacets runs the facets of a filequery query compiled for SQLite
+func (store *Store) QueryFileFacets(ctx context.Context, query filequery.Node, topLimit int32) ([]db.GetFileFacetsRow, error) {
+	rows, err := db.QueryFileFacetsOn(ctx, store.db, filequery.SQLite, query, topLimit)
+	return rows, mapError(err)
+}
+
+// QueryLargestFiles returns the largest files of a filequery query compiled
+// for SQLite
+func (store *Store) QueryLargestFiles(ctx context.Context, query filequery.Node, topLimit int32) ([]db.GetLargestFilesRow, error) {
+	rows, err := db.QueryLargestFilesOn(ctx, store.db, filequery.SQLite, query, topLimit)
+	return rows, mapError(err)
+}
+
+// matchQuery returns content, a TextQuery, as an FTS5 match query
+func matchQuery(content string) (string, error) {
+	if content == "" {
+		return "", nil
+	}
+	query, err := db.ParseTextQuery(content)
+	if err != nil {
+		return "", err
+	}
+	return query.MatchQuery(), nil
+}
@@ -0,0 +1,276 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: file_stats.sql
+
+package sqlite
+
+import (
+	"context"
+	"time"
+)
+
+const getFileFacets = `-- name: GetFileFacets :many
+WITH matches AS (
+    SELECT
+        file.path,
+        file.extension,
+        file.size,
+        file.modified_at
+    FROM
+        file
+    WHERE
+            (CAST(?1 AS TEXT) = '' OR file.id IN (
+                SELECT rowid FROM file_text WHERE file_text.content MATCH CAST(?1 AS TEXT)
+            ))
+            AND -- Filename search
+            (CAST(?2 AS TEXT) = ''
+                -- LIKE ignores case like ILIKE and uses the trigram index
+                OR (CAST(?3 AS TEXT) = 'substring' AND file.id IN (
+                    SELECT rowid FROM file_fts WHERE file_fts.name LIKE '%' || CAST(?2 AS TEXT) || '%'
+                ))
+                OR (CAST(?3 AS TEXT) = 'exact' AND file.name = CAST(?2 AS TEXT))
+                OR (CAST(?3 AS TEXT) = 'regex' AND file.name REGEXP CAST(?2 AS TEXT))
+                OR (CAST(?3 AS TEXT) = 'fuzzy' AND max(
+                    word_similarity(CAST(?2 AS TEXT), file.name),
+                    word_similarity(CAST(?2 AS TEXT), file.path)
+                ) >= CAST(?4 AS REAL)))
+            -- File extension exact match
+            AND (CAST(?5 AS TEXT) = '' OR file.extension = CAST(?5 AS TEXT))
+            -- File size range search
+            AND file.size >= ?6
+            AND file.size <= ?7
+            -- File created_at range search
+            AND file.created_at >= ?8
+            AND file.created_at <= ?9
+            -- File modified_At range search
+            AND file.modified_at >= ?10
+            AND file.modified_at <= ?11
+            -- File accessed_at range search
+            AND file.accessed_at >= ?12
+            AND file.accessed_at <= ?13
+            -- Metadata values, metadata_contains is registered by the store
+            AND (CAST(?14 AS TEXT) = '' OR metadata_contains(file.metadata, CAST(?14 AS TEXT)))
+)
+SELECT
+    CAST('extension' AS TEXT) AS facet,
+    CAST(extension AS TEXT) AS value,
+    count(*) AS files,
+    CAST(sum(size) AS INTEGER) AS size
+FROM matches
+GROUP BY extension
+UNION ALL
+SELECT 'directory', top_directory(path), count(*), CAST(sum(size) AS INTEGER)
+FROM matches
+GROUP BY top_directory(path)
+UNION ALL
+SELECT 'size', size_name(size), count(*), CAST(sum(size) AS INTEGER)
+FROM matches
+GROUP BY size_name(size)
+UNION ALL
+SELECT 'month', utc_month(modified_at), count(*), CAST(sum(size) AS INTEGER)
+FROM matches
+GROUP BY utc_month(modified_at)
+UNION ALL
+SELECT facet, value, files, size FROM (
+    SELECT 'largest_directory' AS facet, parent_directory(path) AS value, count(*) AS files, CAST(sum(size) AS INTEGER) AS size
+    FROM matches
+    GROUP BY parent_directory(path)
+    ORDER BY size DESC, value
+    LIMIT ?15
+) AS largest_directories
+ORDER BY facet, size DESC, value
+`
+
+type GetFileFacetsParams struct {
+	Query          string    `json:"query"`
+	Name           string    `json:"name"`
+	NameMatch      string    `json:"name_match"`
+	Threshold      float64   `json:"threshold"`
+	Extension      string    `json:"extension"`
+	SizeMin        int64     `json:"size_min"`
+	SizeMax        int64     `json:"size_max"`
+	CreatedAfter   time.Time `json:"created_after"`
+	CreatedBefore  time.Time `json:"created_before"`
+	ModifiedAfter  time.Time `json:"modified_after"`
+	ModifiedBefore time.Time `json:"modified_before"`
+	AccessedAfter  time.Time `json:"accessed_after"`
+	AccessedBefore time.Time `json:"accessed_before"`
+	Metadata       string    `json:"metadata"`
+	TopLimit       int64     `json:"top_limit"`
+}
+
+type GetFileFacetsRow struct {
+	Facet string `json:"facet"`
+	Value string `json:"value"`
+	Files int64  `json:"files"`
+	Size  int64  `json:"size"`
+}
+
+// The number and the total size of the files matching the filters of
+// GetFiles by extension, top-level directory, named size and modification
+// month in UTC, and the top_limit directories with the largest total size
+// of the matching files they directly contain. The facets are ordered by
+// name, then by size descending.
+func (q *Queries) GetFileFacets(ctx context.Context, arg GetFileFacetsParams) ([]GetFileFacetsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFileFacets,
+		arg.Query,
+		arg.Name,
+		arg.NameMatch,
+		arg.Threshold,
+		arg.Extension,
+		arg.SizeMin,
+		arg.SizeMax,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.ModifiedAfter,
+		arg.ModifiedBefore,
+		arg.AccessedAfter,
+		arg.AccessedBefore,
+		arg.Metadata,
+		arg.TopLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetFileFacetsRow{}
+	for rows.Next() {
+		var i GetFileFacetsRow
+		if err := rows.Scan(
+			&i.Facet,
+			&i.Value,
+			&i.Files,
+			&i.Size,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLargestFiles = `-- name: GetLargestFiles :many
+SELECT
+    file.path,
+    file.name,
+    file.extension,
+    file.size,
+    file.modified_at
+FROM
+    file
+WHERE
+    (CAST(?1 AS TEXT) = '' OR file.id IN (
+        SELECT rowid FROM file_text WHERE file_text.content MATCH CAST(?1 AS TEXT)
+    ))
+    AND -- Filename search
+    (CAST(?2 AS TEXT) = ''
+        -- LIKE ignores case like ILIKE and uses the trigram index
+        OR (CAST(?3 AS TEXT) = 'substring' AND file.id IN (
+            SELECT rowid FROM file_fts WHERE file_fts.name LIKE '%' || CAST(?2 AS TEXT) || '%'
+        ))
+        OR (CAST(?3 AS TEXT) = 'exact' AND file.name = CAST(?2 AS TEXT))
+        OR (CAST(?3 AS TEXT) = 'regex' AND file.name REGEXP CAST(?2 AS TEXT))
+        OR (CAST(?3 AS TEXT) = 'fuzzy' AND max(
+            word_similarity(CAST(?2 AS TEXT), file.name),
+            word_similarity(CAST(?2 AS TEXT), file.path)
+        ) >= CAST(?4 AS REAL)))
+    -- File extension exact match
+    AND (CAST(?5 AS TEXT) = '' OR file.extension = CAST(?5 AS TEXT))
+    -- File size range search
+    AND file.size >= ?6
+    AND file.size <= ?7
+    -- File created_at range search
+    AND file.created_at >= ?8
+    AND file.created_at <= ?9
+    -- File modified_At range search
+    AND file.modified_at >= ?10
+    AND file.modified_at <= ?11
+    -- File accessed_at range search
+    AND file.accessed_at >= ?12
+    AND file.accessed_at <= ?13
+    -- Metadata values, metadata_contains is registered by the store
+    AND (CAST(?14 AS TEXT) = '' OR metadata_contains(file.metadata, CAST(?14 AS TEXT)))
+ORDER BY
+    file.size DESC,
+    file.path
+LIMIT ?15
+`
+
+type GetLargestFilesParams struct {
+	Query          string    `json:"query"`
+	Name           string    `json:"name"`
+	NameMatch      string    `json:"name_match"`
+	Threshold      float64   `json:"threshold"`
+	Extension      string    `json:"extension"`
+	SizeMin        int64     `json:"size_min"`
+	SizeMax        int64     `json:"size_max"`
+	CreatedAfter   time.Time `json:"created_after"`
+	CreatedBefore  time.Time `json:"created_before"`
+	ModifiedAfter  time.Time `json:"modified_after"`
+	ModifiedBefore time.Time `json:"modified_before"`
+	AccessedAfter  time.Time `json:"accessed_after"`
+	AccessedBefore time.Time `json:"accessed_before"`
+	Metadata       string    `json:"metadata"`
+	TopLimit       int64     `json:"top_limit"`
+}
+
+type GetLargestFilesRow struct {
+	Path       string    `json:"path"`
+	Name       string    `json:"name"`
+	Extension  string    `json:"extension"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// The top_limit largest files matching the filters of GetFiles
+func (q *Queries) GetLargestFiles(ctx context.Context, arg GetLargestFilesParams) ([]GetLargestFilesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getLargestFiles,
+		arg.Query,
+		arg.Name,
+		arg.NameMatch,
+		arg.Threshold,
+		arg.Extension,
+		arg.SizeMin,
+		arg.SizeMax,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.ModifiedAfter,
+		arg.ModifiedBefore,
+		arg.AccessedAfter,
+		arg.AccessedBefore,
+		arg.Metadata,
+		arg.TopLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetLargestFilesRow{}
+	for rows.Next() {
+		var i GetLargestFilesRow
+		if err := rows.Scan(
+			&i.Path,
+			&i.Name,
+			&i.Extension,
+			&i.Size,
+			&i.ModifiedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,120 @@
+package sqlite
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+	"training/db/filequery"
+	db "training/db/sqlc"
+
+	"modernc.org/sqlite"
+)
+
+// The functions of the filename search and of the file facets that SQLite
+// does not have
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("regexp", 2, regexpFunction)
+	sqlite.MustRegisterDeterministicScalarFunction("word_similarity", 2, wordSimilarityFunction)
+	sqlite.MustRegisterDeterministicScalarFunction("to_real", 1, toRealFunction)
+	sqlite.MustRegisterDeterministicScalarFunction("top_directory", 1, textFunction(db.TopDirectory))
+	sqlite.MustRegisterDeterministicScalarFunction("parent_directory", 1, textFunction(db.ParentDirectory))
+	sqlite.MustRegisterDeterministicScalarFunction("size_name", 1, sizeNameFunction)
+	sqlite.MustRegisterDeterministicScalarFunction("utc_month", 1, utcMonthFunction)
+	sqlite.MustRegisterDeterministicScalarFunction("metadata_contains", 2, metadataContainsFunction)
+}
+
+var (
+	patternMutex sync.Mutex
+	lastPattern  *regexp.Regexp
+)
+
+// regexpFunction implements X REGEXP Y, called as regexp(Y, X). It ignores
+// case like ~* on Postgres. The last pattern is kept, a query matches every
+// row with the same pattern.
+func regexpFunction(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	expr, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("regexp: pattern must be text")
+	}
+	text, ok := args[1].(string)
+	if !ok {
+		return false, nil
+	}
+
+	patternMutex.Lock()
+	pattern := lastPattern
+	if pattern == nil || pattern.String() != "(?i)"+expr {
+		var err error
+		if pattern, err = regexp.Compile("(?i)" + expr); err != nil {
+			patternMutex.Unlock()
+			return nil, err
+		}
+		lastPattern = pattern
+	}
+	patternMutex.Unlock()
+
+	return pattern.MatchString(text), nil
+}
+
+// wordSimilarityFunction implements word_similarity(query, text) of pg_trgm
+func wordSimilarityFunction(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	query, _ := args[0].(string)
+	text, _ := args[1].(string)
+	return float64(db.WordSimilarity(query, text)), nil
+}
+
+// toRealFunction rounds a double to the precision of a real on Postgres, the
+// float32 of the ranks of the db package
+func toRealFunction(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	value, ok := args[0].(float64)
+	if !ok {
+		return args[0], nil
+	}
+	return float64(float32(value)), nil
+}
+
+// textFunction returns a function of a text argument, NULL is returned for
+// NULL like the STRICT functions on Postgres
+func textFunction(fn func(string) string) func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error) {
+	return func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		text, ok := args[0].(string)
+		if !ok {
+			return nil, nil
+		}
+		return fn(text), nil
+	}
+}
+
+// sizeNameFunction implements size_name(size), the named size of size
+func sizeNameFunction(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	size, ok := args[0].(int64)
+	if !ok {
+		return nil, nil
+	}
+	return filequery.SizeName(size), nil
+}
+
+// utcMonthFunction implements utc_month(time). Times are stored in UTC as
+// text starting with the date.
+func utcMonthFunction(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	switch value := args[0].(type) {
+	case time.Time:
+		return db.UTCMonth(value), nil
+	case string:
+		if len(value) < len("2006-01") {
+			return nil, fmt.Errorf("utc_month: invalid time %q", value)
+		}
+		return value[:len("2006-01")], nil
+	}
+	return nil, nil
+}
+
+// metadataContainsFunction implements metadata_contains(metadata, filter),
+// the metadata @> filter of Postgres
+func metadataContainsFunction(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	metadata, _ := args[0].(string)
+	filter, _ := args[1].(string)
+	return db.MetadataContains(metadata, filter), nil
+}
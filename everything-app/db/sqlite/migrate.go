@@ -0,0 +1,78 @@
+package sqlite
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+//go:embed migration/*.up.sql
+var migrations embed.FS
+
+// DriverName is the database/sql driver name of SQLite
+const DriverName = "sqlite"
+
+// connectionPragmas let the indexer and the search API share a database file
+// and enforce the foreign keys
+const connectionPragmas = "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+
+// Open opens the SQLite database at dataSource, creating it if needed, and
+// applies the migrations it is missing
+func Open(dataSource string) (*sql.DB, error) {
+	separator := "?"
+	if strings.Contains(dataSource, "?") {
+		separator = "&"
+	}
+	conn, err := sql.Open(DriverName, dataSource+separator+connectionPragmas)
+	if err != nil {
+		return nil, err
+	}
+	if err := Migrate(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Migrate applies the up migrations that are newer than the schema version
+// of the database. The version is kept in PRAGMA user_version.
+func Migrate(conn *sql.DB) error {
+	var version int
+	if err := conn.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+
+	files, err := fs.Glob(migrations, "migration/*.up.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for i, file := range files {
+		if i < version {
+			continue
+		}
+		schema, err := migrations.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		tx, err := conn.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(string(schema)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s: %w", file, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+CREATE TABLE users (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  email TEXT NOT NULL,
+  username TEXT UNIQUE NOT NULL,
+  password TEXT NOT NULL,
+  password_hash TEXT NOT NULL,
+  phone TEXT NOT NULL,
+  fullname TEXT NOT NULL,
+  avatar TEXT NOT NULL,
+  state INTEGER NOT NULL,
+  role TEXT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  update_at DATETIME NOT NULL DEFAULT '0001-01-01 00:00:00+00:00'
+);
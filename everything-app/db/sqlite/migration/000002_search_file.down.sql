@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS file_fts_update;
+DROP TRIGGER IF EXISTS file_fts_delete;
+DROP TRIGGER IF EXISTS file_fts_insert;
+DROP TABLE IF EXISTS file_fts;
+DROP TABLE IF EXISTS file;
@@ -0,0 +1,35 @@
+CREATE TABLE file (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL,
+  extension TEXT NOT NULL,
+  size INTEGER NOT NULL,
+  path TEXT UNIQUE NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  modified_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  accessed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  attributes TEXT NOT NULL,
+  content TEXT NOT NULL
+);
+
+-- Name and content search. The trigram tokenizer lets LIKE '%term%' use the
+-- index like ILIKE does on Postgres.
+CREATE VIRTUAL TABLE file_fts USING fts5(
+  name,
+  content,
+  content = 'file',
+  content_rowid = 'id',
+  tokenize = 'trigram'
+);
+
+CREATE TRIGGER file_fts_insert AFTER INSERT ON file BEGIN
+  INSERT INTO file_fts (rowid, name, content) VALUES (new.id, new.name, new.content);
+END;
+
+CREATE TRIGGER file_fts_delete AFTER DELETE ON file BEGIN
+  INSERT INTO file_fts (file_fts, rowid, name, content) VALUES ('delete', old.id, old.name, old.content);
+END;
+
+CREATE TRIGGER file_fts_update AFTER UPDATE ON file BEGIN
+  INSERT INTO file_fts (file_fts, rowid, name, content) VALUES ('delete', old.id, old.name, old.content);
+  INSERT INTO file_fts (rowid, name, content) VALUES (new.id, new.name, new.content);
+END;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS file_text_update;
+DROP TRIGGER IF EXISTS file_text_delete;
+DROP TRIGGER IF EXISTS file_text_insert;
+DROP TABLE IF EXISTS file_text;
@@ -0,0 +1,23 @@
+-- Ranked content search. Words are stemmed like the english configuration
+-- of the Postgres full-text index.
+CREATE VIRTUAL TABLE file_text USING fts5(
+  content,
+  content = 'file',
+  content_rowid = 'id',
+  tokenize = 'porter unicode61'
+);
+
+INSERT INTO file_text (file_text) VALUES ('rebuild');
+
+CREATE TRIGGER file_text_insert AFTER INSERT ON file BEGIN
+  INSERT INTO file_text (rowid, content) VALUES (new.id, new.content);
+END;
+
+CREATE TRIGGER file_text_delete AFTER DELETE ON file BEGIN
+  INSERT INTO file_text (file_text, rowid, content) VALUES ('delete', old.id, old.content);
+END;
+
+CREATE TRIGGER file_text_update AFTER UPDATE ON file BEGIN
+  INSERT INTO file_text (file_text, rowid, content) VALUES ('delete', old.id, old.content);
+  INSERT INTO file_text (rowid, content) VALUES (new.id, new.content);
+END;
@@ -0,0 +1,30 @@
+-- Named searches of the users, a watched search notifies its owner of the
+-- files created or modified that match its query
+CREATE TABLE saved_search (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  owner TEXT NOT NULL REFERENCES users (username) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  query TEXT NOT NULL,
+  watch BOOLEAN NOT NULL DEFAULT false,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (owner, name)
+);
+
+-- A match of a watched search, delivered to the webhook until it succeeds
+-- or runs out of attempts. delivered_at is the zero time until delivered.
+CREATE TABLE notification (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  saved_search_id INTEGER NOT NULL REFERENCES saved_search (id) ON DELETE CASCADE,
+  path TEXT NOT NULL,
+  change_type TEXT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  last_error TEXT NOT NULL DEFAULT '',
+  delivered_at DATETIME NOT NULL DEFAULT '0001-01-01 00:00:00+00:00'
+);
+
+CREATE INDEX notification_saved_search_id_idx ON notification (saved_search_id);
+CREATE INDEX notification_pending_idx ON notification (next_attempt_at)
+  WHERE delivered_at < '0001-01-02';
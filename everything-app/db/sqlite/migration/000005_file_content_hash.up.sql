@@ -0,0 +1,5 @@
+-- SHA-256 hashes of the file bytes for the duplicate search, empty until
+-- computed, see the Postgres migration
+ALTER TABLE file ADD COLUMN partial_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE file ADD COLUMN content_hash TEXT NOT NULL DEFAULT '';
+CREATE INDEX file_content_hash_idx ON file (content_hash) WHERE content_hash <> '';
@@ -0,0 +1 @@
+ALTER TABLE file DROP COLUMN metadata;
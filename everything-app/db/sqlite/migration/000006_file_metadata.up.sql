@@ -0,0 +1,3 @@
+-- Document and media properties as a JSON object of strings, see the
+-- Postgres migration
+ALTER TABLE file ADD COLUMN metadata TEXT NOT NULL DEFAULT '{}';
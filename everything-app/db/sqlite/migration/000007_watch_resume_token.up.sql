@@ -0,0 +1,7 @@
+-- The resume token of the last change of the indexer handled by the alert
+-- watcher, a single row so a restarted watcher resumes after it
+CREATE TABLE watch_resume_token (
+  id INTEGER PRIMARY KEY NOT NULL CHECK (id = 1),
+  token TEXT NOT NULL,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,77 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+
+package sqlite
+
+import (
+	"time"
+)
+
+type File struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Extension   string    `json:"extension"`
+	Size        int64     `json:"size"`
+	Path        string    `json:"path"`
+	CreatedAt   time.Time `json:"created_at"`
+	ModifiedAt  time.Time `json:"modified_at"`
+	AccessedAt  time.Time `json:"accessed_at"`
+	Attributes  string    `json:"attributes"`
+	Content     string    `json:"content"`
+	PartialHash string    `json:"partial_hash"`
+	ContentHash string    `json:"content_hash"`
+	Metadata    string    `json:"metadata"`
+}
+
+type FileFt struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+type FileText struct {
+	Content string `json:"content"`
+}
+
+type Notification struct {
+	ID            int64     `json:"id"`
+	SavedSearchID int64     `json:"saved_search_id"`
+	Path          string    `json:"path"`
+	ChangeType    string    `json:"change_type"`
+	CreatedAt     time.Time `json:"created_at"`
+	Attempts      int64     `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     string    `json:"last_error"`
+	DeliveredAt   time.Time `json:"delivered_at"`
+}
+
+type SavedSearch struct {
+	ID        int64     `json:"id"`
+	Owner     string    `json:"owner"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	Watch     bool      `json:"watch"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type User struct {
+	ID           int64     `json:"id"`
+	Email        string    `json:"email"`
+	Username     string    `json:"username"`
+	Password     string    `json:"password"`
+	PasswordHash string    `json:"password_hash"`
+	Phone        string    `json:"phone"`
+	Fullname     string    `json:"fullname"`
+	Avatar       string    `json:"avatar"`
+	State        int64     `json:"state"`
+	Role         string    `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdateAt     time.Time `json:"update_at"`
+}
+
+type WatchResumeToken struct {
+	ID        int64     `json:"id"`
+	Token     string    `json:"token"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,333 @@
+-- name: GetFiles :many
+-- name is searched according to name_match like on Postgres, REGEXP and
+-- word_similarity are functions registered by the store. An empty name is
+-- not searched. The order column is selected in params, sqlc does not type
+-- parameters in ORDER BY. Files are sorted and paged like on Postgres.
+SELECT
+    matches.path,
+    matches.name,
+    matches.extension,
+    matches.size,
+    matches.created_at,
+    matches.modified_at,
+    matches.accessed_at,
+    matches.attributes,
+    matches.metadata,
+    matches.similarity
+FROM (
+    SELECT
+        file.path,
+        file.name,
+        file.extension,
+        file.size,
+        file.created_at,
+        file.modified_at,
+        file.accessed_at,
+        file.attributes,
+        file.metadata,
+        0 AS relevance,
+        CAST(CASE WHEN CAST(sqlc.arg(name_match) AS TEXT) = 'fuzzy' AND CAST(sqlc.arg(name) AS TEXT) <> '' THEN max(
+            word_similarity(CAST(sqlc.arg(name) AS TEXT), file.name),
+            word_similarity(CAST(sqlc.arg(name) AS TEXT), file.path)
+        ) ELSE 0 END AS REAL) AS similarity
+    FROM
+        file
+    WHERE
+        -- Filename search
+        (CAST(sqlc.arg(name) AS TEXT) = ''
+            -- LIKE ignores case like ILIKE and uses the trigram index
+            OR (CAST(sqlc.arg(name_match) AS TEXT) = 'substring' AND file.id IN (
+                SELECT rowid FROM file_fts WHERE file_fts.name LIKE '%' || CAST(sqlc.arg(name) AS TEXT) || '%'
+            ))
+            OR (CAST(sqlc.arg(name_match) AS TEXT) = 'exact' AND file.name = CAST(sqlc.arg(name) AS TEXT))
+            OR (CAST(sqlc.arg(name_match) AS TEXT) = 'regex' AND file.name REGEXP CAST(sqlc.arg(name) AS TEXT))
+            OR (CAST(sqlc.arg(name_match) AS TEXT) = 'fuzzy' AND max(
+                word_similarity(CAST(sqlc.arg(name) AS TEXT), file.name),
+                word_similarity(CAST(sqlc.arg(name) AS TEXT), file.path)
+            ) >= CAST(sqlc.arg(threshold) AS REAL)))
+        -- File extension exact match
+        AND (CAST(sqlc.arg(extension) AS TEXT) = '' OR file.extension = CAST(sqlc.arg(extension) AS TEXT))
+        -- File size range search
+        AND file.size >= sqlc.arg(size_min)
+        AND file.size <= sqlc.arg(size_max)
+        -- File created_at range search
+        AND file.created_at >= sqlc.arg(created_after)
+        AND file.created_at <= sqlc.arg(created_before)
+        -- File modified_At range search
+        AND file.modified_at >= sqlc.arg(modified_after)
+        AND file.modified_at <= sqlc.arg(modified_before)
+        -- File accessed_at range search
+        AND file.accessed_at >= sqlc.arg(accessed_after)
+        AND file.accessed_at <= sqlc.arg(accessed_before)
+        -- Metadata values, metadata_contains is registered by the store
+        AND (CAST(sqlc.arg(metadata) AS TEXT) = '' OR metadata_contains(file.metadata, CAST(sqlc.arg(metadata) AS TEXT)))
+) AS matches,
+    (SELECT CAST(sqlc.arg(sort_by) AS TEXT) AS sort_by, CAST(sqlc.arg(sort_order) AS TEXT) AS sort_order) AS params
+WHERE
+    CAST(sqlc.arg(after_path) AS TEXT) = ''
+    OR (params.sort_order = 'asc' AND CASE params.sort_by
+        WHEN 'name' THEN (name > CAST(sqlc.arg(after_text) AS TEXT) OR (name = CAST(sqlc.arg(after_text) AS TEXT) AND path > CAST(sqlc.arg(after_path) AS TEXT)))
+        WHEN 'size' THEN (size > CAST(sqlc.arg(after_size) AS INTEGER) OR (size = CAST(sqlc.arg(after_size) AS INTEGER) AND path > CAST(sqlc.arg(after_path) AS TEXT)))
+        WHEN 'modified_at' THEN (modified_at > sqlc.arg(after_time) OR (modified_at = sqlc.arg(after_time) AND path > CAST(sqlc.arg(after_path) AS TEXT)))
+        WHEN 'created_at' THEN (created_at > sqlc.arg(after_time) OR (created_at = sqlc.arg(after_time) AND path > CAST(sqlc.arg(after_path) AS TEXT)))
+        WHEN 'path' THEN path > CAST(sqlc.arg(after_path) AS TEXT)
+        WHEN 'relevance' THEN (relevance > CAST(sqlc.arg(after_rank) AS REAL) OR (relevance = CAST(sqlc.arg(after_rank) AS REAL)
+            AND (similarity > CAST(sqlc.arg(after_similarity) AS REAL) OR (similarity = CAST(sqlc.arg(after_similarity) AS REAL) AND path > CAST(sqlc.arg(after_path) AS TEXT)))))
+        ELSE path > CAST(sqlc.arg(after_path) AS TEXT)
+    END)
+    OR (params.sort_order = 'desc' AND CASE params.sort_by
+        WHEN 'name' THEN (name < CAST(sqlc.arg(after_text) AS TEXT) OR (name = CAST(sqlc.arg(after_text) AS TEXT) AND path > CAST(sqlc.arg(after_path) AS TEXT)))
+        WHEN 'size' THEN (size < CAST(sqlc.arg(after_size) AS INTEGER) OR (size = CAST(sqlc.arg(after_size) AS INTEGER) AND path > CAST(sqlc.arg(after_path) AS TEXT)))
+        WHEN 'modified_at' THEN (modified_at < sqlc.arg(after_time) OR (modified_at = sqlc.arg(after_time) AND path > CAST(sqlc.arg(after_path) AS TEXT)))
+        WHEN 'created_at' THEN (created_at < sqlc.arg(after_time) OR (created_at = sqlc.arg(after_time) AND path > CAST(sqlc.arg(after_path) AS TEXT)))
+        WHEN 'path' THEN path < CAST(sqlc.arg(after_path) AS TEXT)
+        WHEN 'relevance' THEN (relevance < CAST(sqlc.arg(after_rank) AS REAL) OR (relevance = CAST(sqlc.arg(after_rank) AS REAL)
+            AND (similarity < CAST(sqlc.arg(after_similarity) AS REAL) OR (similarity = CAST(sqlc.arg(after_similarity) AS REAL) AND path > CAST(sqlc.arg(after_path) AS TEXT)))))
+        ELSE path > CAST(sqlc.arg(after_path) AS TEXT)
+    END)
+ORDER BY
+    CASE WHEN params.sort_by = 'name' AND params.sort_order = 'asc' THEN name END ASC,
+    CASE WHEN params.sort_by = 'name' AND params.sort_order = 'desc' THEN name END DESC,
+    CASE WHEN params.sort_by = 'size' AND params.sort_order = 'asc' THEN size END ASC,
+    CASE WHEN params.sort_by = 'size' AND params.sort_order = 'desc' THEN size END DESC,
+    CASE WHEN params.sort_by = 'modified_at' AND params.sort_order = 'asc' THEN modified_at END ASC,
+    CASE WHEN params.sort_by = 'modified_at' AND params.sort_order = 'desc' THEN modified_at END DESC,
+    CASE WHEN params.sort_by = 'created_at' AND params.sort_order = 'asc' THEN created_at END ASC,
+    CASE WHEN params.sort_by = 'created_at' AND params.sort_order = 'desc' THEN created_at END DESC,
+    CASE WHEN params.sort_by = 'path' AND params.sort_order = 'asc' THEN path END ASC,
+    CASE WHEN params.sort_by = 'path' AND params.sort_order = 'desc' THEN path END DESC,
+    CASE WHEN params.sort_by = 'relevance' AND params.sort_order = 'asc' THEN relevance END ASC,
+    CASE WHEN params.sort_by = 'relevance' AND params.sort_order = 'desc' THEN relevance END DESC,
+    CASE WHEN params.sort_by = 'relevance' AND params.sort_order = 'asc' THEN similarity END ASC,
+    CASE WHEN params.sort_by = 'relevance' AND params.sort_order = 'desc' THEN similarity END DESC,
+    path
+-- If limit is 0, return all results
+LIMIT CASE WHEN CAST(sqlc.arg(page_limit) AS INTEGER) = 0 THEN -1 ELSE CAST(sqlc.arg(page_limit) AS INTEGER) END
+OFFSET sqlc.arg(page_offset);
+
+-- name: SearchFiles :many
+-- query is an FTS5 MATCH expression. The bm25 score is scaled to [0, 1) like
+-- ts_rank_cd with normalization 32 on Postgres and rounded by to_real to a
+-- real, so it compares equal to the rank of a cursor. name is searched like
+-- in GetFiles and the files are sorted and paged like in GetFiles.
+SELECT
+    matches.path,
+    matches.name,
+    matches.extension,
+    matches.size,
+    matches.created_at,
+    matches.modified_at,
+    matches.accessed_at,
+    matches.attributes,
+    matches.metadata,
+    matches.relevance,
+    matches.similarity,
+    matches.snippet
+FROM (
+    SELECT
+        file.path,
+        file.name,
+        file.extension,
+        file.size,
+        file.created_at,
+        file.modified_at,
+        file.accessed_at,
+        file.attributes,
+        file.metadata,
+        CAST(to_real(-bm25(file_text) / (1 - bm25(file_text))) AS REAL) AS relevance,
+        CAST(CASE WHEN CAST(sqlc.arg(name_match) AS TEXT) = 'fuzzy' AND CAST(sqlc.arg(name) AS TEXT) <> '' THEN max(
+            word_similarity(CAST(sqlc.arg(name) AS TEXT), file.name),
+            word_similarity(CAST(sqlc.arg(name) AS TEXT), file.path)
+        ) ELSE 0 END AS REAL) AS similarity,
+        CAST(snippet(file_text, 0, '<mark>', '</mark>', '', 20) AS TEXT) AS snippet
+    FROM
+        file_text
+        JOIN file ON file.id = file_text.rowid
+    WHERE
+        file_text.content MATCH sqlc.arg(query)
+        AND -- Filename search
+        (CAST(sqlc.arg(name) AS TEXT) = ''
+            -- LIKE ignores case like ILIKE and uses the trigram index
+            OR (CAST(sqlc.arg(name_match) AS TEXT) = 'substring' AND file.id IN (
+                SELECT rowid FROM file_fts WHERE file_fts.name LIKE '%' || CAST(sqlc.arg(name) AS TEXT) || '%'
+            ))
+            OR (CAST(sqlc.arg(name_match) AS TEXT) = 'exact' AND file.name = CAST(sqlc.arg(name) AS TEXT))
+            OR (CAST(sqlc.arg(name_match) AS TEXT) = 'regex' AND file.name REGEXP CAST(sqlc.arg(name) AS TEXT))
+            OR (CAST(sqlc.arg(name_match) AS TEXT) = 'fuzzy' AND max(
+                word_similarity(CAST(sqlc.arg(name) AS TEXT), file.name),
+                word_similarity(CAST(sqlc.arg(name) AS TEXT), file.path)
+            ) >= CAST(sqlc.arg(threshold) AS REAL)))
+        -- File extension exact match
+        AND (CAST(sqlc.arg(extension) AS TEXT) = '' OR file.extension = CAST(sqlc.arg(extension) AS TEXT))
+        -- File size range search
+        AND file.size >= sqlc.arg(size_min)
+        AND file.size <= sqlc.arg(size_max)
+        -- File created_at range search
+        AND file.created_at >= sqlc.arg(created_after)
+        AND file.created_at <= sqlc.arg(created_before)
+        -- File modified_At range search
+        AND file.modified_at >= sqlc.arg(modified_after)
+        AND file.modified_at <= sqlc.arg(modified_before)
+        -- File accessed_at range search
+        AND file.accessed_at >= sqlc.arg(accessed_after)
+        AND file.accessed_at <= sqlc.arg(accessed_before)
+        -- Metadata values, metadata_contains is registered by the store
+        AND (CAST(sqlc.arg(metadata) AS TEXT) = '' OR metadata_contains(file.metadata, CAST(sqlc.arg(metadata) AS TEXT)))
+) AS matches,
+    (SELECT CAST(sqlc.arg(sort_by) AS TEXT) AS sort_by, CAST(sqlc.arg(sort_order) AS TEXT) AS sort_order) AS params
+WHERE
+    CAST(sqlc.arg(after_path) AS TEXT) = ''
+    OR (params.sort_order = 'asc' AND CASE params.sort_by
+        WHEN 'name' THEN (name > CAST(sqlc.arg(after_text) AS TEXT) OR (name = CAST(sqlc.arg(after_text) AS TEXT) AND path > CAST(sqlc.arg(after_path) AS TEXT)))
+        WHEN 'size' THEN (size > CAST(sqlc.arg(after_size) AS INTEGER) OR (size = CAST(sqlc.arg(after_size) AS INTEGER) AND path > CAST(sqlc.arg(after_path) AS TEXT)))
+        WHEN 'modified_at' THEN (modified_at > sqlc.arg(after_time) OR (modified_at = sqlc.arg(after_time) AND path > CAST(sqlc.arg(after_path) AS TEXT)))
+        WHEN 'created_at' THEN (created_at > sqlc.arg(after_time) OR (created_at = sqlc.arg(after_time) AND path > CAST(sqlc.arg(after_path) AS TEXT)))
+        WHEN 'path' THEN path > CAST(sqlc.arg(after_path) AS TEXT)
+        WHEN 'relevance' THEN (relevance > CAST(sqlc.arg(after_rank) AS REAL) OR (relevance = CAST(sqlc.arg(after_rank) AS REAL)
+            AND (similarity > CAST(sqlc.arg(after_similarity) AS REAL) OR (similarity = CAST(sqlc.arg(after_similarity) AS REAL) AND path > CAST(sqlc.arg(after_path) AS TEXT)))))
+        ELSE path > CAST(sqlc.arg(after_path) AS TEXT)
+    END)
+    OR (params.sort_order = 'desc' AND CASE params.sort_by
+        WHEN 'name' THEN (name < CAST(sqlc.arg(after_text) AS TEXT) OR (name = CAST(sqlc.arg(after_text) AS TEXT) AND path > CAST(sqlc.arg(after_path) AS TEXT)))
+        WHEN 'size' THEN (size < CAST(sqlc.arg(after_size) AS INTEGER) OR (size = CAST(sqlc.arg(after_size) AS INTEGER) AND path > CAST(sqlc.arg(after_path) AS TEXT)))
+        WHEN 'modified_at' THEN (modified_at < sqlc.arg(after_time) OR (modified_at = sqlc.arg(after_time) AND path > CAST(sqlc.arg(after_path) AS TEXT)))
+        WHEN 'created_at' THEN (created_at < sqlc.arg(after_time) OR (created_at = sqlc.arg(after_time) AND path > CAST(sqlc.arg(after_path) AS TEXT)))
+        WHEN 'path' THEN path < CAST(sqlc.arg(after_path) AS TEXT)
+        WHEN 'relevance' THEN (relevance < CAST(sqlc.arg(after_rank) AS REAL) OR (relevance = CAST(sqlc.arg(after_rank) AS REAL)
+            AND (similarity < CAST(sqlc.arg(after_similarity) AS REAL) OR (similarity = CAST(sqlc.arg(after_similarity) AS REAL) AND path > CAST(sqlc.arg(after_path) AS TEXT)))))
+        ELSE path > CAST(sqlc.arg(after_path) AS TEXT)
+    END)
+ORDER BY
+    CASE WHEN params.sort_by = 'name' AND params.sort_order = 'asc' THEN name END ASC,
+    CASE WHEN params.sort_by = 'name' AND params.sort_order = 'desc' THEN name END DESC,
+    CASE WHEN params.sort_by = 'size' AND params.sort_order = 'asc' THEN size END ASC,
+    CASE WHEN params.sort_by = 'size' AND params.sort_order = 'desc' THEN size END DESC,
+    CASE WHEN params.sort_by = 'modified_at' AND params.sort_order = 'asc' THEN modified_at END ASC,
+    CASE WHEN params.sort_by = 'modified_at' AND params.sort_order = 'desc' THEN modified_at END DESC,
+    CASE WHEN params.sort_by = 'created_at' AND params.sort_order = 'asc' THEN created_at END ASC,
+    CASE WHEN params.sort_by = 'created_at' AND params.sort_order = 'desc' THEN created_at END DESC,
+    CASE WHEN params.sort_by = 'path' AND params.sort_order = 'asc' THEN path END ASC,
+    CASE WHEN params.sort_by = 'path' AND params.sort_order = 'desc' THEN path END DESC,
+    CASE WHEN params.sort_by = 'relevance' AND params.sort_order = 'asc' THEN relevance END ASC,
+    CASE WHEN params.sort_by = 'relevance' AND params.sort_order = 'desc' THEN relevance END DESC,
+    CASE WHEN params.sort_by = 'relevance' AND params.sort_order = 'asc' THEN similarity END ASC,
+    CASE WHEN params.sort_by = 'relevance' AND params.sort_order = 'desc' THEN similarity END DESC,
+    path
+-- If limit is 0, return all results
+LIMIT CASE WHEN CAST(sqlc.arg(page_limit) AS INTEGER) = 0 THEN -1 ELSE CAST(sqlc.arg(page_limit) AS INTEGER) END
+OFFSET sqlc.arg(page_offset);
+
+-- name: CountFiles :one
+-- The files of GetFiles and SearchFiles are counted up to count_limit, query
+-- is not matched if it is empty
+SELECT
+    count(*)
+FROM (
+    SELECT
+        1
+    FROM
+        file
+    WHERE
+        (CAST(sqlc.arg(query) AS TEXT) = '' OR file.id IN (
+            SELECT rowid FROM file_text WHERE file_text.content MATCH CAST(sqlc.arg(query) AS TEXT)
+        ))
+        AND -- Filename search
+        (CAST(sqlc.arg(name) AS TEXT) = ''
+            -- LIKE ignores case like ILIKE and uses the trigram index
+            OR (CAST(sqlc.arg(name_match) AS TEXT) = 'substring' AND file.id IN (
+                SELECT rowid FROM file_fts WHERE file_fts.name LIKE '%' || CAST(sqlc.arg(name) AS TEXT) || '%'
+            ))
+            OR (CAST(sqlc.arg(name_match) AS TEXT) = 'exact' AND file.name = CAST(sqlc.arg(name) AS TEXT))
+            OR (CAST(sqlc.arg(name_match) AS TEXT) = 'regex' AND file.name REGEXP CAST(sqlc.arg(name) AS TEXT))
+            OR (CAST(sqlc.arg(name_match) AS TEXT) = 'fuzzy' AND max(
+                word_similarity(CAST(sqlc.arg(name) AS TEXT), file.name),
+                word_similarity(CAST(sqlc.arg(name) AS TEXT), file.path)
+            ) >= CAST(sqlc.arg(threshold) AS REAL)))
+        -- File extension exact match
+        AND (CAST(sqlc.arg(extension) AS TEXT) = '' OR file.extension = CAST(sqlc.arg(extension) AS TEXT))
+        -- File size range search
+        AND file.size >= sqlc.arg(size_min)
+        AND file.size <= sqlc.arg(size_max)
+        -- File created_at range search
+        AND file.created_at >= sqlc.arg(created_after)
+        AND file.created_at <= sqlc.arg(created_before)
+        -- File modified_At range search
+        AND file.modified_at >= sqlc.arg(modified_after)
+        AND file.modified_at <= sqlc.arg(modified_before)
+        -- File accessed_at range search
+        AND file.accessed_at >= sqlc.arg(accessed_after)
+        AND file.accessed_at <= sqlc.arg(accessed_before)
+        -- Metadata values, metadata_contains is registered by the store
+        AND (CAST(sqlc.arg(metadata) AS TEXT) = '' OR metadata_contains(file.metadata, CAST(sqlc.arg(metadata) AS TEXT)))
+    LIMIT sqlc.arg(count_limit)
+) AS matches;
+
+-- name: InsertFile :one
+INSERT INTO file (
+  name,
+  extension,
+  size,
+  path,
+  attributes,
+  content,
+  created_at,
+  modified_at,
+  accessed_at,
+  partial_hash,
+  content_hash,
+  metadata
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+-- No row is returned if the path is already stored
+ON CONFLICT (path) DO NOTHING
+RETURNING *;
+
+-- name: UpsertFile :one
+INSERT INTO file (
+  name,
+  extension,
+  size,
+  path,
+  attributes,
+  content,
+  created_at,
+  modified_at,
+  accessed_at,
+  partial_hash,
+  content_hash,
+  metadata
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT (path) DO UPDATE SET
+  name = excluded.name,
+  extension = excluded.extension,
+  size = excluded.size,
+  attributes = excluded.attributes,
+  content = excluded.content,
+  created_at = excluded.created_at,
+  modified_at = excluded.modified_at,
+  accessed_at = excluded.accessed_at,
+  partial_hash = excluded.partial_hash,
+  content_hash = excluded.content_hash,
+  metadata = excluded.metadata
+RETURNING *;
+
+-- name: UpdateFileHashes :execrows
+-- Stores the hashes computed for the duplicate search
+UPDATE file
+SET
+  partial_hash = ?2,
+  content_hash = ?3
+WHERE path = ?1;
+
+-- name: DeleteFileByPath :execrows
+DELETE FROM file
+WHERE path = ?;
+
+-- name: ListFilesByPrefix :many
+SELECT *
+FROM file
+WHERE substr(path, 1, length(CAST(sqlc.arg(prefix) AS TEXT))) = CAST(sqlc.arg(prefix) AS TEXT)
+ORDER BY path;
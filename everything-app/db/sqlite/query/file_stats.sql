@@ -0,0 +1,122 @@
+-- name: GetFileFacets :many
+-- The number and the total size of the files matching the filters of
+-- GetFiles by extension, top-level directory, named size and modification
+-- month in UTC, and the top_limit directories with the largest total size
+-- of the matching files they directly contain. The facets are ordered by
+-- name, then by size descending.
+WITH matches AS (
+    SELECT
+        file.path,
+        file.extension,
+        file.size,
+        file.modified_at
+    FROM
+        file
+    WHERE
+            (CAST(sqlc.arg(query) AS TEXT) = '' OR file.id IN (
+                SELECT rowid FROM file_text WHERE file_text.content MATCH CAST(sqlc.arg(query) AS TEXT)
+            ))
+            AND -- Filename search
+            (CAST(sqlc.arg(name) AS TEXT) = ''
+                -- LIKE ignores case like ILIKE and uses the trigram index
+                OR (CAST(sqlc.arg(name_match) AS TEXT) = 'substring' AND file.id IN (
+                    SELECT rowid FROM file_fts WHERE file_fts.name LIKE '%' || CAST(sqlc.arg(name) AS TEXT) || '%'
+                ))
+                OR (CAST(sqlc.arg(name_match) AS TEXT) = 'exact' AND file.name = CAST(sqlc.arg(name) AS TEXT))
+                OR (CAST(sqlc.arg(name_match) AS TEXT) = 'regex' AND file.name REGEXP CAST(sqlc.arg(name) AS TEXT))
+                OR (CAST(sqlc.arg(name_match) AS TEXT) = 'fuzzy' AND max(
+                    word_similarity(CAST(sqlc.arg(name) AS TEXT), file.name),
+                    word_similarity(CAST(sqlc.arg(name) AS TEXT), file.path)
+                ) >= CAST(sqlc.arg(threshold) AS REAL)))
+            -- File extension exact match
+            AND (CAST(sqlc.arg(extension) AS TEXT) = '' OR file.extension = CAST(sqlc.arg(extension) AS TEXT))
+            -- File size range search
+            AND file.size >= sqlc.arg(size_min)
+            AND file.size <= sqlc.arg(size_max)
+            -- File created_at range search
+            AND file.created_at >= sqlc.arg(created_after)
+            AND file.created_at <= sqlc.arg(created_before)
+            -- File modified_At range search
+            AND file.modified_at >= sqlc.arg(modified_after)
+            AND file.modified_at <= sqlc.arg(modified_before)
+            -- File accessed_at range search
+            AND file.accessed_at >= sqlc.arg(accessed_after)
+            AND file.accessed_at <= sqlc.arg(accessed_before)
+            -- Metadata values, metadata_contains is registered by the store
+            AND (CAST(sqlc.arg(metadata) AS TEXT) = '' OR metadata_contains(file.metadata, CAST(sqlc.arg(metadata) AS TEXT)))
+)
+SELECT
+    CAST('extension' AS TEXT) AS facet,
+    CAST(extension AS TEXT) AS value,
+    count(*) AS files,
+    CAST(sum(size) AS INTEGER) AS size
+FROM matches
+GROUP BY extension
+UNION ALL
+SELECT 'directory', top_directory(path), count(*), CAST(sum(size) AS INTEGER)
+FROM matches
+GROUP BY top_directory(path)
+UNION ALL
+SELECT 'size', size_name(size), count(*), CAST(sum(size) AS INTEGER)
+FROM matches
+GROUP BY size_name(size)
+UNION ALL
+SELECT 'month', utc_month(modified_at), count(*), CAST(sum(size) AS INTEGER)
+FROM matches
+GROUP BY utc_month(modified_at)
+UNION ALL
+SELECT facet, value, files, size FROM (
+    SELECT 'largest_directory' AS facet, parent_directory(path) AS value, count(*) AS files, CAST(sum(size) AS INTEGER) AS size
+    FROM matches
+    GROUP BY parent_directory(path)
+    ORDER BY size DESC, value
+    LIMIT sqlc.arg(top_limit)
+) AS largest_directories
+ORDER BY facet, size DESC, value;
+
+-- name: GetLargestFiles :many
+-- The top_limit largest files matching the filters of GetFiles
+SELECT
+    file.path,
+    file.name,
+    file.extension,
+    file.size,
+    file.modified_at
+FROM
+    file
+WHERE
+    (CAST(sqlc.arg(query) AS TEXT) = '' OR file.id IN (
+        SELECT rowid FROM file_text WHERE file_text.content MATCH CAST(sqlc.arg(query) AS TEXT)
+    ))
+    AND -- Filename search
+    (CAST(sqlc.arg(name) AS TEXT) = ''
+        -- LIKE ignores case like ILIKE and uses the trigram index
+        OR (CAST(sqlc.arg(name_match) AS TEXT) = 'substring' AND file.id IN (
+            SELECT rowid FROM file_fts WHERE file_fts.name LIKE '%' || CAST(sqlc.arg(name) AS TEXT) || '%'
+        ))
+        OR (CAST(sqlc.arg(name_match) AS TEXT) = 'exact' AND file.name = CAST(sqlc.arg(name) AS TEXT))
+        OR (CAST(sqlc.arg(name_match) AS TEXT) = 'regex' AND file.name REGEXP CAST(sqlc.arg(name) AS TEXT))
+        OR (CAST(sqlc.arg(name_match) AS TEXT) = 'fuzzy' AND max(
+            word_similarity(CAST(sqlc.arg(name) AS TEXT), file.name),
+            word_similarity(CAST(sqlc.arg(name) AS TEXT), file.path)
+        ) >= CAST(sqlc.arg(threshold) AS REAL)))
+    -- File extension exact match
+    AND (CAST(sqlc.arg(extension) AS TEXT) = '' OR file.extension = CAST(sqlc.arg(extension) AS TEXT))
+    -- File size range search
+    AND file.size >= sqlc.arg(size_min)
+    AND file.size <= sqlc.arg(size_max)
+    -- File created_at range search
+    AND file.created_at >= sqlc.arg(created_after)
+    AND file.created_at <= sqlc.arg(created_before)
+    -- File modified_At range search
+    AND file.modified_at >= sqlc.arg(modified_after)
+    AND file.modified_at <= sqlc.arg(modified_before)
+    -- File accessed_at range search
+    AND file.accessed_at >= sqlc.arg(accessed_after)
+    AND file.accessed_at <= sqlc.arg(accessed_before)
+    -- Metadata values, metadata_contains is registered by the store
+    AND (CAST(sqlc.arg(metadata) AS TEXT) = '' OR metadata_contains(file.metadata, CAST(sqlc.arg(metadata) AS TEXT)))
+ORDER BY
+    file.size DESC,
+    file.path
+LIMIT sqlc.arg(top_limit);
@@ -0,0 +1,102 @@
+-- name: CreateSavedSearch :one
+INSERT INTO saved_search (
+  owner,
+  name,
+  query,
+  watch,
+  created_at,
+  updated_at
+) VALUES (
+  ?1, ?2, ?3, ?4, ?5, ?5
+) RETURNING *;
+
+-- name: GetSavedSearch :one
+SELECT * FROM saved_search
+WHERE id = ?1;
+
+-- name: ListSavedSearches :many
+SELECT * FROM saved_search
+WHERE owner = ?1
+ORDER BY name;
+
+-- name: ListWatchedSearches :many
+SELECT * FROM saved_search
+WHERE watch
+ORDER BY id;
+
+-- name: UpdateSavedSearch :one
+UPDATE saved_search
+SET
+  name = ?2,
+  query = ?3,
+  watch = ?4,
+  updated_at = ?5
+WHERE id = ?1
+RETURNING *;
+
+-- name: DeleteSavedSearch :execrows
+DELETE FROM saved_search
+WHERE id = ?1;
+
+-- name: CreateNotification :one
+INSERT INTO notification (
+  saved_search_id,
+  path,
+  change_type,
+  created_at,
+  next_attempt_at
+) VALUES (
+  ?1, ?2, ?3, ?4, ?4
+) RETURNING *;
+
+-- name: ListNotifications :many
+-- The newest notifications of a saved search first
+SELECT * FROM notification
+WHERE saved_search_id = ?1
+ORDER BY id DESC
+LIMIT ?2;
+
+-- name: ListDueNotifications :many
+-- Notifications waiting for an attempt due at now, with their saved search.
+-- The driver writes the zero delivered_at in another format than the column
+-- default, both sort before the next day.
+SELECT
+  sqlc.embed(notification),
+  saved_search.owner,
+  saved_search.name,
+  saved_search.query
+FROM notification
+JOIN saved_search ON saved_search.id = notification.saved_search_id
+WHERE notification.delivered_at < '0001-01-02'
+  AND notification.attempts < sqlc.arg(max_attempts)
+  AND notification.next_attempt_at <= sqlc.arg(now)
+ORDER BY notification.next_attempt_at
+LIMIT sqlc.arg(due_limit);
+
+-- name: UpdateNotificationAttempt :exec
+-- Records an attempt to deliver a notification, delivered_at is the zero
+-- time if it failed
+UPDATE notification
+SET
+  attempts = attempts + 1,
+  next_attempt_at = ?2,
+  last_error = ?3,
+  delivered_at = ?4
+WHERE id = ?1;
+
+-- name: GetWatchResumeToken :one
+SELECT token FROM watch_resume_token
+WHERE id = 1;
+
+-- name: SetWatchResumeToken :exec
+INSERT INTO watch_resume_token (
+  id,
+  token,
+  updated_at
+) VALUES (
+  1, ?1, ?2
+)
+ON CONFLICT (id) DO UPDATE
+SET
+  token = excluded.token,
+  updated_at = excluded.updated_at;
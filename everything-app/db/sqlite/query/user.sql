@@ -0,0 +1,68 @@
+-- name: CreateUser :one
+INSERT INTO users (
+  email,
+  username,
+  password,
+  password_hash,
+  phone,
+  fullname,
+  avatar,
+  state,
+  role,
+  created_at,
+  update_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+) RETURNING *;
+
+
+-- name: GetUsersAsc :many
+-- The order column is selected in params, sqlc does not type parameters in ORDER BY
+SELECT users.* FROM users, (SELECT CAST(sqlc.arg(order_by) AS TEXT) AS order_by) AS params
+WHERE (username LIKE '%' || CAST(sqlc.narg(search) AS TEXT) || '%' OR fullname LIKE '%' || CAST(sqlc.narg(search) AS TEXT) || '%' OR CAST(sqlc.narg(search) AS TEXT) IS NULL)
+  AND state = sqlc.arg(state)
+ORDER BY
+  CASE WHEN params.order_by = 'id' THEN id END ASC,
+  CASE WHEN params.order_by = 'username' THEN username END ASC,
+  CASE WHEN params.order_by = 'fullname' THEN fullname END ASC
+LIMIT sqlc.arg(limit) OFFSET sqlc.arg(offset);
+
+-- name: GetUsersDesc :many
+SELECT users.* FROM users, (SELECT CAST(sqlc.arg(order_by) AS TEXT) AS order_by) AS params
+WHERE (username LIKE '%' || CAST(sqlc.narg(search) AS TEXT) || '%' OR fullname LIKE '%' || CAST(sqlc.narg(search) AS TEXT) || '%' OR CAST(sqlc.narg(search) AS TEXT) IS NULL)
+  AND state = sqlc.arg(state)
+ORDER BY
+  CASE WHEN params.order_by = 'id' THEN id END DESC,
+  CASE WHEN params.order_by = 'username' THEN username END DESC,
+  CASE WHEN params.order_by = 'fullname' THEN fullname END DESC
+LIMIT sqlc.arg(limit) OFFSET sqlc.arg(offset);
+
+
+-- name: GetUserById :one
+SELECT * FROM users
+WHERE id = ?;
+
+-- name: GetUserByUsername :one
+SELECT * FROM users
+WHERE username = ?;
+
+-- name: UpdateUser :one
+UPDATE users
+SET
+  fullname = COALESCE(sqlc.arg(fullname), fullname),
+  email = COALESCE(sqlc.arg(email), email),
+  phone = COALESCE(sqlc.arg(phone), phone),
+  password = COALESCE(sqlc.arg(password), password),
+  avatar = COALESCE(sqlc.arg(avatar), avatar),
+  role = COALESCE(sqlc.arg(role), role),
+  password_hash = COALESCE(sqlc.arg(password_hash), password_hash),
+  update_at = sqlc.arg(update_at)
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+
+-- name: DeleteUser :one
+UPDATE users
+SET state = ?
+WHERE id = ?
+RETURNING *;
@@ -0,0 +1,149 @@
+package sqlite
+
+import (
+	"context"
+	db "training/db/sqlc"
+)
+
+func (store *Store) CreateSavedSearch(ctx context.Context, arg db.CreateSavedSearchParams) (db.SavedSearch, error) {
+	search, err := store.queries.CreateSavedSearch(ctx, CreateSavedSearchParams{
+		Owner:     arg.Owner,
+		Name:      arg.Name,
+		Query:     arg.Query,
+		Watch:     arg.Watch,
+		CreatedAt: arg.CreatedAt.UTC(),
+	})
+	return newSavedSearch(search), mapError(err)
+}
+
+func (store *Store) GetSavedSearch(ctx context.Context, id int32) (db.SavedSearch, error) {
+	search, err := store.queries.GetSavedSearch(ctx, int64(id))
+	return newSavedSearch(search), mapError(err)
+}
+
+func (store *Store) ListSavedSearches(ctx context.Context, owner string) ([]db.SavedSearch, error) {
+	searches, err := store.queries.ListSavedSearches(ctx, owner)
+	return newSavedSearches(searches), mapError(err)
+}
+
+func (store *Store) ListWatchedSearches(ctx context.Context) ([]db.SavedSearch, error) {
+	searches, err := store.queries.ListWatchedSearches(ctx)
+	return newSavedSearches(searches), mapError(err)
+}
+
+func (store *Store) UpdateSavedSearch(ctx context.Context, arg db.UpdateSavedSearchParams) (db.SavedSearch, error) {
+	search, err := store.queries.UpdateSavedSearch(ctx, UpdateSavedSearchParams{
+		ID:        int64(arg.ID),
+		Name:      arg.Name,
+		Query:     arg.Query,
+		Watch:     arg.Watch,
+		UpdatedAt: arg.UpdatedAt.UTC(),
+	})
+	return newSavedSearch(search), mapError(err)
+}
+
+func (store *Store) DeleteSavedSearch(ctx context.Context, id int32) (int64, error) {
+	deleted, err := store.queries.DeleteSavedSearch(ctx, int64(id))
+	return deleted, mapError(err)
+}
+
+func (store *Store) CreateNotification(ctx context.Context, arg db.CreateNotificationParams) (db.Notification, error) {
+	notification, err := store.queries.CreateNotification(ctx, CreateNotificationParams{
+		SavedSearchID: int64(arg.SavedSearchID),
+		Path:          arg.Path,
+		ChangeType:    arg.ChangeType,
+		CreatedAt:     arg.CreatedAt.UTC(),
+	})
+	return newNotification(notification), mapError(err)
+}
+
+func (store *Store) ListNotifications(ctx context.Context, arg db.ListNotificationsParams) ([]db.Notification, error) {
+	notifications, err := store.queries.ListNotifications(ctx, ListNotificationsParams{
+		SavedSearchID: int64(arg.SavedSearchID),
+		Limit:         int64(arg.Limit),
+	})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	result := make([]db.Notification, len(notifications))
+	for i, notification := range notifications {
+		result[i] = newNotification(notification)
+	}
+	return result, nil
+}
+
+func (store *Store) ListDueNotifications(ctx context.Context, arg db.ListDueNotificationsParams) ([]db.ListDueNotificationsRow, error) {
+	rows, err := store.queries.ListDueNotifications(ctx, ListDueNotificationsParams{
+		MaxAttempts: int64(arg.MaxAttempts),
+		Now:         arg.Now.UTC(),
+		DueLimit:    int64(arg.DueLimit),
+	})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	result := make([]db.ListDueNotificationsRow, len(rows))
+	for i, row := range rows {
+		result[i] = db.ListDueNotificationsRow{
+			Notification: newNotification(row.Notification),
+			Owner:        row.Owner,
+			Name:         row.Name,
+			Query:        row.Query,
+		}
+	}
+	return result, nil
+}
+
+func (store *Store) UpdateNotificationAttempt(ctx context.Context, arg db.UpdateNotificationAttemptParams) error {
+	return mapError(store.queries.UpdateNotificationAttempt(ctx, UpdateNotificationAttemptParams{
+		ID:            int64(arg.ID),
+		NextAttemptAt: arg.NextAttemptAt.UTC(),
+		LastError:     arg.LastError,
+		DeliveredAt:   arg.DeliveredAt.UTC(),
+	}))
+}
+
+func (store *Store) GetWatchResumeToken(ctx context.Context) (string, error) {
+	token, err := store.queries.GetWatchResumeToken(ctx)
+	return token, mapError(err)
+}
+
+func (store *Store) SetWatchResumeToken(ctx context.Context, arg db.SetWatchResumeTokenParams) error {
+	return mapError(store.queries.SetWatchResumeToken(ctx, SetWatchResumeTokenParams{
+		Token:     arg.Token,
+		UpdatedAt: arg.UpdatedAt.UTC(),
+	}))
+}
+
+func newSavedSearch(search SavedSearch) db.SavedSearch {
+	return db.SavedSearch{
+		ID:        int32(search.ID),
+		Owner:     search.Owner,
+		Name:      search.Name,
+		Query:     search.Query,
+		Watch:     search.Watch,
+		CreatedAt: search.CreatedAt,
+		UpdatedAt: search.UpdatedAt,
+	}
+}
+
+func newSavedSearches(searches []SavedSearch) []db.SavedSearch {
+	result := make([]db.SavedSearch, len(searches))
+	for i, search := range searches {
+		result[i] = newSavedSearch(search)
+	}
+	return result
+}
+
+func newNotification(notification Notification) db.Notification {
+	return db.Notification{
+		ID:            int32(notification.ID),
+		SavedSearchID: int32(notification.SavedSearchID),
+		Path:          notification.Path,
+		ChangeType:    notification.ChangeType,
+		CreatedAt:     notification.CreatedAt,
+		Attempts:      int32(notification.Attempts),
+		NextAttemptAt: notification.NextAttemptAt,
+		LastError:     notification.LastError,
+		DeliveredAt:   notification.DeliveredAt,
+	}
+}
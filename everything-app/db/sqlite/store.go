@@ -0,0 +1,425 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"training/db/filequery"
+	db "training/db/sqlc"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Store implements db.Store on SQLite. Arguments and results are converted
+// from and to the types of the Postgres queries, so the store can be used
+// wherever a db.Store is expected.
+type Store struct {
+	queries *Queries
+	db      *sql.DB
+}
+
+var _ db.Store = (*Store)(nil)
+
+// NewStore returns a Store on a migrated SQLite database
+func NewStore(conn *sql.DB) *Store {
+	return &Store{
+		queries: New(conn),
+		db:      conn,
+	}
+}
+
+func (store *Store) execTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	q := store.queries.WithTx(tx)
+	err = fn(q)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// FileBatchTx applies ops in order in a single database transaction
+func (store *Store) FileBatchTx(ctx context.Context, ops []db.FileOp) (db.FileBatchTxResult, error) {
+	var result db.FileBatchTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		result = db.FileBatchTxResult{}
+		for i, op := range ops {
+			var err error
+			switch {
+			case op.Insert != nil:
+				_, err = q.InsertFile(ctx, InsertFileParams(newUpsertFileParams(db.UpsertFileParams(*op.Insert))))
+				if errors.Is(err, sql.ErrNoRows) {
+					result.Existing = append(result.Existing, i)
+					err = nil
+				}
+			case op.Upsert != nil:
+				_, err = q.UpsertFile(ctx, newUpsertFileParams(*op.Upsert))
+			case op.Hashes != nil:
+				_, err = q.UpdateFileHashes(ctx, UpdateFileHashesParams(*op.Hashes))
+			default:
+				var deleted int64
+				deleted, err = q.DeleteFileByPath(ctx, op.Delete)
+				result.Deleted += deleted
+			}
+			if err != nil {
+				return mapError(err)
+			}
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// GetFiles runs the content search, arg.Content parsed as a db.TextQuery, on
+// the FTS5 index of the content. Without it all files matching the other
+// filters are returned with a rank of 0.
+func (store *Store) GetFiles(ctx context.Context, arg db.GetFilesParams) ([]db.GetFilesRow, error) {
+	if arg.Content == "" {
+		files, err := store.queries.GetFiles(ctx, GetFilesParams{
+			NameMatch:       arg.NameMatch,
+			Name:            arg.Name,
+			Threshold:       float64(arg.Threshold),
+			Extension:       arg.Extension,
+			SizeMin:         arg.SizeMin,
+			SizeMax:         arg.SizeMax,
+			CreatedAfter:    arg.CreatedAfter.UTC(),
+			CreatedBefore:   arg.CreatedBefore.UTC(),
+			ModifiedAfter:   arg.ModifiedAfter.UTC(),
+			ModifiedBefore:  arg.ModifiedBefore.UTC(),
+			AccessedAfter:   arg.AccessedAfter.UTC(),
+			AccessedBefore:  arg.AccessedBefore.UTC(),
+			Metadata:        arg.Metadata,
+			SortBy:          arg.SortBy,
+			SortOrder:       arg.SortOrder,
+			AfterPath:       arg.AfterPath,
+			AfterText:       arg.AfterText,
+			AfterSize:       arg.AfterSize,
+			AfterTime:       arg.AfterTime.UTC(),
+			AfterRank:       float64(arg.AfterRank),
+			AfterSimilarity: float64(arg.AfterSimilarity),
+			PageOffset:      int64(arg.PageOffset),
+			PageLimit:       int64(arg.PageLimit),
+		})
+		if err != nil {
+			return nil, mapError(err)
+		}
+		rows := make([]db.GetFilesRow, len(files))
+		for i, file := range files {
+			rows[i] = db.GetFilesRow{
+				Path:       file.Path,
+				Name:       file.Name,
+				Extension:  file.Extension,
+				Size:       file.Size,
+				CreatedAt:  file.CreatedAt,
+				ModifiedAt: file.ModifiedAt,
+				AccessedAt: file.AccessedAt,
+				Attributes: file.Attributes,
+				Metadata:   json.RawMessage(file.Metadata),
+				Similarity: float32(file.Similarity),
+			}
+		}
+		return rows, nil
+	}
+
+	query, err := db.ParseTextQuery(arg.Content)
+	if err != nil {
+		return nil, err
+	}
+	hits, err := store.queries.SearchFiles(ctx, SearchFilesParams{
+		Query:           query.MatchQuery(),
+		NameMatch:       arg.NameMatch,
+		Name:            arg.Name,
+		Threshold:       float64(arg.Threshold),
+		Extension:       arg.Extension,
+		SizeMin:         arg.SizeMin,
+		SizeMax:         arg.SizeMax,
+		CreatedAfter:    arg.CreatedAfter.UTC(),
+		CreatedBefore:   arg.CreatedBefore.UTC(),
+		ModifiedAfter:   arg.ModifiedAfter.UTC(),
+		ModifiedBefore:  arg.ModifiedBefore.UTC(),
+		AccessedAfter:   arg.AccessedAfter.UTC(),
+		AccessedBefore:  arg.AccessedBefore.UTC(),
+		Metadata:        arg.Metadata,
+		SortBy:          arg.SortBy,
+		SortOrder:       arg.SortOrder,
+		AfterPath:       arg.AfterPath,
+		AfterText:       arg.AfterText,
+		AfterSize:       arg.AfterSize,
+		AfterTime:       arg.AfterTime.UTC(),
+		AfterRank:       float64(arg.AfterRank),
+		AfterSimilarity: float64(arg.AfterSimilarity),
+		PageOffset:      int64(arg.PageOffset),
+		PageLimit:       int64(arg.PageLimit),
+	})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	rows := make([]db.GetFilesRow, len(hits))
+	for i, hit := range hits {
+		rows[i] = db.GetFilesRow{
+			Path:       hit.Path,
+			Name:       hit.Name,
+			Extension:  hit.Extension,
+			Size:       hit.Size,
+			CreatedAt:  hit.CreatedAt,
+			ModifiedAt: hit.ModifiedAt,
+			AccessedAt: hit.AccessedAt,
+			Attributes: hit.Attributes,
+			Metadata:   json.RawMessage(hit.Metadata),
+			Rank:       float32(hit.Relevance),
+			Similarity: float32(hit.Similarity),
+			Snippet:    hit.Snippet,
+		}
+	}
+	return rows, nil
+}
+
+// CountFiles counts the files of GetFiles up to arg.CountLimit
+func (store *Store) CountFiles(ctx context.Context, arg db.CountFilesParams) (int64, error) {
+	match, err := matchQuery(arg.Content)
+	if err != nil {
+		return 0, err
+	}
+	count, err := store.queries.CountFiles(ctx, CountFilesParams{
+		Query:          match,
+		NameMatch:      arg.NameMatch,
+		Name:           arg.Name,
+		Threshold:      float64(arg.Threshold),
+		Extension:      arg.Extension,
+		SizeMin:        arg.SizeMin,
+		SizeMax:        arg.SizeMax,
+		CreatedAfter:   arg.CreatedAfter.UTC(),
+		CreatedBefore:  arg.CreatedBefore.UTC(),
+		ModifiedAfter:  arg.ModifiedAfter.UTC(),
+		ModifiedBefore: arg.ModifiedBefore.UTC(),
+		AccessedAfter:  arg.AccessedAfter.UTC(),
+		AccessedBefore: arg.AccessedBefore.UTC(),
+		Metadata:       arg.Metadata,
+		CountLimit:     arg.CountLimit,
+	})
+	return count, mapError(err)
+}
+
+func (store *Store) InsertFile(ctx context.Context, arg db.InsertFileParams) (db.File, error) {
+	file, err := store.queries.InsertFile(ctx, InsertFileParams(newUpsertFileParams(db.UpsertFileParams(arg))))
+	return newFile(file), mapError(err)
+}
+
+func (store *Store) UpsertFile(ctx context.Context, arg db.UpsertFileParams) (db.File, error) {
+	file, err := store.queries.UpsertFile(ctx, newUpsertFileParams(arg))
+	return newFile(file), mapError(err)
+}
+
+func (store *Store) UpdateFileHashes(ctx context.Context, arg db.UpdateFileHashesParams) (int64, error) {
+	updated, err := store.queries.UpdateFileHashes(ctx, UpdateFileHashesParams(arg))
+	return updated, mapError(err)
+}
+
+func (store *Store) DeleteFileByPath(ctx context.Context, path string) (int64, error) {
+	deleted, err := store.queries.DeleteFileByPath(ctx, path)
+	return deleted, mapError(err)
+}
+
+func (store *Store) ListFilesByPrefix(ctx context.Context, prefix string) ([]db.File, error) {
+	files, err := store.queries.ListFilesByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	result := make([]db.File, len(files))
+	for i, file := range files {
+		result[i] = newFile(file)
+	}
+	return result, nil
+}
+
+func (store *Store) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	user, err := store.queries.CreateUser(ctx, CreateUserParams{
+		Email:        arg.Email,
+		Username:     arg.Username,
+		Password:     arg.Password,
+		PasswordHash: arg.PasswordHash,
+		Phone:        arg.Phone,
+		Fullname:     arg.Fullname,
+		Avatar:       arg.Avatar,
+		State:        arg.State,
+		Role:         arg.Role,
+		CreatedAt:    arg.CreatedAt.UTC(),
+		UpdateAt:     arg.UpdateAt.UTC(),
+	})
+	return newUser(user), mapError(err)
+}
+
+func (store *Store) DeleteUser(ctx context.Context, arg db.DeleteUserParams) (db.User, error) {
+	user, err := store.queries.DeleteUser(ctx, DeleteUserParams{
+		State: arg.State,
+		ID:    int64(arg.ID),
+	})
+	return newUser(user), mapError(err)
+}
+
+func (store *Store) GetUserById(ctx context.Context, id int32) (db.User, error) {
+	user, err := store.queries.GetUserById(ctx, int64(id))
+	return newUser(user), mapError(err)
+}
+
+func (store *Store) GetUserByUsername(ctx context.Context, username string) (db.User, error) {
+	user, err := store.queries.GetUserByUsername(ctx, username)
+	return newUser(user), mapError(err)
+}
+
+func (store *Store) GetUsersAsc(ctx context.Context, arg db.GetUsersAscParams) ([]db.User, error) {
+	users, err := store.queries.GetUsersAsc(ctx, GetUsersAscParams{
+		OrderBy: toString(arg.Column3),
+		Search:  arg.Column1,
+		State:   arg.State,
+		Offset:  int64(arg.Offset),
+		Limit:   int64(arg.Limit),
+	})
+	return newUsers(users), mapError(err)
+}
+
+func (store *Store) GetUsersDesc(ctx context.Context, arg db.GetUsersDescParams) ([]db.User, error) {
+	users, err := store.queries.GetUsersDesc(ctx, GetUsersDescParams{
+		OrderBy: toString(arg.Column3),
+		Search:  arg.Column1,
+		State:   arg.State,
+		Offset:  int64(arg.Offset),
+		Limit:   int64(arg.Limit),
+	})
+	return newUsers(users), mapError(err)
+}
+
+func (store *Store) UpdateUser(ctx context.Context, arg db.UpdateUserParams) (db.User, error) {
+	user, err := store.queries.UpdateUser(ctx, UpdateUserParams{
+		Fullname:     arg.Fullname,
+		Email:        arg.Email,
+		Phone:        arg.Phone,
+		Password:     arg.Password,
+		Avatar:       arg.Avatar,
+		Role:         arg.Role,
+		PasswordHash: arg.PasswordHash,
+		UpdateAt:     time.Now().UTC(),
+		Username:     arg.Username,
+	})
+	return newUser(user), mapError(err)
+}
+
+// mapError wraps db.ErrUniqueViolation around unique constraint errors and
+// db.ErrForeignKeyViolation around foreign key errors
+func mapError(err error) error {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return fmt.Errorf("%w: %v", db.ErrUniqueViolation, err)
+		case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+			return fmt.Errorf("%w: %v", db.ErrForeignKeyViolation, err)
+		}
+	}
+	return err
+}
+
+// QueryFiles runs the filequery query compiled for SQLite
+func (store *Store) QueryFiles(ctx context.Context, arg db.QueryFilesParams) ([]db.GetFilesRow, error) {
+	rows, err := db.QueryFilesOn(ctx, store.db, filequery.SQLite, arg)
+	return rows, mapError(err)
+}
+
+// CountQueryFiles counts the files of QueryFiles up to limit
+func (store *Store) CountQueryFiles(ctx context.Context, query filequery.Node, limit int64) (int64, error) {
+	count, err := db.CountQueryFilesOn(ctx, store.db, filequery.SQLite, query, limit)
+	return count, mapError(err)
+}
+
+// newUpsertFileParams stores times in UTC, SQLite compares them as text
+func newUpsertFileParams(arg db.UpsertFileParams) UpsertFileParams {
+	return UpsertFileParams{
+		Name:        arg.Name,
+		Extension:   arg.Extension,
+		Size:        arg.Size,
+		Path:        arg.Path,
+		Attributes:  arg.Attributes,
+		Content:     arg.Content,
+		CreatedAt:   arg.CreatedAt.UTC(),
+		ModifiedAt:  arg.ModifiedAt.UTC(),
+		AccessedAt:  arg.AccessedAt.UTC(),
+		PartialHash: arg.PartialHash,
+		ContentHash: arg.ContentHash,
+		Metadata:    metadataText(arg.Metadata),
+	}
+}
+
+// metadataText stores files without metadata with an empty object like the
+// column default
+func metadataText(metadata json.RawMessage) string {
+	if len(metadata) == 0 {
+		return "{}"
+	}
+	return string(metadata)
+}
+
+func newFile(file File) db.File {
+	return db.File{
+		ID:          int32(file.ID),
+		Name:        file.Name,
+		Extension:   file.Extension,
+		Size:        file.Size,
+		Path:        file.Path,
+		CreatedAt:   file.CreatedAt,
+		ModifiedAt:  file.ModifiedAt,
+		AccessedAt:  file.AccessedAt,
+		Attributes:  file.Attributes,
+		Content:     file.Content,
+		PartialHash: file.PartialHash,
+		ContentHash: file.ContentHash,
+		Metadata:    json.RawMessage(file.Metadata),
+	}
+}
+
+func newUser(user User) db.User {
+	return db.User{
+		ID:           int32(user.ID),
+		Email:        user.Email,
+		Username:     user.Username,
+		Password:     user.Password,
+		PasswordHash: user.PasswordHash,
+		Phone:        user.Phone,
+		Fullname:     user.Fullname,
+		Avatar:       user.Avatar,
+		State:        user.State,
+		Role:         user.Role,
+		CreatedAt:    user.CreatedAt,
+		UpdateAt:     user.UpdateAt,
+	}
+}
+
+func newUsers(users []User) []db.User {
+	result := make([]db.User, len(users))
+	for i, user := range users {
+		result[i] = newUser(user)
+	}
+	return result
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case sql.NullString:
+		return v.String
+	}
+	return ""
+}
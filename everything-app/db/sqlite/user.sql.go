@@ -0,0 +1,338 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: user.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const createUser = `-- name: CreateUser :one
+INSERT INTO users (
+  email,
+  username,
+  password,
+  password_hash,
+  phone,
+  fullname,
+  avatar,
+  state,
+  role,
+  created_at,
+  update_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+) RETURNING id, email, username, password, password_hash, phone, fullname, avatar, state, role, created_at, update_at
+`
+
+type CreateUserParams struct {
+	Email        string    `json:"email"`
+	Username     string    `json:"username"`
+	Password     string    `json:"password"`
+	PasswordHash string    `json:"password_hash"`
+	Phone        string    `json:"phone"`
+	Fullname     string    `json:"fullname"`
+	Avatar       string    `json:"avatar"`
+	State        int64     `json:"state"`
+	Role         string    `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdateAt     time.Time `json:"update_at"`
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, createUser,
+		arg.Email,
+		arg.Username,
+		arg.Password,
+		arg.PasswordHash,
+		arg.Phone,
+		arg.Fullname,
+		arg.Avatar,
+		arg.State,
+		arg.Role,
+		arg.CreatedAt,
+		arg.UpdateAt,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.Password,
+		&i.PasswordHash,
+		&i.Phone,
+		&i.Fullname,
+		&i.Avatar,
+		&i.State,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdateAt,
+	)
+	return i, err
+}
+
+const deleteUser = `-- name: DeleteUser :one
+UPDATE users
+SET state = ?
+WHERE id = ?
+RETURNING id, email, username, password, password_hash, phone, fullname, avatar, state, role, created_at, update_at
+`
+
+type DeleteUserParams struct {
+	State int64 `json:"state"`
+	ID    int64 `json:"id"`
+}
+
+func (q *Queries) DeleteUser(ctx context.Context, arg DeleteUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, deleteUser, arg.State, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.Password,
+		&i.PasswordHash,
+		&i.Phone,
+		&i.Fullname,
+		&i.Avatar,
+		&i.State,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdateAt,
+	)
+	return i, err
+}
+
+const getUserById = `-- name: GetUserById :one
+SELECT id, email, username, password, password_hash, phone, fullname, avatar, state, role, created_at, update_at FROM users
+WHERE id = ?
+`
+
+func (q *Queries) GetUserById(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserById, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.Password,
+		&i.PasswordHash,
+		&i.Phone,
+		&i.Fullname,
+		&i.Avatar,
+		&i.State,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdateAt,
+	)
+	return i, err
+}
+
+const getUserByUsername = `-- name: GetUserByUsername :one
+SELECT id, email, username, password, password_hash, phone, fullname, avatar, state, role, created_at, update_at FROM users
+WHERE username = ?
+`
+
+func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByUsername, username)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.Password,
+		&i.PasswordHash,
+		&i.Phone,
+		&i.Fullname,
+		&i.Avatar,
+		&i.State,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdateAt,
+	)
+	return i, err
+}
+
+const getUsersAsc = `-- name: GetUsersAsc :many
+SELECT users.id, users.email, users.username, users.password, users.password_hash, users.phone, users.fullname, users.avatar, users.state, users.role, users.created_at, users.update_at FROM users, (SELECT CAST(?1 AS TEXT) AS order_by) AS params
+WHERE (username LIKE '%' || CAST(?2 AS TEXT) || '%' OR fullname LIKE '%' || CAST(?2 AS TEXT) || '%' OR CAST(?2 AS TEXT) IS NULL)
+  AND state = ?3
+ORDER BY
+  CASE WHEN params.order_by = 'id' THEN id END ASC,
+  CASE WHEN params.order_by = 'username' THEN username END ASC,
+  CASE WHEN params.order_by = 'fullname' THEN fullname END ASC
+LIMIT ?5 OFFSET ?4
+`
+
+type GetUsersAscParams struct {
+	OrderBy string         `json:"order_by"`
+	Search  sql.NullString `json:"search"`
+	State   int64          `json:"state"`
+	Offset  int64          `json:"offset"`
+	Limit   int64          `json:"limit"`
+}
+
+// The order column is selected in params, sqlc does not type parameters in ORDER BY
+func (q *Queries) GetUsersAsc(ctx context.Context, arg GetUsersAscParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersAsc,
+		arg.OrderBy,
+		arg.Search,
+		arg.State,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Username,
+			&i.Password,
+			&i.PasswordHash,
+			&i.Phone,
+			&i.Fullname,
+			&i.Avatar,
+			&i.State,
+			&i.Role,
+			&i.CreatedAt,
+			&i.UpdateAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUsersDesc = `-- name: GetUsersDesc :many
+SELECT users.id, users.email, users.username, users.password, users.password_hash, users.phone, users.fullname, users.avatar, users.state, users.role, users.created_at, users.update_at FROM users, (SELECT CAST(?1 AS TEXT) AS order_by) AS params
+WHERE (username LIKE '%' || CAST(?2 AS TEXT) || '%' OR fullname LIKE '%' || CAST(?2 AS TEXT) || '%' OR CAST(?2 AS TEXT) IS NULL)
+  AND state = ?3
+ORDER BY
+  CASE WHEN params.order_by = 'id' THEN id END DESC,
+  CASE WHEN params.order_by = 'username' THEN username END DESC,
+  CASE WHEN params.order_by = 'fullname' THEN fullname END DESC
+LIMIT ?5 OFFSET ?4
+`
+
+type GetUsersDescParams struct {
+	OrderBy string         `json:"order_by"`
+	Search  sql.NullString `json:"search"`
+	State   int64          `json:"state"`
+	Offset  int64          `json:"offset"`
+	Limit   int64          `json:"limit"`
+}
+
+func (q *Queries) GetUsersDesc(ctx context.Context, arg GetUsersDescParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersDesc,
+		arg.OrderBy,
+		arg.Search,
+		arg.State,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Username,
+			&i.Password,
+			&i.PasswordHash,
+			&i.Phone,
+			&i.Fullname,
+			&i.Avatar,
+			&i.State,
+			&i.Role,
+			&i.CreatedAt,
+			&i.UpdateAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateUser = `-- name: UpdateUser :one
+UPDATE users
+SET
+  fullname = COALESCE(?1, fullname),
+  email = COALESCE(?2, email),
+  phone = COALESCE(?3, phone),
+  password = COALESCE(?4, password),
+  avatar = COALESCE(?5, avatar),
+  role = COALESCE(?6, role),
+  password_hash = COALESCE(?7, password_hash),
+  update_at = ?8
+WHERE username = ?9
+RETURNING id, email, username, password, password_hash, phone, fullname, avatar, state, role, created_at, update_at
+`
+
+type UpdateUserParams struct {
+	Fullname     string    `json:"fullname"`
+	Email        string    `json:"email"`
+	Phone        string    `json:"phone"`
+	Password     string    `json:"password"`
+	Avatar       string    `json:"avatar"`
+	Role         string    `json:"role"`
+	PasswordHash string    `json:"password_hash"`
+	UpdateAt     time.Time `json:"update_at"`
+	Username     string    `json:"username"`
+}
+
+func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUser,
+		arg.Fullname,
+		arg.Email,
+		arg.Phone,
+		arg.Password,
+		arg.Avatar,
+		arg.Role,
+		arg.PasswordHash,
+		arg.UpdateAt,
+		arg.Username,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.Password,
+		&i.PasswordHash,
+		&i.Phone,
+		&i.Fullname,
+		&i.Avatar,
+		&i.State,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdateAt,
+	)
+	return i, err
+}
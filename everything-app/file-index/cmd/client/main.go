@@ -44,6 +44,7 @@ func loadTLSCredentials() (credentials.TransportCredentials, error) {
 
 func main() {
 	serverAddress := flag.String("address", "", "the server address")
+	watch := flag.Bool("watch", false, "keep following file changes after listing the files")
 	flag.Parse()
 	log.Printf("Dial server %s", *serverAddress)
 	// create a tls credential for client
@@ -58,10 +59,14 @@ func main() {
 	}
 
 	fileSearcherClient := pb.NewFileIndexClient(conn)
-	searchFile(fileSearcherClient)
+	resumeToken := searchFile(fileSearcherClient)
+	if *watch {
+		watchChanges(fileSearcherClient, resumeToken)
+	}
 }
 
-func searchFile(fileClient pb.FileIndexClient) {
+// searchFile lists the indexed files and returns the token to follow the changes made after the listing
+func searchFile(fileClient pb.FileIndexClient) string {
 	ctx := context.Background()
 
 	req := &pb.CreateFileDiscoverRequest{
@@ -72,17 +77,52 @@ func searchFile(fileClient pb.FileIndexClient) {
 		log.Fatal("cannot search file: ", err)
 	}
 
+	var resumeToken string
 	for {
 		res, err := stream.Recv()
 		if err == io.EOF {
-			return
+			return resumeToken
 		}
 
 		if err != nil {
 			log.Fatal("cannot receive response: ", err)
 		}
 
-		file := res.GetFiles()
-		log.Printf("- found: %s", file)
+		if token := res.GetResumeToken(); token != "" {
+			resumeToken = token
+		}
+		for _, skipped := range res.GetSkippedRoots() {
+			log.Printf("- skipped root %s: %s", skipped.GetPath(), skipped.GetReason())
+		}
+		if file := res.GetFiles(); file != nil {
+			log.Printf("- found: %s", file)
+		}
+	}
+}
+
+func watchChanges(fileClient pb.FileIndexClient, resumeToken string) {
+	req := &pb.WatchChangesRequest{
+		ResumeToken: resumeToken,
+	}
+	stream, err := fileClient.WatchChanges(context.Background(), req)
+	if err != nil {
+		log.Fatal("cannot watch changes: ", err)
+	}
+
+	for {
+		change, err := stream.Recv()
+		if err == io.EOF {
+			return
+		}
+
+		if err != nil {
+			log.Fatal("cannot receive change: ", err)
+		}
+
+		if change.GetType() == pb.ChangeType_RENAMED {
+			log.Printf("- renamed: %s -> %s", change.GetOldPath(), change.GetFile().GetPath())
+			continue
+		}
+		log.Printf("- %s: %s", change.GetType(), change.GetFile().GetPath())
 	}
 }
@@ -0,0 +1,513 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.35.1
+// 	protoc        v5.28.3
+// source: crawl_state.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// CrawlCheckpoint is the last path, in walk order, of a root up to which
+// every file has been stored
+type CrawlCheckpoint struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Root     string `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	LastPath string `protobuf:"bytes,2,opt,name=last_path,json=lastPath,proto3" json:"last_path,omitempty"`
+}
+
+func (x *CrawlCheckpoint) Reset() {
+	*x = CrawlCheckpoint{}
+	mi := &file_crawl_state_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CrawlCheckpoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CrawlCheckpoint) ProtoMessage() {}
+
+func (x *CrawlCheckpoint) ProtoReflect() protoreflect.Message {
+	mi := &file_crawl_state_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CrawlCheckpoint.ProtoReflect.Descriptor instead.
+func (*CrawlCheckpoint) Descriptor() ([]byte, []int) {
+	return file_crawl_state_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CrawlCheckpoint) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *CrawlCheckpoint) GetLastPath() string {
+	if x != nil {
+		return x.LastPath
+	}
+	return ""
+}
+
+// CrawlSnapshot is the state of the indexer saved to disk, files are saved
+// without their content
+type CrawlSnapshot struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Files       []*FileAttr            `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	Checkpoints []*CrawlCheckpoint     `protobuf:"bytes,2,rep,name=checkpoints,proto3" json:"checkpoints,omitempty"`
+	SavedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=saved_at,json=savedAt,proto3" json:"saved_at,omitempty"`
+	// Sequence number of the last published change, so resume tokens stay
+	// valid across restarts
+	LastSequence uint64            `protobuf:"varint,4,opt,name=last_sequence,json=lastSequence,proto3" json:"last_sequence,omitempty"`
+	Checksums    []*CachedChecksum `protobuf:"bytes,5,rep,name=checksums,proto3" json:"checksums,omitempty"`
+	MerkleNodes  []*MerkleNode     `protobuf:"bytes,6,rep,name=merkle_nodes,json=merkleNodes,proto3" json:"merkle_nodes,omitempty"`
+}
+
+func (x *CrawlSnapshot) Reset() {
+	*x = CrawlSnapshot{}
+	mi := &file_crawl_state_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CrawlSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CrawlSnapshot) ProtoMessage() {}
+
+func (x *CrawlSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_crawl_state_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CrawlSnapshot.ProtoReflect.Descriptor instead.
+func (*CrawlSnapshot) Descriptor() ([]byte, []int) {
+	return file_crawl_state_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CrawlSnapshot) GetFiles() []*FileAttr {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *CrawlSnapshot) GetCheckpoints() []*CrawlCheckpoint {
+	if x != nil {
+		return x.Checkpoints
+	}
+	return nil
+}
+
+func (x *CrawlSnapshot) GetSavedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SavedAt
+	}
+	return nil
+}
+
+func (x *CrawlSnapshot) GetLastSequence() uint64 {
+	if x != nil {
+		return x.LastSequence
+	}
+	return 0
+}
+
+func (x *CrawlSnapshot) GetChecksums() []*CachedChecksum {
+	if x != nil {
+		return x.Checksums
+	}
+	return nil
+}
+
+func (x *CrawlSnapshot) GetMerkleNodes() []*MerkleNode {
+	if x != nil {
+		return x.MerkleNodes
+	}
+	return nil
+}
+
+// CachedChecksum holds the checksums computed for a file, they are valid as
+// long as the size, modification time and inode of the file are unchanged
+type CachedChecksum struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path       string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Size       int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	ModifiedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=modified_at,json=modifiedAt,proto3" json:"modified_at,omitempty"`
+	Inode      uint64                 `protobuf:"varint,4,opt,name=inode,proto3" json:"inode,omitempty"`
+	// Lowercase hex checksums by ChecksumAlgorithm number
+	Checksums map[int32]string `protobuf:"bytes,5,rep,name=checksums,proto3" json:"checksums,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *CachedChecksum) Reset() {
+	*x = CachedChecksum{}
+	mi := &file_crawl_state_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CachedChecksum) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CachedChecksum) ProtoMessage() {}
+
+func (x *CachedChecksum) ProtoReflect() protoreflect.Message {
+	mi := &file_crawl_state_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CachedChecksum.ProtoReflect.Descriptor instead.
+func (*CachedChecksum) Descriptor() ([]byte, []int) {
+	return file_crawl_state_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CachedChecksum) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *CachedChecksum) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *CachedChecksum) GetModifiedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ModifiedAt
+	}
+	return nil
+}
+
+func (x *CachedChecksum) GetInode() uint64 {
+	if x != nil {
+		return x.Inode
+	}
+	return 0
+}
+
+func (x *CachedChecksum) GetChecksums() map[int32]string {
+	if x != nil {
+		return x.Checksums
+	}
+	return nil
+}
+
+// MerkleEntry is a file or a subdirectory of a MerkleNode
+type MerkleEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name      string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Directory bool   `protobuf:"varint,2,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Content hash of a file, digest of a directory
+	Digest string `protobuf:"bytes,3,opt,name=digest,proto3" json:"digest,omitempty"`
+}
+
+func (x *MerkleEntry) Reset() {
+	*x = MerkleEntry{}
+	mi := &file_crawl_state_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MerkleEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MerkleEntry) ProtoMessage() {}
+
+func (x *MerkleEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_crawl_state_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MerkleEntry.ProtoReflect.Descriptor instead.
+func (*MerkleEntry) Descriptor() ([]byte, []int) {
+	return file_crawl_state_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MerkleEntry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MerkleEntry) GetDirectory() bool {
+	if x != nil {
+		return x.Directory
+	}
+	return false
+}
+
+func (x *MerkleEntry) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+// MerkleNode is a directory in the Merkle tree of the indexed files. Its
+// digest is the SHA-256 of its entries sorted by name, so a directory has
+// the same digest as long as no file below it changed.
+type MerkleNode struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Digest  string         `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	Entries []*MerkleEntry `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"`
+	// Last time the node was part of the tree, old nodes are pruned
+	LastSeen *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+}
+
+func (x *MerkleNode) Reset() {
+	*x = MerkleNode{}
+	mi := &file_crawl_state_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MerkleNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MerkleNode) ProtoMessage() {}
+
+func (x *MerkleNode) ProtoReflect() protoreflect.Message {
+	mi := &file_crawl_state_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MerkleNode.ProtoReflect.Descriptor instead.
+func (*MerkleNode) Descriptor() ([]byte, []int) {
+	return file_crawl_state_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *MerkleNode) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *MerkleNode) GetEntries() []*MerkleEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *MerkleNode) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
+var File_crawl_state_proto protoreflect.FileDescriptor
+
+var file_crawl_state_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x63, 0x72, 0x61, 0x77, 0x6c, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x14, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x5f,
+	0x64, 0x69, 0x73, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x79, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x12, 0x6d, 0x6f, 0x6e, 0x69,
+	0x74, 0x6f, 0x72, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x42,
+	0x0a, 0x0f, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x72, 0x6f, 0x6f, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x70, 0x61,
+	0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61, 0x73, 0x74, 0x50, 0x61,
+	0x74, 0x68, 0x22, 0xf3, 0x02, 0x0a, 0x0d, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x53, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x12, 0x34, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d,
+	0x5f, 0x64, 0x69, 0x73, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x79, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x41,
+	0x74, 0x74, 0x72, 0x52, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x47, 0x0a, 0x0b, 0x63, 0x68,
+	0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x25, 0x2e, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x5f, 0x64, 0x69, 0x73,
+	0x63, 0x6f, 0x76, 0x65, 0x72, 0x79, 0x2e, 0x43, 0x72, 0x61, 0x77, 0x6c, 0x43, 0x68, 0x65, 0x63,
+	0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x0b, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69,
+	0x6e, 0x74, 0x73, 0x12, 0x35, 0x0a, 0x08, 0x73, 0x61, 0x76, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x07, 0x73, 0x61, 0x76, 0x65, 0x64, 0x41, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x6c, 0x61,
+	0x73, 0x74, 0x5f, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x53, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x12,
+	0x42, 0x0a, 0x09, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x73, 0x18, 0x05, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x24, 0x2e, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x5f,
+	0x64, 0x69, 0x73, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x79, 0x2e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x64,
+	0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x52, 0x09, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73,
+	0x75, 0x6d, 0x73, 0x12, 0x43, 0x0a, 0x0c, 0x6d, 0x65, 0x72, 0x6b, 0x6c, 0x65, 0x5f, 0x6e, 0x6f,
+	0x64, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x66, 0x69, 0x6c, 0x65,
+	0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x5f, 0x64, 0x69, 0x73, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x79,
+	0x2e, 0x4d, 0x65, 0x72, 0x6b, 0x6c, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x0b, 0x6d, 0x65, 0x72,
+	0x6b, 0x6c, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x9c, 0x02, 0x0a, 0x0e, 0x43, 0x61, 0x63,
+	0x68, 0x65, 0x64, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x70,
+	0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12,
+	0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73,
+	0x69, 0x7a, 0x65, 0x12, 0x3b, 0x0a, 0x0b, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x5f,
+	0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x41, 0x74,
+	0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x05, 0x69, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x51, 0x0a, 0x09, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73,
+	0x75, 0x6d, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x33, 0x2e, 0x66, 0x69, 0x6c, 0x65,
+	0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x5f, 0x64, 0x69, 0x73, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x79,
+	0x2e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x64, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x2e,
+	0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x09,
+	0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x73, 0x1a, 0x3c, 0x0a, 0x0e, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x73, 0x75, 0x6d, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x57, 0x0a, 0x0b, 0x4d, 0x65, 0x72, 0x6b, 0x6c,
+	0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x69,
+	0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x64,
+	0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65,
+	0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74,
+	0x22, 0x9a, 0x01, 0x0a, 0x0a, 0x4d, 0x65, 0x72, 0x6b, 0x6c, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x3b, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69,
+	0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x66, 0x69, 0x6c, 0x65, 0x73,
+	0x79, 0x73, 0x74, 0x65, 0x6d, 0x5f, 0x64, 0x69, 0x73, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x79, 0x2e,
+	0x4d, 0x65, 0x72, 0x6b, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x74,
+	0x72, 0x69, 0x65, 0x73, 0x12, 0x37, 0x0a, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x73, 0x65, 0x65,
+	0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x08, 0x6c, 0x61, 0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x42, 0x06, 0x5a,
+	0x04, 0x2e, 0x3b, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_crawl_state_proto_rawDescOnce sync.Once
+	file_crawl_state_proto_rawDescData = file_crawl_state_proto_rawDesc
+)
+
+func file_crawl_state_proto_rawDescGZIP() []byte {
+	file_crawl_state_proto_rawDescOnce.Do(func() {
+		file_crawl_state_proto_rawDescData = protoimpl.X.CompressGZIP(file_crawl_state_proto_rawDescData)
+	})
+	return file_crawl_state_proto_rawDescData
+}
+
+var file_crawl_state_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_crawl_state_proto_goTypes = []any{
+	(*CrawlCheckpoint)(nil),       // 0: filesystem_discovery.CrawlCheckpoint
+	(*CrawlSnapshot)(nil),         // 1: filesystem_discovery.CrawlSnapshot
+	(*CachedChecksum)(nil),        // 2: filesystem_discovery.CachedChecksum
+	(*MerkleEntry)(nil),           // 3: filesystem_discovery.MerkleEntry
+	(*MerkleNode)(nil),            // 4: filesystem_discovery.MerkleNode
+	nil,                           // 5: filesystem_discovery.CachedChecksum.ChecksumsEntry
+	(*FileAttr)(nil),              // 6: filesystem_discovery.FileAttr
+	(*timestamppb.Timestamp)(nil), // 7: google.protobuf.Timestamp
+}
+var file_crawl_state_proto_depIdxs = []int32{
+	6, // 0: filesystem_discovery.CrawlSnapshot.files:type_name -> filesystem_discovery.FileAttr
+	0, // 1: filesystem_discovery.CrawlSnapshot.checkpoints:type_name -> filesystem_discovery.CrawlCheckpoint
+	7, // 2: filesystem_discovery.CrawlSnapshot.saved_at:type_name -> google.protobuf.Timestamp
+	2, // 3: filesystem_discovery.CrawlSnapshot.checksums:type_name -> filesystem_discovery.CachedChecksum
+	4, // 4: filesystem_discovery.CrawlSnapshot.merkle_nodes:type_name -> filesystem_discovery.MerkleNode
+	7, // 5: filesystem_discovery.CachedChecksum.modified_at:type_name -> google.protobuf.Timestamp
+	5, // 6: filesystem_discovery.CachedChecksum.checksums:type_name -> filesystem_discovery.CachedChecksum.ChecksumsEntry
+	3, // 7: filesystem_discovery.MerkleNode.entries:type_name -> filesystem_discovery.MerkleEntry
+	7, // 8: filesystem_discovery.MerkleNode.last_seen:type_name -> google.protobuf.Timestamp
+	9, // [9:9] is the sub-list for method output_type
+	9, // [9:9] is the sub-list for method input_type
+	9, // [9:9] is the sub-list for extension type_name
+	9, // [9:9] is the sub-list for extension extendee
+	0, // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_crawl_state_proto_init() }
+func file_crawl_state_proto_init() {
+	if File_crawl_state_proto != nil {
+		return
+	}
+	file_monitor_file_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_crawl_state_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_crawl_state_proto_goTypes,
+		DependencyIndexes: file_crawl_state_proto_depIdxs,
+		MessageInfos:      file_crawl_state_proto_msgTypes,
+	}.Build()
+	File_crawl_state_proto = out.File
+	file_crawl_state_proto_rawDesc = nil
+	file_crawl_state_proto_goTypes = nil
+	file_crawl_state_proto_depIdxs = nil
+}
@@ -19,8 +19,14 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	FileIndex_ListFiles_FullMethodName        = "/filesystem_discovery.FileIndex/ListFiles"
-	FileIndex_GetCheckSumFiles_FullMethodName = "/filesystem_discovery.FileIndex/GetCheckSumFiles"
+	FileIndex_ListFiles_FullMethodName            = "/filesystem_discovery.FileIndex/ListFiles"
+	FileIndex_GetCheckSumFiles_FullMethodName     = "/filesystem_discovery.FileIndex/GetCheckSumFiles"
+	FileIndex_StreamChecksums_FullMethodName      = "/filesystem_discovery.FileIndex/StreamChecksums"
+	FileIndex_GetCrawlStats_FullMethodName        = "/filesystem_discovery.FileIndex/GetCrawlStats"
+	FileIndex_WatchChanges_FullMethodName         = "/filesystem_discovery.FileIndex/WatchChanges"
+	FileIndex_FindDuplicates_FullMethodName       = "/filesystem_discovery.FileIndex/FindDuplicates"
+	FileIndex_GetDirectoryDigest_FullMethodName   = "/filesystem_discovery.FileIndex/GetDirectoryDigest"
+	FileIndex_DiffDirectoryDigests_FullMethodName = "/filesystem_discovery.FileIndex/DiffDirectoryDigests"
 )
 
 // FileIndexClient is the client API for FileIndex service.
@@ -28,7 +34,14 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type FileIndexClient interface {
 	ListFiles(ctx context.Context, in *CreateFileDiscoverRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CreateFileDiscoverResponse], error)
+	// Deprecated: use StreamChecksums
 	GetCheckSumFiles(ctx context.Context, in *CreateFileChecksumRequest, opts ...grpc.CallOption) (*CreateFileChecksumResponse, error)
+	StreamChecksums(ctx context.Context, in *StreamChecksumsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileChecksum], error)
+	GetCrawlStats(ctx context.Context, in *GetCrawlStatsRequest, opts ...grpc.CallOption) (*GetCrawlStatsResponse, error)
+	WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileChange], error)
+	FindDuplicates(ctx context.Context, in *FindDuplicatesRequest, opts ...grpc.CallOption) (*FindDuplicatesResponse, error)
+	GetDirectoryDigest(ctx context.Context, in *GetDirectoryDigestRequest, opts ...grpc.CallOption) (*DirectoryDigest, error)
+	DiffDirectoryDigests(ctx context.Context, in *DiffDirectoryDigestsRequest, opts ...grpc.CallOption) (*DiffDirectoryDigestsResponse, error)
 }
 
 type fileIndexClient struct {
@@ -68,12 +81,97 @@ func (c *fileIndexClient) GetCheckSumFiles(ctx context.Context, in *CreateFileCh
 	return out, nil
 }
 
+func (c *fileIndexClient) StreamChecksums(ctx context.Context, in *StreamChecksumsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileChecksum], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FileIndex_ServiceDesc.Streams[1], FileIndex_StreamChecksums_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamChecksumsRequest, FileChecksum]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FileIndex_StreamChecksumsClient = grpc.ServerStreamingClient[FileChecksum]
+
+func (c *fileIndexClient) GetCrawlStats(ctx context.Context, in *GetCrawlStatsRequest, opts ...grpc.CallOption) (*GetCrawlStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCrawlStatsResponse)
+	err := c.cc.Invoke(ctx, FileIndex_GetCrawlStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileIndexClient) WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileChange], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FileIndex_ServiceDesc.Streams[2], FileIndex_WatchChanges_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchChangesRequest, FileChange]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FileIndex_WatchChangesClient = grpc.ServerStreamingClient[FileChange]
+
+func (c *fileIndexClient) FindDuplicates(ctx context.Context, in *FindDuplicatesRequest, opts ...grpc.CallOption) (*FindDuplicatesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindDuplicatesResponse)
+	err := c.cc.Invoke(ctx, FileIndex_FindDuplicates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileIndexClient) GetDirectoryDigest(ctx context.Context, in *GetDirectoryDigestRequest, opts ...grpc.CallOption) (*DirectoryDigest, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DirectoryDigest)
+	err := c.cc.Invoke(ctx, FileIndex_GetDirectoryDigest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileIndexClient) DiffDirectoryDigests(ctx context.Context, in *DiffDirectoryDigestsRequest, opts ...grpc.CallOption) (*DiffDirectoryDigestsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiffDirectoryDigestsResponse)
+	err := c.cc.Invoke(ctx, FileIndex_DiffDirectoryDigests_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // FileIndexServer is the server API for FileIndex service.
 // All implementations must embed UnimplementedFileIndexServer
 // for forward compatibility.
 type FileIndexServer interface {
 	ListFiles(*CreateFileDiscoverRequest, grpc.ServerStreamingServer[CreateFileDiscoverResponse]) error
+	// Deprecated: use StreamChecksums
 	GetCheckSumFiles(context.Context, *CreateFileChecksumRequest) (*CreateFileChecksumResponse, error)
+	StreamChecksums(*StreamChecksumsRequest, grpc.ServerStreamingServer[FileChecksum]) error
+	GetCrawlStats(context.Context, *GetCrawlStatsRequest) (*GetCrawlStatsResponse, error)
+	WatchChanges(*WatchChangesRequest, grpc.ServerStreamingServer[FileChange]) error
+	FindDuplicates(context.Context, *FindDuplicatesRequest) (*FindDuplicatesResponse, error)
+	GetDirectoryDigest(context.Context, *GetDirectoryDigestRequest) (*DirectoryDigest, error)
+	DiffDirectoryDigests(context.Context, *DiffDirectoryDigestsRequest) (*DiffDirectoryDigestsResponse, error)
 	mustEmbedUnimplementedFileIndexServer()
 }
 
@@ -90,6 +188,24 @@ func (UnimplementedFileIndexServer) ListFiles(*CreateFileDiscoverRequest, grpc.S
 func (UnimplementedFileIndexServer) GetCheckSumFiles(context.Context, *CreateFileChecksumRequest) (*CreateFileChecksumResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetCheckSumFiles not implemented")
 }
+func (UnimplementedFileIndexServer) StreamChecksums(*StreamChecksumsRequest, grpc.ServerStreamingServer[FileChecksum]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamChecksums not implemented")
+}
+func (UnimplementedFileIndexServer) GetCrawlStats(context.Context, *GetCrawlStatsRequest) (*GetCrawlStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCrawlStats not implemented")
+}
+func (UnimplementedFileIndexServer) WatchChanges(*WatchChangesRequest, grpc.ServerStreamingServer[FileChange]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchChanges not implemented")
+}
+func (UnimplementedFileIndexServer) FindDuplicates(context.Context, *FindDuplicatesRequest) (*FindDuplicatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindDuplicates not implemented")
+}
+func (UnimplementedFileIndexServer) GetDirectoryDigest(context.Context, *GetDirectoryDigestRequest) (*DirectoryDigest, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDirectoryDigest not implemented")
+}
+func (UnimplementedFileIndexServer) DiffDirectoryDigests(context.Context, *DiffDirectoryDigestsRequest) (*DiffDirectoryDigestsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiffDirectoryDigests not implemented")
+}
 func (UnimplementedFileIndexServer) mustEmbedUnimplementedFileIndexServer() {}
 func (UnimplementedFileIndexServer) testEmbeddedByValue()                   {}
 
@@ -140,6 +256,100 @@ func _FileIndex_GetCheckSumFiles_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _FileIndex_StreamChecksums_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamChecksumsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FileIndexServer).StreamChecksums(m, &grpc.GenericServerStream[StreamChecksumsRequest, FileChecksum]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FileIndex_StreamChecksumsServer = grpc.ServerStreamingServer[FileChecksum]
+
+func _FileIndex_GetCrawlStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCrawlStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileIndexServer).GetCrawlStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileIndex_GetCrawlStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileIndexServer).GetCrawlStats(ctx, req.(*GetCrawlStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileIndex_WatchChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FileIndexServer).WatchChanges(m, &grpc.GenericServerStream[WatchChangesRequest, FileChange]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FileIndex_WatchChangesServer = grpc.ServerStreamingServer[FileChange]
+
+func _FileIndex_FindDuplicates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindDuplicatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileIndexServer).FindDuplicates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileIndex_FindDuplicates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileIndexServer).FindDuplicates(ctx, req.(*FindDuplicatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileIndex_GetDirectoryDigest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDirectoryDigestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileIndexServer).GetDirectoryDigest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileIndex_GetDirectoryDigest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileIndexServer).GetDirectoryDigest(ctx, req.(*GetDirectoryDigestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileIndex_DiffDirectoryDigests_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffDirectoryDigestsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileIndexServer).DiffDirectoryDigests(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileIndex_DiffDirectoryDigests_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileIndexServer).DiffDirectoryDigests(ctx, req.(*DiffDirectoryDigestsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // FileIndex_ServiceDesc is the grpc.ServiceDesc for FileIndex service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -151,6 +361,22 @@ var FileIndex_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetCheckSumFiles",
 			Handler:    _FileIndex_GetCheckSumFiles_Handler,
 		},
+		{
+			MethodName: "GetCrawlStats",
+			Handler:    _FileIndex_GetCrawlStats_Handler,
+		},
+		{
+			MethodName: "FindDuplicates",
+			Handler:    _FileIndex_FindDuplicates_Handler,
+		},
+		{
+			MethodName: "GetDirectoryDigest",
+			Handler:    _FileIndex_GetDirectoryDigest_Handler,
+		},
+		{
+			MethodName: "DiffDirectoryDigests",
+			Handler:    _FileIndex_DiffDirectoryDigests_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -158,6 +384,16 @@ var FileIndex_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _FileIndex_ListFiles_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "StreamChecksums",
+			Handler:       _FileIndex_StreamChecksums_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchChanges",
+			Handler:       _FileIndex_WatchChanges_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "supervisor_service.proto",
 }
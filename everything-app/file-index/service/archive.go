@@ -0,0 +1,430 @@
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"training/file-index/pb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ArchiveSeparator separates the path of an archive from the path of a
+// member inside it, as in /srv/x.zip!/docs/a.docx
+const ArchiveSeparator = "!/"
+
+var errArchiveLimit = errors.New("archive limit exceeded")
+
+// ArchiveConfig controls the indexing of the members of archives. Zero
+// limits are replaced by defaults.
+type ArchiveConfig struct {
+	// Enabled indexes the members of .zip, .jar, .tar, .tar.gz/.tgz and
+	// .tar.bz2 archives as virtual files. 7z and rar archives need decoders
+	// outside the standard library and are indexed as plain files.
+	Enabled bool `mapstructure:"enabled"`
+	// MaxDepth is the number of archive levels indexed, 1 only indexes the
+	// members of the archives on disk
+	MaxDepth int `mapstructure:"max_depth"`
+	// MaxRatio bounds the expansion of a zip member over its compressed
+	// size, and of all members over the size of the archive on disk
+	MaxRatio float64 `mapstructure:"max_ratio"`
+	// MaxMembers is the number of members indexed per archive on disk,
+	// including the members of nested archives
+	MaxMembers int `mapstructure:"max_members"`
+	// MaxMemberSize is the size of the largest member read, larger members
+	// are indexed without content
+	MaxMemberSize int64 `mapstructure:"max_member_size"`
+	// MaxTotalSize is the number of bytes decompressed per archive on disk
+	MaxTotalSize int64 `mapstructure:"max_total_size"`
+}
+
+func (config ArchiveConfig) withDefaults() ArchiveConfig {
+	if config.MaxDepth <= 0 {
+		config.MaxDepth = 2
+	}
+	if config.MaxRatio <= 0 {
+		config.MaxRatio = 100
+	}
+	if config.MaxMembers <= 0 {
+		config.MaxMembers = 10000
+	}
+	if config.MaxMemberSize <= 0 {
+		config.MaxMemberSize = 64 << 20
+	}
+	if config.MaxTotalSize <= 0 {
+		config.MaxTotalSize = 1 << 30
+	}
+	return config
+}
+
+type archiveFormat int
+
+const (
+	notArchive archiveFormat = iota
+	zipArchive
+	tarArchive
+	gzipTarArchive
+	bzip2TarArchive
+)
+
+// archiveFormatOf returns the format of the archive named name from its
+// extension
+func archiveFormatOf(name string) archiveFormat {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".zip"), strings.HasSuffix(name, ".jar"):
+		return zipArchive
+	case strings.HasSuffix(name, ".tar"):
+		return tarArchive
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return gzipTarArchive
+	case strings.HasSuffix(name, ".tar.bz2"), strings.HasSuffix(name, ".tbz2"):
+		return bzip2TarArchive
+	}
+	return notArchive
+}
+
+// isArchiveMember reports whether path is the virtual path of a member
+func isArchiveMember(path string) bool {
+	return strings.Contains(path, ArchiveSeparator)
+}
+
+// archiveOf returns the archive on disk that holds the member at path, or
+// path itself if it is not a member
+func archiveOf(path string) string {
+	if i := strings.Index(path, ArchiveSeparator); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
+
+// newMemberAttr returns the attributes of the member name of archive, or
+// nil if the name is empty
+func newMemberAttr(archive, name string, size int64, modifiedAt, accessedAt time.Time) *pb.FileAttr {
+	name = strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(name, "\\", "/")), "/")
+	if name == "" {
+		return nil
+	}
+	if accessedAt.IsZero() {
+		accessedAt = modifiedAt
+	}
+	base := path.Base(name)
+	attribute := "Read Only"
+	if strings.HasPrefix(base, ".") {
+		attribute = "Hidden"
+	}
+	return &pb.FileAttr{
+		Path:       archive + ArchiveSeparator + name,
+		Name:       base,
+		Type:       path.Ext(base),
+		Size:       size,
+		CreatedAt:  timestamppb.New(modifiedAt),
+		ModifiedAt: timestamppb.New(modifiedAt),
+		AccessedAt: timestamppb.New(accessedAt),
+		Attributes: attribute,
+	}
+}
+
+// archiveReader lists the members of an archive on disk and of the archives
+// nested in it. They share one budget of decompressed bytes, so a zip bomb
+// is cut off whatever the nesting.
+type archiveReader struct {
+	config     ArchiveConfig
+	extractors *ExtractorRegistry
+	// cached holds the members indexed before, unchanged members are reused
+	// without reading them
+	cached  map[string]*pb.FileAttr
+	members []*pb.FileAttr
+	listed  map[string]bool
+	// budget is the number of bytes that may still be decompressed out of
+	// maxExpanded
+	budget      int64
+	maxExpanded int64
+}
+
+// readArchive lists the members of the archive of job and extracts the
+// content of the supported ones. Reading stops at the first limit exceeded,
+// the members listed until then are indexed.
+func (indexer *Indexer) readArchive(ctx context.Context, job *crawlJob) []*pb.FileAttr {
+	config := indexer.walker.config.Archives.withDefaults()
+	// Small archives may hold one well compressed member of any ratio
+	maxExpanded := min(max(int64(config.MaxRatio*float64(job.info.Size())), config.MaxMemberSize), config.MaxTotalSize)
+	reader := &archiveReader{
+		config:      config,
+		extractors:  indexer.extractors,
+		listed:      make(map[string]bool),
+		budget:      maxExpanded,
+		maxExpanded: maxExpanded,
+	}
+	if job.cached != nil {
+		prefix := job.path + ArchiveSeparator
+		reader.cached = make(map[string]*pb.FileAttr)
+		for _, member := range indexer.cache.Select(func(path string) bool { return strings.HasPrefix(path, prefix) }) {
+			reader.cached[member.Path] = member
+		}
+	}
+
+	file, err := os.Open(job.path)
+	if err == nil {
+		err = reader.read(ctx, job.path, file, job.info.Size(), archiveFormatOf(job.path), 1)
+		file.Close()
+	}
+	if err != nil && ctx.Err() == nil {
+		fmt.Printf("Failed to read the archive %s: %v\n", job.path, err)
+	}
+	return reader.members
+}
+
+// read lists the members of the archive in file, depth is its nesting level
+func (reader *archiveReader) read(ctx context.Context, archive string, file *os.File, size int64, format archiveFormat, depth int) error {
+	switch format {
+	case zipArchive:
+		return reader.readZip(ctx, archive, file, size, depth)
+	case gzipTarArchive:
+		stream, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+		return reader.readTar(ctx, archive, reader.limit(stream), depth)
+	case bzip2TarArchive:
+		return reader.readTar(ctx, archive, reader.limit(bzip2.NewReader(file)), depth)
+	case tarArchive:
+		return reader.readTar(ctx, archive, file, depth)
+	}
+	return nil
+}
+
+func (reader *archiveReader) readZip(ctx context.Context, archive string, file io.ReaderAt, size int64, depth int) error {
+	zipReader, err := zip.NewReader(file, size)
+	if err != nil {
+		return err
+	}
+	for _, file := range zipReader.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		modifiedAt := file.FileInfo().ModTime()
+		member := newMemberAttr(archive, file.Name, int64(file.UncompressedSize64), modifiedAt, modifiedAt)
+		if member == nil {
+			continue
+		}
+		// Small members are bounded by the budget, their ratio does not matter
+		var open func() (io.ReadCloser, error)
+		if file.UncompressedSize64 <= 1<<20 || float64(file.UncompressedSize64) <= reader.config.MaxRatio*float64(file.CompressedSize64) {
+			open = func() (io.ReadCloser, error) {
+				stream, err := file.Open()
+				if err != nil {
+					return nil, err
+				}
+				return readCloser{reader.limit(stream), stream}, nil
+			}
+		}
+		if err := reader.addMember(ctx, member, open, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (reader *archiveReader) readTar(ctx context.Context, archive string, stream io.Reader, depth int) error {
+	tarReader := tar.NewReader(stream)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		member := newMemberAttr(archive, header.Name, header.Size, header.ModTime, header.AccessTime)
+		if member == nil {
+			continue
+		}
+		// The data of a member that is not read is skipped by Next
+		open := func() (io.ReadCloser, error) { return io.NopCloser(tarReader), nil }
+		if err := reader.addMember(ctx, member, open, depth); err != nil {
+			return err
+		}
+	}
+}
+
+// addMember indexes a member. Its data is only read, with open, when an
+// extractor handles it or when it is an archive to descend into. A nil open
+// indexes the member without content.
+func (reader *archiveReader) addMember(ctx context.Context, member *pb.FileAttr, open func() (io.ReadCloser, error), depth int) error {
+	if reader.listed[member.Path] {
+		return nil
+	}
+	if len(reader.members) >= reader.config.MaxMembers {
+		return fmt.Errorf("%w: more than %d members", errArchiveLimit, reader.config.MaxMembers)
+	}
+	reader.listed[member.Path] = true
+
+	format := archiveFormatOf(member.Name)
+	nested := format != notArchive && depth < reader.config.MaxDepth
+	if cached, ok := reader.cached[member.Path]; ok && cached.Size == member.Size &&
+		cached.ModifiedAt.AsTime().Equal(member.ModifiedAt.AsTime()) {
+		reader.members = append(reader.members, cached)
+		if nested {
+			reader.reuseMembers(member.Path)
+		}
+		return nil
+	}
+	reader.members = append(reader.members, member)
+	if open == nil || member.Size > reader.config.MaxMemberSize {
+		return nil
+	}
+
+	var stream io.ReadCloser
+	var head []byte
+	openHead := func() (err error) {
+		if stream != nil {
+			return nil
+		}
+		if stream, err = open(); err != nil {
+			return err
+		}
+		head = make([]byte, sniffSize)
+		n, err := io.ReadFull(stream, head)
+		head = head[:n]
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		return err
+	}
+	defer func() {
+		if stream != nil {
+			stream.Close()
+		}
+	}()
+	_, extract := reader.extractors.lookup(member.Name, func() (string, error) {
+		if err := openHead(); err != nil {
+			return "", err
+		}
+		return detectMIMEType(head)
+	})
+	if !extract && !nested {
+		return nil
+	}
+	if err := openHead(); err != nil {
+		return memberError(member, err)
+	}
+
+	// The extractors and the zip reader need a file
+	file, err := os.CreateTemp("", "member-*"+strings.ReplaceAll(member.Type, "*", ""))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	n, err := io.Copy(file, io.LimitReader(io.MultiReader(bytes.NewReader(head), stream), reader.config.MaxMemberSize+1))
+	if err != nil {
+		return memberError(member, err)
+	}
+	if n > reader.config.MaxMemberSize {
+		// Larger than its header claimed
+		return nil
+	}
+
+	if extract {
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		document, err := reader.extractors.Extract(ctx, file.Name(), info)
+		if err != nil && ctx.Err() == nil {
+			fmt.Printf("Failed to extract the content of %s: %v\n", member.Path, err)
+		}
+		if document != nil {
+			member.Content = document.Content
+			member.Metadata = document.Metadata
+		}
+	}
+	if nested {
+		// The tar formats read the file as a stream from its start
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		listed := len(reader.members)
+		err := reader.read(ctx, member.Path, file, n, format, depth+1)
+		if errors.Is(err, errArchiveLimit) || ctx.Err() != nil {
+			return err
+		}
+		if err != nil {
+			fmt.Printf("Failed to read the archive %s: %v\n", member.Path, err)
+		}
+		if member.Metadata == nil {
+			member.Metadata = make(map[string]string)
+		}
+		member.Metadata[MetaMembers] = strconv.Itoa(len(reader.members) - listed)
+	}
+	return nil
+}
+
+// reuseMembers lists the cached members of the unchanged nested archive
+func (reader *archiveReader) reuseMembers(archive string) {
+	prefix := archive + ArchiveSeparator
+	for path, cached := range reader.cached {
+		if strings.HasPrefix(path, prefix) && !reader.listed[path] {
+			reader.listed[path] = true
+			reader.members = append(reader.members, cached)
+		}
+	}
+}
+
+// memberError keeps the limit errors that stop reading the archive, other
+// errors only leave the member without content
+func memberError(member *pb.FileAttr, err error) error {
+	if errors.Is(err, errArchiveLimit) {
+		return err
+	}
+	fmt.Printf("Failed to read %s: %v\n", member.Path, err)
+	return nil
+}
+
+// limit counts the bytes read from stream against the budget of the archive
+func (reader *archiveReader) limit(stream io.Reader) io.Reader {
+	return &budgetReader{stream, reader}
+}
+
+// budgetReader fails with errArchiveLimit once its archive decompressed more
+// bytes than its budget
+type budgetReader struct {
+	io.Reader
+	archive *archiveReader
+}
+
+func (reader *budgetReader) Read(p []byte) (int, error) {
+	n, err := reader.Reader.Read(p)
+	reader.archive.budget -= int64(n)
+	if reader.archive.budget < 0 {
+		return n, fmt.Errorf("%w: expands beyond %d bytes", errArchiveLimit, reader.archive.maxExpanded)
+	}
+	return n, err
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,320 @@
+package service
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"training/file-index/pb"
+)
+
+// tarData returns a tar archive of files, keyed by name, compressed with
+// gzip or bzip2 by the extension of name
+func tarData(t *testing.T, name string, files map[string]string) []byte {
+	t.Helper()
+	names := make([]string, 0, len(files))
+	for member := range files {
+		names = append(names, member)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	for _, member := range names {
+		header := &tar.Header{Name: member, Mode: 0o644, Size: int64(len(files[member])), ModTime: time.Unix(1700000000, 0)}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write([]byte(files[member])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	switch archiveFormatOf(name) {
+	case gzipTarArchive:
+		var compressed bytes.Buffer
+		gzipWriter := gzip.NewWriter(&compressed)
+		gzipWriter.Write(buf.Bytes())
+		gzipWriter.Close()
+		return compressed.Bytes()
+	case bzip2TarArchive:
+		// The standard library only decompresses bzip2
+		bzip2, err := exec.LookPath("bzip2")
+		if err != nil {
+			t.Skip("bzip2 is not installed")
+		}
+		cmd := exec.Command(bzip2, "-c")
+		cmd.Stdin = &buf
+		compressed, err := cmd.Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return compressed
+	}
+	return buf.Bytes()
+}
+
+// archiveData returns an archive of files in the format of the extension of
+// name
+func archiveData(t *testing.T, name string, files map[string]string) []byte {
+	t.Helper()
+	if archiveFormatOf(name) == zipArchive {
+		return zipData(t, files)
+	}
+	return tarData(t, name, files)
+}
+
+// zeros returns size zero bytes, they compress at a ratio of about 1000
+func zeros(size int) string {
+	return string(make([]byte, size))
+}
+
+// newArchiveIndexer returns an indexer of the archives below root that
+// extracts the text of .txt files and counts the extractions
+func newArchiveIndexer(t *testing.T, root string, config ArchiveConfig) (*Indexer, *atomic.Int32) {
+	t.Helper()
+	config.Enabled = true
+	indexer, _ := newTestIndexer(t, CrawlConfig{Roots: []string{root}, Archives: config})
+	extractions := new(atomic.Int32)
+	registry := NewExtractorRegistry()
+	err := registry.Register(ExtractorConfig{
+		Name:       "text",
+		Extensions: []string{".txt"},
+		Extractor: TextExtractor(func(ctx context.Context, path string) (string, error) {
+			extractions.Add(1)
+			return readTextContent(ctx, path)
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexer.SetExtractors(registry)
+	return indexer, extractions
+}
+
+// readTestArchive writes the archive name to root and returns its members
+// by path relative to the archive
+func readTestArchive(t *testing.T, indexer *Indexer, root, name string, data []byte) map[string]*pb.FileAttr {
+	t.Helper()
+	path := filepath.Join(root, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	members := make(map[string]*pb.FileAttr)
+	for _, member := range indexer.readArchive(context.Background(), &crawlJob{path: path, info: info}) {
+		members[strings.TrimPrefix(member.Path, path)] = member
+	}
+	return members
+}
+
+// memberNames returns the sorted keys of members
+func memberNames(members map[string]*pb.FileAttr) []string {
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestReadArchiveFormats(t *testing.T) {
+	files := map[string]string{
+		"docs/a.txt":         "alpha",
+		"./docs/../b.txt":    "beta",
+		`win\path\c.txt`:     "gamma",
+		"image.bin":          "\x00\x01\x02",
+		"docs/.hidden/d.txt": "delta",
+	}
+	for _, name := range []string{"files.zip", "files.jar", "files.tar", "files.tar.gz", "files.tgz", "files.tar.bz2"} {
+		root := t.TempDir()
+		indexer, extractions := newArchiveIndexer(t, root, ArchiveConfig{})
+		members := readTestArchive(t, indexer, root, name, archiveData(t, name, files))
+
+		want := []string{"!/b.txt", "!/docs/.hidden/d.txt", "!/docs/a.txt", "!/image.bin", "!/win/path/c.txt"}
+		if got := memberNames(members); strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("%s: members %q, want %q", name, got, want)
+			continue
+		}
+		for member, content := range map[string]string{"!/docs/a.txt": "alpha", "!/b.txt": "beta", "!/win/path/c.txt": "gamma", "!/image.bin": ""} {
+			if members[member].Content != content {
+				t.Errorf("%s: content of %s %q, want %q", name, member, members[member].Content, content)
+			}
+		}
+		if n := extractions.Load(); n != 4 {
+			t.Errorf("%s: %d extractions, want 4", name, n)
+		}
+		if attribute := members["!/docs/.hidden/d.txt"].Attributes; attribute != "Read Only" {
+			t.Errorf("%s: d.txt is %q, want Read Only", name, attribute)
+		}
+	}
+}
+
+// TestReadArchiveDepth reads an archive nested three levels deep in each
+// format
+func TestReadArchiveDepth(t *testing.T) {
+	level3 := tarData(t, "level3.tar.bz2", map[string]string{"deep.txt": "deep"})
+	level2 := tarData(t, "level2.tar.gz", map[string]string{"level3.tar.bz2": string(level3), "middle.txt": "middle"})
+	level1 := zipData(t, map[string]string{"level2.tar.gz": string(level2), "top.txt": "top"})
+
+	for _, test := range []struct {
+		maxDepth int
+		want     []string
+	}{
+		{1, []string{"!/level2.tar.gz", "!/top.txt"}},
+		// The default
+		{0, []string{"!/level2.tar.gz", "!/level2.tar.gz!/level3.tar.bz2", "!/level2.tar.gz!/middle.txt", "!/top.txt"}},
+		{3, []string{
+			"!/level2.tar.gz", "!/level2.tar.gz!/level3.tar.bz2", "!/level2.tar.gz!/level3.tar.bz2!/deep.txt",
+			"!/level2.tar.gz!/middle.txt", "!/top.txt",
+		}},
+	} {
+		root := t.TempDir()
+		indexer, _ := newArchiveIndexer(t, root, ArchiveConfig{MaxDepth: test.maxDepth})
+		members := readTestArchive(t, indexer, root, "level1.zip", level1)
+		if got := memberNames(members); strings.Join(got, " ") != strings.Join(test.want, " ") {
+			t.Errorf("max depth %d: members %q, want %q", test.maxDepth, got, test.want)
+			continue
+		}
+		if test.maxDepth == 3 {
+			if content := members["!/level2.tar.gz!/level3.tar.bz2!/deep.txt"].Content; content != "deep" {
+				t.Errorf("content of deep.txt %q, want %q", content, "deep")
+			}
+			if count := members["!/level2.tar.gz"].Metadata[MetaMembers]; count != "3" {
+				t.Errorf("level2.tar.gz has %s members, want 3", count)
+			}
+		}
+	}
+}
+
+// TestReadArchiveRatio checks that a highly compressed member is not read
+// and that the members of an archive stop at its expansion budget
+func TestReadArchiveRatio(t *testing.T) {
+	root := t.TempDir()
+	indexer, extractions := newArchiveIndexer(t, root, ArchiveConfig{})
+	members := readTestArchive(t, indexer, root, "bomb.zip", zipData(t, map[string]string{
+		"bomb.txt":  zeros(4 << 20),
+		"small.txt": "small",
+	}))
+	if bomb := members["!/bomb.txt"]; bomb == nil || bomb.Size != 4<<20 || bomb.Content != "" {
+		t.Errorf("bomb.txt = %+v, want a member of 4 MiB without content", bomb)
+	}
+	if small := members["!/small.txt"]; small == nil || small.Content != "small" {
+		t.Errorf("small.txt = %+v, want its content", small)
+	}
+	if n := extractions.Load(); n != 1 {
+		t.Errorf("%d extractions, want 1", n)
+	}
+
+	// 16 MiB of members compressed to a few KiB, the budget is 1 MiB
+	files := make(map[string]string)
+	for i := 0; i < 16; i++ {
+		files[filepath.Join("zeros", strings.Repeat("x", i+1)+".txt")] = zeros(1 << 20)
+	}
+	for _, name := range []string{"bombs.zip", "bombs.tar.gz", "bombs.tar.bz2"} {
+		root := t.TempDir()
+		indexer, _ := newArchiveIndexer(t, root, ArchiveConfig{MaxRatio: 10, MaxMemberSize: 1 << 20})
+		members := readTestArchive(t, indexer, root, name, archiveData(t, name, files))
+		if len(members) == 0 || len(members) > 2 {
+			t.Errorf("%s: read %d members, want the ones within 1 MiB", name, len(members))
+		}
+	}
+}
+
+func TestReadArchiveMaxMembers(t *testing.T) {
+	inner := make(map[string]string)
+	outer := make(map[string]string)
+	for i := 0; i < 10; i++ {
+		inner[filepath.Join("inner", strings.Repeat("i", i+1))] = "inner"
+		outer[filepath.Join("outer", strings.Repeat("o", i+1))] = "outer"
+	}
+	outer["a.zip"] = string(zipData(t, inner))
+
+	root := t.TempDir()
+	indexer, _ := newArchiveIndexer(t, root, ArchiveConfig{MaxMembers: 8})
+	members := readTestArchive(t, indexer, root, "outer.zip", zipData(t, outer))
+	// a.zip comes first and its members count against the limit
+	if len(members) != 8 || members["!/a.zip!/inner/iiiiiii"] == nil {
+		t.Errorf("members %q, want a.zip and 7 of its members", memberNames(members))
+	}
+}
+
+// TestReadArchiveReuse checks that the members of an unchanged nested
+// archive are reused when its archive changes
+func TestReadArchiveReuse(t *testing.T) {
+	root := t.TempDir()
+	indexer, extractions := newArchiveIndexer(t, root, ArchiveConfig{})
+	inner := tarData(t, "inner.tar.gz", map[string]string{"b.txt": "beta", "c.txt": "gamma"})
+	path := filepath.Join(root, "outer.zip")
+	write := func(a string, modifiedAt time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, zipData(t, map[string]string{"a.txt": a, "inner.tar.gz": string(inner)}), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modifiedAt, modifiedAt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("alpha", time.Now().Add(-time.Hour))
+	if err := indexer.crawl(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := extractions.Load(); n != 3 {
+		t.Fatalf("first crawl extracted %d members, want 3", n)
+	}
+
+	write("alpha, changed", time.Now())
+	if err := indexer.crawl(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := extractions.Load(); n != 4 {
+		t.Errorf("second crawl extracted %d members, want only a.txt", n-3)
+	}
+	for member, content := range map[string]string{
+		"!/a.txt":               "alpha, changed",
+		"!/inner.tar.gz!/b.txt": "beta",
+		"!/inner.tar.gz!/c.txt": "gamma",
+	} {
+		cached, ok := indexer.cache.Get(path + member)
+		if !ok || cached.Content != content {
+			t.Errorf("cached %s = %+v, want content %q", member, cached, content)
+		}
+	}
+	if deleted := changesOf(indexer, pb.ChangeType_DELETED); len(deleted) > 0 {
+		t.Errorf("second crawl deleted %s", deleted[0].GetFile().GetPath())
+	}
+}
+
+// TestArchiveFormatOf checks that 7z and rar archives are indexed as files
+func TestArchiveFormatOf(t *testing.T) {
+	for name, want := range map[string]archiveFormat{
+		"a.ZIP":     zipArchive,
+		"a.jar":     zipArchive,
+		"a.tar":     tarArchive,
+		"a.Tar.Gz":  gzipTarArchive,
+		"a.tgz":     gzipTarArchive,
+		"a.tar.bz2": bzip2TarArchive,
+		"a.tbz2":    bzip2TarArchive,
+		"a.gz":      notArchive,
+		"a.7z":      notArchive,
+		"a.rar":     notArchive,
+	} {
+		if got := archiveFormatOf(name); got != want {
+			t.Errorf("archiveFormatOf(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
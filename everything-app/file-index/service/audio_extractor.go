@@ -0,0 +1,392 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// The tags of music files
+func init() {
+	err := RegisterExtractor(ExtractorConfig{
+		Name:       "audio",
+		Extensions: []string{".mp3", ".flac", ".ogg", ".oga", ".opus"},
+		Extractor:  ExtractorFunc(readAudioDocument),
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// maxTagSize bounds the tags read, cover pictures can make them large
+const maxTagSize = 16 << 20
+
+var errTagSyntax = errors.New("invalid audio tag")
+
+// id3Frames map the text frames of ID3v2.2 and later versions to metadata
+// keys. The recording time of v2.4 replaces the year of v2.3.
+var id3Frames = map[string]string{
+	"TT2": MetaTitle, "TIT2": MetaTitle,
+	"TP1": MetaArtist, "TPE1": MetaArtist,
+	"TAL": MetaAlbum, "TALB": MetaAlbum,
+	"TYE": MetaDate, "TYER": MetaDate, "TDRC": MetaDate,
+	"TCO": MetaGenre, "TCON": MetaGenre,
+}
+
+// vorbisFields map the Vorbis comments of FLAC, Vorbis and Opus to metadata
+// keys
+var vorbisFields = map[string]string{
+	"TITLE": MetaTitle, "ARTIST": MetaArtist, "ALBUM": MetaAlbum, "DATE": MetaDate, "GENRE": MetaGenre,
+}
+
+// id3Genres are the genres numbered by ID3v1
+var id3Genres = []string{
+	"Blues", "Classic Rock", "Country", "Dance", "Disco", "Funk", "Grunge", "Hip-Hop",
+	"Jazz", "Metal", "New Age", "Oldies", "Other", "Pop", "R&B", "Rap",
+	"Reggae", "Rock", "Techno", "Industrial", "Alternative", "Ska", "Death Metal", "Pranks",
+	"Soundtrack", "Euro-Techno", "Ambient", "Trip-Hop", "Vocal", "Jazz+Funk", "Fusion", "Trance",
+	"Classical", "Instrumental", "Acid", "House", "Game", "Sound Clip", "Gospel", "Noise",
+	"AlternRock", "Bass", "Soul", "Punk", "Space", "Meditative", "Instrumental Pop", "Instrumental Rock",
+	"Ethnic", "Gothic", "Darkwave", "Techno-Industrial", "Electronic", "Pop-Folk", "Eurodance", "Dream",
+	"Southern Rock", "Comedy", "Cult", "Gangsta", "Top 40", "Christian Rap", "Pop/Funk", "Jungle",
+	"Native American", "Cabaret", "New Wave", "Psychadelic", "Rave", "Showtunes", "Trailer", "Lo-Fi",
+	"Tribal", "Acid Punk", "Acid Jazz", "Polka", "Retro", "Musical", "Rock & Roll", "Hard Rock",
+}
+
+// readAudioDocument reads the title, artist, album, date and genre tags of
+// MP3, FLAC and Ogg files
+func readAudioDocument(ctx context.Context, path string) (*Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	magic, err := reader.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+	document := &Document{}
+	switch {
+	case string(magic) == "fLaC":
+		err = readFLACTags(reader, document)
+	case string(magic) == "OggS":
+		err = readOggTags(reader, document)
+	default:
+		if string(magic[:3]) == "ID3" {
+			err = readID3v2(reader, document)
+		}
+		if err == nil {
+			// Older files only have the ID3v1 tag at the end
+			err = readID3v1(file, document)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// readID3v2 reads the text frames of an ID3v2 tag
+func readID3v2(reader io.Reader, document *Document) error {
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return err
+	}
+	version, flags := header[3], header[5]
+	size := syncsafe(header[6:10])
+	if version < 2 || version > 4 || size > maxTagSize {
+		return fmt.Errorf("%w: ID3v2.%d tag of %d bytes", errTagSyntax, version, size)
+	}
+	tag := make([]byte, size)
+	if _, err := io.ReadFull(reader, tag); err != nil {
+		return err
+	}
+	if flags&0x80 != 0 && version < 4 {
+		tag = removeUnsynchronisation(tag)
+	}
+	if flags&0x40 != 0 && version > 2 && len(tag) >= 4 {
+		// Skip the extended header
+		extended := int(binary.BigEndian.Uint32(tag))
+		if version == 4 {
+			extended = syncsafe(tag[:4])
+		} else {
+			extended += 4
+		}
+		if extended > len(tag) {
+			return errTagSyntax
+		}
+		tag = tag[extended:]
+	}
+
+	idSize, headerSize := 4, 10
+	if version == 2 {
+		idSize, headerSize = 3, 6
+	}
+	for len(tag) >= headerSize && tag[0] != 0 {
+		id := string(tag[:idSize])
+		var frameSize int
+		var frameFlags byte
+		switch version {
+		case 2:
+			frameSize = int(tag[3])<<16 | int(tag[4])<<8 | int(tag[5])
+		case 3:
+			frameSize, frameFlags = int(binary.BigEndian.Uint32(tag[4:])), tag[9]
+		case 4:
+			frameSize, frameFlags = syncsafe(tag[4:8]), tag[9]
+		}
+		if frameSize < 0 || frameSize > len(tag)-headerSize {
+			return errTagSyntax
+		}
+		frame := tag[headerSize : headerSize+frameSize]
+		tag = tag[headerSize+frameSize:]
+
+		key, ok := id3Frames[id]
+		// Compressed and encrypted frames are skipped
+		if !ok || (version == 3 && frameFlags&0xc0 != 0) || (version == 4 && frameFlags&0x0c != 0) {
+			continue
+		}
+		if version == 4 && frameFlags&0x02 != 0 {
+			frame = removeUnsynchronisation(frame)
+		}
+		if version == 4 && frameFlags&0x01 != 0 && len(frame) >= 4 {
+			// Data length indicator
+			frame = frame[4:]
+		}
+		if _, ok := document.Metadata[key]; ok && id != "TDRC" {
+			continue
+		}
+		value := decodeID3Text(frame)
+		if key == MetaGenre {
+			value = id3Genre(value)
+		}
+		document.setMeta(key, value)
+	}
+	return nil
+}
+
+// readID3v1 reads the ID3v1 tag at the end of file, only the values missing
+// from the ID3v2 tag are set
+func readID3v1(file *os.File, document *Document) error {
+	info, err := file.Stat()
+	if err != nil || info.Size() < 128 {
+		return err
+	}
+	tag := make([]byte, 128)
+	if _, err := file.ReadAt(tag, info.Size()-128); err != nil {
+		return err
+	}
+	if string(tag[:3]) != "TAG" {
+		return nil
+	}
+	field := func(data []byte) string {
+		text, _, _ := bytes.Cut(data, []byte{0})
+		decoded, _ := charmap.Windows1252.NewDecoder().Bytes(text)
+		return strings.TrimSpace(string(decoded))
+	}
+	values := map[string]string{
+		MetaTitle:  field(tag[3:33]),
+		MetaArtist: field(tag[33:63]),
+		MetaAlbum:  field(tag[63:93]),
+		MetaDate:   field(tag[93:97]),
+	}
+	if genre := int(tag[127]); genre < len(id3Genres) {
+		values[MetaGenre] = id3Genres[genre]
+	}
+	for key, value := range values {
+		if _, ok := document.Metadata[key]; !ok {
+			document.setMeta(key, value)
+		}
+	}
+	return nil
+}
+
+// syncsafe decodes a 28-bit integer stored in the low 7 bits of 4 bytes
+func syncsafe(data []byte) int {
+	return int(data[0]&0x7f)<<21 | int(data[1]&0x7f)<<14 | int(data[2]&0x7f)<<7 | int(data[3]&0x7f)
+}
+
+// removeUnsynchronisation drops the 0x00 inserted after every 0xFF
+func removeUnsynchronisation(data []byte) []byte {
+	return bytes.ReplaceAll(data, []byte{0xff, 0x00}, []byte{0xff})
+}
+
+// decodeID3Text decodes a text frame, several values are joined with commas
+func decodeID3Text(frame []byte) string {
+	if len(frame) == 0 {
+		return ""
+	}
+	text := frame[1:]
+	var decoded []byte
+	var err error
+	switch frame[0] {
+	case 0:
+		decoded, err = charmap.ISO8859_1.NewDecoder().Bytes(text)
+	case 1:
+		decoded, err = unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder().Bytes(text)
+	case 2:
+		decoded, err = unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewDecoder().Bytes(text)
+	default:
+		decoded = text
+	}
+	if err != nil {
+		return ""
+	}
+	var values []string
+	for _, value := range strings.Split(string(decoded), "\x00") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return strings.Join(values, ", ")
+}
+
+// id3Genre resolves the ID3v1 genre numbers of a genre frame, as in "(17)"
+// or "17"
+func id3Genre(value string) string {
+	number := value
+	if strings.HasPrefix(value, "(") {
+		var rest string
+		number, rest, _ = strings.Cut(value[1:], ")")
+		if rest != "" {
+			return rest
+		}
+	}
+	if n, err := strconv.Atoi(number); err == nil && n >= 0 && n < len(id3Genres) {
+		return id3Genres[n]
+	}
+	return value
+}
+
+// readFLACTags reads the Vorbis comment block of a FLAC file
+func readFLACTags(reader *bufio.Reader, document *Document) error {
+	if _, err := reader.Discard(4); err != nil {
+		return err
+	}
+	for {
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return err
+		}
+		last, kind := header[0]&0x80 != 0, header[0]&0x7f
+		size := int(header[1])<<16 | int(header[2])<<8 | int(header[3])
+		if kind == 4 {
+			block := make([]byte, size)
+			if _, err := io.ReadFull(reader, block); err != nil {
+				return err
+			}
+			return readVorbisComment(block, document)
+		}
+		if _, err := reader.Discard(size); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+	}
+}
+
+// readOggTags reads the comment header of the first stream of an Ogg file,
+// the second packet of a Vorbis or Opus stream
+func readOggTags(reader *bufio.Reader, document *Document) error {
+	var packet []byte
+	packets := 0
+	var serial uint32
+	for read := 0; read < maxTagSize; {
+		header := make([]byte, 27)
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return err
+		}
+		if string(header[:4]) != "OggS" {
+			return errTagSyntax
+		}
+		pageSerial := binary.LittleEndian.Uint32(header[14:])
+		if read == 0 {
+			serial = pageSerial
+		}
+		segments := make([]byte, header[26])
+		if _, err := io.ReadFull(reader, segments); err != nil {
+			return err
+		}
+		read += 27 + len(segments)
+		for _, segment := range segments {
+			data := make([]byte, segment)
+			if _, err := io.ReadFull(reader, data); err != nil {
+				return err
+			}
+			read += len(data)
+			if pageSerial != serial {
+				continue
+			}
+			packet = append(packet, data...)
+			// A packet ends with a segment shorter than 255 bytes
+			if segment == 255 {
+				continue
+			}
+			packets++
+			if packets == 2 {
+				switch {
+				case bytes.HasPrefix(packet, []byte("\x03vorbis")):
+					return readVorbisComment(packet[7:], document)
+				case bytes.HasPrefix(packet, []byte("OpusTags")):
+					return readVorbisComment(packet[8:], document)
+				}
+				return nil
+			}
+			packet = packet[:0]
+		}
+	}
+	return nil
+}
+
+// readVorbisComment reads the FIELD=value comments of a Vorbis comment
+// header
+func readVorbisComment(data []byte, document *Document) error {
+	next := func() ([]byte, bool) {
+		if len(data) < 4 {
+			return nil, false
+		}
+		size := binary.LittleEndian.Uint32(data)
+		if uint64(size) > uint64(len(data)-4) {
+			return nil, false
+		}
+		value := data[4 : 4+size]
+		data = data[4+size:]
+		return value, true
+	}
+	// The vendor string
+	if _, ok := next(); !ok || len(data) < 4 {
+		return errTagSyntax
+	}
+	count := binary.LittleEndian.Uint32(data)
+	data = data[4:]
+	for i := uint32(0); i < count; i++ {
+		comment, ok := next()
+		if !ok {
+			return errTagSyntax
+		}
+		field, value, ok := strings.Cut(string(comment), "=")
+		if !ok {
+			continue
+		}
+		key, ok := vorbisFields[strings.ToUpper(field)]
+		if !ok {
+			continue
+		}
+		if _, exists := document.Metadata[key]; !exists {
+			document.setMeta(key, value)
+		}
+	}
+	return nil
+}
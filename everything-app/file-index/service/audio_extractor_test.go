@@ -0,0 +1,258 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// id3Frame returns a frame of an ID3v2 tag of version, the size of v2.4 is
+// syncsafe
+func id3Frame(version byte, id string, flags byte, data []byte) []byte {
+	var frame []byte
+	switch version {
+	case 2:
+		size := len(data)
+		frame = append([]byte(id), byte(size>>16), byte(size>>8), byte(size))
+	case 3:
+		frame = binary.BigEndian.AppendUint32([]byte(id), uint32(len(data)))
+		frame = append(frame, 0, flags)
+	case 4:
+		frame = append([]byte(id), syncsafeBytes(len(data))...)
+		frame = append(frame, 0, flags)
+	}
+	return append(frame, data...)
+}
+
+func syncsafeBytes(n int) []byte {
+	return []byte{byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+}
+
+// unsynchronise inserts a 0x00 after every 0xFF
+func unsynchronise(data []byte) []byte {
+	return bytes.ReplaceAll(data, []byte{0xff}, []byte{0xff, 0x00})
+}
+
+// id3Tag returns an ID3v2 tag of frames
+func id3Tag(version, flags byte, frames ...[]byte) []byte {
+	tag := bytes.Join(frames, nil)
+	// Padding
+	tag = append(tag, make([]byte, 16)...)
+	if flags&0x80 != 0 {
+		tag = unsynchronise(tag)
+	}
+	header := append([]byte{'I', 'D', '3', version, 0, flags}, syncsafeBytes(len(tag))...)
+	return append(header, tag...)
+}
+
+// id3v1Tag returns the ID3v1 tag at the end of a file
+func id3v1Tag(title, artist, album, year string, genre byte) []byte {
+	tag := make([]byte, 128)
+	copy(tag, "TAG")
+	copy(tag[3:33], title)
+	copy(tag[33:63], artist)
+	copy(tag[63:93], album)
+	copy(tag[93:97], year)
+	tag[127] = genre
+	return tag
+}
+
+// vorbisComment returns a Vorbis comment header of comments
+func vorbisComment(comments ...string) []byte {
+	data := binary.LittleEndian.AppendUint32(nil, 9)
+	data = append(data, "reference"...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(comments)))
+	for _, comment := range comments {
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(comment)))
+		data = append(data, comment...)
+	}
+	return data
+}
+
+// flacBlock returns a metadata block of a FLAC file
+func flacBlock(kind byte, last bool, data []byte) []byte {
+	if last {
+		kind |= 0x80
+	}
+	return append([]byte{kind, byte(len(data) >> 16), byte(len(data) >> 8), byte(len(data))}, data...)
+}
+
+// oggPage returns a page of the stream serial holding segments, the
+// checksum is not verified by the reader
+func oggPage(serial uint32, segments ...[]byte) []byte {
+	page := []byte("OggS\x00\x00")
+	page = append(page, make([]byte, 8)...)
+	page = binary.LittleEndian.AppendUint32(page, serial)
+	page = append(page, make([]byte, 8)...)
+	page = append(page, byte(len(segments)))
+	for _, segment := range segments {
+		page = append(page, byte(len(segment)))
+	}
+	return append(page, bytes.Join(segments, nil)...)
+}
+
+// oggSegments splits a packet into segments, a packet of a multiple of 255
+// bytes ends with an empty segment
+func oggSegments(packet []byte) [][]byte {
+	var segments [][]byte
+	for len(packet) >= 255 {
+		segments = append(segments, packet[:255])
+		packet = packet[255:]
+	}
+	return append(segments, packet)
+}
+
+var audioTags = map[string]string{
+	MetaTitle:  "Café del Mar",
+	MetaArtist: "Sigur Rós",
+	MetaAlbum:  "Takk...",
+	MetaDate:   "2005",
+	MetaGenre:  "Rock",
+}
+
+func TestReadAudioDocumentID3(t *testing.T) {
+	latin1 := func(text string) []byte {
+		return append([]byte{0}, strings.NewReplacer("é", "\xe9", "ó", "\xf3").Replace(text)...)
+	}
+	utf16 := func(order binary.AppendByteOrder, encoding byte, bom bool, text string) []byte {
+		data := []byte{encoding}
+		if bom {
+			data = order.AppendUint16(data, 0xfeff)
+		}
+		for _, r := range text {
+			data = order.AppendUint16(data, uint16(r))
+		}
+		return data
+	}
+	utf8 := func(text string) []byte { return append([]byte{3}, text...) }
+	audio := make([]byte, 256)
+
+	for _, test := range []struct {
+		name string
+		data []byte
+		want map[string]string
+	}{
+		{
+			// Unsynchronisation of the whole tag, the byte order marks hold
+			// 0xFF
+			"v23.mp3",
+			id3Tag(3, 0x80,
+				id3Frame(3, "TIT2", 0, utf16(binary.LittleEndian, 1, true, "Café del Mar")),
+				id3Frame(3, "TPE1", 0, utf16(binary.BigEndian, 1, true, "Sigur Rós")),
+				id3Frame(3, "TALB", 0, latin1("Takk...")),
+				id3Frame(3, "TYER", 0, latin1("2005")),
+				id3Frame(3, "TCON", 0, latin1("(17)")),
+				// Compressed frames are skipped
+				id3Frame(3, "TIT2", 0x80, latin1("compressed")),
+			),
+			audioTags,
+		},
+		{
+			// The recording time replaces the year, unsynchronised frames
+			// with a data length indicator
+			"v24.mp3",
+			id3Tag(4, 0,
+				id3Frame(4, "TIT2", 0, utf8("Café del Mar")),
+				id3Frame(4, "TPE1", 0x03, append([]byte{0, 0, 0, 21}, unsynchronise(utf16(binary.LittleEndian, 1, true, "Sigur Rós"))...)),
+				id3Frame(4, "TALB", 0, utf16(binary.BigEndian, 2, false, "Takk...")),
+				id3Frame(4, "TYER", 0, latin1("1999")),
+				id3Frame(4, "TDRC", 0, latin1("2005")),
+				id3Frame(4, "TCON", 0, latin1("17")),
+			),
+			audioTags,
+		},
+		{
+			"v22.mp3",
+			id3Tag(2, 0,
+				id3Frame(2, "TT2", 0, latin1("Café del Mar")),
+				id3Frame(2, "TP1", 0, utf8("Sigur Rós\x00Jónsi")),
+				id3Frame(2, "TCO", 0, latin1("(17)Post-rock")),
+			),
+			map[string]string{MetaTitle: "Café del Mar", MetaArtist: "Sigur Rós, Jónsi", MetaGenre: "Post-rock"},
+		},
+		{
+			// The ID3v1 tag only fills the missing values
+			"both.mp3",
+			append(append(id3Tag(3, 0, id3Frame(3, "TIT2", 0, utf8("Café del Mar"))), audio...),
+				id3v1Tag("Cafe", "Sigur R\xf3s", "Takk...", "2005", 17)...),
+			audioTags,
+		},
+		{
+			"v1.mp3",
+			append(audio, id3v1Tag("Caf\xe9 del Mar", "Sigur R\xf3s", "Takk...", "2005", 17)...),
+			audioTags,
+		},
+		{"untagged.mp3", audio, nil},
+	} {
+		path, _ := writeFile(t, test.name, test.data)
+		document, err := readAudioDocument(context.Background(), path)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(document.Metadata, test.want) {
+			t.Errorf("%s: metadata %v, want %v", test.name, document.Metadata, test.want)
+		}
+	}
+}
+
+func TestReadAudioDocumentVorbis(t *testing.T) {
+	comments := vorbisComment(
+		"TITLE=Café del Mar",
+		// Field names are not case sensitive, the first value is kept
+		"artist=Sigur Rós",
+		"ARTIST=Jónsi",
+		"ALBUM=Takk...",
+		"DATE=2005",
+		"GENRE=Rock",
+		"ENCODER=reference",
+		"COMMENT",
+	)
+	flac := []byte("fLaC")
+	flac = append(flac, flacBlock(0, false, make([]byte, 34))...)
+	flac = append(flac, flacBlock(1, false, make([]byte, 100))...)
+	flac = append(flac, flacBlock(4, true, comments)...)
+
+	// The comment packet spans two pages, with a page of another stream
+	// between them
+	packet := append(append([]byte("\x03vorbis"), comments...), strings.Repeat("x", 500)...)
+	segments := oggSegments(packet)
+	vorbis := oggPage(1, []byte("\x01vorbis"+strings.Repeat("\x00", 23)))
+	vorbis = append(vorbis, oggPage(1, segments[:1]...)...)
+	vorbis = append(vorbis, oggPage(2, []byte("OpusHead"))...)
+	vorbis = append(vorbis, oggPage(1, segments[1:]...)...)
+
+	opus := oggPage(7, []byte("OpusHead\x01\x02"))
+	opus = append(opus, oggPage(7, oggSegments(append([]byte("OpusTags"), comments...))...)...)
+
+	for name, data := range map[string][]byte{"song.flac": flac, "song.ogg": vorbis, "song.opus": opus} {
+		path, _ := writeFile(t, name, data)
+		document, err := readAudioDocument(context.Background(), path)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(document.Metadata, audioTags) {
+			t.Errorf("%s: metadata %v, want %v", name, document.Metadata, audioTags)
+		}
+	}
+}
+
+func TestReadAudioDocumentErrors(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"version.mp3":   append([]byte("ID3\x05\x00\x00"), syncsafeBytes(10)...),
+		"truncated.mp3": append([]byte("ID3\x03\x00\x00"), syncsafeBytes(100)...),
+		"frame.mp3":     id3Tag(3, 0, []byte("TIT2\x00\x00\x01\x00\x00\x00")),
+		"comment.flac":  append([]byte("fLaC"), flacBlock(4, true, []byte{0xff, 0xff, 0xff, 0xff})...),
+		"block.flac":    append([]byte("fLaC"), 0x84, 0, 1, 0),
+		"page.ogg":      append(oggPage(1, []byte("\x01vorbis")), "OggX"+strings.Repeat("\x00", 23)...),
+	} {
+		path, _ := writeFile(t, name, data)
+		if document, err := readAudioDocument(context.Background(), path); err == nil {
+			t.Errorf("%s: metadata %v, want an error", name, document.Metadata)
+		}
+	}
+}
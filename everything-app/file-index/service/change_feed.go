@@ -0,0 +1,133 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"training/file-index/pb"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var ErrTokenNotFound = errors.New("resume token is not in the retained change history")
+
+// ChangeFeed keeps the most recent file changes in sequence order so several
+// WatchChanges streams can share one crawl and resume after reconnecting
+type ChangeFeed struct {
+	mutex    sync.Mutex
+	capacity int
+	// events is a ring buffer growing up to capacity, the event with
+	// sequence number seq is at events[(seq-origin)%capacity]
+	events []*pb.FileChange
+	origin uint64
+	// first is the sequence number of the oldest retained event, last the
+	// one of the newest event
+	first  uint64
+	last   uint64
+	notify chan struct{}
+}
+
+// NewChangeFeed returns a ChangeFeed retaining up to capacity changes, at
+// least one
+func NewChangeFeed(capacity int) *ChangeFeed {
+	return &ChangeFeed{
+		capacity: max(capacity, 1),
+		origin:   1,
+		first:    1,
+		notify:   make(chan struct{}),
+	}
+}
+
+// slot returns the index in events of the event with sequence number seq
+func (feed *ChangeFeed) slot(seq uint64) int {
+	return int((seq - feed.origin) % uint64(feed.capacity))
+}
+
+// Reset drops the history and continues numbering after lastSequence
+func (feed *ChangeFeed) Reset(lastSequence uint64) {
+	feed.mutex.Lock()
+	defer feed.mutex.Unlock()
+
+	feed.events = nil
+	feed.origin = lastSequence + 1
+	feed.first = lastSequence + 1
+	feed.last = lastSequence
+}
+
+// Publish appends a change and wakes up the waiting subscribers
+func (feed *ChangeFeed) Publish(changeType pb.ChangeType, fileAttr *pb.FileAttr, oldPath string) {
+	withoutContent := proto.Clone(fileAttr).(*pb.FileAttr)
+	withoutContent.Content = ""
+
+	feed.mutex.Lock()
+	defer feed.mutex.Unlock()
+
+	feed.last++
+	change := &pb.FileChange{
+		Type:        changeType,
+		File:        withoutContent,
+		OldPath:     oldPath,
+		ResumeToken: encodeResumeToken(feed.last),
+		ChangedAt:   timestamppb.Now(),
+	}
+	if len(feed.events) < feed.capacity {
+		feed.events = append(feed.events, change)
+	} else {
+		// The oldest event is overwritten
+		feed.events[feed.slot(feed.last)] = change
+		feed.first++
+	}
+	close(feed.notify)
+	feed.notify = make(chan struct{})
+}
+
+// LastSequence returns the sequence number of the newest change
+func (feed *ChangeFeed) LastSequence() uint64 {
+	feed.mutex.Lock()
+	defer feed.mutex.Unlock()
+
+	return feed.last
+}
+
+// Since returns the changes after sequence number after and a channel that
+// is closed when the next change is published. ErrTokenNotFound is returned
+// if changes after after were already dropped from the history or after was
+// never published.
+func (feed *ChangeFeed) Since(after uint64) ([]*pb.FileChange, <-chan struct{}, error) {
+	feed.mutex.Lock()
+	defer feed.mutex.Unlock()
+
+	if after+1 < feed.first || after > feed.last {
+		return nil, nil, ErrTokenNotFound
+	}
+	if after >= feed.last {
+		return nil, feed.notify, nil
+	}
+	changes := make([]*pb.FileChange, 0, feed.last-after)
+	for seq := after + 1; seq <= feed.last; seq++ {
+		changes = append(changes, feed.events[feed.slot(seq)])
+	}
+	return changes, feed.notify, nil
+}
+
+const resumeTokenPrefix = "seq:"
+
+func encodeResumeToken(sequence uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(resumeTokenPrefix + strconv.FormatUint(sequence, 10)))
+}
+
+func decodeResumeToken(token string) (uint64, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || !strings.HasPrefix(string(data), resumeTokenPrefix) {
+		return 0, fmt.Errorf("invalid resume token %q", token)
+	}
+	sequence, err := strconv.ParseUint(strings.TrimPrefix(string(data), resumeTokenPrefix), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid resume token %q", token)
+	}
+	return sequence, nil
+}
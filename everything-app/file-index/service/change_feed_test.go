@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"training/file-index/pb"
+)
+
+// publishFiles publishes count creations of numbered files
+func publishFiles(feed *ChangeFeed, count int) {
+	for i := 0; i < count; i++ {
+		feed.Publish(pb.ChangeType_CREATED, &pb.FileAttr{Path: fmt.Sprintf("/file%d", i)}, "")
+	}
+}
+
+func TestChangeFeedRetainsCapacity(t *testing.T) {
+	feed := NewChangeFeed(3)
+	feed.Reset(10)
+	publishFiles(feed, 8)
+
+	if last := feed.LastSequence(); last != 18 {
+		t.Fatalf("last sequence %d, want 18", last)
+	}
+	// 11 to 15 were overwritten
+	if _, _, err := feed.Since(14); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("Since(14) returned %v, want %v", err, ErrTokenNotFound)
+	}
+	for after, want := range map[uint64][]string{
+		15: {"/file5", "/file6", "/file7"},
+		16: {"/file6", "/file7"},
+		18: nil,
+	} {
+		changes, _, err := feed.Since(after)
+		if err != nil {
+			t.Fatalf("Since(%d): %v", after, err)
+		}
+		var paths []string
+		for _, change := range changes {
+			paths = append(paths, change.GetFile().GetPath())
+		}
+		if fmt.Sprint(paths) != fmt.Sprint(want) {
+			t.Errorf("Since(%d) = %v, want %v", after, paths, want)
+		}
+		if len(changes) > 0 {
+			token, err := decodeResumeToken(changes[len(changes)-1].GetResumeToken())
+			if err != nil || token != 18 {
+				t.Errorf("Since(%d) ends with token %d, %v, want 18", after, token, err)
+			}
+		}
+	}
+	if _, _, err := feed.Since(19); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("Since(19) returned %v, want %v", err, ErrTokenNotFound)
+	}
+}
+
+func TestChangeFeedNotifies(t *testing.T) {
+	feed := NewChangeFeed(2)
+	_, notify, err := feed.Since(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publishFiles(feed, 1)
+	select {
+	case <-notify:
+	default:
+		t.Fatal("publish did not notify the subscribers")
+	}
+}
@@ -1,12 +1,127 @@
 package service
 
 import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
 	"fmt"
 	"hash"
+	"hash/crc32"
 	"io"
 	"os"
+	"path/filepath"
+	"runtime"
+	"training/file-index/pb"
+
+	"golang.org/x/crypto/blake2b"
+	"google.golang.org/grpc/codes"
 )
 
+// checksumWorkers bounds the number of files hashed at once by a
+// StreamChecksums call
+var checksumWorkers = min(runtime.NumCPU(), 8)
+
+// checksumHashes are the hash functions of the checksum algorithms
+var checksumHashes = map[pb.ChecksumAlgorithm]func() hash.Hash{
+	pb.ChecksumAlgorithm_MD5:    md5.New,
+	pb.ChecksumAlgorithm_SHA1:   sha1.New,
+	pb.ChecksumAlgorithm_SHA256: sha256.New,
+	pb.ChecksumAlgorithm_SHA512: sha512.New,
+	pb.ChecksumAlgorithm_BLAKE2B_256: func() hash.Hash {
+		// Only fails for a key longer than 64 bytes
+		h, _ := blake2b.New256(nil)
+		return h
+	},
+	pb.ChecksumAlgorithm_CRC32: func() hash.Hash { return crc32.NewIEEE() },
+}
+
+// contextReader is a reader that fails once ctx is done
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (reader contextReader) Read(p []byte) (int, error) {
+	if err := reader.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return reader.reader.Read(p)
+}
+
+// checksumFile hashes the file at path with algorithm, reusing the checksum
+// of an indexed file that did not change since it was cached
+func (indexer *Indexer) checksumFile(ctx context.Context, path string, algorithm pb.ChecksumAlgorithm) *pb.FileChecksum {
+	// Only indexed files are cached, the crawler invalidates them on change
+	if _, ok := indexer.cache.Get(path); ok {
+		return checksumFile(ctx, path, algorithm, indexer.checksums)
+	}
+	return checksumFile(ctx, path, algorithm, nil)
+}
+
+// checksumFile hashes the file at path with algorithm, the checksum is read
+// from and saved to cache unless it is nil. The hashing stops when ctx is
+// done, errors are reported in the result with a gRPC code.
+func checksumFile(ctx context.Context, path string, algorithm pb.ChecksumAlgorithm, cache *checksumCache) *pb.FileChecksum {
+	result := &pb.FileChecksum{Path: path, Algorithm: algorithm}
+	fail := func(code codes.Code, err error) *pb.FileChecksum {
+		result.ErrorCode = int32(code)
+		result.Error = err.Error()
+		return result
+	}
+	if !filepath.IsAbs(path) {
+		return fail(codes.InvalidArgument, fmt.Errorf("%s is not an absolute path", path))
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return fail(fileErrorCode(err), err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return fail(fileErrorCode(err), err)
+	}
+	if !info.Mode().IsRegular() {
+		return fail(codes.InvalidArgument, fmt.Errorf("%s is not a regular file", path))
+	}
+	if cache != nil {
+		if checksum, ok := cache.Get(path, info, algorithm); ok {
+			result.Size = info.Size()
+			result.Checksum = checksum
+			return result
+		}
+	}
+
+	h := checksumHashes[algorithm]()
+	result.Size, err = io.Copy(h, contextReader{ctx: ctx, reader: file})
+	if err != nil {
+		return fail(fileErrorCode(err), err)
+	}
+	result.Checksum = fmt.Sprintf("%x", h.Sum(nil))
+	if cache != nil {
+		cache.Put(path, info, algorithm, result.Checksum)
+	}
+	return result
+}
+
+// fileErrorCode returns the gRPC code of an error reading a file
+func fileErrorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case os.IsNotExist(err):
+		return codes.NotFound
+	case os.IsPermission(err):
+		return codes.PermissionDenied
+	default:
+		return codes.Internal
+	}
+}
+
 // function to calculate the hash of a file with a specific hash function (MD5, SHA1, SHA256)
 func calculateHash(filePath string, hashFunc func() hash.Hash) (string, error) {
 	file, err := os.Open(filePath)
@@ -23,3 +138,40 @@ func calculateHash(filePath string, hashFunc func() hash.Hash) (string, error) {
 	hashBytes := h.Sum(nil)
 	return fmt.Sprintf("%x", hashBytes), nil
 }
+
+// partialHashSize is the number of bytes hashed at each end of a file by
+// calculatePartialHash
+const partialHashSize = 64 << 10
+
+// calculatePartialHash returns the SHA-256 of the first and last
+// partialHashSize bytes of a file of size bytes. A file of at most twice
+// partialHashSize bytes is hashed whole, its partial hash is its content hash.
+func calculatePartialHash(filePath string, size int64) (string, error) {
+	if size <= 2*partialHashSize {
+		return calculateHash(filePath, sha256.New)
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(file, 0, partialHashSize)); err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(h, io.NewSectionReader(file, size-partialHashSize, partialHashSize)); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// calculateHashes returns the partial and content hashes of a file of size bytes
+func calculateHashes(filePath string, size int64) (partialHash, contentHash string, err error) {
+	partialHash, err = calculatePartialHash(filePath, size)
+	if err != nil || size <= 2*partialHashSize {
+		return partialHash, partialHash, err
+	}
+	contentHash, err = calculateHash(filePath, sha256.New)
+	return partialHash, contentHash, err
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"io/fs"
+	"sync"
+	"sync/atomic"
+	"training/file-index/pb"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// checksumCache keeps the checksums computed for the indexed files by path.
+// A cached checksum is used while the size, modification time and inode of
+// the file are unchanged. The cache is saved with the crawl state.
+type checksumCache struct {
+	mutex   sync.RWMutex
+	entries map[string]*pb.CachedChecksum
+	// version is incremented on every change
+	version uint64
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+func newChecksumCache() *checksumCache {
+	return &checksumCache{
+		entries: make(map[string]*pb.CachedChecksum),
+	}
+}
+
+// sameFile reports whether entry was computed for the file described by info
+func sameFile(entry *pb.CachedChecksum, info fs.FileInfo) bool {
+	return entry.Size == info.Size() &&
+		entry.ModifiedAt.AsTime().Equal(info.ModTime()) &&
+		entry.Inode == fileInode(info)
+}
+
+// Get returns the cached checksum of path with algorithm, info is the
+// current state of the file
+func (cache *checksumCache) Get(path string, info fs.FileInfo, algorithm pb.ChecksumAlgorithm) (string, bool) {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	entry, ok := cache.entries[path]
+	if ok && sameFile(entry, info) {
+		if checksum, ok := entry.Checksums[int32(algorithm)]; ok {
+			cache.hits.Add(1)
+			return checksum, true
+		}
+	}
+	cache.misses.Add(1)
+	return "", false
+}
+
+// Put caches the checksum of path computed with algorithm, info is the
+// state of the file before it was hashed
+func (cache *checksumCache) Put(path string, info fs.FileInfo, algorithm pb.ChecksumAlgorithm, checksum string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	entry, ok := cache.entries[path]
+	if !ok || !sameFile(entry, info) {
+		entry = &pb.CachedChecksum{
+			Path:       path,
+			Size:       info.Size(),
+			ModifiedAt: timestamppb.New(info.ModTime()),
+			Inode:      fileInode(info),
+			Checksums:  make(map[int32]string),
+		}
+		cache.entries[path] = entry
+	}
+	entry.Checksums[int32(algorithm)] = checksum
+	cache.version++
+}
+
+// Invalidate drops the checksums of path
+func (cache *checksumCache) Invalidate(path string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if _, ok := cache.entries[path]; !ok {
+		return
+	}
+	delete(cache.entries, path)
+	cache.invalidations.Add(1)
+	cache.version++
+}
+
+// Restore replaces the cached checksums with entries
+func (cache *checksumCache) Restore(entries []*pb.CachedChecksum) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	clear(cache.entries)
+	for _, entry := range entries {
+		if entry.Checksums == nil {
+			entry.Checksums = make(map[int32]string)
+		}
+		cache.entries[entry.Path] = entry
+	}
+	cache.version++
+}
+
+// Entries returns a copy of the cached checksums
+func (cache *checksumCache) Entries() []*pb.CachedChecksum {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	entries := make([]*pb.CachedChecksum, 0, len(cache.entries))
+	for _, entry := range cache.entries {
+		entries = append(entries, proto.Clone(entry).(*pb.CachedChecksum))
+	}
+	return entries
+}
+
+// Version returns a counter that changes whenever the cache changes
+func (cache *checksumCache) Version() uint64 {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	return cache.version
+}
+
+// Stats returns the lookup counters of the cache
+func (cache *checksumCache) Stats() *pb.ChecksumCacheStats {
+	cache.mutex.RLock()
+	entries := len(cache.entries)
+	cache.mutex.RUnlock()
+
+	return &pb.ChecksumCacheStats{
+		Hits:          cache.hits.Load(),
+		Misses:        cache.misses.Load(),
+		Invalidations: cache.invalidations.Load(),
+		Entries:       int64(entries),
+	}
+}
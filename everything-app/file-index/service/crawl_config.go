@@ -0,0 +1,137 @@
+package service
+
+import (
+	"path/filepath"
+	"runtime"
+	"training/file-index/pb"
+
+	"github.com/spf13/viper"
+)
+
+// CrawlConfig controls which parts of the file system are indexed.
+// The values are read by viper from a config file or set per request.
+type CrawlConfig struct {
+	// Roots are the absolute paths to index
+	Roots []string `mapstructure:"roots"`
+	// Include and Exclude are gitignore-style patterns relative to each
+	// root. When Include is not empty only matching files are indexed.
+	Include []string `mapstructure:"include"`
+	Exclude []string `mapstructure:"exclude"`
+	// MaxDepth is the number of directory levels below a root to descend
+	// into, 0 means unlimited. 1 indexes the files of the root and of its
+	// subdirectories, but not the files of their subdirectories.
+	MaxDepth int `mapstructure:"max_depth"`
+	// OneFileSystem skips directories on another device than their root
+	OneFileSystem bool `mapstructure:"one_file_system"`
+	// SkipNetworkMounts skips NFS, SMB and other network file systems
+	SkipNetworkMounts bool `mapstructure:"skip_network_mounts"`
+	// SkipPaths are absolute paths that are never indexed
+	SkipPaths []string `mapstructure:"skip_paths"`
+	// DirectoryDigests keeps a Merkle digest of every indexed directory. It
+	// hashes every indexed file once, then only the new and modified files.
+	DirectoryDigests bool `mapstructure:"directory_digests"`
+	// MaxContentBytes is the number of bytes of content indexed per file,
+	// longer content is truncated. 0 means unlimited.
+	MaxContentBytes int64 `mapstructure:"max_content_bytes"`
+	// Extractors overrides the size and time limits of the content
+	// extractors by name
+	Extractors map[string]ExtractorLimits `mapstructure:"extractors"`
+	// Archives indexes the files inside archives
+	Archives ArchiveConfig `mapstructure:"archives"`
+	// Pipeline sizes the stages of the crawler
+	Pipeline PipelineConfig `mapstructure:"pipeline"`
+}
+
+// PipelineConfig sets the number of workers of each crawl stage and the size
+// of the queues between them. Zero values are replaced by defaults.
+type PipelineConfig struct {
+	// WalkWorkers is the number of roots enumerated in parallel
+	WalkWorkers    int `mapstructure:"walk_workers"`
+	StatWorkers    int `mapstructure:"stat_workers"`
+	ExtractWorkers int `mapstructure:"extract_workers"`
+	StoreWorkers   int `mapstructure:"store_workers"`
+	QueueSize      int `mapstructure:"queue_size"`
+}
+
+func (config PipelineConfig) withDefaults() PipelineConfig {
+	if config.WalkWorkers <= 0 {
+		config.WalkWorkers = 4
+	}
+	if config.StatWorkers <= 0 {
+		config.StatWorkers = 8
+	}
+	if config.ExtractWorkers <= 0 {
+		config.ExtractWorkers = runtime.NumCPU()
+	}
+	if config.StoreWorkers <= 0 {
+		config.StoreWorkers = 2
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 256
+	}
+	return config
+}
+
+// DefaultMaxContentBytes keeps the index small when a root holds large logs
+const DefaultMaxContentBytes = 1 << 20
+
+// DefaultSkipPaths are the virtual file systems that are never worth indexing
+var DefaultSkipPaths = []string{"/proc", "/sys", "/dev", "/run"}
+
+// DefaultCrawlConfig returns the configuration used when no config file is given.
+// It indexes the drive folders found in the working directory.
+func DefaultCrawlConfig() CrawlConfig {
+	var roots []string
+	for _, drive := range getAvailableDrives() {
+		if root, err := filepath.Abs(drive); err == nil {
+			roots = append(roots, root)
+		}
+	}
+	return CrawlConfig{
+		Roots:             roots,
+		SkipNetworkMounts: true,
+		SkipPaths:         DefaultSkipPaths,
+		MaxContentBytes:   DefaultMaxContentBytes,
+	}
+}
+
+// LoadCrawlConfig reads the crawl configuration from a YAML, JSON or TOML file
+func LoadCrawlConfig(path string) (config CrawlConfig, err error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetDefault("skip_network_mounts", true)
+	v.SetDefault("skip_paths", DefaultSkipPaths)
+	v.SetDefault("max_content_bytes", DefaultMaxContentBytes)
+
+	err = v.ReadInConfig()
+	if err != nil {
+		return
+	}
+
+	err = v.Unmarshal(&config)
+	return
+}
+
+// WithRequest returns a copy of the configuration overridden by the crawl
+// options set on a ListFiles request
+func (config CrawlConfig) WithRequest(req *pb.CreateFileDiscoverRequest) CrawlConfig {
+	if len(req.GetRoots()) > 0 {
+		config.Roots = req.GetRoots()
+	}
+	if len(req.GetInclude()) > 0 {
+		config.Include = req.GetInclude()
+	}
+	if len(req.GetExclude()) > 0 {
+		config.Exclude = req.GetExclude()
+	}
+	if req.GetMaxDepth() > 0 {
+		config.MaxDepth = int(req.GetMaxDepth())
+	}
+	if req.GetOneFileSystem() {
+		config.OneFileSystem = true
+	}
+	if req.GetSkipNetworkMounts() {
+		config.SkipNetworkMounts = true
+	}
+	return config
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"training/file-index/pb"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// CrawlState saves the indexed files and crawl checkpoints to a snapshot file
+// so the indexer resumes after a restart instead of indexing everything again
+type CrawlState struct {
+	mutex sync.Mutex
+	path  string
+}
+
+// NewCrawlState returns a CrawlState saving snapshots to path
+func NewCrawlState(path string) *CrawlState {
+	return &CrawlState{
+		path: path,
+	}
+}
+
+// Load reads the last saved snapshot, an empty snapshot is returned if
+// none was saved yet
+func (state *CrawlState) Load() (*pb.CrawlSnapshot, error) {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	data, err := os.ReadFile(state.path)
+	if os.IsNotExist(err) {
+		return &pb.CrawlSnapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &pb.CrawlSnapshot{}
+	if err := proto.Unmarshal(data, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Save writes the snapshot, its files are saved without content. The file is
+// replaced atomically so a crash while saving leaves the previous snapshot in
+// place.
+func (state *CrawlState) Save(snapshot *pb.CrawlSnapshot) error {
+	snapshot = proto.Clone(snapshot).(*pb.CrawlSnapshot)
+	snapshot.SavedAt = timestamppb.Now()
+	for _, fileAttr := range snapshot.Files {
+		// Content is in the store, the snapshot only needs what change detection uses
+		fileAttr.Content = ""
+	}
+	data, err := proto.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(state.path), filepath.Base(state.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), state.path)
+}
+
+// checkpointTracker follows the files of one root through the crawl
+// pipeline. Files finish out of order, the checkpoint is the last path in
+// walk order up to which every file has finished.
+type checkpointTracker struct {
+	mutex    sync.Mutex
+	root     string
+	next     uint64
+	low      uint64
+	paths    map[uint64]string
+	done     map[uint64]bool
+	lastPath string
+}
+
+func newCheckpointTracker(root string) *checkpointTracker {
+	return &checkpointTracker{
+		root:  root,
+		paths: make(map[uint64]string),
+		done:  make(map[uint64]bool),
+	}
+}
+
+// add registers the next file in walk order and returns its sequence number
+func (tracker *checkpointTracker) add(path string) uint64 {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	seq := tracker.next
+	tracker.next++
+	tracker.paths[seq] = path
+	return seq
+}
+
+// finish marks a file as done and advances the checkpoint
+func (tracker *checkpointTracker) finish(seq uint64) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	tracker.done[seq] = true
+	for tracker.done[tracker.low] {
+		tracker.lastPath = tracker.paths[tracker.low]
+		delete(tracker.done, tracker.low)
+		delete(tracker.paths, tracker.low)
+		tracker.low++
+	}
+}
+
+func (tracker *checkpointTracker) checkpoint() *pb.CrawlCheckpoint {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	return &pb.CrawlCheckpoint{
+		Root:     tracker.root,
+		LastPath: tracker.lastPath,
+	}
+}
+
+// walkOrderLess reports whether filepath.WalkDir visits path a before path b
+func walkOrderLess(a, b string) bool {
+	as := strings.Split(a, string(filepath.Separator))
+	bs := strings.Split(b, string(filepath.Separator))
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] != bs[i] {
+			return as[i] < bs[i]
+		}
+	}
+	return len(as) < len(bs)
+}
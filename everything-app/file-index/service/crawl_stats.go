@@ -0,0 +1,55 @@
+package service
+
+import (
+	"sync/atomic"
+	"time"
+	"training/file-index/pb"
+)
+
+// stageStats counts the work done by one stage of the crawl pipeline
+type stageStats struct {
+	name      string
+	workers   atomic.Int32
+	processed atomic.Uint64
+	failed    atomic.Uint64
+	queued    atomic.Int64
+}
+
+// CrawlStats holds the throughput counters of the crawl pipeline stages.
+// Counters accumulate over every crawl since the server started.
+type CrawlStats struct {
+	started   time.Time
+	enumerate stageStats
+	stat      stageStats
+	extract   stageStats
+	store     stageStats
+}
+
+// NewCrawlStats returns zeroed crawl counters
+func NewCrawlStats() *CrawlStats {
+	return &CrawlStats{
+		started:   time.Now(),
+		enumerate: stageStats{name: "enumerate"},
+		stat:      stageStats{name: "stat"},
+		extract:   stageStats{name: "extract"},
+		store:     stageStats{name: "store"},
+	}
+}
+
+// Stages returns a snapshot of the counters of every stage in pipeline order
+func (stats *CrawlStats) Stages() []*pb.StageStats {
+	elapsed := time.Since(stats.started).Seconds()
+	var stages []*pb.StageStats
+	for _, stage := range []*stageStats{&stats.enumerate, &stats.stat, &stats.extract, &stats.store} {
+		processed := stage.processed.Load()
+		stages = append(stages, &pb.StageStats{
+			Stage:     stage.name,
+			Workers:   stage.workers.Load(),
+			Processed: processed,
+			Failed:    stage.failed.Load(),
+			Queued:    stage.queued.Load(),
+			PerSecond: float64(processed) / elapsed,
+		})
+	}
+	return stages
+}
@@ -0,0 +1,430 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"training/file-index/pb"
+)
+
+// crawlJob is a file travelling through the crawl pipeline
+type crawlJob struct {
+	path   string
+	info   fs.FileInfo
+	cached *pb.FileAttr
+	// fileAttr is only set once a new or modified file has been extracted
+	fileAttr *pb.FileAttr
+	tracker  *checkpointTracker
+	seq      uint64
+	// archive is set for an extracted archive, members are the members read
+	// from it when archives are indexed
+	archive bool
+	members []*pb.FileAttr
+}
+
+// changed reports whether the file is new or modified since it was cached
+func (job *crawlJob) changed() bool {
+	if job.cached == nil {
+		return true
+	}
+	inode := fileInode(job.info)
+	return job.cached.Size != job.info.Size() ||
+		!job.cached.ModifiedAt.AsTime().Equal(job.info.ModTime()) ||
+		(inode != 0 && job.cached.Inode != 0 && job.cached.Inode != inode)
+}
+
+// contentChanged reports whether an extracted file has other content than
+// the cached version, a touched but unchanged file is not a real change
+func (job *crawlJob) contentChanged() bool {
+	return job.cached == nil ||
+		job.cached.ContentHash == "" ||
+		job.cached.ContentHash != job.fileAttr.ContentHash
+}
+
+// finish marks the job as done for the crawl checkpoint
+func (job *crawlJob) finish() {
+	if job.tracker != nil {
+		job.tracker.finish(job.seq)
+	}
+}
+
+// crawl walks every root once, saving new and modified files and deleting
+// files that are gone since the previous crawl. Every change is published to
+// the change feed.
+//
+// The crawl runs as a pipeline: enumerate -> stat -> extract -> store. Stages
+// are joined by bounded queues so a slow store or stream consumer blocks the
+// walk instead of buffering the whole file system in memory.
+//
+// Progress is checkpointed to the crawl state every checkpointInterval. A
+// crawl interrupted by a crash resumes after the checkpoint of each root.
+func (indexer *Indexer) crawl(ctx context.Context) error {
+	walker := indexer.walker
+	pipeline := walker.config.Pipeline.withDefaults()
+	stats := indexer.stats
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		errOnce  sync.Once
+		crawlErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			crawlErr = err
+			cancel()
+		})
+	}
+
+	roots := make(chan string, len(walker.Roots()))
+	for _, root := range walker.Roots() {
+		roots <- root
+	}
+	close(roots)
+
+	paths := make(chan *crawlJob, pipeline.QueueSize)
+	stated := make(chan *crawlJob, pipeline.QueueSize)
+	extracted := make(chan *crawlJob, pipeline.QueueSize)
+
+	var seenMutex sync.Mutex
+	seen := make(map[string]struct{})
+	markSeen := func(path string) {
+		seenMutex.Lock()
+		defer seenMutex.Unlock()
+		seen[path] = struct{}{}
+	}
+
+	var trackersMutex sync.Mutex
+	var trackers []*checkpointTracker
+	checkpoints := func() []*pb.CrawlCheckpoint {
+		trackersMutex.Lock()
+		defer trackersMutex.Unlock()
+		var checkpoints []*pb.CrawlCheckpoint
+		for _, tracker := range trackers {
+			checkpoints = append(checkpoints, tracker.checkpoint())
+		}
+		return checkpoints
+	}
+	stopCheckpoints := indexer.saveCheckpoints(checkpoints)
+	defer stopCheckpoints()
+
+	// enumerate: list the directories of every root
+	runStage(&stats.enumerate, pipeline.WalkWorkers, paths, func() {
+		for root := range roots {
+			tracker := newCheckpointTracker(root)
+			trackersMutex.Lock()
+			trackers = append(trackers, tracker)
+			trackersMutex.Unlock()
+
+			// Everything up to resumeAfter was stored before the last crawl was interrupted
+			resumeAfter := indexer.takeCheckpoint(root)
+			tracker.lastPath = resumeAfter
+			onDir := func(path string) error {
+				if resumeAfter == "" || !walkOrderLess(path, resumeAfter) || isWithin(path, resumeAfter) {
+					return nil
+				}
+				for _, fileAttr := range indexer.cache.Select(func(cachedPath string) bool { return isWithin(path, cachedPath) }) {
+					markSeen(fileAttr.Path)
+				}
+				return filepath.SkipDir
+			}
+			err := walker.WalkDir(root, onDir, func(path string, d fs.DirEntry) error {
+				stats.enumerate.processed.Add(1)
+				if resumeAfter != "" && !walkOrderLess(resumeAfter, path) {
+					markSeen(path)
+					return nil
+				}
+				job := &crawlJob{path: path, tracker: tracker, seq: tracker.add(path)}
+				return enqueue(ctx, paths, job, &stats.stat)
+			})
+			if err != nil {
+				fail(err)
+			}
+		}
+	})
+
+	// stat: read size and times, unchanged files skip extraction
+	runStage(&stats.stat, pipeline.StatWorkers, stated, func() {
+		for job := range paths {
+			stats.stat.queued.Add(-1)
+			if ctx.Err() != nil {
+				continue
+			}
+			info, err := os.Lstat(job.path)
+			if err != nil {
+				// Removed since it was listed
+				stats.stat.failed.Add(1)
+				job.finish()
+				continue
+			}
+			stats.stat.processed.Add(1)
+			markSeen(job.path)
+
+			job.info = info
+			job.cached, _ = indexer.cache.Get(job.path)
+			next, stage := stated, &stats.extract
+			if !job.changed() && !indexer.membersStale(job) {
+				next, stage = extracted, &stats.store
+			}
+			if err := enqueue(ctx, next, job, stage); err != nil {
+				fail(err)
+			}
+		}
+	})
+
+	// extract: read timestamps, attributes and document content
+	runStage(&stats.extract, pipeline.ExtractWorkers, extracted, func() {
+		for job := range stated {
+			stats.extract.queued.Add(-1)
+			if ctx.Err() != nil {
+				continue
+			}
+			if err := indexer.extractFile(ctx, job); err != nil {
+				stats.extract.failed.Add(1)
+				job.finish()
+				continue
+			}
+			stats.extract.processed.Add(1)
+			if err := enqueue(ctx, extracted, job, &stats.store); err != nil {
+				fail(err)
+			}
+		}
+	})
+
+	// store: write changes to the store and publish them
+	done := make(chan struct{})
+	runStage(&stats.store, pipeline.StoreWorkers, done, func() {
+		for job := range extracted {
+			stats.store.queued.Add(-1)
+			if ctx.Err() != nil {
+				continue
+			}
+			if err := indexer.storeFile(job); err != nil {
+				stats.store.failed.Add(1)
+				fail(err)
+				continue
+			}
+			stats.store.processed.Add(1)
+			job.finish()
+		}
+	})
+	<-done
+	stopCheckpoints()
+
+	if crawlErr != nil {
+		return crawlErr
+	}
+	// The stages skip the queued files once ctx is done, so an unseen file
+	// may still be on disk. The next crawl resumes after the checkpoints,
+	// which only cover flushed files.
+	if err := ctx.Err(); err != nil {
+		if indexer.flush() == nil {
+			indexer.saveState(checkpoints())
+		}
+		return err
+	}
+
+	// Check file deleted, archive members are seen with their archive
+	deleted := indexer.cache.Select(func(path string) bool {
+		_, exists := seen[archiveOf(path)]
+		return !exists && walker.rootsContain(path)
+	})
+	for _, fileAttr := range deleted {
+		if err := indexer.deleteFile(fileAttr); err != nil {
+			return err
+		}
+	}
+	if err := indexer.flush(); err != nil {
+		return err
+	}
+	if err := indexer.hashFiles(ctx); err != nil {
+		return err
+	}
+	// The crawl is complete, the next one has nothing to resume
+	return indexer.saveState(nil)
+}
+
+// indexFile runs a single file through the stat, extract and store stages
+func (indexer *Indexer) indexFile(path string, info fs.FileInfo) error {
+	cached, _ := indexer.cache.Get(path)
+	job := &crawlJob{path: path, info: info, cached: cached}
+	if job.changed() || indexer.membersStale(job) {
+		if err := indexer.extractFile(context.Background(), job); err != nil {
+			return nil
+		}
+	}
+	return indexer.storeFile(job)
+}
+
+// extractFile reads the attributes and content of a new or modified file.
+// A file whose content cannot be extracted is indexed without content. The
+// file is only hashed if the cached version was, so a touched file is
+// recognised, the other files are hashed by hashDuplicates when another file
+// has the same size.
+func (indexer *Indexer) extractFile(ctx context.Context, job *crawlJob) error {
+	fileAttr, err := newFileAttr(job.path, job.info)
+	if err == nil {
+		document, extractErr := indexer.extractors.Extract(ctx, job.path, job.info)
+		if extractErr != nil && ctx.Err() == nil {
+			fmt.Printf("Failed to extract the content of %s: %v\n", job.path, extractErr)
+		}
+		if document != nil {
+			fileAttr.Content = document.Content
+			fileAttr.Metadata = document.Metadata
+		}
+		if archiveFormatOf(job.path) != notArchive {
+			job.archive = true
+			if indexer.walker.config.Archives.Enabled {
+				job.members = indexer.readArchive(ctx, job)
+				if fileAttr.Metadata == nil {
+					fileAttr.Metadata = make(map[string]string)
+				}
+				fileAttr.Metadata[MetaMembers] = strconv.Itoa(len(job.members))
+			}
+		}
+	}
+	if err == nil && job.cached != nil && job.cached.ContentHash != "" {
+		fileAttr.PartialHash, fileAttr.ContentHash, err = calculateHashes(job.path, fileAttr.Size)
+	}
+	if err != nil {
+		fmt.Printf("Failed to read %s file: %s, error: %v\n", filepath.Ext(job.path), job.path, err)
+		return err
+	}
+	job.fileAttr = fileAttr
+	return nil
+}
+
+// storeFile saves extracted files and publishes the change. A new file with
+// the inode of a cached file that no longer exists is published as a rename.
+func (indexer *Indexer) storeFile(job *crawlJob) error {
+	if job.fileAttr == nil {
+		return nil
+	}
+	if err := indexer.saveFile(job); err != nil {
+		return err
+	}
+	if job.archive {
+		return indexer.storeMembers(job)
+	}
+	return nil
+}
+
+func (indexer *Indexer) saveFile(job *crawlJob) error {
+	if job.cached != nil {
+		if err := indexer.handleStoreError(indexer.fileStore.Update(job.fileAttr)); err != nil {
+			return err
+		}
+		indexer.cache.Put(job.fileAttr)
+		indexer.checksums.Invalidate(job.path)
+		if job.contentChanged() {
+			indexer.feed.Publish(pb.ChangeType_MODIFIED, job.fileAttr, "")
+		}
+		return nil
+	}
+
+	renamed := indexer.renamedFrom(job.fileAttr)
+	if renamed != nil {
+		if err := indexer.handleStoreError(indexer.fileStore.Delete(renamed.Path)); err != nil {
+			return err
+		}
+		indexer.cache.Delete(renamed.Path)
+		indexer.checksums.Invalidate(renamed.Path)
+	}
+	if err := indexer.handleStoreError(indexer.fileStore.Save(job.fileAttr)); err != nil {
+		return err
+	}
+	indexer.cache.Put(job.fileAttr)
+	if renamed != nil {
+		indexer.feed.Publish(pb.ChangeType_RENAMED, job.fileAttr, renamed.Path)
+		return nil
+	}
+	indexer.feed.Publish(pb.ChangeType_CREATED, job.fileAttr, "")
+	return nil
+}
+
+// storeMembers brings the stored members of an archive in line with the ones
+// just read from it. Unchanged members are the cached attributes themselves.
+func (indexer *Indexer) storeMembers(job *crawlJob) error {
+	listed := make(map[string]struct{}, len(job.members))
+	for _, member := range job.members {
+		listed[member.Path] = struct{}{}
+		cached, ok := indexer.cache.Get(member.Path)
+		if ok && cached == member {
+			continue
+		}
+		if err := indexer.saveFile(&crawlJob{path: member.Path, cached: cached, fileAttr: member}); err != nil {
+			return err
+		}
+	}
+	if job.cached == nil {
+		return nil
+	}
+	prefix := job.path + ArchiveSeparator
+	for _, fileAttr := range indexer.cache.Select(func(path string) bool { return strings.HasPrefix(path, prefix) }) {
+		if _, ok := listed[fileAttr.Path]; ok {
+			continue
+		}
+		if err := indexer.deleteFile(fileAttr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// membersStale reports whether an unchanged archive has to be read again
+// because the indexing of archives was turned on or off since it was stored
+func (indexer *Indexer) membersStale(job *crawlJob) bool {
+	if job.cached == nil || archiveFormatOf(job.path) == notArchive {
+		return false
+	}
+	_, indexed := job.cached.Metadata[MetaMembers]
+	return indexed != indexer.walker.config.Archives.Enabled
+}
+
+// renamedFrom returns the cached file that fileAttr was renamed from, if any
+func (indexer *Indexer) renamedFrom(fileAttr *pb.FileAttr) *pb.FileAttr {
+	cached, ok := indexer.cache.GetByInode(fileAttr.Inode)
+	if !ok || cached.Path == fileAttr.Path {
+		return nil
+	}
+	if _, err := os.Lstat(cached.Path); !os.IsNotExist(err) {
+		// Still there, a hard link or a reused inode
+		return nil
+	}
+	return cached
+}
+
+// runStage starts workers goroutines running fn and closes out once all of
+// them have returned
+func runStage[T any](stage *stageStats, workers int, out chan T, fn func()) {
+	stage.workers.Store(int32(workers))
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn()
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+}
+
+// enqueue sends item to queue unless ctx is done, blocking while the queue is full
+func enqueue[T any](ctx context.Context, queue chan<- T, item T, next *stageStats) error {
+	next.queued.Add(1)
+	select {
+	case queue <- item:
+		return nil
+	case <-ctx.Done():
+		next.queued.Add(-1)
+		return ctx.Err()
+	}
+}
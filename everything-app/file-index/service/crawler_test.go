@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	"training/db/filequery"
+	"training/db/memdb"
+	db "training/db/sqlc"
+	"training/file-index/pb"
+)
+
+// newTestIndexer returns an indexer of config storing into a memdb store,
+// without content extractors
+func newTestIndexer(t *testing.T, config CrawlConfig) (*Indexer, *memdb.Store) {
+	t.Helper()
+	store := memdb.NewStore()
+	indexer, err := NewIndexer(NewInMemoryFileStore(store), config, IndexModeRescan, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexer.SetExtractors(NewExtractorRegistry())
+	return indexer, store
+}
+
+// writeFiles creates count files in dir and returns their paths
+func writeFiles(t *testing.T, dir string, count int) []string {
+	t.Helper()
+	paths := make([]string, count)
+	for i := range paths {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("file%03d.txt", i))
+		if err := os.WriteFile(paths[i], []byte(paths[i]), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+// storedPaths returns the number of files stored below dir
+func storedPaths(t *testing.T, indexer *Indexer, dir string) int {
+	t.Helper()
+	files, err := indexer.fileStore.List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(files)
+}
+
+// changesOf returns the published changes of type changeType
+func changesOf(indexer *Indexer, changeType pb.ChangeType) []*pb.FileChange {
+	changes, _, _ := indexer.feed.Since(0)
+	var matching []*pb.FileChange
+	for _, change := range changes {
+		if change.GetType() == changeType {
+			matching = append(matching, change)
+		}
+	}
+	return matching
+}
+
+func TestCrawlCancelledDeletesNothing(t *testing.T) {
+	root := t.TempDir()
+	paths := writeFiles(t, root, 1)
+	indexer, _ := newTestIndexer(t, CrawlConfig{Roots: []string{root}})
+	if err := indexer.crawl(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := storedPaths(t, indexer, root); n != len(paths) {
+		t.Fatalf("stored %d files, want %d", n, len(paths))
+	}
+
+	// enqueue picks a free queue or the done context at random, so about
+	// every other crawl enumerates the file and then skips it in the stat
+	// stage
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 30; i++ {
+		if err := indexer.crawl(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("crawl returned %v, want %v", err, context.Canceled)
+		}
+	}
+
+	if n := storedPaths(t, indexer, root); n != len(paths) {
+		t.Errorf("stored %d files after the cancelled crawls, want %d", n, len(paths))
+	}
+	if deleted := changesOf(indexer, pb.ChangeType_DELETED); len(deleted) > 0 {
+		t.Errorf("cancelled crawls published %d deletions of %s", len(deleted), deleted[0].GetFile().GetPath())
+	}
+}
+
+// TestCrawlMetadataFilter checks that the metadata extracted from photos and
+// music files is found by the meta filters of the stores
+func TestCrawlMetadataFilter(t *testing.T) {
+	root := t.TempDir()
+	for name, data := range map[string][]byte{
+		"photo.jpg": jpegData(photoTIFF(binary.LittleEndian, false)),
+		"song.mp3": id3Tag(3, 0,
+			id3Frame(3, "TPE1", 0, []byte("\x00Sigur R\xf3s")),
+			id3Frame(3, "TYER", 0, []byte("\x002005")),
+			id3Frame(3, "TCON", 0, []byte("\x00(17)")),
+		),
+		"song.flac": append([]byte("fLaC"), flacBlock(4, true, vorbisComment("ARTIST=Sigur Rós", "DATE=2005", "GENRE=Rock"))...),
+		"notes.txt": []byte("Sigur Rós"),
+	} {
+		if err := os.WriteFile(filepath.Join(root, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	indexer, store := newTestIndexer(t, CrawlConfig{Roots: []string{root}})
+	indexer.SetExtractors(DefaultExtractors)
+	if err := indexer.crawl(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	names := func(rows []db.GetFilesRow) string {
+		var names []string
+		for _, row := range rows {
+			names = append(names, row.Name)
+		}
+		sort.Strings(names)
+		return fmt.Sprint(names)
+	}
+
+	for query, want := range map[string]string{
+		`meta.camera:"FUJIFILM X100V"`:                            "[photo.jpg]",
+		`meta.taken_at:2023-07-14T18:30:05+02:00`:                 "[photo.jpg]",
+		`meta.gps_latitude:48.858333 meta.gps_longitude:2.294444`: "[photo.jpg]",
+		`meta.artist:"Sigur Rós"`:                                 "[song.flac song.mp3]",
+		`meta.genre:Rock !ext:mp3`:                                "[song.flac]",
+		`meta.artist:"Sigur"`:                                     "[]",
+		`meta.album:Takk...`:                                      "[]",
+	} {
+		node, err := filequery.Parse(query, time.Now())
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", query, err)
+		}
+		rows, err := store.QueryFiles(context.Background(), db.QueryFilesParams{Query: node})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := names(rows); got != want {
+			t.Errorf("QueryFiles(%q) = %s, want %s", query, got, want)
+		}
+	}
+
+	for _, test := range []struct {
+		metadata map[string]string
+		want     string
+	}{
+		{map[string]string{MetaArtist: "Sigur Rós", MetaDate: "2005"}, "[song.flac song.mp3]"},
+		{map[string]string{MetaCamera: "FUJIFILM X100V"}, "[photo.jpg]"},
+		{map[string]string{MetaCamera: "Canon"}, "[]"},
+	} {
+		rows, err := store.GetFiles(context.Background(), db.GetFilesParams{
+			Metadata:       db.MetadataFilter(test.metadata),
+			SizeMax:        math.MaxInt64,
+			CreatedBefore:  time.Now().Add(time.Hour),
+			ModifiedBefore: time.Now().Add(time.Hour),
+			AccessedBefore: time.Now().Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := names(rows); got != test.want {
+			t.Errorf("GetFiles with metadata %v = %s, want %s", test.metadata, got, test.want)
+		}
+	}
+}
+
+// TestCrawlMaxDepth checks that the root is on depth 0, so max depth 1
+// indexes the files of the root and of its subdirectories
+func TestCrawlMaxDepth(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a/b/c", "d"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"root.txt", "a/a.txt", "a/b/b.txt", "a/b/c/c.txt", "d/d.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, test := range []struct {
+		maxDepth int
+		want     string
+	}{
+		{0, "[a/a.txt a/b/b.txt a/b/c/c.txt d/d.txt root.txt]"},
+		{1, "[a/a.txt d/d.txt root.txt]"},
+		{2, "[a/a.txt a/b/b.txt d/d.txt root.txt]"},
+	} {
+		indexer, _ := newTestIndexer(t, CrawlConfig{Roots: []string{root}, MaxDepth: test.maxDepth})
+		if err := indexer.crawl(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		files, err := indexer.fileStore.List(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var paths []string
+		for _, file := range files {
+			rel, _ := filepath.Rel(root, file.GetPath())
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		sort.Strings(paths)
+		if got := fmt.Sprint(paths); got != test.want {
+			t.Errorf("max depth %d indexed %s, want %s", test.maxDepth, got, test.want)
+		}
+
+		// The files of the events are accepted on the same depths
+		for _, name := range []string{"a/b/new.txt", "a/b/c/new.txt"} {
+			want := test.maxDepth == 0 || strings.Count(name, "/") <= test.maxDepth
+			if got := indexer.walker.AcceptFile(filepath.Join(root, name)); got != want {
+				t.Errorf("max depth %d: AcceptFile(%s) = %v, want %v", test.maxDepth, name, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+
+package service
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// deviceID returns the id of the device the file lives on
+func deviceID(info fs.FileInfo) (uint64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(stat.Dev), true
+}
+
+// fileInode returns the inode number of the file, 0 if unknown
+func fileInode(info fs.FileInfo) uint64 {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0
+	}
+	return uint64(stat.Ino)
+}
@@ -0,0 +1,14 @@
+package service
+
+import "io/fs"
+
+// deviceID is not available on Windows, every file is treated as being on
+// the same device as its root
+func deviceID(info fs.FileInfo) (uint64, bool) {
+	return 0, false
+}
+
+// fileInode is not available from os.FileInfo on Windows
+func fileInode(info fs.FileInfo) uint64 {
+	return 0
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"slices"
+	"training/file-index/pb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// hashCandidate is a cached file that may have a duplicate with its hashes
+type hashCandidate struct {
+	cached      *pb.FileAttr
+	partialHash string
+	contentHash string
+}
+
+// hashDuplicates hashes the cached files that may have a duplicate and stores
+// their hashes. Hashing is staged so only files that may be identical are
+// read: a file of a unique size is not hashed, files of the same size get a
+// partial hash of their ends and only files with the same size and partial
+// hash are hashed whole. Known hashes are kept, so after the first run only
+// new and modified files are read.
+func (indexer *Indexer) hashDuplicates(ctx context.Context) error {
+	if indexer.cache.Version() == indexer.hashedVersion.Load() {
+		return nil
+	}
+
+	bySize := make(map[int64][]*pb.FileAttr)
+	for _, fileAttr := range indexer.cache.Select(func(string) bool { return true }) {
+		// Empty files are all identical, they do not waste space. Archive
+		// members cannot be read on their own.
+		if fileAttr.Size > 0 && !isArchiveMember(fileAttr.Path) {
+			bySize[fileAttr.Size] = append(bySize[fileAttr.Size], fileAttr)
+		}
+	}
+
+	var hashed []hashCandidate
+	for size, files := range bySize {
+		if len(files) < 2 {
+			continue
+		}
+		byPartial := make(map[string][]hashCandidate)
+		for _, fileAttr := range files {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			candidate := hashCandidate{cached: fileAttr, partialHash: fileAttr.PartialHash, contentHash: fileAttr.ContentHash}
+			if candidate.partialHash == "" {
+				var err error
+				candidate.partialHash, err = calculatePartialHash(fileAttr.Path, size)
+				if err != nil {
+					// Removed or unreadable, the next crawl handles it
+					continue
+				}
+			}
+			byPartial[candidate.partialHash] = append(byPartial[candidate.partialHash], candidate)
+		}
+
+		for _, candidates := range byPartial {
+			for _, candidate := range candidates {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if candidate.contentHash == "" && size <= 2*partialHashSize {
+					candidate.contentHash = candidate.partialHash
+				}
+				if candidate.contentHash == "" && len(candidates) > 1 {
+					var err error
+					candidate.contentHash, err = calculateHash(candidate.cached.Path, sha256.New)
+					if err != nil {
+						continue
+					}
+				}
+				if candidate.partialHash != candidate.cached.PartialHash || candidate.contentHash != candidate.cached.ContentHash {
+					hashed = append(hashed, candidate)
+				}
+			}
+		}
+	}
+
+	if err := indexer.storeHashes(hashed); err != nil {
+		return err
+	}
+	indexer.hashedVersion.Store(indexer.cache.Version())
+	return nil
+}
+
+// storeHashes writes the hashes of the candidates to the cache and the store
+func (indexer *Indexer) storeHashes(hashed []hashCandidate) error {
+	for _, candidate := range hashed {
+		fileAttr := proto.Clone(candidate.cached).(*pb.FileAttr)
+		fileAttr.PartialHash = candidate.partialHash
+		fileAttr.ContentHash = candidate.contentHash
+		// The file changed while it was hashed, the hashes may be stale
+		if !indexer.cache.Replace(candidate.cached, fileAttr) {
+			continue
+		}
+		if err := indexer.handleStoreError(indexer.fileStore.UpdateHashes(fileAttr)); err != nil {
+			return err
+		}
+	}
+	return indexer.flush()
+}
+
+// duplicateGroups groups the files of at least minSize bytes with the same
+// content hash and size, the groups wasting the most bytes come first
+func duplicateGroups(files []*pb.FileAttr, minSize int64) []*pb.DuplicateGroup {
+	type groupKey struct {
+		hash string
+		size int64
+	}
+	byKey := make(map[groupKey]*pb.DuplicateGroup)
+	for _, fileAttr := range files {
+		if fileAttr.ContentHash == "" || fileAttr.Size < minSize {
+			continue
+		}
+		key := groupKey{hash: fileAttr.ContentHash, size: fileAttr.Size}
+		group, ok := byKey[key]
+		if !ok {
+			group = &pb.DuplicateGroup{ContentHash: key.hash, Size: key.size}
+			byKey[key] = group
+		}
+		group.Paths = append(group.Paths, fileAttr.Path)
+	}
+
+	groups := []*pb.DuplicateGroup{}
+	for _, group := range byKey {
+		if len(group.Paths) < 2 {
+			continue
+		}
+		slices.Sort(group.Paths)
+		group.WastedBytes = int64(len(group.Paths)-1) * group.Size
+		groups = append(groups, group)
+	}
+	slices.SortFunc(groups, func(a, b *pb.DuplicateGroup) int {
+		if c := cmp.Compare(b.WastedBytes, a.WastedBytes); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ContentHash, b.ContentHash)
+	})
+	return groups
+}
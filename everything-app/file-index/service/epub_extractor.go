@@ -0,0 +1,114 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path"
+)
+
+// The e-books
+func init() {
+	err := RegisterExtractor(ExtractorConfig{
+		Name:       "epub",
+		Extensions: []string{".epub"},
+		Extractor:  ExtractorFunc(readEPUBDocument),
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// epubPackage is the OPF file listing the metadata and the reading order of
+// an EPUB
+type epubPackage struct {
+	Titles   []string `xml:"metadata>title"`
+	Creators []string `xml:"metadata>creator"`
+	Items    []struct {
+		ID        string `xml:"id,attr"`
+		Href      string `xml:"href,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// readEPUBDocument reads the chapters of an EPUB in reading order, and its
+// title and first author
+func readEPUBDocument(ctx context.Context, filePath string) (*Document, error) {
+	archive, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+	container, ok := files["META-INF/container.xml"]
+	if !ok {
+		return nil, fmt.Errorf("no META-INF/container.xml")
+	}
+	var rootFiles struct {
+		RootFiles []struct {
+			FullPath string `xml:"full-path,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := readZipXML(container, func(decoder *xml.Decoder) error { return decoder.Decode(&rootFiles) }); err != nil {
+		return nil, err
+	}
+	if len(rootFiles.RootFiles) == 0 || files[rootFiles.RootFiles[0].FullPath] == nil {
+		return nil, fmt.Errorf("no package document")
+	}
+	opfPath := rootFiles.RootFiles[0].FullPath
+	var pkg epubPackage
+	if err := readZipXML(files[opfPath], func(decoder *xml.Decoder) error { return decoder.Decode(&pkg) }); err != nil {
+		return nil, err
+	}
+
+	document := &Document{}
+	if len(pkg.Titles) > 0 {
+		document.setMeta(MetaTitle, pkg.Titles[0])
+	}
+	if len(pkg.Creators) > 0 {
+		document.setMeta(MetaAuthor, pkg.Creators[0])
+	}
+	hrefs := make(map[string]string, len(pkg.Items))
+	for _, item := range pkg.Items {
+		if item.MediaType == "application/xhtml+xml" || item.MediaType == "text/html" {
+			hrefs[item.ID] = item.Href
+		}
+	}
+	// The chapter titles would replace the title of the book
+	chapter := &Document{}
+	text := newTextBuilder(ctx)
+	for _, itemRef := range pkg.Spine {
+		href, ok := hrefs[itemRef.IDRef]
+		if !ok || text.full() {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(href); err == nil {
+			href = unescaped
+		}
+		file, ok := files[path.Join(path.Dir(opfPath), href)]
+		if !ok {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		err = parseHTML(reader, text, chapter)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		text.WriteString("\n\n")
+	}
+	document.Content = normalizeText(text.String())
+	return document, nil
+}
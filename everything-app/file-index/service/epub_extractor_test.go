@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// epubChapter returns an XHTML chapter with a title and body
+func epubChapter(title, body string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?><html xmlns="http://www.w3.org/1999/xhtml"><head><title>` +
+		title + `</title></head><body>` + body + `</body></html>`
+}
+
+func TestReadEPUBDocument(t *testing.T) {
+	data := zipData(t, map[string]string{
+		"mimetype": "application/epub+zip",
+		"META-INF/container.xml": `<?xml version="1.0"?><container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">` +
+			`<rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles></container>`,
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?><package xmlns="http://www.idpf.org/2007/opf" version="3.0">` +
+			`<metadata xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>The Book</dc:title>` +
+			`<dc:creator>First Author</dc:creator><dc:creator>Second Author</dc:creator></metadata>` +
+			`<manifest><item id="ch1" href="Chapter%201.xhtml" media-type="application/xhtml+xml"/>` +
+			`<item id="ch2" href="text/ch2.xhtml" media-type="application/xhtml+xml"/>` +
+			`<item id="css" href="style.css" media-type="text/css"/>` +
+			`<item id="missing" href="missing.xhtml" media-type="application/xhtml+xml"/></manifest>` +
+			`<spine><itemref idref="ch2"/><itemref idref="css"/><itemref idref="missing"/><itemref idref="ch1"/></spine></package>`,
+		"OEBPS/Chapter 1.xhtml":  epubChapter("Chapter one", `<h1>One</h1><p>It was a dark night.</p>`),
+		"OEBPS/text/ch2.xhtml":   epubChapter("Chapter two", `<h1>Two</h1><p>Read first, it comes first in the spine.</p><script>skipped()</script>`),
+		"OEBPS/style.css":        `p { color: red }`,
+		"OEBPS/unlisted.xhtml":   epubChapter("Unlisted", `<p>Not in the spine</p>`),
+		"OEBPS/images/cover.jpg": "\xFF\xD8\xFF",
+	})
+	path, _ := writeFile(t, "book.epub", data)
+	document, err := readEPUBDocument(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Document{
+		Content:  "Two\n\nRead first, it comes first in the spine.\n\nOne\n\nIt was a dark night.",
+		Metadata: map[string]string{MetaTitle: "The Book", MetaAuthor: "First Author"},
+	}
+	if !reflect.DeepEqual(*document, want) {
+		t.Errorf("read %+v, want %+v", *document, want)
+	}
+}
+
+func TestReadEPUBDocumentErrors(t *testing.T) {
+	for name, files := range map[string]map[string]string{
+		"no container": {"mimetype": "application/epub+zip"},
+		"no package document": {
+			"META-INF/container.xml": `<container><rootfiles><rootfile full-path="missing.opf"/></rootfiles></container>`,
+		},
+	} {
+		path, _ := writeFile(t, "book.epub", zipData(t, files))
+		if document, err := readEPUBDocument(context.Background(), path); err == nil {
+			t.Errorf("%s: read %+v, want an error", name, document)
+		}
+	}
+}
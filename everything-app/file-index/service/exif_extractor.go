@@ -0,0 +1,295 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The photos of cameras and phones
+func init() {
+	err := RegisterExtractor(ExtractorConfig{
+		Name:       "exif",
+		Extensions: []string{".jpg", ".jpeg", ".jpe", ".tif", ".tiff"},
+		MIMETypes:  []string{"image/jpeg"},
+		Extractor:  ExtractorFunc(readEXIFDocument),
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// The EXIF tags of the image, camera and GPS directories
+const (
+	tiffMake               = 0x010f
+	tiffModel              = 0x0110
+	tiffDateTime           = 0x0132
+	tiffExifIFD            = 0x8769
+	tiffGPSIFD             = 0x8825
+	exifDateTimeOriginal   = 0x9003
+	exifOffsetTimeOriginal = 0x9011
+	gpsLatitudeRef         = 0x0001
+	gpsLatitude            = 0x0002
+	gpsLongitudeRef        = 0x0003
+	gpsLongitude           = 0x0004
+)
+
+var errEXIFSyntax = errors.New("invalid EXIF data")
+
+// tiffTypeSizes are the sizes of the values of the TIFF field types
+var tiffTypeSizes = map[uint16]int64{
+	1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8, 13: 4,
+}
+
+// tiffEntry is a field of an image file directory, value holds the data of
+// the field or its offset if the data is longer than 4 bytes
+type tiffEntry struct {
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+// tiffReader reads the directories of a TIFF file or of the EXIF segment of
+// a JPEG
+type tiffReader struct {
+	reader io.ReaderAt
+	order  binary.ByteOrder
+}
+
+// readEXIFDocument reads the camera, the time a photo was taken and where
+func readEXIFDocument(ctx context.Context, path string) (*Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	buffered := bufio.NewReader(file)
+	magic, err := buffered.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+	var data io.ReaderAt
+	switch {
+	case magic[0] == 0xff && magic[1] == 0xd8:
+		exif, err := readJPEGExif(buffered)
+		if err != nil {
+			return nil, err
+		}
+		if exif == nil {
+			return &Document{}, nil
+		}
+		data = bytes.NewReader(exif)
+	case string(magic) == "II*\x00" || string(magic) == "MM\x00*":
+		data = file
+	default:
+		return nil, fmt.Errorf("not a JPEG or TIFF image")
+	}
+
+	tiff, offset, err := newTIFFReader(data)
+	if err != nil {
+		return nil, err
+	}
+	image, err := tiff.directory(offset)
+	if err != nil {
+		return nil, err
+	}
+	exif, gps := map[uint16]tiffEntry{}, map[uint16]tiffEntry{}
+	if entry, ok := image[tiffExifIFD]; ok {
+		exif, _ = tiff.directory(tiff.long(entry))
+	}
+	if entry, ok := image[tiffGPSIFD]; ok {
+		gps, _ = tiff.directory(tiff.long(entry))
+	}
+
+	document := &Document{}
+	document.setMeta(MetaCamera, cameraName(tiff.text(image[tiffMake]), tiff.text(image[tiffModel])))
+	takenAt := tiff.text(exif[exifDateTimeOriginal])
+	if takenAt == "" {
+		takenAt = tiff.text(image[tiffDateTime])
+	}
+	document.setMeta(MetaTakenAt, exifTime(takenAt, tiff.text(exif[exifOffsetTimeOriginal])))
+	if latitude, ok := tiff.coordinate(gps[gpsLatitude], tiff.text(gps[gpsLatitudeRef]), "S"); ok {
+		if longitude, ok := tiff.coordinate(gps[gpsLongitude], tiff.text(gps[gpsLongitudeRef]), "W"); ok {
+			document.setMeta(MetaLatitude, strconv.FormatFloat(latitude, 'f', 6, 64))
+			document.setMeta(MetaLongitude, strconv.FormatFloat(longitude, 'f', 6, 64))
+		}
+	}
+	return document, nil
+}
+
+// readJPEGExif returns the TIFF data of the EXIF segment of a JPEG, or nil
+// if the image has none. The segments before the image data are read.
+func readJPEGExif(reader *bufio.Reader) ([]byte, error) {
+	for {
+		marker, err := reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if marker != 0xff {
+			return nil, errEXIFSyntax
+		}
+		kind, err := reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case kind == 0xff:
+			// Fill byte
+			reader.UnreadByte()
+			continue
+		case kind == 0xd8 || kind == 0x01 || (kind >= 0xd0 && kind <= 0xd7):
+			continue
+		case kind == 0xda || kind == 0xd9:
+			// The image data starts, the metadata segments come first
+			return nil, nil
+		}
+		var length uint16
+		if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+			return nil, err
+		}
+		if length < 2 {
+			return nil, errEXIFSyntax
+		}
+		segment := make([]byte, length-2)
+		if _, err := io.ReadFull(reader, segment); err != nil {
+			return nil, err
+		}
+		if kind == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:], nil
+		}
+	}
+}
+
+// newTIFFReader reads the byte order of a TIFF header and returns the offset
+// of the first directory
+func newTIFFReader(reader io.ReaderAt) (*tiffReader, uint32, error) {
+	header := make([]byte, 8)
+	if _, err := reader.ReadAt(header, 0); err != nil {
+		return nil, 0, err
+	}
+	tiff := &tiffReader{reader: reader}
+	switch string(header[:2]) {
+	case "II":
+		tiff.order = binary.LittleEndian
+	case "MM":
+		tiff.order = binary.BigEndian
+	default:
+		return nil, 0, errEXIFSyntax
+	}
+	return tiff, tiff.order.Uint32(header[4:]), nil
+}
+
+// directory reads the entries of the image file directory at offset
+func (tiff *tiffReader) directory(offset uint32) (map[uint16]tiffEntry, error) {
+	if offset < 8 {
+		return nil, errEXIFSyntax
+	}
+	count := make([]byte, 2)
+	if _, err := tiff.reader.ReadAt(count, int64(offset)); err != nil {
+		return nil, err
+	}
+	n := int(tiff.order.Uint16(count))
+	data := make([]byte, 12*n)
+	if _, err := tiff.reader.ReadAt(data, int64(offset)+2); err != nil {
+		return nil, err
+	}
+	entries := make(map[uint16]tiffEntry, n)
+	for i := 0; i < n; i++ {
+		field := data[12*i : 12*i+12]
+		entries[tiff.order.Uint16(field)] = tiffEntry{
+			typ:   tiff.order.Uint16(field[2:]),
+			count: tiff.order.Uint32(field[4:]),
+			value: field[8:12],
+		}
+	}
+	return entries, nil
+}
+
+// data returns the data of an entry, nil for a missing or invalid entry
+func (tiff *tiffReader) data(entry tiffEntry) []byte {
+	size := tiffTypeSizes[entry.typ] * int64(entry.count)
+	switch {
+	case size == 0 || size > 1<<16:
+		return nil
+	case size <= 4:
+		return entry.value[:size]
+	}
+	data := make([]byte, size)
+	if _, err := tiff.reader.ReadAt(data, int64(tiff.order.Uint32(entry.value))); err != nil {
+		return nil
+	}
+	return data
+}
+
+// long returns the value of a LONG or IFD entry, a directory offset
+func (tiff *tiffReader) long(entry tiffEntry) uint32 {
+	if (entry.typ != 4 && entry.typ != 13) || entry.count != 1 {
+		return 0
+	}
+	return tiff.order.Uint32(entry.value)
+}
+
+// text returns the value of an ASCII entry
+func (tiff *tiffReader) text(entry tiffEntry) string {
+	if entry.typ != 2 {
+		return ""
+	}
+	text, _, _ := strings.Cut(string(tiff.data(entry)), "\x00")
+	return strings.TrimSpace(text)
+}
+
+// coordinate returns the degrees of a GPS latitude or longitude given as
+// degrees, minutes and seconds, negative when ref is negativeRef
+func (tiff *tiffReader) coordinate(entry tiffEntry, ref, negativeRef string) (float64, bool) {
+	data := tiff.data(entry)
+	if entry.typ != 5 || len(data) < 24 {
+		return 0, false
+	}
+	degrees := 0.0
+	for i, scale := range []float64{1, 60, 3600} {
+		numerator := tiff.order.Uint32(data[8*i:])
+		denominator := tiff.order.Uint32(data[8*i+4:])
+		if denominator == 0 {
+			return 0, false
+		}
+		degrees += float64(numerator) / float64(denominator) / scale
+	}
+	if ref == negativeRef {
+		degrees = -degrees
+	}
+	return degrees, !math.IsNaN(degrees)
+}
+
+// cameraName joins the make and the model of a camera, most models already
+// start with the make
+func cameraName(vendor, model string) string {
+	if vendor == "" || strings.HasPrefix(strings.ToLower(model), strings.ToLower(vendor)) {
+		return model
+	}
+	return strings.TrimSpace(vendor + " " + model)
+}
+
+// exifTime converts an EXIF date to RFC 3339, with the zone of offset if
+// the camera recorded one. Unknown dates are returned empty.
+func exifTime(value, offset string) string {
+	taken, err := time.Parse("2006:01:02 15:04:05", value)
+	if err != nil {
+		return ""
+	}
+	if zone, err := time.Parse("-07:00", offset); err == nil {
+		_, seconds := zone.Zone()
+		return time.Date(taken.Year(), taken.Month(), taken.Day(), taken.Hour(), taken.Minute(), taken.Second(), 0,
+			time.FixedZone("", seconds)).Format(time.RFC3339)
+	}
+	return taken.Format("2006-01-02T15:04:05")
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// tiffField is a field of a test directory, ifd > 0 makes it the offset of
+// that directory
+type tiffField struct {
+	tag  uint16
+	typ  uint16
+	data []byte
+	ifd  int
+}
+
+func tiffASCII(tag uint16, text string) tiffField {
+	return tiffField{tag: tag, typ: 2, data: []byte(text + "\x00")}
+}
+
+// tiffRationals returns a RATIONAL field of numerator and denominator pairs
+func tiffRationals(order binary.ByteOrder, tag uint16, values ...uint32) tiffField {
+	data := make([]byte, 4*len(values))
+	for i, value := range values {
+		order.PutUint32(data[4*i:], value)
+	}
+	return tiffField{tag: tag, typ: 5, data: data}
+}
+
+func tiffIFD(tag uint16, ifd int) tiffField {
+	return tiffField{tag: tag, typ: 4, ifd: ifd}
+}
+
+// tiffData returns a TIFF file of directories, the first is the image
+// directory. The values longer than 4 bytes follow the directories.
+func tiffData(order binary.ByteOrder, directories ...[]tiffField) []byte {
+	offsets := make([]uint32, len(directories))
+	end := uint32(8)
+	for i, fields := range directories {
+		offsets[i] = end
+		end += 2 + 12*uint32(len(fields)) + 4
+	}
+	var values []byte
+	var buf bytes.Buffer
+	if order == binary.ByteOrder(binary.LittleEndian) {
+		buf.WriteString("II")
+	} else {
+		buf.WriteString("MM")
+	}
+	binary.Write(&buf, order, uint16(42))
+	binary.Write(&buf, order, offsets[0])
+	for _, fields := range directories {
+		binary.Write(&buf, order, uint16(len(fields)))
+		for _, field := range fields {
+			count := uint32(len(field.data)) / uint32(tiffTypeSizes[field.typ])
+			value := make([]byte, 4)
+			switch {
+			case field.ifd > 0:
+				count = 1
+				order.PutUint32(value, offsets[field.ifd])
+			case len(field.data) <= 4:
+				copy(value, field.data)
+			default:
+				order.PutUint32(value, end+uint32(len(values)))
+				values = append(values, field.data...)
+			}
+			binary.Write(&buf, order, field.tag)
+			binary.Write(&buf, order, field.typ)
+			binary.Write(&buf, order, count)
+			buf.Write(value)
+		}
+		// No next directory
+		binary.Write(&buf, order, uint32(0))
+	}
+	buf.Write(values)
+	return buf.Bytes()
+}
+
+// photoTIFF returns the EXIF data of a photo taken at the Eiffel tower, or
+// at its antipode with the south and west references
+func photoTIFF(order binary.ByteOrder, antipode bool) []byte {
+	latitudeRef, longitudeRef := "N", "E"
+	if antipode {
+		latitudeRef, longitudeRef = "S", "W"
+	}
+	return tiffData(order,
+		[]tiffField{
+			tiffASCII(tiffMake, "FUJIFILM"),
+			tiffASCII(tiffModel, "X100V"),
+			tiffASCII(tiffDateTime, "2024:03:01 10:00:00"),
+			tiffIFD(tiffExifIFD, 1),
+			tiffIFD(tiffGPSIFD, 2),
+		},
+		[]tiffField{
+			tiffASCII(exifDateTimeOriginal, "2023:07:14 18:30:05"),
+			tiffASCII(exifOffsetTimeOriginal, "+02:00"),
+		},
+		[]tiffField{
+			tiffASCII(gpsLatitudeRef, latitudeRef),
+			tiffRationals(order, gpsLatitude, 48, 1, 51, 1, 3000, 100),
+			tiffASCII(gpsLongitudeRef, longitudeRef),
+			tiffRationals(order, gpsLongitude, 2, 1, 17, 1, 4000, 100),
+		},
+	)
+}
+
+// jpegData returns a JPEG with a JFIF segment and the EXIF segment of tiff,
+// only the segments before the image data
+func jpegData(tiff []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{0xff, 0xd8})
+	buf.Write([]byte{0xff, 0xe0, 0x00, 0x10})
+	buf.WriteString("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00")
+	if tiff != nil {
+		// A fill byte before the marker
+		buf.Write([]byte{0xff, 0xff, 0xe1})
+		binary.Write(&buf, binary.BigEndian, uint16(2+6+len(tiff)))
+		buf.WriteString("Exif\x00\x00")
+		buf.Write(tiff)
+	}
+	buf.Write([]byte{0xff, 0xda, 0x00, 0x02, 0x00, 0x00, 0xff, 0xd9})
+	return buf.Bytes()
+}
+
+func TestReadEXIFDocument(t *testing.T) {
+	eiffel := map[string]string{
+		MetaCamera:    "FUJIFILM X100V",
+		MetaTakenAt:   "2023-07-14T18:30:05+02:00",
+		MetaLatitude:  "48.858333",
+		MetaLongitude: "2.294444",
+	}
+	antipode := map[string]string{
+		MetaCamera:    "FUJIFILM X100V",
+		MetaTakenAt:   "2023-07-14T18:30:05+02:00",
+		MetaLatitude:  "-48.858333",
+		MetaLongitude: "-2.294444",
+	}
+	for _, test := range []struct {
+		name string
+		data []byte
+		want map[string]string
+	}{
+		{"little.tif", photoTIFF(binary.LittleEndian, false), eiffel},
+		{"big.tiff", photoTIFF(binary.BigEndian, true), antipode},
+		{"photo.jpg", jpegData(photoTIFF(binary.BigEndian, false)), eiffel},
+		{"antipode.jpeg", jpegData(photoTIFF(binary.LittleEndian, true)), antipode},
+		{"camera.jpg", jpegData(tiffData(binary.LittleEndian, []tiffField{
+			// The model already names the make, the camera recorded no zone
+			tiffASCII(tiffMake, "Canon"),
+			tiffASCII(tiffModel, "Canon EOS R5 "),
+			tiffASCII(tiffDateTime, "2024:03:01 10:00:00"),
+			// Without its longitude the position is left out
+			tiffIFD(tiffGPSIFD, 1),
+		}, []tiffField{
+			tiffASCII(gpsLatitudeRef, "N"),
+			tiffRationals(binary.LittleEndian, gpsLatitude, 48, 1, 51, 1, 3000, 100),
+		})), map[string]string{MetaCamera: "Canon EOS R5", MetaTakenAt: "2024-03-01T10:00:00"}},
+		{"plain.jpg", jpegData(nil), nil},
+	} {
+		path, _ := writeFile(t, test.name, test.data)
+		document, err := readEXIFDocument(context.Background(), path)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(document.Metadata, test.want) {
+			t.Errorf("%s: metadata %v, want %v", test.name, document.Metadata, test.want)
+		}
+	}
+}
+
+func TestReadEXIFDocumentErrors(t *testing.T) {
+	tiff := photoTIFF(binary.LittleEndian, false)
+	for name, data := range map[string][]byte{
+		"png.jpg":       []byte("\x89PNG\r\n\x1a\n"),
+		"truncated.tif": tiff[:12],
+		"segment.jpg":   {0xff, 0xd8, 0xff, 0xe1, 0x00, 0x01},
+		"marker.jpg":    {0xff, 0xd8, 0x00, 0x00},
+		"exif.jpg":      jpegData([]byte("XX*\x00\x08\x00\x00\x00")),
+	} {
+		path, _ := writeFile(t, name, data)
+		if document, err := readEXIFDocument(context.Background(), path); err == nil {
+			t.Errorf("%s: metadata %v, want an error", name, document.Metadata)
+		}
+	}
+}
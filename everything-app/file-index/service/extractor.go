@@ -0,0 +1,387 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultExtractTimeout bounds extractors registered without a timeout
+const defaultExtractTimeout = 30 * time.Second
+
+// sniffSize is the number of bytes read to detect the MIME type of a file
+const sniffSize = 512
+
+var (
+	ErrExtractorExists = errors.New("extractor already registered")
+	ErrExtractTimeout  = errors.New("content extraction timed out")
+	ErrExtractorPanic  = errors.New("content extractor panicked")
+)
+
+// Keys of the document metadata shared by the extractors
+const (
+	MetaTitle          = "title"
+	MetaAuthor         = "author"
+	MetaLastModifiedBy = "last_modified_by"
+	MetaPageCount      = "page_count"
+	// MetaMembers is the number of members indexed of an archive
+	MetaMembers = "members"
+	// The photo properties, MetaTakenAt is an RFC 3339 time without a zone
+	// when the camera did not record one, the coordinates are in degrees
+	MetaCamera    = "camera"
+	MetaTakenAt   = "taken_at"
+	MetaLatitude  = "gps_latitude"
+	MetaLongitude = "gps_longitude"
+	// The tags of audio files
+	MetaArtist = "artist"
+	MetaAlbum  = "album"
+	MetaDate   = "date"
+	MetaGenre  = "genre"
+)
+
+// Document is the text and the properties extracted from a file
+type Document struct {
+	Content string
+	// Metadata holds properties such as MetaTitle, MetaAuthor and
+	// MetaPageCount, empty values are left out
+	Metadata map[string]string
+}
+
+// setMeta sets a metadata value unless it is empty
+func (document *Document) setMeta(key, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	if document.Metadata == nil {
+		document.Metadata = make(map[string]string)
+	}
+	document.Metadata[key] = value
+}
+
+// Extractor reads the text content and metadata of the files of a format
+type Extractor interface {
+	// Extract returns the document in the file at path. Long extractions
+	// should stop when ctx is done, the result is dropped anyway, and may
+	// stop reading once they have ContentLimit(ctx) bytes of text.
+	Extract(ctx context.Context, path string) (*Document, error)
+}
+
+type contentLimitKey struct{}
+
+// ContentLimit returns the number of bytes of content indexed per file, 0
+// means unlimited. Longer content is truncated.
+func ContentLimit(ctx context.Context) int64 {
+	limit, _ := ctx.Value(contentLimitKey{}).(int64)
+	return limit
+}
+
+// truncateContent cuts content to at most limit bytes without splitting a
+// character
+func truncateContent(content string, limit int64) string {
+	if limit <= 0 || int64(len(content)) <= limit {
+		return content
+	}
+	end := int(limit)
+	for end > 0 && !utf8.RuneStart(content[end]) {
+		end--
+	}
+	return content[:end]
+}
+
+// textBuilder collects the text of a document up to the content limit
+type textBuilder struct {
+	strings.Builder
+	limit int64
+}
+
+func newTextBuilder(ctx context.Context) *textBuilder {
+	return &textBuilder{limit: ContentLimit(ctx)}
+}
+
+// full reports whether the builder holds as much text as is indexed
+func (text *textBuilder) full() bool {
+	return text.limit > 0 && int64(text.Len()) >= text.limit
+}
+
+// normalizeText trims the lines of text and collapses runs of spaces, tabs
+// are kept as cell separators. Paragraphs are separated by one blank line
+// at most.
+func normalizeText(text string) string {
+	var normalized strings.Builder
+	blank := 0
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r != '\t' && unicode.IsSpace(r)
+		})
+		line = strings.Trim(strings.Join(fields, " "), " \t")
+		if line == "" {
+			blank++
+			continue
+		}
+		if normalized.Len() > 0 {
+			normalized.WriteString("\n")
+			if blank > 0 {
+				normalized.WriteString("\n")
+			}
+		}
+		blank = 0
+		normalized.WriteString(line)
+	}
+	return normalized.String()
+}
+
+// ExtractorFunc adapts a function to the Extractor interface
+type ExtractorFunc func(ctx context.Context, path string) (*Document, error)
+
+func (extract ExtractorFunc) Extract(ctx context.Context, path string) (*Document, error) {
+	return extract(ctx, path)
+}
+
+// TextExtractor adapts a function that only reads the text of a file
+type TextExtractor func(ctx context.Context, path string) (string, error)
+
+func (extract TextExtractor) Extract(ctx context.Context, path string) (*Document, error) {
+	content, err := extract(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{Content: content}, nil
+}
+
+// ExtractorConfig describes an extractor and the files it handles
+type ExtractorConfig struct {
+	// Name identifies the extractor in logs and in the crawl config
+	Name      string
+	Extractor Extractor
+	// Extensions are the file extensions handled, with the dot and matched
+	// case-insensitively
+	Extensions []string
+	// MIMETypes are matched against the type sniffed from the first bytes
+	// of the files whose extension has no extractor
+	MIMETypes []string
+	// MaxSize is the size of the largest file extracted, larger files are
+	// indexed without content. 0 means unlimited.
+	MaxSize int64
+	// Timeout bounds the extraction of a file, 0 means defaultExtractTimeout
+	Timeout time.Duration
+}
+
+// ExtractorLimits overrides the limits of a registered extractor, a zero
+// limit keeps the one of the extractor and a negative MaxSize removes it
+type ExtractorLimits struct {
+	MaxSize int64         `mapstructure:"max_size"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// ExtractorRegistry selects the extractor of a file by extension, or by
+// sniffed MIME type when no extractor handles its extension
+type ExtractorRegistry struct {
+	mutex       sync.RWMutex
+	byExtension map[string]*ExtractorConfig
+	byMIMEType  map[string]*ExtractorConfig
+	// maxContent is the number of bytes of content indexed per file
+	maxContent int64
+}
+
+func NewExtractorRegistry() *ExtractorRegistry {
+	return &ExtractorRegistry{
+		byExtension: make(map[string]*ExtractorConfig),
+		byMIMEType:  make(map[string]*ExtractorConfig),
+	}
+}
+
+// DefaultExtractors is the registry used by the indexers. Extractors of new
+// formats register themselves in an init function with RegisterExtractor.
+var DefaultExtractors = NewExtractorRegistry()
+
+// RegisterExtractor adds an extractor to DefaultExtractors
+func RegisterExtractor(config ExtractorConfig) error {
+	return DefaultExtractors.Register(config)
+}
+
+// Register adds an extractor. An extension or MIME type can only be handled
+// by one extractor.
+func (registry *ExtractorRegistry) Register(config ExtractorConfig) error {
+	if config.Name == "" || config.Extractor == nil {
+		return fmt.Errorf("extractor needs a name and an implementation")
+	}
+	if len(config.Extensions) == 0 && len(config.MIMETypes) == 0 {
+		return fmt.Errorf("extractor %s handles no extension or MIME type", config.Name)
+	}
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	for _, ext := range config.Extensions {
+		if existing, ok := registry.byExtension[strings.ToLower(ext)]; ok {
+			return fmt.Errorf("%w: %s handles %s", ErrExtractorExists, existing.Name, ext)
+		}
+	}
+	for _, mimeType := range config.MIMETypes {
+		if existing, ok := registry.byMIMEType[mimeType]; ok {
+			return fmt.Errorf("%w: %s handles %s", ErrExtractorExists, existing.Name, mimeType)
+		}
+	}
+	registered := config
+	for _, ext := range config.Extensions {
+		registry.byExtension[strings.ToLower(ext)] = &registered
+	}
+	for _, mimeType := range config.MIMETypes {
+		registry.byMIMEType[mimeType] = &registered
+	}
+	return nil
+}
+
+// WithLimits returns a copy of the registry with the non-zero limits of the
+// extractors named in limits replaced, indexing at most maxContent bytes of
+// content per file
+func (registry *ExtractorRegistry) WithLimits(limits map[string]ExtractorLimits, maxContent int64) *ExtractorRegistry {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	limited := NewExtractorRegistry()
+	limited.maxContent = maxContent
+	copies := make(map[*ExtractorConfig]*ExtractorConfig)
+	copyOf := func(config *ExtractorConfig) *ExtractorConfig {
+		if copied, ok := copies[config]; ok {
+			return copied
+		}
+		copied := *config
+		// A limit left out keeps the default of the extractor
+		if limit, ok := limits[config.Name]; ok && limit.MaxSize != 0 {
+			copied.MaxSize = limit.MaxSize
+		}
+		if limit, ok := limits[config.Name]; ok && limit.Timeout != 0 {
+			copied.Timeout = limit.Timeout
+		}
+		copies[config] = &copied
+		return &copied
+	}
+	for ext, config := range registry.byExtension {
+		limited.byExtension[ext] = copyOf(config)
+	}
+	for mimeType, config := range registry.byMIMEType {
+		limited.byMIMEType[mimeType] = copyOf(config)
+	}
+	return limited
+}
+
+// Lookup returns the extractor of the file at path. The file is only read
+// when its extension has no extractor and some extractor handles MIME types.
+func (registry *ExtractorRegistry) Lookup(path string) (ExtractorConfig, bool) {
+	return registry.lookup(path, func() (string, error) { return sniffMIMEType(path) })
+}
+
+// lookup returns the extractor of the file named name, sniff is only called
+// when the extension has no extractor and some extractor handles MIME types
+func (registry *ExtractorRegistry) lookup(name string, sniff func() (string, error)) (ExtractorConfig, bool) {
+	registry.mutex.RLock()
+	config, ok := registry.byExtension[strings.ToLower(filepath.Ext(name))]
+	sniffed := len(registry.byMIMEType) > 0
+	registry.mutex.RUnlock()
+	if ok {
+		return *config, true
+	}
+	if !sniffed {
+		return ExtractorConfig{}, false
+	}
+
+	mimeType, err := sniff()
+	if err != nil {
+		return ExtractorConfig{}, false
+	}
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+	config, ok = registry.byMIMEType[mimeType]
+	if !ok {
+		return ExtractorConfig{}, false
+	}
+	return *config, true
+}
+
+// sniffMIMEType detects the MIME type of a file from its first bytes,
+// without parameters such as the charset
+func sniffMIMEType(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	buf := make([]byte, sniffSize)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	return detectMIMEType(buf[:n])
+}
+
+// detectMIMEType detects the MIME type of the first bytes of a file
+func detectMIMEType(head []byte) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	return mediaType, err
+}
+
+// Extract returns the document in the file at path with the extractor of
+// its format, info is the state of the file. Files without an extractor or
+// over the size limit of their extractor have no document. An extractor
+// that panics fails with ErrExtractorPanic.
+func (registry *ExtractorRegistry) Extract(ctx context.Context, path string, info fs.FileInfo) (*Document, error) {
+	config, ok := registry.Lookup(path)
+	if !ok || (config.MaxSize > 0 && info.Size() > config.MaxSize) {
+		return nil, nil
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultExtractTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ctx = context.WithValue(ctx, contentLimitKey{}, registry.maxContent)
+
+	// Most parsers ignore ctx, so a stuck extraction is abandoned rather
+	// than holding an extract worker
+	type result struct {
+		document *Document
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		// A parser crashing on a malformed file fails that file only
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{err: fmt.Errorf("%w: %v\n%s", ErrExtractorPanic, r, debug.Stack())}
+			}
+		}()
+		document, err := config.Extractor.Extract(ctx, path)
+		done <- result{document, err}
+	}()
+	select {
+	case res := <-done:
+		if res.err != nil {
+			return nil, fmt.Errorf("%s: %w", config.Name, res.err)
+		}
+		if res.document != nil {
+			res.document.Content = truncateContent(res.document.Content, registry.maxContent)
+		}
+		return res.document, nil
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%s: %w after %v", config.Name, ErrExtractTimeout, timeout)
+		}
+		return nil, ctx.Err()
+	}
+}
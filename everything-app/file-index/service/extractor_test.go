@@ -0,0 +1,243 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// writeFile creates the file name in a temporary directory and returns its
+// path and state
+func writeFile(t *testing.T, name string, content []byte) (string, fs.FileInfo) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, info
+}
+
+// zipData returns a zip archive of files, keyed by name, with the members
+// in order of name
+func zipData(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, name := range names {
+		member, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := member.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// constExtractor returns content for every file and counts its calls
+func constExtractor(content string, calls *atomic.Int32) Extractor {
+	return TextExtractor(func(ctx context.Context, path string) (string, error) {
+		calls.Add(1)
+		return content, nil
+	})
+}
+
+func TestExtractorRegistryLookup(t *testing.T) {
+	registry := NewExtractorRegistry()
+	var calls atomic.Int32
+	for _, config := range []ExtractorConfig{
+		{Name: "text", Extractor: constExtractor("text", &calls), Extensions: []string{".txt", ".MD"}},
+		{Name: "pdf", Extractor: constExtractor("pdf", &calls), MIMETypes: []string{"application/pdf"}},
+	} {
+		if err := registry.Register(config); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, test := range []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"notes.txt", "%PDF-1.4", "text"},
+		{"README.md", "", "text"},
+		{"NOTES.TXT", "", "text"},
+		// The type of files whose extension has no extractor is sniffed
+		{"report", "%PDF-1.4\n", "pdf"},
+		{"report.bin", "%PDF-1.4\n", "pdf"},
+		{"image.png", "\x89PNG\r\n\x1a\n", ""},
+		{"missing.bin", "", ""},
+	} {
+		path := filepath.Join(t.TempDir(), test.name)
+		if test.name != "missing.bin" {
+			path, _ = writeFile(t, test.name, []byte(test.content))
+		}
+		config, ok := registry.Lookup(path)
+		if ok != (test.want != "") || config.Name != test.want {
+			t.Errorf("Lookup(%s) = %q, %v, want %q", test.name, config.Name, ok, test.want)
+		}
+	}
+
+	err := registry.Register(ExtractorConfig{Name: "markdown", Extractor: constExtractor("", &calls), Extensions: []string{".md"}})
+	if !errors.Is(err, ErrExtractorExists) {
+		t.Errorf("Register of a handled extension returned %v, want %v", err, ErrExtractorExists)
+	}
+	err = registry.Register(ExtractorConfig{Name: "pdf2", Extractor: constExtractor("", &calls), MIMETypes: []string{"application/pdf"}})
+	if !errors.Is(err, ErrExtractorExists) {
+		t.Errorf("Register of a handled MIME type returned %v, want %v", err, ErrExtractorExists)
+	}
+	if err := registry.Register(ExtractorConfig{Name: "none", Extractor: constExtractor("", &calls)}); err == nil {
+		t.Error("Register of an extractor without extensions or MIME types succeeded")
+	}
+}
+
+// TestExtractorRegistryLookupNoSniff checks that files are not read when no
+// extractor handles MIME types
+func TestExtractorRegistryLookupNoSniff(t *testing.T) {
+	registry := NewExtractorRegistry()
+	var calls atomic.Int32
+	if err := registry.Register(ExtractorConfig{Name: "text", Extractor: constExtractor("", &calls), Extensions: []string{".txt"}}); err != nil {
+		t.Fatal(err)
+	}
+	sniffed := false
+	if _, ok := registry.lookup("report", func() (string, error) { sniffed = true; return "text/plain", nil }); ok || sniffed {
+		t.Errorf("lookup without MIME extractors found %v, sniffed %v", ok, sniffed)
+	}
+}
+
+func TestExtractorRegistryMaxSize(t *testing.T) {
+	registry := NewExtractorRegistry()
+	var calls atomic.Int32
+	if err := registry.Register(ExtractorConfig{Name: "text", Extractor: constExtractor("content", &calls), Extensions: []string{".txt"}, MaxSize: 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	path, info := writeFile(t, "small.txt", []byte("1234"))
+	document, err := registry.Extract(context.Background(), path, info)
+	if err != nil || document == nil || document.Content != "content" {
+		t.Errorf("Extract of a file at the size limit = %+v, %v", document, err)
+	}
+	path, info = writeFile(t, "large.txt", []byte("12345"))
+	document, err = registry.Extract(context.Background(), path, info)
+	if err != nil || document != nil {
+		t.Errorf("Extract of a file over the size limit = %+v, %v, want no document", document, err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("extractor called %d times, want 1", n)
+	}
+
+	// WithLimits replaces the limits and truncates the content
+	limited := registry.WithLimits(map[string]ExtractorLimits{"text": {MaxSize: -1}}, 4)
+	document, err = limited.Extract(context.Background(), path, info)
+	if err != nil || document == nil || document.Content != "cont" {
+		t.Errorf("Extract with the limit removed = %+v, %v, want content %q", document, err, "cont")
+	}
+	if config, _ := registry.Lookup(path); config.MaxSize != 4 {
+		t.Errorf("WithLimits changed the max size of the registry to %d", config.MaxSize)
+	}
+}
+
+// TestExtractorRegistryWithLimits checks that the limits left out of an
+// override keep the ones of the extractor
+func TestExtractorRegistryWithLimits(t *testing.T) {
+	registry := NewExtractorRegistry()
+	var calls atomic.Int32
+	for _, config := range []ExtractorConfig{
+		{Name: "pdf", Extractor: constExtractor("", &calls), Extensions: []string{".pdf"}, MaxSize: 100, Timeout: time.Second},
+		{Name: "text", Extractor: constExtractor("", &calls), Extensions: []string{".txt"}, MaxSize: 200, Timeout: 2 * time.Second},
+	} {
+		if err := registry.Register(config); err != nil {
+			t.Fatal(err)
+		}
+	}
+	limited := registry.WithLimits(map[string]ExtractorLimits{
+		"pdf":  {Timeout: time.Minute},
+		"text": {MaxSize: 300},
+	}, 0)
+
+	for _, test := range []struct {
+		name    string
+		maxSize int64
+		timeout time.Duration
+	}{
+		{"report.pdf", 100, time.Minute},
+		{"notes.txt", 300, 2 * time.Second},
+	} {
+		config, ok := limited.Lookup(test.name)
+		if !ok || config.MaxSize != test.maxSize || config.Timeout != test.timeout {
+			t.Errorf("limits of %s = %d, %v, want %d, %v", test.name, config.MaxSize, config.Timeout, test.maxSize, test.timeout)
+		}
+	}
+}
+
+func TestExtractorRegistryTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	registry := NewExtractorRegistry()
+	// The extractor ignores ctx, like most parsers
+	stuck := ExtractorFunc(func(ctx context.Context, path string) (*Document, error) {
+		<-release
+		return &Document{Content: "late"}, nil
+	})
+	if err := registry.Register(ExtractorConfig{Name: "stuck", Extractor: stuck, Extensions: []string{".txt"}, Timeout: 20 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	path, info := writeFile(t, "file.txt", nil)
+
+	start := time.Now()
+	document, err := registry.Extract(context.Background(), path, info)
+	if !errors.Is(err, ErrExtractTimeout) || document != nil {
+		t.Errorf("Extract of a stuck extractor = %+v, %v, want %v", document, err, ErrExtractTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Extract returned after %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := registry.Extract(ctx, path, info); !errors.Is(err, context.Canceled) {
+		t.Errorf("Extract with a cancelled context returned %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestExtractorRegistryPanic(t *testing.T) {
+	registry := NewExtractorRegistry()
+	crash := ExtractorFunc(func(ctx context.Context, path string) (*Document, error) {
+		var document *Document
+		return &Document{Content: document.Content}, nil
+	})
+	if err := registry.Register(ExtractorConfig{Name: "crash", Extractor: crash, Extensions: []string{".bin"}}); err != nil {
+		t.Fatal(err)
+	}
+	path, info := writeFile(t, "file.bin", nil)
+
+	document, err := registry.Extract(context.Background(), path, info)
+	if !errors.Is(err, ErrExtractorPanic) || document != nil {
+		t.Fatalf("Extract of a panicking extractor = %+v, %v, want %v", document, err, ErrExtractorPanic)
+	}
+	if !strings.HasPrefix(err.Error(), "crash: content extractor panicked: runtime error: invalid memory address") {
+		t.Errorf("Extract error %q does not name the extractor and the panic", err)
+	}
+}
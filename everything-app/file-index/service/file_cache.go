@@ -0,0 +1,113 @@
+package service
+
+import (
+	"sync"
+	"training/file-index/pb"
+)
+
+type FileInfoMap map[string]*pb.FileAttr
+
+// fileCache holds the last indexed attributes of every file by path
+type fileCache struct {
+	mutex sync.RWMutex
+	files FileInfoMap
+	// byInode maps inode numbers to paths to detect renamed files
+	byInode map[uint64]string
+	// version is incremented on every change
+	version uint64
+}
+
+func newFileCache() *fileCache {
+	return &fileCache{
+		files:   make(FileInfoMap),
+		byInode: make(map[uint64]string),
+	}
+}
+
+// Get returns the cached attributes of path
+func (cache *fileCache) Get(path string) (*pb.FileAttr, bool) {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	fileAttr, ok := cache.files[path]
+	return fileAttr, ok
+}
+
+// Put stores the attributes of a file
+func (cache *fileCache) Put(fileAttr *pb.FileAttr) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if old, ok := cache.files[fileAttr.Path]; ok && cache.byInode[old.Inode] == old.Path {
+		delete(cache.byInode, old.Inode)
+	}
+	cache.files[fileAttr.Path] = fileAttr
+	if fileAttr.Inode != 0 {
+		cache.byInode[fileAttr.Inode] = fileAttr.Path
+	}
+	cache.version++
+}
+
+// Replace stores fileAttr in place of old, unless the cached attributes of
+// the path changed since old was read. It reports whether fileAttr was stored.
+func (cache *fileCache) Replace(old, fileAttr *pb.FileAttr) bool {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.files[fileAttr.Path] != old {
+		return false
+	}
+	cache.files[fileAttr.Path] = fileAttr
+	cache.version++
+	return true
+}
+
+// Delete removes path from the cache
+func (cache *fileCache) Delete(path string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if old, ok := cache.files[path]; ok && cache.byInode[old.Inode] == path {
+		delete(cache.byInode, old.Inode)
+	}
+	delete(cache.files, path)
+	cache.version++
+}
+
+// GetByInode returns the cached attributes of the file with inode
+func (cache *fileCache) GetByInode(inode uint64) (*pb.FileAttr, bool) {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	if inode == 0 {
+		return nil, false
+	}
+	path, ok := cache.byInode[inode]
+	if !ok {
+		return nil, false
+	}
+	fileAttr, ok := cache.files[path]
+	return fileAttr, ok
+}
+
+// Version returns a counter that changes whenever the cache changes
+func (cache *fileCache) Version() uint64 {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	return cache.version
+}
+
+// Select returns the cached files whose path matches fn
+func (cache *fileCache) Select(fn func(path string) bool) []*pb.FileAttr {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	var files []*pb.FileAttr
+	for path, fileAttr := range cache.files {
+		if fn(path) {
+			files = append(files, fileAttr)
+		}
+	}
+	return files
+}
@@ -1,6 +1,9 @@
 package service
 
 import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,11 +14,69 @@ import (
 	"github.com/xuri/excelize/v2"
 )
 
+// The extractors of the Office formats
+func init() {
+	for _, config := range []ExtractorConfig{
+		{Name: "docx", Extensions: []string{".docx"}, Extractor: officeExtractor(readDocxContent)},
+		{Name: "xlsx", Extensions: []string{".xlsx"}, Extractor: officeExtractor(readExcelContent)},
+		{Name: "pptx", Extensions: []string{".pptx"}, Extractor: officeExtractor(readPPTXContent)},
+	} {
+		if err := RegisterExtractor(config); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// officeExtractor adapts a reader that cannot be cancelled to Extractor and
+// adds the core properties of the file
+func officeExtractor(read func(filePath string) (string, error)) Extractor {
+	return ExtractorFunc(func(_ context.Context, path string) (*Document, error) {
+		content, err := read(path)
+		if err != nil {
+			return nil, err
+		}
+		document := &Document{Content: content}
+		// The properties are optional, the content is kept without them
+		_ = readOfficeProperties(path, document)
+		return document, nil
+	})
+}
+
+// readOfficeProperties sets the title, author and last author of document
+// from the docProps/core.xml part of an Office file
+func readOfficeProperties(path string, document *Document) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if file.Name != "docProps/core.xml" {
+			continue
+		}
+		var properties struct {
+			Title          string `xml:"title"`
+			Creator        string `xml:"creator"`
+			LastModifiedBy string `xml:"lastModifiedBy"`
+		}
+		err := readZipXML(file, func(decoder *xml.Decoder) error { return decoder.Decode(&properties) })
+		if err != nil {
+			return err
+		}
+		document.setMeta(MetaTitle, properties.Title)
+		document.setMeta(MetaAuthor, properties.Creator)
+		document.setMeta(MetaLastModifiedBy, properties.LastModifiedBy)
+	}
+	return nil
+}
+
 func readDocxContent(filePath string) (string, error) {
 	readFile, err := os.Open(filePath)
 	if err != nil {
 		return "", err
 	}
+	defer readFile.Close()
 	fileinfo, err := readFile.Stat()
 	if err != nil {
 		return "", err
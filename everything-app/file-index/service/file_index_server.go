@@ -2,152 +2,346 @@ package service
 
 import (
 	"context"
-	"crypto/md5"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
-	"strings"
+	"sort"
+	"sync"
 	"time"
 	"training/file-index/pb"
 
 	"github.com/djherbis/times"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// FileDiscoveryServer serves the files indexed by an Indexer running in the
+// background. Streams only read the index, they never start a crawl.
 type FileDiscoveryServer struct {
 	pb.UnimplementedFileIndexServer
-	fileStore FileStore
+	indexer *Indexer
 }
-type FileInfoMap map[string]*pb.FileAttr
 
-var fileInfoCache FileInfoMap
-
-func NewFileDiscoveryServer(fileStore FileStore) *FileDiscoveryServer {
+// NewFileDiscoveryServer returns a new FileDiscoveryServer serving the index of indexer
+func NewFileDiscoveryServer(indexer *Indexer) *FileDiscoveryServer {
 	return &FileDiscoveryServer{
-		fileStore: fileStore,
+		indexer: indexer,
 	}
 }
 
+// GetCheckSumFiles returns the MD5 checksums of the requested files, the
+// files that cannot be hashed are left out. Deprecated: StreamChecksums
+// hashes files in parallel and reports the errors.
 func (server *FileDiscoveryServer) GetCheckSumFiles(ctx context.Context, req *pb.CreateFileChecksumRequest) (*pb.CreateFileChecksumResponse, error) {
-	filepaths := req.GetFilepath()
 	var res = &pb.CreateFileChecksumResponse{Checksums: make(map[string]string)}
+	for _, filepath := range req.GetFilepath() {
+		result := server.indexer.checksumFile(ctx, filepath, pb.ChecksumAlgorithm_MD5)
+		if err := contextError(ctx); err != nil {
+			return nil, err
+		}
+		if result.Error != "" {
+			log.Printf("cannot hash %s: %s", filepath, result.Error)
+			continue
+		}
+		res.Checksums[filepath] = result.Checksum
+	}
+	return res, nil
+}
 
-	for _, filepath := range filepaths {
-		_, err := os.Stat(filepath)
-		if err != nil {
-			if os.IsPermission(err) {
-				log.Printf("Permission denied")
-				continue
+// StreamChecksums hashes the requested files, up to checksumWorkers at once,
+// and streams the result of each file as soon as it is hashed. A file that
+// cannot be hashed is reported in its result, the call stops when the client
+// cancels or the deadline is exceeded.
+func (server *FileDiscoveryServer) StreamChecksums(req *pb.StreamChecksumsRequest, stream grpc.ServerStreamingServer[pb.FileChecksum]) error {
+	algorithm := req.GetAlgorithm()
+	if _, ok := checksumHashes[algorithm]; !ok {
+		return logError(status.Errorf(codes.InvalidArgument, "unknown checksum algorithm %v", algorithm))
+	}
+
+	// Stops the workers when the stream fails
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	paths := make(chan string)
+	go func() {
+		defer close(paths)
+		for _, path := range req.GetPaths() {
+			select {
+			case paths <- path:
+			case <-ctx.Done():
+				return
 			}
 		}
-		filename := strings.Split(filepath, "/")
+	}()
 
-		fileChecksum, err := calculateHash(filepath, md5.New)
-		if err != nil {
-			continue
+	results := make(chan *pb.FileChecksum)
+	var wg sync.WaitGroup
+	for range min(checksumWorkers, len(req.GetPaths())) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				result := server.indexer.checksumFile(ctx, path, algorithm)
+				select {
+				case results <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for result := range results {
+		if err := contextError(stream.Context()); err != nil {
+			return err
+		}
+		if err := stream.Send(result); err != nil {
+			return err
 		}
-		log.Printf("receive a filepath checksum request with path :%s with checksum %s\n", filename[len(filename)-1], fileChecksum)
-		res.Checksums[filepath] = fileChecksum
 	}
+	return contextError(stream.Context())
+}
+
+// GetCrawlStats returns the throughput counters of the crawl pipeline stages
+// and the counters of the checksum cache
+func (server *FileDiscoveryServer) GetCrawlStats(ctx context.Context, req *pb.GetCrawlStatsRequest) (*pb.GetCrawlStatsResponse, error) {
+	return &pb.GetCrawlStatsResponse{
+		Stages:        server.indexer.Stats().Stages(),
+		ChecksumCache: server.indexer.checksums.Stats(),
+	}, nil
+}
+
+// defaultDuplicateGroups is the number of groups FindDuplicates returns when
+// the request has no limit
+const defaultDuplicateGroups = 100
+
+// FindDuplicates returns the groups of indexed files with the same content.
+// Files are hashed after each crawl, a file created since may be missing.
+func (server *FileDiscoveryServer) FindDuplicates(ctx context.Context, req *pb.FindDuplicatesRequest) (*pb.FindDuplicatesResponse, error) {
+	root := req.GetRoot()
+	if root != "" && !filepath.IsAbs(root) {
+		return nil, logError(status.Errorf(codes.InvalidArgument, "root %q is not an absolute path", root))
+	}
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = defaultDuplicateGroups
+	}
+
+	files := server.indexer.cache.Select(func(path string) bool {
+		return root == "" || isWithin(filepath.Clean(root), path)
+	})
+	groups := duplicateGroups(files, max(req.GetMinSize(), 1))
+	res := &pb.FindDuplicatesResponse{TotalGroups: int64(len(groups))}
+	for _, group := range groups {
+		res.TotalWastedBytes += group.WastedBytes
+	}
+	res.Groups = groups[:min(limit, len(groups))]
 	return res, nil
 }
 
+// GetDirectoryDigest returns the current Merkle digest of an indexed
+// directory, it changes whenever a file below the directory changes
+func (server *FileDiscoveryServer) GetDirectoryDigest(ctx context.Context, req *pb.GetDirectoryDigestRequest) (*pb.DirectoryDigest, error) {
+	dir := req.GetPath()
+	if !filepath.IsAbs(dir) {
+		return nil, logError(status.Errorf(codes.InvalidArgument, "path %q is not an absolute path", dir))
+	}
+	dir = filepath.Clean(dir)
+	digest, computedAt, err := server.currentDigest(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.DirectoryDigest{Path: dir, Digest: digest, ComputedAt: timestamppb.New(computedAt)}, nil
+}
+
+// DiffDirectoryDigests returns the subtrees that differ between two versions
+// of a directory. Digests of versions that left the index are kept for a
+// month.
+func (server *FileDiscoveryServer) DiffDirectoryDigests(ctx context.Context, req *pb.DiffDirectoryDigestsRequest) (*pb.DiffDirectoryDigestsResponse, error) {
+	dir := req.GetPath()
+	if dir != "" {
+		if !filepath.IsAbs(dir) {
+			return nil, logError(status.Errorf(codes.InvalidArgument, "path %q is not an absolute path", dir))
+		}
+		dir = filepath.Clean(dir)
+	}
+	if req.GetOldDigest() == "" {
+		return nil, logError(status.Error(codes.InvalidArgument, "old_digest is required"))
+	}
+	if req.GetMaxDepth() < 0 {
+		return nil, logError(status.Error(codes.InvalidArgument, "max_depth must not be negative"))
+	}
+
+	newDigest := req.GetNewDigest()
+	if newDigest == "" {
+		if dir == "" {
+			return nil, logError(status.Error(codes.InvalidArgument, "path or new_digest is required"))
+		}
+		var err error
+		newDigest, _, err = server.currentDigest(dir)
+		if err != nil {
+			return nil, err
+		}
+	} else if !server.indexer.walker.config.DirectoryDigests {
+		return nil, logError(status.Error(codes.FailedPrecondition, "directory digests are disabled by the crawl config"))
+	}
+
+	changes, err := server.indexer.merkle.Diff(dir, req.GetOldDigest(), newDigest, int(req.GetMaxDepth()))
+	if errors.Is(err, ErrDigestNotFound) {
+		return nil, logError(status.Errorf(codes.NotFound, "%v", err))
+	}
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot compare digests: %v", err))
+	}
+	return &pb.DiffDirectoryDigestsResponse{
+		OldDigest: req.GetOldDigest(),
+		NewDigest: newDigest,
+		Changes:   changes,
+	}, nil
+}
+
+// currentDigest returns the current digest of dir as a gRPC error if there is none
+func (server *FileDiscoveryServer) currentDigest(dir string) (string, time.Time, error) {
+	if !server.indexer.walker.config.DirectoryDigests {
+		return "", time.Time{}, logError(status.Error(codes.FailedPrecondition, "directory digests are disabled by the crawl config"))
+	}
+	digest, computedAt, ok := server.indexer.merkle.Digest(dir)
+	if computedAt.IsZero() {
+		return "", time.Time{}, logError(status.Error(codes.Unavailable, "directory digests are not computed yet"))
+	}
+	if !ok {
+		return "", time.Time{}, logError(status.Errorf(codes.NotFound, "no indexed file below %s", dir))
+	}
+	return digest, computedAt, nil
+}
+
+// ListFiles streams the indexed files accepted by the crawl options of the
+// request and returns. The first response carries the skipped roots and a
+// resume token, WatchChanges called with it follows the changes made after
+// the listing.
 func (server *FileDiscoveryServer) ListFiles(req *pb.CreateFileDiscoverRequest, stream grpc.ServerStreamingServer[pb.CreateFileDiscoverResponse]) error {
 	request := req.GetRequest()
 	fmt.Printf("Receive request to list all files in computer %s\n", request)
 
-	for {
-		drives := getAvailableDrives()
-		for _, drive := range drives {
-			currentFiles := make(FileInfoMap)
-			err := filepath.Walk(drive, func(path string, info fs.FileInfo, err error) error {
-				if err != nil {
-					if os.IsPermission(err) {
-						fmt.Printf("Permission denied: %s\n", path)
-						return nil
-					}
-					return err
-				}
-				// Skip directories
-				if info.IsDir() {
-					return nil
-				}
-				ext := filepath.Ext(path)
-
-				// Get file timestamps
-				createdAt, modifiedAt, accessedAt, err := getFileTimes(path)
-
-				var content string
-				switch ext {
-				case ".docx":
-					content, err = readDocxContent(path)
-				case ".xlsx":
-					content, err = readExcelContent(path)
-				case ".pptx":
-					content, err = readPPTXContent(path)
-				}
-				if err != nil {
-					fmt.Printf("Failed to read %s file: %s, error: %v\n", ext, path, err)
-					return nil
-				}
-				var attribute string
-				flagAttributes, _ := IsHiddenFile(path)
-				if flagAttributes {
-					attribute = "Hidden"
-				} else {
-					attribute = "Read Only"
-				}
-				fileAttr := &pb.FileAttr{
-					Path:       path,
-					Name:       info.Name(),
-					Type:       ext,
-					Size:       info.Size(),
-					CreatedAt:  timestamppb.New(createdAt),
-					ModifiedAt: timestamppb.New(modifiedAt),
-					AccessedAt: timestamppb.New(accessedAt),
-					Attributes: attribute,
-					Content:    content,
-				}
-				currentFiles[path] = fileAttr
-				// Save file to store if not exists
-				if _, exists := fileInfoCache[path]; !exists {
-					if err := server.fileStore.Save(fileAttr); err != nil {
-						return err
-					}
-				}
+	indexed := server.indexer.walker
+	walker, err := NewFileWalker(indexed.config.WithRequest(req))
+	if err != nil {
+		return logError(status.Errorf(codes.InvalidArgument, "invalid crawl config: %v", err))
+	}
+	skipped := walker.Skipped()
+	for _, root := range walker.Roots() {
+		if !indexed.rootsContain(root) && !containsRoot(root, indexed.Roots()) {
+			skipped = append(skipped, &pb.SkippedRoot{Path: root, Reason: "not indexed"})
+		}
+	}
 
-				res := &pb.CreateFileDiscoverResponse{
-					Files: fileAttr,
-				}
-				if err := stream.Send(res); err != nil {
-					return err
-				}
+	// Take the token first, changes made while listing are sent again by WatchChanges
+	token := encodeResumeToken(server.indexer.feed.LastSequence())
+	files := server.indexer.cache.Select(func(path string) bool {
+		return walker.AcceptFile(path) && indexed.AcceptFile(path)
+	})
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
 
-				return nil
-			})
+	err = stream.Send(&pb.CreateFileDiscoverResponse{SkippedRoots: skipped, ResumeToken: token})
+	if err != nil {
+		return err
+	}
+	for _, fileAttr := range files {
+		if err := contextError(stream.Context()); err != nil {
+			return err
+		}
+		if err := stream.Send(&pb.CreateFileDiscoverResponse{Files: fileAttr}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WatchChanges streams the changes made after the resume token of the
+// request, or after the call if no token is given, until the client cancels
+func (server *FileDiscoveryServer) WatchChanges(req *pb.WatchChangesRequest, stream grpc.ServerStreamingServer[pb.FileChange]) error {
+	feed := server.indexer.feed
+	after := feed.LastSequence()
+	if token := req.GetResumeToken(); token != "" {
+		var err error
+		after, err = decodeResumeToken(token)
+		if err != nil {
+			return logError(status.Errorf(codes.InvalidArgument, "%v", err))
+		}
+	}
 
-			if err != nil {
+	for {
+		changes, notify, err := feed.Since(after)
+		if errors.Is(err, ErrTokenNotFound) {
+			return logError(status.Errorf(codes.OutOfRange, "%v, list the files again", err))
+		}
+		if err != nil {
+			return logError(status.Errorf(codes.Internal, "cannot read changes: %v", err))
+		}
+		for _, change := range changes {
+			if err := stream.Send(change); err != nil {
 				return err
 			}
+		}
+		after += uint64(len(changes))
 
-			// Check file deleted
-			for path, fileAttr := range fileInfoCache {
-				if _, exists := currentFiles[path]; !exists {
-					if err := server.fileStore.Delete(fileAttr.Name); err != nil {
-						return err
-					}
-				}
-			}
-			// Update cache
-			fileInfoCache = currentFiles
+		select {
+		case <-notify:
+		case <-stream.Context().Done():
+			return contextError(stream.Context())
 		}
-		time.Sleep(5 * time.Second)
 	}
 }
 
+// containsRoot reports whether one of roots is inside dir
+func containsRoot(dir string, roots []string) bool {
+	for _, root := range roots {
+		if isWithin(dir, root) {
+			return true
+		}
+	}
+	return false
+}
+
+// newFileAttr reads the timestamps and attributes of a file
+func newFileAttr(path string, info fs.FileInfo) (*pb.FileAttr, error) {
+	ext := filepath.Ext(path)
+
+	// Get file timestamps
+	createdAt, modifiedAt, accessedAt, err := getFileTimes(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var attribute string
+	flagAttributes, _ := IsHiddenFile(path)
+	if flagAttributes {
+		attribute = "Hidden"
+	} else {
+		attribute = "Read Only"
+	}
+	return &pb.FileAttr{
+		Path:       path,
+		Name:       info.Name(),
+		Type:       ext,
+		Size:       info.Size(),
+		CreatedAt:  timestamppb.New(createdAt),
+		ModifiedAt: timestamppb.New(modifiedAt),
+		AccessedAt: timestamppb.New(accessedAt),
+		Attributes: attribute,
+		Inode:      fileInode(info),
+	}, nil
+}
+
 func getFileTimes(path string) (createdAt, modifiedAt, accessedAt time.Time, err error) {
 	t, err := times.Stat(path)
 	if err != nil {
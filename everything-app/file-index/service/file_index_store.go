@@ -2,66 +2,293 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"sync"
+	"training/db/memdb"
 	db "training/db/sqlc"
 	"training/file-index/pb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 var ErrAlreadyExists = errors.New("record already exists")
 
+// defaultBatchSize is the number of writes committed in one transaction
+const defaultBatchSize = 500
+
+// FileStore stores the indexed files by path. Writes may be buffered until
+// Flush is called, errors of buffered writes are returned by the call that
+// writes the batch as FileStoreErrors.
 type FileStore interface {
+	// Save stores a new file, ErrAlreadyExists is returned if the path is stored
 	Save(files *pb.FileAttr) error
+	// Update stores a new or modified file
 	Update(files *pb.FileAttr) error
-	Delete(id string) error
+	// Delete deletes the file stored at path
+	Delete(path string) error
+	// UpdateHashes stores the partial and content hashes of a stored file
+	UpdateHashes(files *pb.FileAttr) error
+	// Flush writes the buffered changes
+	Flush() error
+	// List returns the stored files below dir
+	List(dir string) ([]*pb.FileAttr, error)
+}
+
+// FileStoreError reports a write of a file that failed
+type FileStoreError struct {
+	Op   string
+	Path string
+	// File is the written file, nil for deletes
+	File *pb.FileAttr
+	Err  error
+}
+
+func (err *FileStoreError) Error() string {
+	return fmt.Sprintf("cannot %s file %s: %v", err.Op, err.Path, err.Err)
+}
+
+func (err *FileStoreError) Unwrap() error {
+	return err.Err
+}
+
+type fileOp struct {
+	op   string
+	path string
+	file *pb.FileAttr
+}
+
+// DBFileStore stores files in the database, writes are buffered and
+// committed in batches of batchSize in a single transaction
+type DBFileStore struct {
+	mutex     sync.Mutex
+	pending   []fileOp
+	batchSize int
+	// flushMutex keeps batches in order, pending can grow while a batch is written
+	flushMutex sync.Mutex
+	store      db.Store
+}
+
+// NewDBFileStore returns a new DBFileStore
+func NewDBFileStore(store db.Store, batchSize int) *DBFileStore {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	return &DBFileStore{
+		batchSize: batchSize,
+		store:     store,
+	}
+}
+
+// Save saves a file attribute in the store
+func (store *DBFileStore) Save(files *pb.FileAttr) error {
+	return store.add(fileOp{op: "save", path: files.Path, file: files})
 }
 
-// InMemoryLaptopStore stores laptop in memory
+// Update inserts or updates a file attribute in the store
+func (store *DBFileStore) Update(files *pb.FileAttr) error {
+	return store.add(fileOp{op: "update", path: files.Path, file: files})
+}
+
+// Delete deletes the file attribute stored at path
+func (store *DBFileStore) Delete(path string) error {
+	return store.add(fileOp{op: "delete", path: path})
+}
+
+// UpdateHashes stores the hashes of a file attribute in the store
+func (store *DBFileStore) UpdateHashes(files *pb.FileAttr) error {
+	return store.add(fileOp{op: "hash", path: files.Path, file: files})
+}
+
+func (store *DBFileStore) add(op fileOp) error {
+	store.mutex.Lock()
+	store.pending = append(store.pending, op)
+	full := len(store.pending) >= store.batchSize
+	store.mutex.Unlock()
+
+	if !full {
+		return nil
+	}
+	return store.Flush()
+}
+
+// Flush commits the buffered writes. A failed transaction is rolled back and
+// a FileStoreError is returned for each of its writes. Saves of paths that
+// are already stored do not fail the batch, they are returned as
+// FileStoreErrors wrapping ErrAlreadyExists.
+func (store *DBFileStore) Flush() error {
+	store.flushMutex.Lock()
+	defer store.flushMutex.Unlock()
+
+	store.mutex.Lock()
+	batch := store.pending
+	store.pending = nil
+	store.mutex.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	ops := make([]db.FileOp, len(batch))
+	for i, op := range batch {
+		switch op.op {
+		case "save":
+			arg := db.InsertFileParams(newUpsertFileParams(op.file))
+			ops[i].Insert = &arg
+		case "update":
+			arg := newUpsertFileParams(op.file)
+			ops[i].Upsert = &arg
+		case "hash":
+			ops[i].Hashes = &db.UpdateFileHashesParams{
+				Path:        op.file.Path,
+				PartialHash: op.file.PartialHash,
+				ContentHash: op.file.ContentHash,
+			}
+		default:
+			ops[i].Delete = op.path
+		}
+	}
+
+	result, err := store.store.FileBatchTx(context.Background(), ops)
+	if err != nil {
+		errs := make([]error, len(batch))
+		for i, op := range batch {
+			errs[i] = &FileStoreError{Op: op.op, Path: op.path, File: op.file, Err: err}
+		}
+		return errors.Join(errs...)
+	}
+
+	var errs []error
+	for _, i := range result.Existing {
+		op := batch[i]
+		errs = append(errs, &FileStoreError{Op: op.op, Path: op.path, File: op.file, Err: ErrAlreadyExists})
+	}
+	return errors.Join(errs...)
+}
+
+// List returns the stored files below dir
+func (store *DBFileStore) List(dir string) ([]*pb.FileAttr, error) {
+	files, err := store.store.ListFilesByPrefix(context.Background(), dirPrefix(dir))
+	if err != nil {
+		return nil, err
+	}
+	return newFileAttrs(files), nil
+}
+
+// InMemoryFileStore stores files in a memdb.Store, writes are applied at once
 type InMemoryFileStore struct {
-	mutex sync.RWMutex
-	store db.Store
+	store *memdb.Store
 }
 
 // NewInMemoryFileStore returns a new InMemoryFileStore
-func NewInMemoryFileStore(db db.Store) *InMemoryFileStore {
+func NewInMemoryFileStore(store *memdb.Store) *InMemoryFileStore {
 	return &InMemoryFileStore{
-		store: db,
+		store: store,
 	}
 }
 
 // Save saves a file attribute in the store
 func (store *InMemoryFileStore) Save(files *pb.FileAttr) error {
-	store.mutex.Lock()
-	ctx := context.Background()
-	arg := db.InsertFileParams{
-		Name:       files.Name,
-		Path:       files.Path,
-		Extension:  files.Type,
-		Size:       files.Size,
-		Attributes: files.Attributes,
-		Content:    files.Content,
-		CreatedAt:  files.CreatedAt.AsTime(),
-		ModifiedAt: files.CreatedAt.AsTime(),
-		AccessedAt: files.ModifiedAt.AsTime(),
+	_, err := store.store.InsertFile(context.Background(), db.InsertFileParams(newUpsertFileParams(files)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return &FileStoreError{Op: "save", Path: files.Path, File: files, Err: ErrAlreadyExists}
 	}
-	fmt.Println("Insert file with name: ", files.Name)
-	store.store.InsertFile(ctx, arg)
-	defer store.mutex.Unlock()
-	return nil
+	return err
 }
 
-// Update updates a file attribute in the store
+// Update inserts or updates a file attribute in the store
 func (store *InMemoryFileStore) Update(files *pb.FileAttr) error {
-	// Implementation here
-	return nil
+	_, err := store.store.UpsertFile(context.Background(), newUpsertFileParams(files))
+	return err
 }
 
-// Delete deletes a file attribute by its ID
-func (store *InMemoryFileStore) Delete(id string) error {
-	store.mutex.Lock()
-	defer store.mutex.Unlock()
+// Delete deletes the file attribute stored at path
+func (store *InMemoryFileStore) Delete(path string) error {
+	_, err := store.store.DeleteFileByPath(context.Background(), path)
+	return err
+}
 
-	// Your logic to delete the file attribute by ID
+// UpdateHashes stores the hashes of a file attribute in the store
+func (store *InMemoryFileStore) UpdateHashes(files *pb.FileAttr) error {
+	_, err := store.store.UpdateFileHashes(context.Background(), db.UpdateFileHashesParams{
+		Path:        files.Path,
+		PartialHash: files.PartialHash,
+		ContentHash: files.ContentHash,
+	})
+	return err
+}
+
+// Flush does nothing, writes are not buffered
+func (store *InMemoryFileStore) Flush() error {
 	return nil
 }
+
+// List returns the stored files below dir
+func (store *InMemoryFileStore) List(dir string) ([]*pb.FileAttr, error) {
+	files, err := store.store.ListFilesByPrefix(context.Background(), dirPrefix(dir))
+	if err != nil {
+		return nil, err
+	}
+	return newFileAttrs(files), nil
+}
+
+// dirPrefix returns the prefix of the paths below dir
+func dirPrefix(dir string) string {
+	if dir == string(filepath.Separator) {
+		return dir
+	}
+	return dir + string(filepath.Separator)
+}
+
+func newFileAttrs(files []db.File) []*pb.FileAttr {
+	fileAttrs := make([]*pb.FileAttr, len(files))
+	for i, file := range files {
+		fileAttrs[i] = &pb.FileAttr{
+			Path:        file.Path,
+			Name:        file.Name,
+			Type:        file.Extension,
+			Size:        file.Size,
+			CreatedAt:   timestamppb.New(file.CreatedAt),
+			ModifiedAt:  timestamppb.New(file.ModifiedAt),
+			AccessedAt:  timestamppb.New(file.AccessedAt),
+			Attributes:  file.Attributes,
+			ContentHash: file.ContentHash,
+			PartialHash: file.PartialHash,
+		}
+		if len(file.Metadata) > 0 {
+			// Invalid metadata is dropped, it is extracted again when the file changes
+			_ = json.Unmarshal(file.Metadata, &fileAttrs[i].Metadata)
+		}
+	}
+	return fileAttrs
+}
+
+func newUpsertFileParams(files *pb.FileAttr) db.UpsertFileParams {
+	return db.UpsertFileParams{
+		Name:        files.Name,
+		Path:        files.Path,
+		Extension:   files.Type,
+		Size:        files.Size,
+		Attributes:  files.Attributes,
+		Content:     files.Content,
+		CreatedAt:   files.CreatedAt.AsTime(),
+		ModifiedAt:  files.ModifiedAt.AsTime(),
+		AccessedAt:  files.AccessedAt.AsTime(),
+		PartialHash: files.PartialHash,
+		ContentHash: files.ContentHash,
+		Metadata:    metadataJSON(files.Metadata),
+	}
+}
+
+// metadataJSON encodes metadata as a JSON object, the column cannot be null
+func metadataJSON(metadata map[string]string) json.RawMessage {
+	if len(metadata) == 0 {
+		return json.RawMessage("{}")
+	}
+	// A map of strings always encodes
+	data, _ := json.Marshal(metadata)
+	return data
+}
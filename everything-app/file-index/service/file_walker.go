@@ -0,0 +1,300 @@
+package service
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"training/file-index/pb"
+)
+
+// networkFileSystems are the mount types skipped when SkipNetworkMounts is set
+var networkFileSystems = map[string]bool{
+	"nfs":            true,
+	"nfs4":           true,
+	"cifs":           true,
+	"smb3":           true,
+	"smbfs":          true,
+	"9p":             true,
+	"afs":            true,
+	"ceph":           true,
+	"glusterfs":      true,
+	"fuse.glusterfs": true,
+	"fuse.sshfs":     true,
+	"fuse.s3fs":      true,
+	"davfs":          true,
+	"lustre":         true,
+}
+
+type crawlRoot struct {
+	path      string
+	device    uint64
+	hasDevice bool
+}
+
+// FileWalker walks the roots of a CrawlConfig and applies its include,
+// exclude, depth and mount rules
+type FileWalker struct {
+	config  CrawlConfig
+	include PatternList
+	exclude PatternList
+	roots   []crawlRoot
+	skipped []*pb.SkippedRoot
+	mounts  map[string]string
+}
+
+// NewFileWalker validates the configuration and resolves its roots. Roots
+// that cannot be indexed are reported by Skipped rather than failing.
+func NewFileWalker(config CrawlConfig) (*FileWalker, error) {
+	include, err := ParsePatterns(config.Include)
+	if err != nil {
+		return nil, fmt.Errorf("include: %w", err)
+	}
+	exclude, err := ParsePatterns(config.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("exclude: %w", err)
+	}
+	mounts, err := readMounts()
+	if err != nil {
+		log.Printf("cannot read mount table: %v", err)
+	}
+
+	walker := &FileWalker{
+		config:  config,
+		include: include,
+		exclude: exclude,
+		mounts:  mounts,
+	}
+	walker.resolveRoots()
+	return walker, nil
+}
+
+func (walker *FileWalker) resolveRoots() {
+	paths := make([]string, 0, len(walker.config.Roots))
+	for _, root := range walker.config.Roots {
+		if !filepath.IsAbs(root) {
+			walker.skip(root, "not an absolute path")
+			continue
+		}
+		paths = append(paths, filepath.Clean(root))
+	}
+	// Shorter paths first so nested roots are detected
+	sort.Slice(paths, func(i, j int) bool { return len(paths[i]) < len(paths[j]) })
+
+	for _, path := range paths {
+		if parent, ok := walker.rootOf(path); ok {
+			walker.skip(path, fmt.Sprintf("inside root %s", parent.path))
+			continue
+		}
+		if walker.isSkipPath(path) {
+			walker.skip(path, "excluded by skip_paths")
+			continue
+		}
+		if fsType := walker.mountType(path); walker.config.SkipNetworkMounts && networkFileSystems[fsType] {
+			walker.skip(path, fmt.Sprintf("network file system %s", fsType))
+			continue
+		}
+		info, err := os.Stat(path)
+		switch {
+		case os.IsNotExist(err):
+			walker.skip(path, "does not exist")
+			continue
+		case os.IsPermission(err):
+			walker.skip(path, "permission denied")
+			continue
+		case err != nil:
+			walker.skip(path, err.Error())
+			continue
+		case !info.IsDir():
+			walker.skip(path, "not a directory")
+			continue
+		}
+		root := crawlRoot{path: path}
+		root.device, root.hasDevice = deviceID(info)
+		walker.roots = append(walker.roots, root)
+	}
+}
+
+func (walker *FileWalker) skip(path, reason string) {
+	log.Printf("skip root %s: %s", path, reason)
+	walker.skipped = append(walker.skipped, &pb.SkippedRoot{Path: path, Reason: reason})
+}
+
+// Roots returns the roots that will be crawled
+func (walker *FileWalker) Roots() []string {
+	roots := make([]string, len(walker.roots))
+	for i, root := range walker.roots {
+		roots[i] = root.path
+	}
+	return roots
+}
+
+// Skipped returns the configured roots that will not be crawled and why
+func (walker *FileWalker) Skipped() []*pb.SkippedRoot {
+	return walker.skipped
+}
+
+// Walk calls onDir for every directory and onFile for every file accepted
+// by the crawl rules below all roots. Either callback may be nil. Files are
+// not stat'ed, onFile only gets the directory entry.
+func (walker *FileWalker) Walk(onDir func(path string) error, onFile func(path string, d fs.DirEntry) error) error {
+	for _, root := range walker.roots {
+		if err := walker.WalkDir(root.path, onDir, onFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkDir is like Walk but starts at dir, which must be inside one of the roots
+func (walker *FileWalker) WalkDir(dir string, onDir func(path string) error, onFile func(path string, d fs.DirEntry) error) error {
+	root, ok := walker.rootOf(dir)
+	if !ok {
+		return nil
+	}
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsPermission(err) {
+				fmt.Printf("Permission denied: %s\n", path)
+				return nil
+			}
+			return err
+		}
+		if path != dir && !walker.accept(root, path, d.IsDir(), d.Info) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if onDir != nil {
+				return onDir(path)
+			}
+			return nil
+		}
+		if onFile != nil {
+			return onFile(path, d)
+		}
+		return nil
+	})
+}
+
+// Accept reports whether path is inside a root and allowed by the crawl rules
+func (walker *FileWalker) Accept(path string, info fs.FileInfo) bool {
+	root, ok := walker.rootOf(path)
+	if !ok {
+		return false
+	}
+	return walker.accept(root, path, info.IsDir(), func() (fs.FileInfo, error) { return info, nil })
+}
+
+// AcceptFile is like Accept for a file that is known not to be a directory,
+// without reading its attributes. Archive members are accepted with their
+// archive.
+func (walker *FileWalker) AcceptFile(path string) bool {
+	path = archiveOf(path)
+	root, ok := walker.rootOf(path)
+	if !ok {
+		return false
+	}
+	return walker.accept(root, path, false, nil)
+}
+
+// accept applies the crawl rules to path, info is only called for
+// directories when the device has to be checked
+func (walker *FileWalker) accept(root crawlRoot, path string, isDir bool, info func() (fs.FileInfo, error)) bool {
+	rel, err := filepath.Rel(root.path, path)
+	if err != nil {
+		return false
+	}
+	if rel == "." {
+		return true
+	}
+	rel = filepath.ToSlash(rel)
+
+	if walker.isSkipPath(path) {
+		return false
+	}
+	if maxDepth := walker.config.MaxDepth; maxDepth > 0 {
+		// The root is on depth 0 like in find, a file is on the depth of
+		// its directory
+		depth := strings.Count(rel, "/")
+		if isDir {
+			depth++
+		}
+		if depth > maxDepth {
+			return false
+		}
+	}
+	if walker.exclude.Match(rel, isDir) {
+		return false
+	}
+	if !isDir {
+		return len(walker.include) == 0 || walker.include.Match(rel, false)
+	}
+
+	if fsType, ok := walker.mounts[path]; ok && walker.config.SkipNetworkMounts && networkFileSystems[fsType] {
+		log.Printf("skip network mount %s (%s)", path, fsType)
+		return false
+	}
+	if walker.config.OneFileSystem && root.hasDevice {
+		info, err := info()
+		if err != nil {
+			return false
+		}
+		if device, ok := deviceID(info); ok && device != root.device {
+			return false
+		}
+	}
+	return true
+}
+
+// rootsContain reports whether path is inside one of the roots
+func (walker *FileWalker) rootsContain(path string) bool {
+	_, ok := walker.rootOf(path)
+	return ok
+}
+
+// rootOf returns the root that contains path
+func (walker *FileWalker) rootOf(path string) (crawlRoot, bool) {
+	for _, root := range walker.roots {
+		if isWithin(root.path, path) {
+			return root, true
+		}
+	}
+	return crawlRoot{}, false
+}
+
+func (walker *FileWalker) isSkipPath(path string) bool {
+	for _, skip := range walker.config.SkipPaths {
+		if isWithin(filepath.Clean(skip), path) {
+			return true
+		}
+	}
+	return false
+}
+
+// mountType returns the file system type of the mount containing path
+func (walker *FileWalker) mountType(path string) string {
+	var mountPoint, fsType string
+	for mount, typ := range walker.mounts {
+		if isWithin(mount, path) && len(mount) > len(mountPoint) {
+			mountPoint, fsType = mount, typ
+		}
+	}
+	return fsType
+}
+
+// isWithin reports whether path is dir or inside dir
+func isWithin(dir, path string) bool {
+	if path == dir {
+		return true
+	}
+	if !strings.HasSuffix(dir, string(filepath.Separator)) {
+		dir += string(filepath.Separator)
+	}
+	return strings.HasPrefix(path, dir)
+}
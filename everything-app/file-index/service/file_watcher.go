@@ -0,0 +1,53 @@
+package service
+
+import (
+	"log"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// FileWatcher watches directory trees for changes using fsnotify
+// (inotify on Linux). fsnotify only watches single directories, so every
+// sub directory has to be added explicitly.
+type FileWatcher struct {
+	watcher *fsnotify.Watcher
+}
+
+// NewFileWatcher returns a new FileWatcher
+func NewFileWatcher() (*FileWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &FileWatcher{
+		watcher: watcher,
+	}, nil
+}
+
+// AddRecursive adds a watch on dir and on every directory below it that is
+// accepted by walker. Directories that cannot be watched are logged and
+// skipped, the periodic reconciliation crawl still picks up changes inside them.
+func (w *FileWatcher) AddRecursive(walker *FileWalker, dir string) error {
+	return walker.WalkDir(dir, func(path string) error {
+		if err := w.watcher.Add(path); err != nil {
+			log.Printf("cannot watch directory %s: %v\n", path, err)
+		}
+		return nil
+	}, nil)
+}
+
+// Events returns the channel of file system events
+func (w *FileWatcher) Events() <-chan fsnotify.Event {
+	return w.watcher.Events
+}
+
+// Errors returns the channel of watcher errors. fsnotify.ErrEventOverflow
+// is sent when the kernel queue overflowed and events were lost.
+func (w *FileWatcher) Errors() <-chan error {
+	return w.watcher.Errors
+}
+
+// Close stops watching and releases the watcher
+func (w *FileWatcher) Close() error {
+	return w.watcher.Close()
+}
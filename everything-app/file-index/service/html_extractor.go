@@ -0,0 +1,125 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// The saved web pages
+func init() {
+	err := RegisterExtractor(ExtractorConfig{
+		Name:       "html",
+		Extensions: []string{".html", ".htm", ".xhtml"},
+		MIMETypes:  []string{"text/html"},
+		Extractor:  ExtractorFunc(readHTMLDocument),
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// readHTMLDocument reads the text, title and author of an HTML page in the
+// charset given by its BOM or meta tags
+func readHTMLDocument(ctx context.Context, path string) (*Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := charset.NewReader(file, "")
+	if err != nil {
+		return nil, err
+	}
+	text := newTextBuilder(ctx)
+	document := &Document{}
+	if err := parseHTML(reader, text, document); err != nil {
+		return nil, err
+	}
+	document.Content = normalizeText(text.String())
+	return document, nil
+}
+
+// htmlSkipped are the elements without readable text
+var htmlSkipped = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Svg: true, atom.Math: true, atom.Iframe: true, atom.Object: true,
+}
+
+// htmlBlocks are the elements that start a new line
+var htmlBlocks = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Br: true, atom.Hr: true, atom.Li: true,
+	atom.Ul: true, atom.Ol: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Table: true, atom.Tr: true, atom.Pre: true, atom.Blockquote: true,
+	atom.Section: true, atom.Article: true, atom.Header: true, atom.Footer: true,
+	atom.Nav: true, atom.Aside: true, atom.Main: true, atom.Figure: true, atom.Figcaption: true,
+	atom.Address: true, atom.Form: true, atom.Fieldset: true, atom.Caption: true,
+}
+
+// parseHTML appends the text of the HTML page in r to text and sets the
+// title and author of document if they are not set yet. A byte order mark
+// is dropped, the parser keeps it as text.
+func parseHTML(r io.Reader, text *textBuilder, document *Document) error {
+	buffered := bufio.NewReader(r)
+	if bom, err := buffered.Peek(3); err == nil && string(bom) == "\xEF\xBB\xBF" {
+		buffered.Discard(3)
+	}
+	root, err := html.Parse(buffered)
+	if err != nil {
+		return err
+	}
+
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		if text.full() {
+			return
+		}
+		switch node.Type {
+		case html.TextNode:
+			text.WriteString(node.Data)
+			return
+		case html.ElementNode:
+			switch {
+			case htmlSkipped[node.DataAtom]:
+				return
+			case node.DataAtom == atom.Title:
+				if document.Metadata[MetaTitle] == "" && node.FirstChild != nil {
+					document.setMeta(MetaTitle, strings.Join(strings.Fields(node.FirstChild.Data), " "))
+				}
+				return
+			case node.DataAtom == atom.Meta:
+				if strings.EqualFold(htmlAttr(node, "name"), "author") && document.Metadata[MetaAuthor] == "" {
+					document.setMeta(MetaAuthor, htmlAttr(node, "content"))
+				}
+				return
+			case node.DataAtom == atom.Td || node.DataAtom == atom.Th:
+				defer text.WriteString("\t")
+			case htmlBlocks[node.DataAtom]:
+				text.WriteString("\n")
+				defer text.WriteString("\n")
+			}
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(root)
+	return nil
+}
+
+func htmlAttr(node *html.Node, name string) string {
+	for _, attr := range node.Attr {
+		if strings.EqualFold(attr.Key, name) {
+			return attr.Val
+		}
+	}
+	return ""
+}
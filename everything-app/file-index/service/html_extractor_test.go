@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestReadHTMLDocument(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		html string
+		want Document
+	}{
+		{
+			name: "page",
+			html: `<!DOCTYPE html><html><head><title>  Quarterly
+   report </title><meta name="Author" content="Jane Doe"><style>p { color: red }</style>
+<script>var hidden = "script";</script></head><body><nav>Home</nav><h1>Results</h1><p>Revenue <b>grew</b> by 5%.</p>
+<table><tr><th>Year</th><th>Total</th></tr><tr><td>2024</td><td>1&nbsp;000 &euro;</td></tr></table>
+<noscript>Enable scripts</noscript><svg><text>Chart</text></svg><ul><li>one</li><li>two</li></ul></body></html>`,
+			want: Document{
+				Content:  "Home\n\nResults\n\nRevenue grew by 5%.\n\nYear\tTotal\n\n2024\t1 000 €\n\none\n\ntwo",
+				Metadata: map[string]string{MetaTitle: "Quarterly report", MetaAuthor: "Jane Doe"},
+			},
+		},
+		{
+			name: "windows-1252 page",
+			html: `<html><head><meta charset="windows-1252"><title>Caf` + "\xE9" + `</title></head><body><p>na` + "\xEF" + `ve ` + "\x80" + `</p></body></html>`,
+			want: Document{Content: "naïve €", Metadata: map[string]string{MetaTitle: "Café"}},
+		},
+		{
+			name: "utf-16 page with bom",
+			html: "\xFF\xFE<\x00p\x00>\x00h\x00\xE9\x00<\x00/\x00p\x00>\x00",
+			want: Document{Content: "hé"},
+		},
+		{
+			name: "utf-8 page with bom",
+			html: "\xEF\xBB\xBF<p>h\xC3\xA9</p>",
+			want: Document{Content: "hé"},
+		},
+	} {
+		path, _ := writeFile(t, "page.html", []byte(test.html))
+		document, err := readHTMLDocument(context.Background(), path)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*document, test.want) {
+			t.Errorf("%s: read %+v, want %+v", test.name, *document, test.want)
+		}
+	}
+}
@@ -0,0 +1,433 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+	"training/file-index/pb"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// IndexMode selects how the indexer keeps the store up to date
+type IndexMode string
+
+const (
+	// IndexModeWatch crawls once and then follows file system events
+	IndexModeWatch IndexMode = "watch"
+	// IndexModeRescan walks every root again every few seconds
+	IndexModeRescan IndexMode = "rescan"
+)
+
+const (
+	rescanInterval     = 5 * time.Second
+	eventDebounce      = 500 * time.Millisecond
+	checkpointInterval = 30 * time.Second
+	changeHistory      = 100000
+)
+
+// Indexer crawls the configured roots in the background, keeps the store up
+// to date and publishes every change to its ChangeFeed
+type Indexer struct {
+	fileStore         FileStore
+	walker            *FileWalker
+	mode              IndexMode
+	reconcileInterval time.Duration
+	cache             *fileCache
+	checksums         *checksumCache
+	merkle            *merkleTree
+	extractors        *ExtractorRegistry
+	stats             *CrawlStats
+	feed              *ChangeFeed
+	state             *CrawlState
+	// savedVersion and savedChecksumVersion are the cache versions written
+	// to the last snapshot
+	savedVersion         atomic.Uint64
+	savedChecksumVersion atomic.Uint64
+	// hashedVersion and digestedVersion are the cache versions after the
+	// last hashDuplicates and updateDigests
+	hashedVersion   atomic.Uint64
+	digestedVersion atomic.Uint64
+	// resume holds the checkpoint of each root of an interrupted crawl
+	resumeMutex sync.Mutex
+	resume      map[string]string
+}
+
+// NewIndexer returns a new Indexer crawling the roots of crawlConfig. In
+// watch mode a reconciliation crawl runs every reconcileInterval to catch
+// lost events.
+func NewIndexer(fileStore FileStore, crawlConfig CrawlConfig, mode IndexMode, reconcileInterval time.Duration) (*Indexer, error) {
+	walker, err := NewFileWalker(crawlConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &Indexer{
+		fileStore:         fileStore,
+		walker:            walker,
+		mode:              mode,
+		reconcileInterval: reconcileInterval,
+		cache:             newFileCache(),
+		checksums:         newChecksumCache(),
+		merkle:            newMerkleTree(),
+		extractors:        DefaultExtractors.WithLimits(crawlConfig.Extractors, crawlConfig.MaxContentBytes),
+		stats:             NewCrawlStats(),
+		feed:              NewChangeFeed(changeHistory),
+	}, nil
+}
+
+// Stats returns the crawl pipeline counters
+func (indexer *Indexer) Stats() *CrawlStats {
+	return indexer.stats
+}
+
+// Feed returns the feed of file changes
+func (indexer *Indexer) Feed() *ChangeFeed {
+	return indexer.feed
+}
+
+// SetExtractors replaces the content extractors, DefaultExtractors by
+// default. It must be called before the indexer runs.
+func (indexer *Indexer) SetExtractors(extractors *ExtractorRegistry) {
+	indexer.extractors = extractors
+}
+
+// RestoreState loads the files and checkpoints saved by the previous run so
+// the first crawl only stores real changes and resumes where it stopped.
+// The state is saved again after every crawl and every checkpointInterval.
+func (indexer *Indexer) RestoreState(state *CrawlState) error {
+	snapshot, err := state.Load()
+	if err != nil {
+		return err
+	}
+	for _, fileAttr := range snapshot.GetFiles() {
+		indexer.cache.Put(fileAttr)
+	}
+	indexer.checksums.Restore(snapshot.GetChecksums())
+	indexer.merkle.Restore(snapshot.GetMerkleNodes())
+	indexer.savedVersion.Store(indexer.cache.Version())
+	indexer.savedChecksumVersion.Store(indexer.checksums.Version())
+	indexer.feed.Reset(snapshot.GetLastSequence())
+
+	indexer.resumeMutex.Lock()
+	defer indexer.resumeMutex.Unlock()
+	indexer.resume = make(map[string]string)
+	for _, checkpoint := range snapshot.GetCheckpoints() {
+		if checkpoint.GetLastPath() != "" {
+			indexer.resume[checkpoint.GetRoot()] = checkpoint.GetLastPath()
+		}
+	}
+	indexer.state = state
+	log.Printf("restored %d files and %d checkpoints from crawl state", len(snapshot.GetFiles()), len(indexer.resume))
+	return nil
+}
+
+// Run keeps the store up to date until ctx is done. Crawl errors are logged
+// and the crawl is retried, Run only fails if file events cannot be watched.
+func (indexer *Indexer) Run(ctx context.Context) error {
+	if indexer.cache.Version() == 0 {
+		indexer.loadStore()
+	}
+	if indexer.mode == IndexModeRescan {
+		return indexer.rescanFiles(ctx)
+	}
+	return indexer.watchFiles(ctx)
+}
+
+// rescanFiles walks every root again every rescanInterval
+func (indexer *Indexer) rescanFiles(ctx context.Context) error {
+	for {
+		indexer.crawlAndLog(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(rescanInterval):
+		}
+	}
+}
+
+// watchFiles crawls every root once, then applies file system events to the
+// store as they arrive. A reconciliation crawl runs every reconcileInterval
+// and whenever the watcher reports that events were lost.
+func (indexer *Indexer) watchFiles(ctx context.Context) error {
+	watcher, err := NewFileWatcher()
+	if err != nil {
+		return fmt.Errorf("cannot create file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	// Watch before crawling so changes made during the first crawl are not lost
+	for _, root := range indexer.walker.Roots() {
+		if err := watcher.AddRecursive(indexer.walker, root); err != nil {
+			return err
+		}
+	}
+	indexer.crawlAndLog(ctx)
+
+	reconcile := time.NewTicker(indexer.reconcileInterval)
+	defer reconcile.Stop()
+	save := time.NewTicker(checkpointInterval)
+	defer save.Stop()
+	debounce := time.NewTicker(eventDebounce)
+	defer debounce.Stop()
+
+	// Paths touched by events since the last flush. Editors write a file in
+	// several steps, so events are collected and applied once per eventDebounce.
+	pending := make(map[string]struct{})
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events():
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			pending[event.Name] = struct{}{}
+		case err, ok := <-watcher.Errors():
+			if !ok {
+				return nil
+			}
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				log.Print("file watcher queue overflowed, reconciling index")
+				clear(pending)
+				indexer.crawlAndLog(ctx)
+				continue
+			}
+			log.Printf("file watcher error: %v", err)
+		case <-debounce.C:
+			if len(pending) == 0 {
+				continue
+			}
+			err := indexer.applyChanges(watcher, pending)
+			if err == nil {
+				err = indexer.flush()
+			}
+			if err != nil {
+				log.Printf("cannot apply file changes: %v", err)
+			}
+			clear(pending)
+		case <-reconcile.C:
+			indexer.crawlAndLog(ctx)
+		case <-save.C:
+			if err := indexer.hashFiles(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("cannot hash files: %v", err)
+			}
+			indexer.saveStateIfChanged()
+		}
+	}
+}
+
+func (indexer *Indexer) crawlAndLog(ctx context.Context) {
+	if err := indexer.crawl(ctx); err != nil && ctx.Err() == nil {
+		log.Printf("crawl failed: %v", err)
+	}
+}
+
+// applyChanges brings the store in line with the current state of paths,
+// which may have been created, modified, renamed or removed. Existing paths
+// are applied first so a rename is seen before the old path is removed.
+func (indexer *Indexer) applyChanges(watcher *FileWatcher, paths map[string]struct{}) error {
+	var removed []string
+	for path := range paths {
+		info, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			removed = append(removed, path)
+			continue
+		}
+		if err != nil {
+			if os.IsPermission(err) {
+				fmt.Printf("Permission denied: %s\n", path)
+				continue
+			}
+			return err
+		}
+		if err := indexer.applyChange(watcher, path, info); err != nil {
+			return err
+		}
+	}
+	for _, path := range removed {
+		if err := indexer.removeFiles(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (indexer *Indexer) applyChange(watcher *FileWatcher, path string, info fs.FileInfo) error {
+	if !indexer.walker.Accept(path, info) {
+		return nil
+	}
+	if !info.IsDir() {
+		return indexer.indexFile(path, info)
+	}
+
+	// A new directory: watch it and index whatever was written into it
+	// before the watch was in place.
+	if err := watcher.AddRecursive(indexer.walker, path); err != nil {
+		return err
+	}
+	return indexer.walker.WalkDir(path, nil, func(path string, d fs.DirEntry) error {
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		return indexer.indexFile(path, info)
+	})
+}
+
+// removeFiles deletes path, and everything below it if it was a directory
+// or the members if it was an archive, from the store
+func (indexer *Indexer) removeFiles(path string) error {
+	removed := indexer.cache.Select(func(cachedPath string) bool {
+		return isWithin(path, archiveOf(cachedPath))
+	})
+	for _, fileAttr := range removed {
+		if err := indexer.deleteFile(fileAttr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (indexer *Indexer) deleteFile(fileAttr *pb.FileAttr) error {
+	if err := indexer.handleStoreError(indexer.fileStore.Delete(fileAttr.Path)); err != nil {
+		return err
+	}
+	indexer.cache.Delete(fileAttr.Path)
+	indexer.checksums.Invalidate(fileAttr.Path)
+	indexer.feed.Publish(pb.ChangeType_DELETED, fileAttr, "")
+	return nil
+}
+
+// loadStore fills an empty cache with the files already in the store, so the
+// first crawl without a crawl state does not write every file again
+func (indexer *Indexer) loadStore() {
+	for _, root := range indexer.walker.Roots() {
+		files, err := indexer.fileStore.List(root)
+		if err != nil {
+			log.Printf("cannot list stored files of %s: %v", root, err)
+			continue
+		}
+		for _, fileAttr := range files {
+			indexer.cache.Put(fileAttr)
+		}
+	}
+}
+
+// flush writes the buffered store changes
+func (indexer *Indexer) flush() error {
+	return indexer.handleStoreError(indexer.fileStore.Flush())
+}
+
+// handleStoreError retries saves of files that are already stored as updates
+// and drops files that could not be written from the cache, so the next crawl
+// writes them again. The errors that are left are returned.
+func (indexer *Indexer) handleStoreError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var errs []error
+	for _, err := range unwrapErrors(err) {
+		var storeErr *FileStoreError
+		if !errors.As(err, &storeErr) || storeErr.File == nil {
+			errs = append(errs, err)
+			continue
+		}
+		if errors.Is(storeErr, ErrAlreadyExists) {
+			err = indexer.handleStoreError(indexer.fileStore.Update(storeErr.File))
+			if err == nil {
+				continue
+			}
+		}
+		indexer.cache.Delete(storeErr.Path)
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// unwrapErrors returns the errors joined in err
+func unwrapErrors(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+// takeCheckpoint returns the path to resume the crawl of root after, a
+// checkpoint is only used by the first crawl after a restart
+func (indexer *Indexer) takeCheckpoint(root string) string {
+	indexer.resumeMutex.Lock()
+	defer indexer.resumeMutex.Unlock()
+
+	lastPath := indexer.resume[root]
+	delete(indexer.resume, root)
+	return lastPath
+}
+
+// saveState writes the caches and the checkpoints of a running crawl to the crawl state
+func (indexer *Indexer) saveState(checkpoints []*pb.CrawlCheckpoint) error {
+	if indexer.state == nil {
+		return nil
+	}
+	version := indexer.cache.Version()
+	checksumVersion := indexer.checksums.Version()
+	snapshot := &pb.CrawlSnapshot{
+		Files:        indexer.cache.Select(func(string) bool { return true }),
+		Checkpoints:  checkpoints,
+		LastSequence: indexer.feed.LastSequence(),
+		Checksums:    indexer.checksums.Entries(),
+		MerkleNodes:  indexer.merkle.Nodes(),
+	}
+	if err := indexer.state.Save(snapshot); err != nil {
+		return logError(fmt.Errorf("cannot save crawl state: %w", err))
+	}
+	indexer.savedVersion.Store(version)
+	indexer.savedChecksumVersion.Store(checksumVersion)
+	return nil
+}
+
+// saveStateIfChanged saves the state if files or checksums changed since the
+// last save
+func (indexer *Indexer) saveStateIfChanged() error {
+	if indexer.cache.Version() == indexer.savedVersion.Load() &&
+		indexer.checksums.Version() == indexer.savedChecksumVersion.Load() {
+		return nil
+	}
+	return indexer.saveState(nil)
+}
+
+// saveCheckpoints saves the state with the checkpoints returned by fn every
+// checkpointInterval until the returned stop function is called
+func (indexer *Indexer) saveCheckpoints(fn func() []*pb.CrawlCheckpoint) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(checkpointInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// Errors are logged, the crawl goes on without a checkpoint
+				indexer.saveState(fn())
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}
+}
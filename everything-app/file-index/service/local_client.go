@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"net"
+	"training/file-index/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const localBufferSize = 1 << 20
+
+// NewLocalClient serves server on an in-process connection and returns a
+// client for it, so the search API and the indexer run in one binary without
+// network or TLS. The returned stop function shuts the server down.
+func NewLocalClient(server pb.FileIndexServer) (pb.FileIndexClient, func(), error) {
+	listener := bufconn.Listen(localBufferSize)
+	grpcServer := grpc.NewServer()
+	pb.RegisterFileIndexServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///local",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		grpcServer.Stop()
+		return nil, nil, err
+	}
+	stop := func() {
+		conn.Close()
+		grpcServer.Stop()
+	}
+	return pb.NewFileIndexClient(conn), stop, nil
+}
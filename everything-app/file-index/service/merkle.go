@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+	"training/file-index/pb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// merkleRetention is how long a directory version that is no longer in the
+// tree can still be compared
+const merkleRetention = 30 * 24 * time.Hour
+
+var ErrDigestNotFound = errors.New("directory digest not found")
+
+// merkleTree keeps the Merkle digests of the indexed directories. The leaves
+// are the content hashes of the files and a directory digest hashes the
+// digests of its entries, so comparing two digests only descends into the
+// subtrees that differ. Nodes are stored by digest and kept for
+// merkleRetention after they leave the tree, so older versions of a
+// directory can be compared with the current one.
+type merkleTree struct {
+	mutex sync.RWMutex
+	nodes map[string]*pb.MerkleNode
+	// dirs maps the indexed directories to their current digest
+	dirs       map[string]string
+	computedAt time.Time
+}
+
+func newMerkleTree() *merkleTree {
+	return &merkleTree{
+		nodes: make(map[string]*pb.MerkleNode),
+		dirs:  make(map[string]string),
+	}
+}
+
+// merkleDigest returns the digest of a directory with entries sorted by name
+func merkleDigest(entries []*pb.MerkleEntry) string {
+	h := sha256.New()
+	for _, entry := range entries {
+		kind := "file"
+		if entry.Directory {
+			kind = "dir"
+		}
+		fmt.Fprintf(h, "%s %s %s\n", kind, entry.Digest, strconv.Quote(entry.Name))
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// Build computes the digests of roots and of every directory below them from
+// the content hashes of files. Files without a content hash are left out.
+func (tree *merkleTree) Build(roots []string, files []*pb.FileAttr) {
+	entries := make(map[string][]*pb.MerkleEntry)
+	for _, root := range roots {
+		entries[root] = nil
+	}
+	for _, fileAttr := range files {
+		root := rootOf(roots, fileAttr.Path)
+		if root == "" || fileAttr.ContentHash == "" {
+			continue
+		}
+		// Register the directories up to the root
+		dir := filepath.Dir(fileAttr.Path)
+		for parent := dir; parent != root; parent = filepath.Dir(parent) {
+			if _, ok := entries[parent]; ok {
+				break
+			}
+			entries[parent] = nil
+		}
+		entries[dir] = append(entries[dir], &pb.MerkleEntry{Name: filepath.Base(fileAttr.Path), Digest: fileAttr.ContentHash})
+	}
+
+	// Deepest directories first, so the digests of subdirectories are known
+	dirs := make([]string, 0, len(entries))
+	for dir := range entries {
+		dirs = append(dirs, dir)
+	}
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i]) > len(dirs[j]) })
+
+	now := time.Now()
+	digests := make(map[string]string, len(dirs))
+	nodes := make(map[string]*pb.MerkleNode, len(dirs))
+	for _, dir := range dirs {
+		dirEntries := entries[dir]
+		sort.Slice(dirEntries, func(i, j int) bool { return dirEntries[i].Name < dirEntries[j].Name })
+		digest := merkleDigest(dirEntries)
+		digests[dir] = digest
+		nodes[digest] = &pb.MerkleNode{Digest: digest, Entries: dirEntries, LastSeen: timestamppb.New(now)}
+		if parent := filepath.Dir(dir); parent != dir && !isRoot(roots, dir) {
+			entries[parent] = append(entries[parent], &pb.MerkleEntry{Name: filepath.Base(dir), Directory: true, Digest: digest})
+		}
+	}
+
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	for digest, node := range tree.nodes {
+		if _, ok := nodes[digest]; !ok && now.Sub(node.LastSeen.AsTime()) < merkleRetention {
+			nodes[digest] = node
+		}
+	}
+	tree.nodes = nodes
+	tree.dirs = digests
+	tree.computedAt = now
+}
+
+// rootOf returns the root of roots that path is within
+func rootOf(roots []string, path string) string {
+	for _, root := range roots {
+		if isWithin(root, path) && root != path {
+			return root
+		}
+	}
+	return ""
+}
+
+func isRoot(roots []string, dir string) bool {
+	for _, root := range roots {
+		if root == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// Digest returns the current digest of dir and when it was computed
+func (tree *merkleTree) Digest(dir string) (string, time.Time, bool) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	digest, ok := tree.dirs[dir]
+	return digest, tree.computedAt, ok
+}
+
+// Restore adds the nodes saved with the crawl state
+func (tree *merkleTree) Restore(nodes []*pb.MerkleNode) {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	for _, node := range nodes {
+		tree.nodes[node.Digest] = node
+	}
+}
+
+// Nodes returns the stored nodes, they are never modified once stored
+func (tree *merkleTree) Nodes() []*pb.MerkleNode {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	nodes := make([]*pb.MerkleNode, 0, len(tree.nodes))
+	for _, node := range tree.nodes {
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// Diff returns the subtrees that differ between the directories with digests
+// oldDigest and newDigest. The paths are joined to base. Directories are
+// compared down to maxDepth levels, 0 means unlimited.
+func (tree *merkleTree) Diff(base, oldDigest, newDigest string, maxDepth int) ([]*pb.DigestChange, error) {
+	tree.mutex.RLock()
+	defer tree.mutex.RUnlock()
+
+	oldNode, ok := tree.nodes[oldDigest]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDigestNotFound, oldDigest)
+	}
+	newNode, ok := tree.nodes[newDigest]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDigestNotFound, newDigest)
+	}
+	changes := []*pb.DigestChange{}
+	tree.diff(base, oldNode, newNode, 1, maxDepth, &changes)
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+func (tree *merkleTree) diff(dir string, oldNode, newNode *pb.MerkleNode, depth, maxDepth int, changes *[]*pb.DigestChange) {
+	join := func(name string) string {
+		if dir == "" {
+			return name
+		}
+		return filepath.Join(dir, name)
+	}
+	oldEntries := make(map[string]*pb.MerkleEntry, len(oldNode.Entries))
+	for _, entry := range oldNode.Entries {
+		oldEntries[entry.Name] = entry
+	}
+
+	for _, newEntry := range newNode.Entries {
+		oldEntry, ok := oldEntries[newEntry.Name]
+		delete(oldEntries, newEntry.Name)
+		switch {
+		case !ok:
+			*changes = append(*changes, &pb.DigestChange{Path: join(newEntry.Name), Type: pb.ChangeType_CREATED, Directory: newEntry.Directory})
+		case oldEntry.Digest == newEntry.Digest && oldEntry.Directory == newEntry.Directory:
+		case oldEntry.Directory != newEntry.Directory:
+			*changes = append(*changes,
+				&pb.DigestChange{Path: join(newEntry.Name), Type: pb.ChangeType_DELETED, Directory: oldEntry.Directory},
+				&pb.DigestChange{Path: join(newEntry.Name), Type: pb.ChangeType_CREATED, Directory: newEntry.Directory})
+		case newEntry.Directory && (maxDepth == 0 || depth < maxDepth) && tree.nodes[oldEntry.Digest] != nil && tree.nodes[newEntry.Digest] != nil:
+			tree.diff(join(newEntry.Name), tree.nodes[oldEntry.Digest], tree.nodes[newEntry.Digest], depth+1, maxDepth, changes)
+		default:
+			*changes = append(*changes, &pb.DigestChange{Path: join(newEntry.Name), Type: pb.ChangeType_MODIFIED, Directory: newEntry.Directory})
+		}
+	}
+	for _, oldEntry := range oldEntries {
+		*changes = append(*changes, &pb.DigestChange{Path: join(oldEntry.Name), Type: pb.ChangeType_DELETED, Directory: oldEntry.Directory})
+	}
+}
+
+// hashFiles runs the hash passes that follow changes of the cache
+func (indexer *Indexer) hashFiles(ctx context.Context) error {
+	if err := indexer.hashDuplicates(ctx); err != nil {
+		return err
+	}
+	return indexer.updateDigests(ctx)
+}
+
+// updateDigests hashes the cached files without a content hash and computes
+// the digests of the indexed directories, if enabled by the crawl config
+func (indexer *Indexer) updateDigests(ctx context.Context) error {
+	if !indexer.walker.config.DirectoryDigests || indexer.cache.Version() == indexer.digestedVersion.Load() {
+		return nil
+	}
+
+	var hashed []hashCandidate
+	for _, fileAttr := range indexer.cache.Select(func(string) bool { return true }) {
+		if fileAttr.ContentHash != "" || isArchiveMember(fileAttr.Path) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		partialHash, contentHash, err := calculateHashes(fileAttr.Path, fileAttr.Size)
+		if err != nil {
+			// Removed or unreadable, the next crawl handles it
+			continue
+		}
+		hashed = append(hashed, hashCandidate{cached: fileAttr, partialHash: partialHash, contentHash: contentHash})
+	}
+	if err := indexer.storeHashes(hashed); err != nil {
+		return err
+	}
+
+	indexer.merkle.Build(indexer.walker.Roots(), indexer.cache.Select(func(string) bool { return true }))
+	indexer.digestedVersion.Store(indexer.cache.Version())
+	return nil
+}
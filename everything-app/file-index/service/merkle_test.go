@@ -0,0 +1,179 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+	"training/file-index/pb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// merkleFiles returns the files of contents, a map of paths to content hashes
+func merkleFiles(contents map[string]string) []*pb.FileAttr {
+	files := make([]*pb.FileAttr, 0, len(contents))
+	for path, hash := range contents {
+		files = append(files, &pb.FileAttr{Path: path, ContentHash: hash})
+	}
+	return files
+}
+
+// buildDigest builds tree from contents and returns the digest of /root
+func buildDigest(t *testing.T, tree *merkleTree, contents map[string]string) string {
+	t.Helper()
+	tree.Build([]string{"/root"}, merkleFiles(contents))
+	digest, _, ok := tree.Digest("/root")
+	if !ok {
+		t.Fatal("no digest of /root")
+	}
+	return digest
+}
+
+// formatChanges formats changes as "TYPE path" with a slash after directories
+func formatChanges(changes []*pb.DigestChange) []string {
+	formatted := make([]string, len(changes))
+	for i, change := range changes {
+		path := change.Path
+		if change.Directory {
+			path += "/"
+		}
+		formatted[i] = fmt.Sprintf("%s %s", change.Type, path)
+	}
+	return formatted
+}
+
+var merkleContents = map[string]string{
+	"/root/a.txt":         "a1",
+	"/root/b.txt":         "b1",
+	"/root/docs/c.txt":    "c1",
+	"/root/docs/old/d.md": "d1",
+	"/root/src/e.go":      "e1",
+	"/root/unhashed.bin":  "",
+	"/elsewhere/f.txt":    "f1",
+}
+
+func TestMerkleDigestStable(t *testing.T) {
+	want := buildDigest(t, newMerkleTree(), merkleContents)
+	files := merkleFiles(merkleContents)
+	for i := 0; i < 10; i++ {
+		rand.Shuffle(len(files), func(i, j int) { files[i], files[j] = files[j], files[i] })
+		tree := newMerkleTree()
+		tree.Build([]string{"/root"}, files)
+		if digest, _, _ := tree.Digest("/root"); digest != want {
+			t.Fatalf("digest of reordered files %s, want %s", digest, want)
+		}
+	}
+
+	// Files without a content hash and outside the roots are left out
+	contents := map[string]string{}
+	for path, hash := range merkleContents {
+		contents[path] = hash
+	}
+	contents["/root/unhashed2.bin"] = ""
+	contents["/elsewhere/g.txt"] = "g1"
+	if digest := buildDigest(t, newMerkleTree(), contents); digest != want {
+		t.Errorf("digest with unhashed and outside files %s, want %s", digest, want)
+	}
+
+	contents["/root/docs/old/d.md"] = "d2"
+	if digest := buildDigest(t, newMerkleTree(), contents); digest == want {
+		t.Error("digest did not change with a nested file")
+	}
+}
+
+func TestMerkleDiff(t *testing.T) {
+	tree := newMerkleTree()
+	oldDigest := buildDigest(t, tree, merkleContents)
+	newDigest := buildDigest(t, tree, map[string]string{
+		"/root/a.txt":           "a2", // modified
+		"/root/c.txt":           "c1", // created
+		"/root/docs/c.txt":      "c1",
+		"/root/docs/old/d.md":   "d2", // modified two levels down
+		"/root/src":             "s1", // directory replaced by a file
+		"/root/new/deep/e.go":   "e1", // created directory
+		"/root/docs/old/new.md": "n1",
+	})
+
+	for _, test := range []struct {
+		maxDepth int
+		want     []string
+	}{
+		{0, []string{
+			"MODIFIED /root/a.txt",
+			"DELETED /root/b.txt",
+			"CREATED /root/c.txt",
+			"MODIFIED /root/docs/old/d.md",
+			"CREATED /root/docs/old/new.md",
+			"CREATED /root/new/",
+			"DELETED /root/src/",
+			"CREATED /root/src",
+		}},
+		{1, []string{
+			"MODIFIED /root/a.txt",
+			"DELETED /root/b.txt",
+			"CREATED /root/c.txt",
+			"MODIFIED /root/docs/",
+			"CREATED /root/new/",
+			"DELETED /root/src/",
+			"CREATED /root/src",
+		}},
+		{2, []string{
+			"MODIFIED /root/a.txt",
+			"DELETED /root/b.txt",
+			"CREATED /root/c.txt",
+			"MODIFIED /root/docs/old/",
+			"CREATED /root/new/",
+			"DELETED /root/src/",
+			"CREATED /root/src",
+		}},
+	} {
+		changes, err := tree.Diff("/root", oldDigest, newDigest, test.maxDepth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := formatChanges(changes); fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("Diff with max depth %d = %q, want %q", test.maxDepth, got, test.want)
+		}
+	}
+
+	changes, err := tree.Diff("", newDigest, newDigest, 0)
+	if err != nil || len(changes) != 0 {
+		t.Errorf("Diff of the same digest = %v, %v, want no changes", formatChanges(changes), err)
+	}
+	changes, err = tree.Diff("", oldDigest, newDigest, 1)
+	if err != nil || len(changes) == 0 || changes[0].Path != "a.txt" {
+		t.Errorf("Diff without base = %v, %v, want relative paths", formatChanges(changes), err)
+	}
+	if _, err := tree.Diff("", "unknown", newDigest, 0); !errors.Is(err, ErrDigestNotFound) {
+		t.Errorf("Diff of an unknown digest returned %v, want %v", err, ErrDigestNotFound)
+	}
+}
+
+func TestMerkleRetention(t *testing.T) {
+	tree := newMerkleTree()
+	expired := &pb.MerkleNode{Digest: "expired", LastSeen: timestamppb.New(time.Now().Add(-merkleRetention - time.Hour))}
+	retained := &pb.MerkleNode{Digest: "retained", LastSeen: timestamppb.New(time.Now().Add(-merkleRetention + time.Hour))}
+	tree.Restore([]*pb.MerkleNode{expired, retained})
+	current := buildDigest(t, tree, merkleContents)
+
+	if _, err := tree.Diff("", "expired", current, 0); !errors.Is(err, ErrDigestNotFound) {
+		t.Errorf("Diff of an expired digest returned %v, want %v", err, ErrDigestNotFound)
+	}
+	changes, err := tree.Diff("", "retained", current, 1)
+	if err != nil {
+		t.Fatalf("Diff of a retained digest: %v", err)
+	}
+	// The retained version is empty, so every entry is created
+	want := []string{"CREATED a.txt", "CREATED b.txt", "CREATED docs/", "CREATED src/"}
+	if got := formatChanges(changes); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Diff of the retained digest = %q, want %q", got, want)
+	}
+
+	// A version that leaves the tree stays comparable
+	next := buildDigest(t, tree, map[string]string{"/root/a.txt": "a2"})
+	if _, err := tree.Diff("", current, next, 0); err != nil {
+		t.Errorf("Diff of the previous version: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readMounts returns the file system type of every mount point, read from
+// /proc/self/mountinfo
+func readMounts() (map[string]string, error) {
+	file, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	mounts := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		for i := 5; i < len(fields)-1; i++ {
+			if fields[i] == "-" {
+				mounts[unescapeMountPath(fields[4])] = fields[i+1]
+				break
+			}
+		}
+	}
+	return mounts, scanner.Err()
+}
+
+// unescapeMountPath decodes the octal escapes (\040 for a space) used in mountinfo
+func unescapeMountPath(path string) string {
+	if !strings.Contains(path, `\`) {
+		return path
+	}
+	var builder strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+3 < len(path) {
+			if c, err := strconv.ParseUint(path[i+1:i+4], 8, 8); err == nil {
+				builder.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		builder.WriteByte(path[i])
+	}
+	return builder.String()
+}
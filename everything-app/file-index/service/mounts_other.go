@@ -0,0 +1,8 @@
+//go:build !linux
+
+package service
+
+// readMounts is only implemented on Linux, network mounts are not detected elsewhere
+func readMounts() (map[string]string, error) {
+	return map[string]string{}, nil
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// The OpenDocument text, spreadsheet and presentation files of LibreOffice
+func init() {
+	err := RegisterExtractor(ExtractorConfig{
+		Name:       "odf",
+		Extensions: []string{".odt", ".ods", ".odp", ".ott", ".ots", ".otp"},
+		Extractor:  ExtractorFunc(readODFDocument),
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// readODFDocument reads the text of content.xml and the title, author and
+// page count of meta.xml
+func readODFDocument(ctx context.Context, path string) (*Document, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	document := &Document{}
+	text := newTextBuilder(ctx)
+	slides := 0
+	for _, file := range archive.File {
+		switch file.Name {
+		case "content.xml":
+			err = readZipXML(file, func(decoder *xml.Decoder) (err error) {
+				slides, err = readODFContent(decoder, text)
+				return err
+			})
+		case "meta.xml":
+			err = readZipXML(file, func(decoder *xml.Decoder) error {
+				return readODFMeta(decoder, document)
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Presentations have no page count statistic, their pages are the slides
+	if _, ok := document.Metadata[MetaPageCount]; !ok && slides > 0 {
+		document.setMeta(MetaPageCount, strconv.Itoa(slides))
+	}
+	document.Content = normalizeText(text.String())
+	return document, nil
+}
+
+// readZipXML runs read on a decoder of the XML file of an archive
+func readZipXML(file *zip.File, read func(decoder *xml.Decoder) error) error {
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	decoder := xml.NewDecoder(reader)
+	decoder.Strict = false
+	return read(decoder)
+}
+
+// readODFContent appends the text of the body of content.xml to text and
+// returns the number of presentation pages. Deleted tracked changes are left
+// out.
+func readODFContent(decoder *xml.Decoder, text *textBuilder) (int, error) {
+	pages := 0
+	skip := 0
+	for !text.full() {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return pages, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if skip > 0 || token.Name.Local == "tracked-changes" {
+				skip++
+				continue
+			}
+			switch token.Name.Local {
+			case "tab":
+				text.WriteString("\t")
+			case "line-break":
+				text.WriteString("\n")
+			case "s":
+				spaces := 1
+				for _, attr := range token.Attr {
+					if attr.Name.Local == "c" {
+						if count, err := strconv.Atoi(attr.Value); err == nil && count > 0 && count < 100 {
+							spaces = count
+						}
+					}
+				}
+				for i := 0; i < spaces; i++ {
+					text.WriteString(" ")
+				}
+			case "page":
+				pages++
+			}
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			switch token.Name.Local {
+			case "p", "h", "page", "table-row":
+				text.WriteString("\n")
+			case "table-cell":
+				text.WriteString("\t")
+			}
+		case xml.CharData:
+			if skip == 0 {
+				text.Write(token)
+			}
+		}
+	}
+	return pages, nil
+}
+
+// readODFMeta sets the title, author, last author and page count of
+// document from meta.xml. The author is the initial creator, dc:creator is
+// the last person who saved the document.
+func readODFMeta(decoder *xml.Decoder, document *Document) error {
+	var meta struct {
+		Title          string `xml:"meta>title"`
+		Creator        string `xml:"meta>creator"`
+		InitialCreator string `xml:"meta>initial-creator"`
+		Statistic      struct {
+			PageCount string `xml:"page-count,attr"`
+		} `xml:"meta>document-statistic"`
+	}
+	if err := decoder.Decode(&meta); err != nil {
+		return err
+	}
+	document.setMeta(MetaTitle, meta.Title)
+	if meta.InitialCreator != "" {
+		document.setMeta(MetaAuthor, meta.InitialCreator)
+	} else {
+		document.setMeta(MetaAuthor, meta.Creator)
+	}
+	document.setMeta(MetaLastModifiedBy, meta.Creator)
+	document.setMeta(MetaPageCount, meta.Statistic.PageCount)
+	return nil
+}
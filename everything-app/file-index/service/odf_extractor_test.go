@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+const odfNamespaces = `xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" ` +
+	`xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" ` +
+	`xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" ` +
+	`xmlns:draw="urn:oasis:names:tc:opendocument:xmlns:drawing:1.0" ` +
+	`xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0" ` +
+	`xmlns:dc="http://purl.org/dc/elements/1.1/"`
+
+// odfFile returns an OpenDocument package with the body of content.xml and
+// the metadata of meta.xml
+func odfFile(t *testing.T, mimeType, body, meta string) []byte {
+	t.Helper()
+	return zipData(t, map[string]string{
+		"mimetype":              mimeType,
+		"META-INF/manifest.xml": `<?xml version="1.0" encoding="UTF-8"?><manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0"/>`,
+		"content.xml":           `<?xml version="1.0" encoding="UTF-8"?><office:document-content ` + odfNamespaces + `><office:body>` + body + `</office:body></office:document-content>`,
+		"meta.xml":              `<?xml version="1.0" encoding="UTF-8"?><office:document-meta ` + odfNamespaces + `><office:meta>` + meta + `</office:meta></office:document-meta>`,
+		"styles.xml":            `<?xml version="1.0" encoding="UTF-8"?><office:document-styles ` + odfNamespaces + `><text:p>Style text</text:p></office:document-styles>`,
+	})
+}
+
+func TestReadODFDocument(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		data []byte
+		want Document
+	}{
+		{
+			name: "text",
+			data: odfFile(t, "application/vnd.oasis.opendocument.text",
+				`<office:text>
+<text:tracked-changes><text:changed-region><text:deletion><text:p>Deleted text</text:p></text:deletion></text:changed-region></text:tracked-changes>
+<text:h text:outline-level="1">Quarterly report</text:h>
+<text:p>Hello<text:s text:c="3"/>world<text:tab/>tab<text:line-break/>next &amp; last</text:p>
+<table:table><table:table-row><table:table-cell><text:p>a</text:p></table:table-cell><table:table-cell><text:p>b</text:p></table:table-cell></table:table-row></table:table>
+</office:text>`,
+				`<dc:title>Report</dc:title><meta:initial-creator>Jane Doe</meta:initial-creator><dc:creator>John Roe</dc:creator>`+
+					`<meta:document-statistic meta:page-count="4" meta:word-count="12"/>`),
+			want: Document{
+				Content: "Quarterly report\n\nHello world\ttab\nnext & last\n\na\nb",
+				Metadata: map[string]string{
+					MetaTitle: "Report", MetaAuthor: "Jane Doe", MetaLastModifiedBy: "John Roe", MetaPageCount: "4",
+				},
+			},
+		},
+		{
+			name: "presentation",
+			data: odfFile(t, "application/vnd.oasis.opendocument.presentation",
+				`<office:presentation><draw:page draw:name="1"><draw:frame><draw:text-box><text:p>First slide</text:p></draw:text-box></draw:frame></draw:page>`+
+					`<draw:page draw:name="2"><draw:frame><draw:text-box><text:p>Second slide</text:p></draw:text-box></draw:frame></draw:page></office:presentation>`,
+				`<dc:creator>John Roe</dc:creator>`),
+			want: Document{
+				Content:  "First slide\n\nSecond slide",
+				Metadata: map[string]string{MetaAuthor: "John Roe", MetaLastModifiedBy: "John Roe", MetaPageCount: "2"},
+			},
+		},
+	} {
+		path, _ := writeFile(t, "document.odt", test.data)
+		document, err := readODFDocument(context.Background(), path)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*document, test.want) {
+			t.Errorf("%s: read %+v, want %+v", test.name, *document, test.want)
+		}
+	}
+
+	path, _ := writeFile(t, "document.odt", []byte("not a zip archive"))
+	if document, err := readODFDocument(context.Background(), path); err == nil {
+		t.Errorf("read %+v from a file that is not an archive, want an error", document)
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// pattern is a single gitignore-style pattern
+type pattern struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+}
+
+// PatternList is an ordered list of gitignore-style patterns. As in
+// .gitignore the last matching pattern wins and a leading "!" negates it.
+//
+//   - "*.tmp" matches a file or directory name at any depth
+//   - "/build" is anchored to the root being crawled
+//   - "cache/" only matches directories
+//   - "docs/**/*.md" matches any number of directories at "**"
+type PatternList []pattern
+
+// ParsePatterns compiles gitignore-style patterns
+func ParsePatterns(patterns []string) (PatternList, error) {
+	var list PatternList
+	for _, raw := range patterns {
+		p := strings.TrimSpace(raw)
+		if p == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+		var compiled pattern
+		if strings.HasPrefix(p, "!") {
+			compiled.negate = true
+			p = p[1:]
+		}
+		if strings.HasSuffix(p, "/") {
+			compiled.dirOnly = true
+			p = strings.TrimRight(p, "/")
+		}
+		// A pattern without a slash matches at any depth, one with a
+		// slash is relative to the root
+		anchored := strings.Contains(p, "/")
+		p = strings.TrimPrefix(p, "/")
+		if p == "" {
+			return nil, fmt.Errorf("invalid pattern %q", raw)
+		}
+		compiled.segments = strings.Split(p, "/")
+		if !anchored {
+			compiled.segments = append([]string{"**"}, compiled.segments...)
+		}
+		for _, segment := range compiled.segments {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", raw, err)
+			}
+		}
+		list = append(list, compiled)
+	}
+	return list, nil
+}
+
+// Match reports whether the slash separated path rel, relative to the
+// crawl root, or one of its parent directories is matched by the list
+func (list PatternList) Match(rel string, isDir bool) bool {
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if list.matchExact(parts[:i], true) {
+			return true
+		}
+	}
+	return list.matchExact(parts, isDir)
+}
+
+func (list PatternList) matchExact(parts []string, isDir bool) bool {
+	matched := false
+	for _, p := range list {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if matchSegments(p.segments, parts) {
+			matched = !p.negate
+		}
+	}
+	return matched
+}
+
+// matchSegments matches path segments against pattern segments where "**"
+// matches zero or more segments
+func matchSegments(segments, parts []string) bool {
+	for len(segments) > 0 {
+		if segments[0] == "**" {
+			segments = segments[1:]
+			if len(segments) == 0 {
+				return true
+			}
+			for i := range parts {
+				if matchSegments(segments, parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(segments[0], parts[0]); !ok {
+			return false
+		}
+		segments, parts = segments[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
@@ -0,0 +1,807 @@
+package service
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/ascii85"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	// pdfMaxStream bounds the size of a decoded stream
+	pdfMaxStream = 64 << 20
+	// pdfMaxFormDepth bounds the nesting of form XObjects
+	pdfMaxFormDepth = 4
+)
+
+// The PDF documents. The file is read into memory, so large files are
+// skipped.
+func init() {
+	err := RegisterExtractor(ExtractorConfig{
+		Name:       "pdf",
+		Extensions: []string{".pdf"},
+		MIMETypes:  []string{"application/pdf"},
+		MaxSize:    200 << 20,
+		Extractor:  ExtractorFunc(readPDFDocument),
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// pdfFile resolves the objects of a PDF. The objects are found by scanning
+// for "num gen obj" rather than by reading the cross-reference table, so
+// damaged files and incremental updates are read as well.
+type pdfFile struct {
+	data    []byte
+	offsets map[int]int
+	objects map[int]any
+	// streamsLoaded is set once the objects of the object streams are known
+	streamsLoaded bool
+	// parsing is the number of objects being parsed, stream lengths can
+	// refer to objects that are streams themselves
+	parsing int
+	// showing are the form XObjects being shown, a form that shows itself
+	// is skipped
+	showing map[*pdfStream]bool
+}
+
+var (
+	pdfObjectHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+	pdfTrailer      = regexp.MustCompile(`trailer\s*<<`)
+)
+
+func newPDFFile(data []byte) *pdfFile {
+	file := &pdfFile{
+		data:    data,
+		offsets: make(map[int]int),
+		objects: make(map[int]any),
+		showing: make(map[*pdfStream]bool),
+	}
+	// Later definitions replace earlier ones like in incremental updates
+	for _, match := range pdfObjectHeader.FindAllSubmatchIndex(data, -1) {
+		if match[0] > 0 && !isPDFSpace(data[match[0]-1]) && !isPDFDelimiter(data[match[0]-1]) {
+			continue
+		}
+		num, err := strconv.Atoi(string(data[match[2]:match[3]]))
+		if err == nil {
+			file.offsets[num] = match[1]
+		}
+	}
+	return file
+}
+
+// resolve returns the object obj refers to, or obj itself
+func (file *pdfFile) resolve(obj any) any {
+	for i := 0; i < 8; i++ {
+		ref, ok := obj.(pdfRef)
+		if !ok {
+			return obj
+		}
+		obj = file.object(ref.num)
+	}
+	return nil
+}
+
+// object parses the object num, nil if it does not exist
+func (file *pdfFile) object(num int) any {
+	if obj, ok := file.objects[num]; ok {
+		return obj
+	}
+	offset, ok := file.offsets[num]
+	if !ok {
+		if !file.streamsLoaded {
+			file.loadObjectStreams()
+			return file.objects[num]
+		}
+		return nil
+	}
+	if file.parsing >= pdfMaxNesting {
+		return nil
+	}
+	// A reference cycle through a stream length resolves to nil
+	file.objects[num] = nil
+	file.parsing++
+	obj := file.parseAt(offset)
+	file.parsing--
+	file.objects[num] = obj
+	return obj
+}
+
+// parseAt parses the object after "num gen obj" at offset, with its stream
+func (file *pdfFile) parseAt(offset int) any {
+	lex := &pdfLexer{data: file.data, pos: offset}
+	obj, err := lex.object()
+	if err != nil {
+		return nil
+	}
+	dict, ok := obj.(pdfDict)
+	if !ok {
+		return obj
+	}
+	start := lex.pos
+	if token, err := lex.token(); err != nil || token != pdfKeyword("stream") {
+		return dict
+	}
+	// The data starts after the end of line following the keyword
+	if lex.pos < len(file.data) && file.data[lex.pos] == '\r' {
+		lex.pos++
+	}
+	if lex.pos < len(file.data) && file.data[lex.pos] == '\n' {
+		lex.pos++
+	}
+	start = lex.pos
+	if length, ok := file.resolve(dict["Length"]).(float64); ok {
+		end := start + int(length)
+		if end <= len(file.data) && end >= start {
+			rest := bytes.TrimLeft(file.data[end:min(end+32, len(file.data))], "\r\n\t ")
+			if bytes.HasPrefix(rest, []byte("endstream")) {
+				return &pdfStream{dict: dict, data: file.data[start:end]}
+			}
+		}
+	}
+	end := bytes.Index(file.data[start:], []byte("endstream"))
+	if end < 0 {
+		return &pdfStream{dict: dict, data: file.data[start:]}
+	}
+	return &pdfStream{dict: dict, data: bytes.TrimRight(file.data[start:start+end], "\r\n")}
+}
+
+// loadObjectStreams parses the objects compressed in object streams
+func (file *pdfFile) loadObjectStreams() {
+	file.streamsLoaded = true
+	for num := range file.offsets {
+		stream, ok := file.object(num).(*pdfStream)
+		if !ok || stream.dict["Type"] != pdfName("ObjStm") {
+			continue
+		}
+		data, err := file.decode(stream)
+		if err != nil {
+			continue
+		}
+		count, _ := file.resolve(stream.dict["N"]).(float64)
+		first, _ := file.resolve(stream.dict["First"]).(float64)
+		lex := &pdfLexer{data: data}
+		for i := 0; i < int(count); i++ {
+			objNum, err1 := lex.token()
+			objOffset, err2 := lex.token()
+			objNumber, ok1 := objNum.(float64)
+			offset, ok2 := objOffset.(float64)
+			if err1 != nil || err2 != nil || !ok1 || !ok2 {
+				break
+			}
+			if _, ok := file.offsets[int(objNumber)]; ok {
+				continue
+			}
+			objLex := &pdfLexer{data: data, pos: int(first) + int(offset)}
+			if obj, err := objLex.object(); err == nil {
+				file.objects[int(objNumber)] = obj
+			}
+		}
+	}
+}
+
+// decode returns the decoded data of a stream. FlateDecode, ASCIIHexDecode
+// and ASCII85Decode are supported, predictors are not.
+func (file *pdfFile) decode(stream *pdfStream) ([]byte, error) {
+	var filters []pdfName
+	switch filter := file.resolve(stream.dict["Filter"]).(type) {
+	case pdfName:
+		filters = []pdfName{filter}
+	case pdfArray:
+		for _, f := range filter {
+			if name, ok := file.resolve(f).(pdfName); ok {
+				filters = append(filters, name)
+			}
+		}
+	}
+	data := stream.data
+	for _, filter := range filters {
+		var err error
+		switch filter {
+		case "FlateDecode", "Fl":
+			var reader io.ReadCloser
+			reader, err = zlib.NewReader(bytes.NewReader(data))
+			if err == nil {
+				// Truncated streams are common, keep what could be inflated
+				data, err = io.ReadAll(io.LimitReader(reader, pdfMaxStream))
+				if len(data) > 0 {
+					err = nil
+				}
+			}
+		case "ASCIIHexDecode", "AHx":
+			data = bytes.TrimSuffix(bytes.TrimSpace(data), []byte(">"))
+			data = bytes.Map(func(r rune) rune {
+				if isPDFSpace(byte(r)) {
+					return -1
+				}
+				return r
+			}, data)
+			if len(data)%2 == 1 {
+				data = append(data, '0')
+			}
+			data, err = hex.DecodeString(string(data))
+		case "ASCII85Decode", "A85":
+			data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+			if end := bytes.Index(data, []byte("~>")); end >= 0 {
+				data = data[:end]
+			}
+			decoded := make([]byte, len(data))
+			var n int
+			n, _, err = ascii85.Decode(decoded, data, true)
+			data = decoded[:n]
+		default:
+			return nil, fmt.Errorf("unsupported PDF filter %s", filter)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// trailer returns the trailer dictionary, or the dictionary of the last
+// cross-reference stream
+func (file *pdfFile) trailer() pdfDict {
+	trailer := make(pdfDict)
+	for _, index := range pdfTrailer.FindAllIndex(file.data, -1) {
+		lex := &pdfLexer{data: file.data, pos: index[0] + len("trailer")}
+		obj, _ := lex.object()
+		if dict, ok := obj.(pdfDict); ok {
+			for key, value := range dict {
+				trailer[key] = value
+			}
+		}
+	}
+	if trailer["Root"] != nil {
+		return trailer
+	}
+	last := -1
+	for num, offset := range file.offsets {
+		stream, ok := file.object(num).(*pdfStream)
+		if ok && offset > last && stream.dict["Type"] == pdfName("XRef") && stream.dict["Root"] != nil {
+			trailer, last = stream.dict, offset
+		}
+	}
+	return trailer
+}
+
+// pdfPage is a page with the resources it inherits from the page tree
+type pdfPage struct {
+	dict      pdfDict
+	resources pdfDict
+}
+
+// pages returns the pages in order of the page tree below node. visited
+// holds the numbers of the nodes already read, a node listed twice or in a
+// cycle is only read once.
+func (file *pdfFile) pages(node pdfDict, resources pdfDict, depth int, visited map[int]bool, pages []pdfPage) []pdfPage {
+	if depth > 32 {
+		return pages
+	}
+	if own, ok := file.resolve(node["Resources"]).(pdfDict); ok {
+		resources = own
+	}
+	if node["Type"] == pdfName("Page") || node["Kids"] == nil {
+		return append(pages, pdfPage{dict: node, resources: resources})
+	}
+	kids, _ := file.resolve(node["Kids"]).(pdfArray)
+	for _, kid := range kids {
+		if ref, ok := kid.(pdfRef); ok {
+			if visited[ref.num] {
+				continue
+			}
+			visited[ref.num] = true
+		}
+		if child, ok := file.resolve(kid).(pdfDict); ok {
+			pages = file.pages(child, resources, depth+1, visited, pages)
+		}
+	}
+	return pages
+}
+
+// readPDFDocument reads the text of the pages of a PDF, its title, author
+// and page count. Encrypted documents only have metadata.
+func readPDFDocument(ctx context.Context, path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(bytes.TrimLeft(data[:min(len(data), 1024)], "\x00\r\n\t "), []byte("%PDF-")) {
+		return nil, fmt.Errorf("not a PDF file")
+	}
+
+	file := newPDFFile(data)
+	trailer := file.trailer()
+	document := &Document{}
+	encrypted := trailer["Encrypt"] != nil
+	if info, ok := file.resolve(trailer["Info"]).(pdfDict); ok && !encrypted {
+		title, _ := file.resolve(info["Title"]).(string)
+		author, _ := file.resolve(info["Author"]).(string)
+		document.setMeta(MetaTitle, pdfTextString(title))
+		document.setMeta(MetaAuthor, pdfTextString(author))
+	}
+	catalog, _ := file.resolve(trailer["Root"]).(pdfDict)
+	root, _ := file.resolve(catalog["Pages"]).(pdfDict)
+	if root == nil {
+		return nil, fmt.Errorf("no page tree")
+	}
+	pages := file.pages(root, nil, 0, make(map[int]bool), nil)
+	document.setMeta(MetaPageCount, strconv.Itoa(len(pages)))
+	if encrypted {
+		return document, nil
+	}
+
+	text := newTextBuilder(ctx)
+	for _, page := range pages {
+		if text.full() || ctx.Err() != nil {
+			break
+		}
+		file.showContent(file.pageContent(page.dict), page.resources, text, 0)
+		text.WriteString("\n\n")
+	}
+	document.Content = normalizeText(text.String())
+	return document, nil
+}
+
+// pageContent returns the decoded content streams of a page
+func (file *pdfFile) pageContent(page pdfDict) []byte {
+	var streams []any
+	switch contents := file.resolve(page["Contents"]).(type) {
+	case *pdfStream:
+		streams = []any{contents}
+	case pdfArray:
+		streams = contents
+	}
+	var content []byte
+	for _, obj := range streams {
+		if stream, ok := file.resolve(obj).(*pdfStream); ok {
+			if data, err := file.decode(stream); err == nil {
+				content = append(content, data...)
+				content = append(content, '\n')
+			}
+		}
+	}
+	return content
+}
+
+// pdfFont decodes the strings shown with a font
+type pdfFont struct {
+	// toUnicode maps the character codes to text, codes are codeBytes long
+	toUnicode map[uint32]string
+	codeBytes int
+	// encoding decodes the single-byte codes of simple fonts without a
+	// ToUnicode map, differences replaces some of its codes
+	encoding    *charmap.Charmap
+	differences map[byte]rune
+	// composite fonts without a ToUnicode map cannot be decoded
+	composite bool
+	// widths are the advances of the codes in thousandths of the font size
+	widths       map[uint32]float64
+	defaultWidth float64
+}
+
+// pdfDefaultWidth is the advance of the codes of fonts without widths
+const pdfDefaultWidth = 500
+
+// font returns the font named name in resources
+func (file *pdfFile) font(resources pdfDict, name pdfName, fonts map[pdfName]*pdfFont) *pdfFont {
+	if font, ok := fonts[name]; ok {
+		return font
+	}
+	font := &pdfFont{codeBytes: 1, encoding: charmap.Windows1252}
+	fonts[name] = font
+	fontDicts, _ := file.resolve(resources["Font"]).(pdfDict)
+	dict, _ := file.resolve(fontDicts[name]).(pdfDict)
+	if dict == nil {
+		return font
+	}
+	font.composite = dict["Subtype"] == pdfName("Type0")
+	if font.composite {
+		font.codeBytes = 2
+	}
+	file.fontWidths(font, dict)
+	if stream, ok := file.resolve(dict["ToUnicode"]).(*pdfStream); ok {
+		if data, err := file.decode(stream); err == nil {
+			font.toUnicode, font.codeBytes = parseToUnicode(data, font.codeBytes)
+		}
+	}
+	switch encoding := file.resolve(dict["Encoding"]).(type) {
+	case pdfName:
+		if encoding == "MacRomanEncoding" {
+			font.encoding = charmap.Macintosh
+		}
+	case pdfDict:
+		if file.resolve(encoding["BaseEncoding"]) == pdfName("MacRomanEncoding") {
+			font.encoding = charmap.Macintosh
+		}
+		differences, _ := file.resolve(encoding["Differences"]).(pdfArray)
+		code := 0
+		for _, element := range differences {
+			switch element := file.resolve(element).(type) {
+			case float64:
+				code = int(element)
+			case pdfName:
+				if r, ok := glyphRune(string(element)); ok && code < 256 {
+					if font.differences == nil {
+						font.differences = make(map[byte]rune)
+					}
+					font.differences[byte(code)] = r
+				}
+				code++
+			}
+		}
+	}
+	return font
+}
+
+// fontWidths reads the Widths of a simple font or the W of the descendant
+// font of a composite font
+func (file *pdfFile) fontWidths(font *pdfFont, dict pdfDict) {
+	font.widths = make(map[uint32]float64)
+	font.defaultWidth = pdfDefaultWidth
+	if !font.composite {
+		first, _ := file.resolve(dict["FirstChar"]).(float64)
+		widths, _ := file.resolve(dict["Widths"]).(pdfArray)
+		for i, width := range widths {
+			if width, ok := file.resolve(width).(float64); ok {
+				font.widths[uint32(first)+uint32(i)] = width
+			}
+		}
+		return
+	}
+
+	descendants, _ := file.resolve(dict["DescendantFonts"]).(pdfArray)
+	if len(descendants) == 0 {
+		return
+	}
+	descendant, _ := file.resolve(descendants[0]).(pdfDict)
+	font.defaultWidth = 1000
+	if width, ok := file.resolve(descendant["DW"]).(float64); ok {
+		font.defaultWidth = width
+	}
+	// W holds "first [w1 w2 ...]" and "first last w" entries
+	w, _ := file.resolve(descendant["W"]).(pdfArray)
+	for i := 0; i+1 < len(w); {
+		first, ok := file.resolve(w[i]).(float64)
+		if !ok {
+			return
+		}
+		if widths, ok := file.resolve(w[i+1]).(pdfArray); ok {
+			for j, width := range widths {
+				if width, ok := file.resolve(width).(float64); ok {
+					font.widths[uint32(first)+uint32(j)] = width
+				}
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(w) {
+			return
+		}
+		last, ok1 := file.resolve(w[i+1]).(float64)
+		width, ok2 := file.resolve(w[i+2]).(float64)
+		if ok1 && ok2 && last >= first && last-first <= 0xFFFF {
+			for code := first; code <= last; code++ {
+				font.widths[uint32(code)] = width
+			}
+		}
+		i += 3
+	}
+}
+
+// advance returns the width of a string shown with the font in thousandths
+// of the font size
+func (font *pdfFont) advance(shown string) float64 {
+	advance := 0.0
+	for i := 0; i+font.codeBytes <= len(shown); i += font.codeBytes {
+		var code uint32
+		for _, b := range []byte(shown[i : i+font.codeBytes]) {
+			code = code<<8 | uint32(b)
+		}
+		if width, ok := font.widths[code]; ok {
+			advance += width
+		} else {
+			advance += font.defaultWidth
+		}
+	}
+	return advance
+}
+
+// decode returns the text of a string shown with the font
+func (font *pdfFont) decode(shown string) string {
+	if font.toUnicode != nil {
+		var text strings.Builder
+		for i := 0; i+font.codeBytes <= len(shown); i += font.codeBytes {
+			var code uint32
+			for _, b := range []byte(shown[i : i+font.codeBytes]) {
+				code = code<<8 | uint32(b)
+			}
+			text.WriteString(font.toUnicode[code])
+		}
+		return text.String()
+	}
+	if font.composite {
+		return ""
+	}
+	runes := make([]rune, 0, len(shown))
+	for _, b := range []byte(shown) {
+		if r, ok := font.differences[b]; ok {
+			runes = append(runes, r)
+		} else {
+			runes = append(runes, font.encoding.DecodeByte(b))
+		}
+	}
+	return string(runes)
+}
+
+// parseToUnicode reads the bfchar and bfrange mappings of a ToUnicode CMap
+// and the code length of its codespace range
+func parseToUnicode(data []byte, codeBytes int) (map[uint32]string, int) {
+	mapping := make(map[uint32]string)
+	lex := &pdfLexer{data: data}
+	var operands []any
+	code := func(s string) uint32 {
+		var value uint32
+		for _, b := range []byte(s) {
+			value = value<<8 | uint32(b)
+		}
+		return value
+	}
+	for {
+		obj, err := lex.object()
+		if err != nil {
+			break
+		}
+		keyword, ok := obj.(pdfKeyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+		switch keyword {
+		case "endcodespacerange":
+			if len(operands) >= 2 {
+				if low, ok := operands[0].(string); ok && len(low) > 0 {
+					codeBytes = len(low)
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(string)
+				dst, ok2 := operands[i+1].(string)
+				if ok1 && ok2 {
+					mapping[code(src)] = utf16BEText(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				low, ok1 := operands[i].(string)
+				high, ok2 := operands[i+1].(string)
+				if !ok1 || !ok2 || code(high) < code(low) || code(high)-code(low) > 0xFFFF {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case string:
+					// The last byte of the destination is incremented
+					runes := utf16.Decode(utf16BEUnits(dst))
+					for c := code(low); c <= code(high) && len(runes) > 0; c++ {
+						mapping[c] = string(runes)
+						runes[len(runes)-1]++
+					}
+				case pdfArray:
+					for j, element := range dst {
+						if text, ok := element.(string); ok {
+							mapping[code(low)+uint32(j)] = utf16BEText(text)
+						}
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	return mapping, codeBytes
+}
+
+func utf16BEUnits(s string) []uint16 {
+	units := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+	return units
+}
+
+func utf16BEText(s string) string {
+	return string(utf16.Decode(utf16BEUnits(s)))
+}
+
+// pdfTextString decodes a string of the document information, in UTF-16BE
+// or UTF-8 with a BOM, or else in PDFDocEncoding that is close to Latin-1
+func pdfTextString(s string) string {
+	switch {
+	case strings.HasPrefix(s, "\xFE\xFF"):
+		return utf16BEText(s[2:])
+	case strings.HasPrefix(s, "\xEF\xBB\xBF"):
+		return s[3:]
+	}
+	text, _ := charmap.Windows1252.NewDecoder().String(s)
+	return text
+}
+
+// glyphNames are the glyph names of the Differences arrays that are not a
+// single letter or uniXXXX
+var glyphNames = map[string]rune{
+	"space": ' ', "exclam": '!', "quotedbl": '"', "numbersign": '#', "dollar": '$',
+	"percent": '%', "ampersand": '&', "quotesingle": '\'', "parenleft": '(', "parenright": ')',
+	"asterisk": '*', "plus": '+', "comma": ',', "hyphen": '-', "period": '.', "slash": '/',
+	"zero": '0', "one": '1', "two": '2', "three": '3', "four": '4', "five": '5', "six": '6',
+	"seven": '7', "eight": '8', "nine": '9', "colon": ':', "semicolon": ';', "less": '<',
+	"equal": '=', "greater": '>', "question": '?', "at": '@', "bracketleft": '[',
+	"backslash": '\\', "bracketright": ']', "underscore": '_', "braceleft": '{', "bar": '|',
+	"braceright": '}', "quoteleft": '‘', "quoteright": '’', "quotedblleft": '“',
+	"quotedblright": '”', "endash": '–', "emdash": '—', "bullet": '•', "ellipsis": '…',
+	"fi": 'ﬁ', "fl": 'ﬂ', "ff": 'ﬀ', "ffi": 'ﬃ', "ffl": 'ﬄ', "Euro": '€', "copyright": '©',
+	"registered": '®', "trademark": '™', "degree": '°',
+}
+
+func glyphRune(name string) (rune, bool) {
+	if r, ok := glyphNames[name]; ok {
+		return r, true
+	}
+	if len(name) == 1 {
+		return rune(name[0]), true
+	}
+	if strings.HasPrefix(name, "uni") && len(name) == 7 {
+		if code, err := strconv.ParseUint(name[3:], 16, 16); err == nil {
+			return rune(code), true
+		}
+	}
+	return 0, false
+}
+
+// showContent appends the text shown by a content stream to text. Lines
+// are broken when the text moves down and words are separated when it
+// moves right.
+func (file *pdfFile) showContent(content []byte, resources pdfDict, text *textBuilder, depth int) {
+	lex := &pdfLexer{data: content}
+	fonts := make(map[pdfName]*pdfFont)
+	font := &pdfFont{codeBytes: 1, encoding: charmap.Windows1252, defaultWidth: pdfDefaultWidth}
+	var operands []any
+	// advance is the width of the text shown since the start of the line,
+	// in text space. A move further right than the end of the text is a word
+	// space.
+	fontSize, advance := 0.0, 0.0
+	lineX, lineY := 0.0, 0.0
+	gap := func(moved float64) bool {
+		return moved-advance > 0.2*math.Abs(fontSize)
+	}
+	number := func(i int) float64 {
+		if i < len(operands) {
+			value, _ := operands[i].(float64)
+			return value
+		}
+		return 0
+	}
+	show := func(obj any) {
+		if shown, ok := obj.(string); ok {
+			text.WriteString(font.decode(shown))
+			advance += font.advance(shown) / 1000 * fontSize
+		}
+	}
+
+	for !text.full() {
+		obj, err := lex.object()
+		if err != nil {
+			break
+		}
+		operator, ok := obj.(pdfKeyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+		switch operator {
+		case "BI":
+			// Inline images hold binary data up to EI
+			end := bytes.Index(content[lex.pos:], []byte("EI"))
+			for end >= 0 && lex.pos+end+2 < len(content) && !isPDFSpace(content[lex.pos+end+2]) {
+				next := bytes.Index(content[lex.pos+end+2:], []byte("EI"))
+				if next < 0 {
+					end = -1
+					break
+				}
+				end += 2 + next
+			}
+			if end < 0 {
+				return
+			}
+			lex.pos += end + 2
+		case "Tf":
+			if len(operands) >= 1 {
+				if name, ok := operands[0].(pdfName); ok {
+					font = file.font(resources, name, fonts)
+				}
+				fontSize = number(1)
+			}
+		case "Td", "TD":
+			if number(1) != 0 {
+				text.WriteString("\n")
+			} else if gap(number(0)) {
+				text.WriteString(" ")
+			}
+			advance = 0
+		case "Tm":
+			scale := number(0)
+			if y := number(5); y != lineY {
+				text.WriteString("\n")
+			} else if scale != 0 && gap((number(4)-lineX)/scale) {
+				text.WriteString(" ")
+			}
+			lineX, lineY, advance = number(4), number(5), 0
+		case "T*":
+			text.WriteString("\n")
+			advance = 0
+		case "Tj":
+			if len(operands) >= 1 {
+				show(operands[0])
+			}
+		case "'":
+			text.WriteString("\n")
+			advance = 0
+			if len(operands) >= 1 {
+				show(operands[0])
+			}
+		case "\"":
+			text.WriteString("\n")
+			advance = 0
+			if len(operands) >= 3 {
+				show(operands[2])
+			}
+		case "TJ":
+			if len(operands) >= 1 {
+				array, _ := operands[0].(pdfArray)
+				for _, element := range array {
+					// A large negative offset is a word space
+					if offset, ok := element.(float64); ok {
+						if offset < -200 {
+							text.WriteString(" ")
+						}
+						advance -= offset / 1000 * fontSize
+					}
+					show(element)
+				}
+			}
+		case "BT":
+			lineX, lineY, advance = 0, 0, 0
+		case "ET":
+			text.WriteString(" ")
+		case "Do":
+			if len(operands) >= 1 && depth < pdfMaxFormDepth {
+				name, _ := operands[0].(pdfName)
+				xObjects, _ := file.resolve(resources["XObject"]).(pdfDict)
+				form, ok := file.resolve(xObjects[name]).(*pdfStream)
+				if ok && form.dict["Subtype"] == pdfName("Form") && !file.showing[form] {
+					formResources, ok := file.resolve(form.dict["Resources"]).(pdfDict)
+					if !ok {
+						formResources = resources
+					}
+					if data, err := file.decode(form); err == nil {
+						file.showing[form] = true
+						file.showContent(data, formResources, text, depth+1)
+						delete(file.showing, form)
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+}
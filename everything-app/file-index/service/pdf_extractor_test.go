@@ -0,0 +1,309 @@
+package service
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// pdfBuilder writes the objects of a test PDF, numbered from 1
+type pdfBuilder struct {
+	objects []string
+}
+
+// add appends an object and returns its number
+func (pdf *pdfBuilder) add(object string) int {
+	pdf.objects = append(pdf.objects, object)
+	return len(pdf.objects)
+}
+
+// stream appends a stream object with the entries of dict
+func (pdf *pdfBuilder) stream(dict string, data []byte) int {
+	return pdf.add(fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data))
+}
+
+// flateStream appends a stream object compressed with FlateDecode
+func (pdf *pdfBuilder) flateStream(dict string, data []byte) int {
+	return pdf.stream(dict+" /Filter /FlateDecode", flate(data))
+}
+
+// objectStream appends an object stream holding objects, numbered from
+// first, and returns its number
+func (pdf *pdfBuilder) objectStream(first int, objects ...string) int {
+	var header, body strings.Builder
+	for i, object := range objects {
+		fmt.Fprintf(&header, "%d %d ", first+i, body.Len())
+		body.WriteString(object + "\n")
+	}
+	dict := fmt.Sprintf("/Type /ObjStm /N %d /First %d", len(objects), header.Len())
+	return pdf.flateStream(dict, []byte(header.String()+body.String()))
+}
+
+// bytes returns the PDF file with a trailer of the entries of trailer, or
+// none if it is empty
+func (pdf *pdfBuilder) bytes(trailer string) []byte {
+	var data bytes.Buffer
+	data.WriteString("%PDF-1.7\n%\xE2\xE3\xCF\xD3\n")
+	for i, object := range pdf.objects {
+		fmt.Fprintf(&data, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	if trailer != "" {
+		fmt.Fprintf(&data, "trailer\n<< %s >>\n", trailer)
+	}
+	data.WriteString("%%EOF\n")
+	return data.Bytes()
+}
+
+func flate(data []byte) []byte {
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	writer.Write(data)
+	writer.Close()
+	return compressed.Bytes()
+}
+
+// simplePDF has two pages shown with a simple font, the second page with
+// two compressed content streams
+func simplePDF() []byte {
+	pdf := &pdfBuilder{}
+	pdf.add("<< /Type /Catalog /Pages 2 0 R >>")
+	pdf.add("<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 5 0 R >> >> >>")
+	pdf.add("<< /Type /Page /Parent 2 0 R /Contents 6 0 R >>")
+	pdf.add("<< /Type /Page /Parent 2 0 R /Contents [7 0 R 8 0 R] >>")
+	pdf.add("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding << /Type /Encoding /Differences [128 /Euro /fi] >> >>")
+	pdf.stream("", []byte(`BT /F1 12 Tf 72 720 Td (Hello) Tj ( world) Tj 0 -14 Td [(Caf) 0 (\351) -400 (\200\201nal)] TJ
+0 -14 Td (\(paren\)\\) Tj ET`))
+	pdf.flateStream("", []byte("BT /F1 10 Tf 72 720 Td (Second) Tj ET"))
+	pdf.flateStream("", []byte("BT /F1 10 Tf 72 700 Td (page) Tj ET"))
+	pdf.add("<< /Title <FEFF005400ED00740072006500> /Author (Jos\\351) >>")
+	return pdf.bytes("/Root 1 0 R /Info 9 0 R /Size 10")
+}
+
+// toUnicodeCMap maps the codes of a composite font: 1 and 2 to H and é,
+// 3 to 5 to a to c, 6 to a letter outside the BMP and 7 to a ligature
+const toUnicodeCMap = `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+/CMapName /Adobe-Identity-UCS def
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+2 beginbfchar
+<0001> <0048>
+<0002> <00E9>
+endbfchar
+2 beginbfrange
+<0003> <0005> <0061>
+<0006> <0007> [<D835DC00> <00660069>]
+endbfrange
+endcmap
+CMapName currentdict /CMap defineresource pop
+end
+end`
+
+// toUnicodePDF shows text with a composite font with a ToUnicode map, and
+// with one without that cannot be decoded
+func toUnicodePDF() []byte {
+	pdf := &pdfBuilder{}
+	pdf.add("<< /Type /Catalog /Pages 2 0 R >>")
+	pdf.add("<< /Type /Pages /Kids [3 0 R] /Count 1 >>")
+	pdf.add("<< /Type /Page /Parent 2 0 R /Resources << /Font << /F0 4 0 R /F1 7 0 R >> >> /Contents 6 0 R >>")
+	pdf.add("<< /Type /Font /Subtype /Type0 /BaseFont /ABCDEF+Sans /Encoding /Identity-H /DescendantFonts [5 0 R] /ToUnicode 8 0 R >>")
+	pdf.add("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /ABCDEF+Sans /DW 1000 /W [1 [500 500] 3 5 250] >>")
+	pdf.stream("", []byte("BT /F0 10 Tf 1 0 0 1 72 720 Tm <0001000200030004> Tj [<0005> -400 <00060007>] TJ\n"+
+		"1 0 0 1 72 700 Tm /F1 10 Tf <00010002> Tj ET"))
+	pdf.add("<< /Type /Font /Subtype /Type0 /BaseFont /Other /Encoding /Identity-H /DescendantFonts [5 0 R] >>")
+	pdf.flateStream("", []byte(toUnicodeCMap))
+	return pdf.bytes("/Root 1 0 R /Size 9")
+}
+
+// objectStreamPDF keeps its catalog, page tree and font in an object stream,
+// as objects 10 to 13, and has a cross-reference stream instead of a trailer
+func objectStreamPDF() []byte {
+	pdf := &pdfBuilder{}
+	pdf.objectStream(10,
+		"<< /Type /Catalog /Pages 11 0 R >>",
+		"<< /Type /Pages /Kids [12 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 11 0 R /Resources << /Font << /F1 13 0 R >> >> /Contents 2 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Times-Roman >>")
+	pdf.flateStream("", []byte("BT /F1 11 Tf 72 720 Td (Compressed objects) Tj ET"))
+	pdf.flateStream("/Type /XRef /Size 14 /W [1 2 1] /Root 10 0 R", []byte{0, 0, 0, 0})
+	return pdf.bytes("")
+}
+
+// readPDF reads the document of the PDF data
+func readPDF(t *testing.T, data []byte) (*Document, error) {
+	t.Helper()
+	path, _ := writeFile(t, "document.pdf", data)
+	return readPDFDocument(context.Background(), path)
+}
+
+func TestReadPDFDocument(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		data []byte
+		want Document
+	}{
+		{
+			name: "simple font",
+			data: simplePDF(),
+			want: Document{
+				Content:  "Hello world\nCafé €ﬁnal\n(paren)\\\n\nSecond\npage",
+				Metadata: map[string]string{MetaTitle: "Títre", MetaAuthor: "José", MetaPageCount: "2"},
+			},
+		},
+		{
+			name: "ToUnicode font",
+			data: toUnicodePDF(),
+			want: Document{
+				Content:  "Héabc 𝐀fi",
+				Metadata: map[string]string{MetaPageCount: "1"},
+			},
+		},
+		{
+			name: "object stream",
+			data: objectStreamPDF(),
+			want: Document{
+				Content:  "Compressed objects",
+				Metadata: map[string]string{MetaPageCount: "1"},
+			},
+		},
+	} {
+		document, err := readPDF(t, test.data)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*document, test.want) {
+			t.Errorf("%s: read %+v, want %+v", test.name, *document, test.want)
+		}
+	}
+}
+
+// TestReadPDFDocumentUpdated checks that the objects of an incremental
+// update replace the earlier ones
+func TestReadPDFDocumentUpdated(t *testing.T) {
+	data := simplePDF()
+	data = append(data, "6 0 obj\n<< /Length 28 >>\nstream\nBT /F1 12 Tf (Updated) Tj ET\nendstream\nendobj\n"+
+		"9 0 obj\n<< /Title (New) >>\nendobj\ntrailer\n<< /Root 1 0 R /Info 9 0 R /Prev 0 >>\n%%EOF\n"...)
+	document, err := readPDF(t, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(document.Content, "Updated\n\nSecond") || document.Metadata[MetaTitle] != "New" {
+		t.Errorf("read %+v, want the updated first page and title", *document)
+	}
+}
+
+// TestReadPDFDocumentCycles checks that a page tree node listed twice and a
+// form that shows itself are read once, and that a long chain of stream
+// lengths is cut
+func TestReadPDFDocumentCycles(t *testing.T) {
+	pdf := &pdfBuilder{}
+	pdf.add("<< /Type /Catalog /Pages 2 0 R >>")
+	pdf.add("<< /Type /Pages /Kids [3 0 R 2 0 R 3 0 R 2 0 R] /Count 1 >>")
+	pdf.add("<< /Type /Page /Parent 2 0 R /Resources << /XObject << /X1 5 0 R >> >> /Contents 4 0 R >>")
+	pdf.stream("", []byte("/X1 Do /X1 Do"))
+	pdf.stream("/Type /XObject /Subtype /Form /Resources << /XObject << /X1 5 0 R >> >>",
+		[]byte("BT (Form) Tj ET /X1 Do /X1 Do"))
+	// Every stream length refers to the next stream
+	for i := 0; i < 10000; i++ {
+		pdf.add(fmt.Sprintf("<< /Length %d 0 R >>\nstream\nx\nendstream", len(pdf.objects)+2))
+	}
+	document, err := readPDF(t, pdf.bytes("/Root 1 0 R"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Document{Content: "Form Form", Metadata: map[string]string{MetaPageCount: "1"}}
+	if !reflect.DeepEqual(*document, want) {
+		t.Errorf("read %+v, want %+v", *document, want)
+	}
+}
+
+func TestReadPDFDocumentEncrypted(t *testing.T) {
+	pdf := &pdfBuilder{}
+	pdf.add("<< /Type /Catalog /Pages 2 0 R >>")
+	pdf.add("<< /Type /Pages /Kids [3 0 R] /Count 1 >>")
+	pdf.add("<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>")
+	pdf.stream("", []byte("BT (\x8F\x12\xA0) Tj ET"))
+	pdf.add("<< /Filter /Standard /V 2 /R 3 >>")
+	pdf.add("<< /Title (\x8F\x12) >>")
+	document, err := readPDF(t, pdf.bytes("/Root 1 0 R /Encrypt 5 0 R /Info 6 0 R"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Document{Metadata: map[string]string{MetaPageCount: "1"}}
+	if !reflect.DeepEqual(*document, want) {
+		t.Errorf("read %+v, want %+v", *document, want)
+	}
+}
+
+func TestReadPDFDocumentErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"not a PDF":    "PK\x03\x04",
+		"no page tree": "%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\ntrailer\n<< /Root 1 0 R >>\n",
+		"no trailer":   "%PDF-1.4\n",
+	} {
+		if document, err := readPDF(t, []byte(data)); err == nil {
+			t.Errorf("%s: read %+v, want an error", name, document)
+		}
+	}
+}
+
+func TestPDFLexer(t *testing.T) {
+	for input, want := range map[string]any{
+		`<< /Type /Font /A#20B 12 /Kids [1 0 R 2 0 R] /N null /Flag true >>`: pdfDict{
+			"Type": pdfName("Font"), "A B": 12.0, "Kids": pdfArray{pdfRef{1, 0}, pdfRef{2, 0}}, "N": nil, "Flag": true,
+		},
+		`(a \(b\) \n\101\0611 line\
+continued (nested))`: "a (b) \nA11 linecontinued (nested)",
+		`<48 65 6C 6>`:            "Hel`",
+		`[ -1.5 +2 .5 3 0 obj ]`:  pdfArray{-1.5, 2.0, 0.5, 3.0, 0.0, pdfKeyword("obj")},
+		"% comment\n/Name%x\n":    pdfName("Name"),
+		`[[1] <<>> ()]`:           pdfArray{pdfArray{1.0}, pdfDict{}, ""},
+		`BT`:                      pdfKeyword("BT"),
+		`false`:                   false,
+		`(unterminated \) string`: "unterminated ) string",
+	} {
+		lex := &pdfLexer{data: []byte(input)}
+		got, err := lex.object()
+		if err != nil {
+			t.Errorf("object(%q): %v", input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("object(%q) = %#v, want %#v", input, got, want)
+		}
+	}
+
+	nested := strings.Repeat("[", pdfMaxNesting+1) + strings.Repeat("]", pdfMaxNesting+1)
+	for _, input := range []string{``, `<< /A >>`, `<< 1 2 >>`, `[1 2`, `)`, `>`, nested} {
+		lex := &pdfLexer{data: []byte(input)}
+		if got, err := lex.object(); err == nil {
+			t.Errorf("object(%q) = %#v, want an error", input, got)
+		}
+	}
+}
+
+// FuzzReadPDFDocument checks that damaged PDFs fail without a panic or a
+// hang
+func FuzzReadPDFDocument(f *testing.F) {
+	for _, data := range [][]byte{simplePDF(), toUnicodePDF(), objectStreamPDF()} {
+		f.Add(data)
+		f.Add(data[:len(data)/2])
+	}
+	dir := f.TempDir()
+	f.Fuzz(func(t *testing.T, data []byte) {
+		path := filepath.Join(dir, "fuzz.pdf")
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		readPDFDocument(context.Background(), path)
+	})
+}
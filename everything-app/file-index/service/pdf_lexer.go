@@ -0,0 +1,275 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// The PDF objects are decoded to these types, numbers to float64, booleans
+// to bool, strings to string and null to nil
+type (
+	pdfName    string
+	pdfKeyword string
+	pdfDelim   string
+	pdfDict    map[pdfName]any
+	pdfArray   []any
+	pdfRef     struct{ num, gen int }
+	pdfStream  struct {
+		dict pdfDict
+		data []byte
+	}
+)
+
+var errPDFSyntax = errors.New("invalid PDF syntax")
+
+// pdfMaxNesting bounds the nesting of arrays and dictionaries
+const pdfMaxNesting = 64
+
+// pdfLexer reads the objects of a PDF file or content stream
+type pdfLexer struct {
+	data []byte
+	pos  int
+	// depth is the number of arrays and dictionaries being read
+	depth int
+}
+
+func isPDFSpace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isPDFDelimiter(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+// skipSpace skips white space and comments
+func (lex *pdfLexer) skipSpace() {
+	for lex.pos < len(lex.data) {
+		c := lex.data[lex.pos]
+		switch {
+		case isPDFSpace(c):
+			lex.pos++
+		case c == '%':
+			for lex.pos < len(lex.data) && lex.data[lex.pos] != '\n' && lex.data[lex.pos] != '\r' {
+				lex.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// token reads a number, name, string, keyword or delimiter
+func (lex *pdfLexer) token() (any, error) {
+	lex.skipSpace()
+	if lex.pos >= len(lex.data) {
+		return nil, errPDFSyntax
+	}
+	c := lex.data[lex.pos]
+	switch c {
+	case '/':
+		lex.pos++
+		return pdfName(lex.regular(true)), nil
+	case '(':
+		lex.pos++
+		return lex.literalString(), nil
+	case '<':
+		if lex.pos+1 < len(lex.data) && lex.data[lex.pos+1] == '<' {
+			lex.pos += 2
+			return pdfDelim("<<"), nil
+		}
+		lex.pos++
+		return lex.hexString(), nil
+	case '>':
+		if lex.pos+1 < len(lex.data) && lex.data[lex.pos+1] == '>' {
+			lex.pos += 2
+			return pdfDelim(">>"), nil
+		}
+		lex.pos++
+		return nil, errPDFSyntax
+	case '[', ']', '{', '}':
+		lex.pos++
+		return pdfDelim(c), nil
+	case ')':
+		lex.pos++
+		return nil, errPDFSyntax
+	}
+	word := lex.regular(false)
+	if number, err := strconv.ParseFloat(word, 64); err == nil {
+		return number, nil
+	}
+	return pdfKeyword(word), nil
+}
+
+// regular reads regular characters, decoding #xx escapes in names
+func (lex *pdfLexer) regular(name bool) string {
+	var word []byte
+	for lex.pos < len(lex.data) {
+		c := lex.data[lex.pos]
+		if isPDFSpace(c) || isPDFDelimiter(c) {
+			break
+		}
+		lex.pos++
+		if name && c == '#' && lex.pos+2 <= len(lex.data) {
+			if b, err := strconv.ParseUint(string(lex.data[lex.pos:lex.pos+2]), 16, 8); err == nil {
+				word = append(word, byte(b))
+				lex.pos += 2
+				continue
+			}
+		}
+		word = append(word, c)
+	}
+	return string(word)
+}
+
+func (lex *pdfLexer) literalString() string {
+	var text []byte
+	depth := 1
+	for lex.pos < len(lex.data) {
+		c := lex.data[lex.pos]
+		lex.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return string(text)
+			}
+		case '\\':
+			if lex.pos >= len(lex.data) {
+				return string(text)
+			}
+			c = lex.data[lex.pos]
+			lex.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				// Line continuation
+				if lex.pos < len(lex.data) && lex.data[lex.pos] == '\n' {
+					lex.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					octal := int(c - '0')
+					for i := 0; i < 2 && lex.pos < len(lex.data) && lex.data[lex.pos] >= '0' && lex.data[lex.pos] <= '7'; i++ {
+						octal = octal*8 + int(lex.data[lex.pos]-'0')
+						lex.pos++
+					}
+					c = byte(octal)
+				}
+			}
+		}
+		text = append(text, c)
+	}
+	return string(text)
+}
+
+func (lex *pdfLexer) hexString() string {
+	var digits []byte
+	for lex.pos < len(lex.data) && lex.data[lex.pos] != '>' {
+		if c := lex.data[lex.pos]; !isPDFSpace(c) {
+			digits = append(digits, c)
+		}
+		lex.pos++
+	}
+	lex.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	text := make([]byte, 0, len(digits)/2)
+	for i := 0; i < len(digits); i += 2 {
+		b, _ := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		text = append(text, byte(b))
+	}
+	return string(text)
+}
+
+// object reads an object, an array or dictionary with its elements, or a
+// keyword. Closing delimiters are returned as pdfDelim.
+func (lex *pdfLexer) object() (any, error) {
+	token, err := lex.token()
+	if err != nil {
+		return nil, err
+	}
+	switch token := token.(type) {
+	case pdfDelim:
+		if token == "<<" || token == "[" {
+			if lex.depth >= pdfMaxNesting {
+				return nil, fmt.Errorf("%w: nested too deeply", errPDFSyntax)
+			}
+			lex.depth++
+			defer func() { lex.depth-- }()
+		}
+		switch token {
+		case "<<":
+			dict := make(pdfDict)
+			for {
+				key, err := lex.object()
+				if err != nil {
+					return nil, err
+				}
+				if key == pdfDelim(">>") {
+					return dict, nil
+				}
+				name, ok := key.(pdfName)
+				if !ok {
+					return nil, fmt.Errorf("%w: dictionary key %v", errPDFSyntax, key)
+				}
+				value, err := lex.object()
+				if err != nil {
+					return nil, err
+				}
+				dict[name] = value
+			}
+		case "[":
+			var array pdfArray
+			for {
+				element, err := lex.object()
+				if err != nil {
+					return nil, err
+				}
+				if element == pdfDelim("]") {
+					return array, nil
+				}
+				array = append(array, element)
+			}
+		}
+		return token, nil
+	case float64:
+		// An indirect reference is "num gen R"
+		start := lex.pos
+		if gen, err := lex.token(); err == nil {
+			if gen, ok := gen.(float64); ok {
+				if r, err := lex.token(); err == nil && r == pdfKeyword("R") {
+					return pdfRef{int(token), int(gen)}, nil
+				}
+			}
+		}
+		lex.pos = start
+		return token, nil
+	case pdfKeyword:
+		switch token {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+	}
+	return token, nil
+}
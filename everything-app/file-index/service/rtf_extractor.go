@@ -0,0 +1,232 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"strconv"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// The Rich Text Format documents of WordPad and older Word versions
+func init() {
+	err := RegisterExtractor(ExtractorConfig{
+		Name:       "rtf",
+		Extensions: []string{".rtf"},
+		Extractor:  ExtractorFunc(readRTFDocument),
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// rtfCodePages are the single-byte code pages of \ansicpg, others are read
+// as Windows-1252
+var rtfCodePages = map[int]*charmap.Charmap{
+	437:  charmap.CodePage437,
+	850:  charmap.CodePage850,
+	866:  charmap.CodePage866,
+	874:  charmap.Windows874,
+	1250: charmap.Windows1250,
+	1251: charmap.Windows1251,
+	1252: charmap.Windows1252,
+	1253: charmap.Windows1253,
+	1254: charmap.Windows1254,
+	1255: charmap.Windows1255,
+	1256: charmap.Windows1256,
+	1257: charmap.Windows1257,
+	1258: charmap.Windows1258,
+}
+
+// rtfSkipped are the destinations without document text
+var rtfSkipped = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "listtable": true,
+	"listoverridetable": true, "revtbl": true, "rsidtbl": true, "generator": true,
+	"pict": true, "object": true, "fldinst": true, "themedata": true,
+	"colorschememapping": true, "datastore": true, "latentstyles": true,
+	"xmlnstbl": true, "header": true, "headerl": true, "headerr": true, "headerf": true,
+	"footer": true, "footerl": true, "footerr": true, "footerf": true, "bkmkstart": true,
+	"bkmkend": true, "pgdsctbl": true, "filetbl": true, "shppict": true, "nonshppict": true,
+}
+
+// rtfGroup is the state of a {} group
+type rtfGroup struct {
+	// skip drops the text of the group
+	skip bool
+	// field is the info field the text of the group goes to
+	field string
+	// unicodeSkip is the number of fallback characters after \u
+	unicodeSkip int
+}
+
+// readRTFDocument reads the text of an RTF document, and the title, author
+// and page count of its info group
+func readRTFDocument(ctx context.Context, path string) (*Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	text := newTextBuilder(ctx)
+	fields := map[string]*[]rune{MetaTitle: new([]rune), MetaAuthor: new([]rune)}
+	document := &Document{}
+	codePage := charmap.Windows1252
+	group := rtfGroup{unicodeSkip: 1}
+	var stack []rtfGroup
+	// pendingSkip counts the fallback characters still to drop after \u,
+	// highSurrogate is the first half of a character outside the BMP
+	pendingSkip := 0
+	var highSurrogate rune
+
+	write := func(r rune) {
+		if pendingSkip > 0 {
+			pendingSkip--
+			return
+		}
+		switch {
+		case group.field != "":
+			*fields[group.field] = append(*fields[group.field], r)
+		case !group.skip:
+			text.WriteRune(r)
+		}
+	}
+
+	for !text.full() {
+		c, err := reader.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch c {
+		case '{':
+			stack = append(stack, group)
+			pendingSkip = 0
+		case '}':
+			if len(stack) > 0 {
+				group = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+			pendingSkip = 0
+		case '\r', '\n':
+		case '\\':
+			word, param, hasParam, err := readRTFControl(reader)
+			if err != nil {
+				break
+			}
+			switch word {
+			case "\\", "{", "}":
+				write(rune(word[0]))
+			case "'":
+				hex := make([]byte, 2)
+				if _, err := io.ReadFull(reader, hex); err == nil {
+					if b, err := strconv.ParseUint(string(hex), 16, 8); err == nil {
+						write(codePage.DecodeByte(byte(b)))
+					}
+				}
+			case "*":
+				group.skip = true
+			case "~":
+				write(' ')
+			case "_":
+				write('-')
+			case "\n", "\r", "par", "line", "sect", "page", "row":
+				write('\n')
+			case "tab", "cell":
+				write('\t')
+			case "u":
+				if hasParam {
+					if param < 0 {
+						param += 65536
+					}
+					r := rune(param)
+					switch {
+					case utf16.IsSurrogate(r) && highSurrogate == 0:
+						highSurrogate = r
+					case utf16.IsSurrogate(r):
+						write(utf16.DecodeRune(highSurrogate, r))
+						highSurrogate = 0
+					default:
+						write(r)
+					}
+					pendingSkip = group.unicodeSkip
+				}
+			case "uc":
+				group.unicodeSkip = param
+			case "ansicpg":
+				if cp, ok := rtfCodePages[param]; ok {
+					codePage = cp
+				}
+			case "info":
+				group.skip = true
+			case "title":
+				group.field = MetaTitle
+			case "author":
+				group.field = MetaAuthor
+			case "nofpages":
+				document.setMeta(MetaPageCount, strconv.Itoa(param))
+			default:
+				if rtfSkipped[word] {
+					group.skip = true
+				}
+			}
+		default:
+			write(codePage.DecodeByte(c))
+		}
+	}
+
+	for key, value := range fields {
+		document.setMeta(key, string(*value))
+	}
+	document.Content = normalizeText(text.String())
+	return document, nil
+}
+
+// readRTFControl reads a control word or symbol after a backslash and its
+// numeric parameter. The space ending a control word is consumed.
+func readRTFControl(reader *bufio.Reader) (word string, param int, hasParam bool, err error) {
+	c, err := reader.ReadByte()
+	if err != nil {
+		return "", 0, false, err
+	}
+	if !isASCIILetter(c) {
+		return string(c), 0, false, nil
+	}
+	name := []byte{c}
+	for {
+		c, err = reader.ReadByte()
+		if err != nil || !isASCIILetter(c) {
+			break
+		}
+		name = append(name, c)
+	}
+	var digits []byte
+	if err == nil && (c == '-' || isASCIIDigit(c)) {
+		for err == nil && (isASCIIDigit(c) || (c == '-' && len(digits) == 0)) {
+			digits = append(digits, c)
+			c, err = reader.ReadByte()
+		}
+	}
+	if err == nil && c != ' ' {
+		reader.UnreadByte()
+	}
+	if len(digits) > 0 {
+		param, _ = strconv.Atoi(string(digits))
+		hasParam = true
+	}
+	return string(name), param, hasParam, nil
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isASCIIDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
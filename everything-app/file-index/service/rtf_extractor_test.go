@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestReadRTFDocument(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		rtf  string
+		want Document
+	}{
+		{
+			name: "unicode escapes",
+			rtf: `{\rtf1\ansi\ansicpg1252\deff0{\fonttbl{\f0\fswiss Arial;}}{\colortbl;\red0\green0\blue0;}
+{\info{\title Report \u8212? 2024}{\author Jane Doe}{\operator Someone}{\nofpages3}}
+{\*\generator Msftedit 5.41;}\viewkind4\pard\f0\fs20 Caf\u233?s and {\uc2\u8364 EU} prices\par
+Emoji \u-10179?\u-8704? and \{braces\}\tab tab\line next\par
+{\header skipped header}{\*\unknowndest skipped too}Non\~breaking\_hyphen\par}`,
+			want: Document{
+				Content:  "Cafés and € prices\nEmoji 😀 and {braces}\ttab\nnext\nNon breaking-hyphen",
+				Metadata: map[string]string{MetaTitle: "Report — 2024", MetaAuthor: "Jane Doe", MetaPageCount: "3"},
+			},
+		},
+		{
+			name: "code page",
+			rtf:  `{\rtf1\ansi\ansicpg1251 \'cf\'f0\'e8\'e2\'e5\'f2, \'ec\'e8\'f0\par}`,
+			want: Document{Content: "Привет, мир"},
+		},
+		{
+			name: "default code page",
+			rtf:  `{\rtf1\ansi na\'efve caf` + "\xE9" + `}`,
+			want: Document{Content: "naïve café"},
+		},
+	} {
+		path, _ := writeFile(t, "document.rtf", []byte(test.rtf))
+		document, err := readRTFDocument(context.Background(), path)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*document, test.want) {
+			t.Errorf("%s: read %+v, want %+v", test.name, *document, test.want)
+		}
+	}
+}
+
+func TestReadRTFDocumentLimit(t *testing.T) {
+	path, _ := writeFile(t, "document.rtf", []byte(`{\rtf1\ansi first paragraph\par second paragraph\par}`))
+	ctx := context.WithValue(context.Background(), contentLimitKey{}, int64(5))
+	document, err := readRTFDocument(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if document.Content != "first" {
+		t.Errorf("read %q with a limit of 5 bytes, want %q", document.Content, "first")
+	}
+}
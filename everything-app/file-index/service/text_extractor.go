@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// textExtensions are the plain-text, markup, data and source files
+var textExtensions = []string{
+	".txt", ".text", ".md", ".markdown", ".rst", ".adoc", ".csv", ".tsv", ".log",
+	".json", ".yaml", ".yml", ".toml", ".ini", ".cfg", ".conf", ".env", ".properties",
+	".xml", ".svg", ".proto", ".sql", ".graphql",
+	".go", ".py", ".js", ".mjs", ".jsx", ".ts", ".tsx", ".vue", ".java", ".kt",
+	".scala", ".groovy", ".c", ".h", ".cc", ".cpp", ".hpp", ".cs", ".rs", ".swift",
+	".m", ".rb", ".php", ".pl", ".lua", ".r", ".dart", ".css", ".scss", ".less",
+	".sh", ".bash", ".zsh", ".ps1", ".bat", ".cmd", ".mk", ".cmake", ".gradle", ".tf",
+}
+
+// binarySample is the number of bytes checked for control characters
+const binarySample = 8 << 10
+
+// The text files, and files without a known extension that look like text,
+// such as README or Makefile
+func init() {
+	err := RegisterExtractor(ExtractorConfig{
+		Name:       "text",
+		Extensions: textExtensions,
+		MIMETypes:  []string{"text/plain"},
+		Extractor:  TextExtractor(readTextContent),
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// readTextContent reads a text file in UTF-8, UTF-16 or Windows-1252 and
+// returns it in UTF-8. UTF-16 without a byte order mark is only recognised
+// in mostly Latin text. Binary files have no content. TCVN3 and VNI files
+// are read as Windows-1252, their Vietnamese letters come out garbled.
+func readTextContent(ctx context.Context, path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	limit := ContentLimit(ctx)
+	var reader io.Reader = file
+	if limit > 0 {
+		// The bytes past the limit complete a UTF-8 character cut by it, the
+		// text is truncated to the limit afterwards
+		reader = io.LimitReader(file, limit+utf8.UTFMax-1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+
+	decoder, data := detectEncoding(data, limit > 0 && int64(len(data)) > limit)
+	if decoder == nil {
+		return "", nil
+	}
+	text, err := decoder.Bytes(data)
+	if err != nil {
+		return "", err
+	}
+	return truncateContent(string(text), limit), nil
+}
+
+// detectEncoding returns the decoder of data, or nil if data is binary. The
+// last character of truncated data may be cut, it is dropped from the
+// returned data.
+func detectEncoding(data []byte, truncated bool) (*encoding.Decoder, []byte) {
+	trim := func(data []byte) []byte {
+		if truncated {
+			return trimIncompleteRune(data)
+		}
+		return data
+	}
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return unicode.UTF8BOM.NewDecoder(), trim(data)
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder(), data[:len(data)&^1]
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder(), data[:len(data)&^1]
+	}
+
+	sample := data
+	if len(sample) > binarySample {
+		sample = sample[:binarySample]
+	}
+	for _, endianness := range []unicode.Endianness{unicode.LittleEndian, unicode.BigEndian} {
+		if looksUTF16(sample, endianness) {
+			return unicode.UTF16(endianness, unicode.IgnoreBOM).NewDecoder(), data[:len(data)&^1]
+		}
+	}
+	if looksBinary(sample) {
+		return nil, data
+	}
+	if trimmed := trim(data); utf8.Valid(trimmed) {
+		return encoding.Nop.NewDecoder(), trimmed
+	}
+	return charmap.Windows1252.NewDecoder(), data
+}
+
+// looksBinary reports whether sample has a NUL byte or more than 10% of
+// control characters other than whitespace and escape
+func looksBinary(sample []byte) bool {
+	control := 0
+	for _, b := range sample {
+		switch {
+		case b == 0:
+			return true
+		case b < 0x20 && b != '\t' && b != '\n' && b != '\r' && b != '\f' && b != '\b' && b != 0x1B:
+			control++
+		}
+	}
+	return control*10 > len(sample)
+}
+
+// looksUTF16 reports whether sample is UTF-16 text with the given byte
+// order: at least 90% of its code units are Latin-1 text and none is NUL
+func looksUTF16(sample []byte, endianness unicode.Endianness) bool {
+	units := len(sample) / 2
+	if units == 0 {
+		return false
+	}
+	latin := 0
+	for i := 0; i+1 < len(sample); i += 2 {
+		low, high := sample[i], sample[i+1]
+		if endianness == unicode.BigEndian {
+			low, high = high, low
+		}
+		switch {
+		case low == 0 && high == 0:
+			return false
+		case high == 0 && (low >= 0x20 || low == '\t' || low == '\n' || low == '\r'):
+			latin++
+		}
+	}
+	return latin*10 >= units*9
+}
+
+// trimIncompleteRune drops a last UTF-8 character cut by the content limit
+func trimIncompleteRune(data []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			break
+		}
+	}
+	return data
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReadTextContent(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"utf-8", "héllo wörld\n", "héllo wörld\n"},
+		{"utf-8 with bom", "\xEF\xBB\xBFhéllo", "héllo"},
+		{"utf-16le with bom", "\xFF\xFEh\x00\xE9\x00\x26\x20", "hé…"},
+		{"utf-16be with bom", "\xFE\xFF\x00h\x00\xE9\x20\x26", "hé…"},
+		{"utf-16le", "h\x00e\x00l\x00l\x00o\x00\r\x00\n\x00\xE9\x00", "hello\r\né"},
+		{"utf-16be", "\x00h\x00e\x00l\x00l\x00o\x00\n", "hello\n"},
+		{"windows-1252", "caf\xE9 \x80 na\xEFve", "café € naïve"},
+		// TCVN3 is read as Windows-1252
+		{"tcvn3", "Vi\xd6t", "ViÖt"},
+		{"windows-1252 ending in a letter", "caf\xE9", "café"},
+		{"empty", "", ""},
+		{"control characters", "text\x1B[1mbold\x1B[0m\f\b", "text\x1B[1mbold\x1B[0m\f\b"},
+		// Binary files have no content
+		{"nul byte", "\x7FELF\x02\x01\x01\x00\x00\x00", ""},
+		{"nul code unit", "a\x00\x00\x00b\x00", ""},
+		{"many control characters", "\x01\x02\x03abcdefgh", ""},
+	} {
+		path, _ := writeFile(t, "file.txt", []byte(test.content))
+		got, err := readTextContent(context.Background(), path)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: read %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+// TestReadTextContentLimit checks that the text is truncated to the content
+// limit without a cut character, and that the encoding is detected on whole
+// characters
+func TestReadTextContentLimit(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		content string
+		limit   int64
+		want    string
+	}{
+		{"utf-8", "abé", 3, "ab"},
+		{"utf-8 at the limit", "abé", 4, "abé"},
+		{"utf-8 with bom", "\xEF\xBB\xBFabé", 3, "ab"},
+		{"utf-16le with bom", "\xFF\xFEa\x00\xE9\x00", 2, "a"},
+		{"utf-16le", "a\x00b\x00c\x00", 2, "ab"},
+		{"windows-1252", "caf\xE9s", 4, "caf"},
+		{"windows-1252 at the limit", "caf\xE9s", 5, "café"},
+		{"windows-1252 cut in the read ahead", "abcde\xE9", 5, "abcde"},
+	} {
+		path, _ := writeFile(t, "file.txt", []byte(test.content))
+		ctx := context.WithValue(context.Background(), contentLimitKey{}, test.limit)
+		got, err := readTextContent(ctx, path)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: read %q with limit %d, want %q", test.name, got, test.limit, test.want)
+		}
+	}
+}
+
+// TestTextExtractorRegistered checks the text files the default registry
+// extracts, by extension or sniffed type
+func TestTextExtractorRegistered(t *testing.T) {
+	for name, want := range map[string]bool{
+		"main.go":  true,
+		"NOTES.MD": true,
+		"README":   true,
+		"logo":     false,
+	} {
+		content := "# Title\n"
+		if name == "logo" {
+			content = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+		}
+		path, info := writeFile(t, name, []byte(content))
+		document, err := DefaultExtractors.Extract(context.Background(), path, info)
+		if err != nil {
+			t.Errorf("Extract(%s): %v", name, err)
+			continue
+		}
+		if got := document != nil && document.Content == content; got != want {
+			t.Errorf("Extract(%s) = %+v, want content %v", name, document, want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	db "training/db/sqlc"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// pollInterval is how often the due notifications are delivered
+	pollInterval = 5 * time.Second
+	// dueLimit is the number of notifications delivered per poll
+	dueLimit = 100
+	// firstRetryDelay is the delay before the second attempt, it doubles
+	// after each failed attempt up to maxRetryDelay
+	firstRetryDelay = 10 * time.Second
+	maxRetryDelay   = time.Hour
+)
+
+// Notifier delivers the notifications to a webhook with a JSON POST. A
+// delivery failed with an error or a status other than 2xx is retried with
+// an exponential backoff until maxAttempts attempts failed.
+type Notifier struct {
+	store       db.Store
+	url         string
+	maxAttempts int32
+	client      *http.Client
+}
+
+// NewNotifier returns a Notifier posting to url
+func NewNotifier(store db.Store, url string, maxAttempts int32) *Notifier {
+	return &Notifier{
+		store:       store,
+		url:         url,
+		maxAttempts: maxAttempts,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// webhookPayload is the body posted to the webhook
+type webhookPayload struct {
+	ID          int32              `json:"id"`
+	SavedSearch webhookSavedSearch `json:"saved_search"`
+	Path        string             `json:"path"`
+	ChangeType  string             `json:"change_type"`
+	CreatedAt   time.Time          `json:"created_at"`
+	Attempt     int32              `json:"attempt"`
+}
+
+type webhookSavedSearch struct {
+	ID    int32  `json:"id"`
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// Run delivers the due notifications every pollInterval until ctx is done
+func (notifier *Notifier) Run(ctx context.Context) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		if err := notifier.DeliverDue(ctx); err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("cannot deliver notifications")
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// DeliverDue attempts to deliver the notifications that are due
+func (notifier *Notifier) DeliverDue(ctx context.Context) error {
+	for {
+		now := time.Now()
+		rows, err := notifier.store.ListDueNotifications(ctx, db.ListDueNotificationsParams{
+			MaxAttempts: notifier.maxAttempts,
+			Now:         now,
+			DueLimit:    dueLimit,
+		})
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			arg := db.UpdateNotificationAttemptParams{ID: row.Notification.ID}
+			if err := notifier.post(ctx, row); err != nil {
+				arg.LastError = err.Error()
+				arg.NextAttemptAt = time.Now().Add(retryDelay(row.Notification.Attempts))
+			} else {
+				arg.DeliveredAt = time.Now()
+				arg.NextAttemptAt = row.Notification.NextAttemptAt
+			}
+			if err := notifier.store.UpdateNotificationAttempt(ctx, arg); err != nil {
+				return err
+			}
+		}
+		if len(rows) < dueLimit {
+			return nil
+		}
+	}
+}
+
+// retryDelay returns the delay before the next attempt when an attempt
+// following attempts failed attempts failed
+func retryDelay(attempts int32) time.Duration {
+	delay := firstRetryDelay
+	for i := int32(0); i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+func (notifier *Notifier) post(ctx context.Context, row db.ListDueNotificationsRow) error {
+	body, err := json.Marshal(webhookPayload{
+		ID: row.Notification.ID,
+		SavedSearch: webhookSavedSearch{
+			ID:    row.Notification.SavedSearchID,
+			Owner: row.Owner,
+			Name:  row.Name,
+			Query: row.Query,
+		},
+		Path:       row.Notification.Path,
+		ChangeType: row.Notification.ChangeType,
+		CreatedAt:  row.Notification.CreatedAt,
+		Attempt:    row.Notification.Attempts + 1,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notifier.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := notifier.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
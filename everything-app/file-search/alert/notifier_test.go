@@ -0,0 +1,124 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+	db "training/db/sqlc"
+	"training/file-index/pb"
+)
+
+// laterStore lists the notifications due a day after now, so every retry is
+// due at the next delivery
+type laterStore struct {
+	db.Store
+}
+
+func (store laterStore) ListDueNotifications(ctx context.Context, arg db.ListDueNotificationsParams) ([]db.ListDueNotificationsRow, error) {
+	arg.Now = arg.Now.Add(24 * time.Hour)
+	return store.Store.ListDueNotifications(ctx, arg)
+}
+
+// failingWebhook returns a webhook failing its first failures requests, it
+// records the payloads received
+func failingWebhook(t *testing.T, failures int) (*httptest.Server, func() []webhookPayload) {
+	t.Helper()
+	var mutex sync.Mutex
+	var payloads []webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		var payload webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+		payloads = append(payloads, payload)
+		if len(payloads) <= failures {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []webhookPayload {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return payloads
+	}
+}
+
+func TestNotifierDeliverDue(t *testing.T) {
+	const maxAttempts = 3
+	for _, test := range []struct {
+		failures  int
+		delivered bool
+		attempts  int32
+	}{
+		{0, true, 1},
+		{2, true, 3},
+		// The attempts stop at maxAttempts
+		{5, false, maxAttempts},
+	} {
+		ctx := context.Background()
+		store, names := newAlertTestStore(t, map[string]string{"pdfs*": "ext:pdf"})
+		var searchID int32
+		for id := range names {
+			searchID = id
+		}
+		created, err := store.CreateNotification(ctx, db.CreateNotificationParams{
+			SavedSearchID: searchID,
+			Path:          "/srv/a.pdf",
+			ChangeType:    pb.ChangeType_CREATED.String(),
+			CreatedAt:     time.Now(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		server, payloads := failingWebhook(t, test.failures)
+		notifier := NewNotifier(laterStore{store}, server.URL, maxAttempts)
+		for i := 0; i < maxAttempts+2; i++ {
+			if err := notifier.DeliverDue(ctx); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		notifications, err := store.ListNotifications(ctx, db.ListNotificationsParams{SavedSearchID: searchID, Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		notification := notifications[0]
+		if notification.Attempts != test.attempts || notification.DeliveredAt.IsZero() == test.delivered {
+			t.Errorf("%d failures: %d attempts, delivered at %v, want %d attempts, delivered %v",
+				test.failures, notification.Attempts, notification.DeliveredAt, test.attempts, test.delivered)
+		}
+		if !test.delivered && notification.LastError == "" {
+			t.Errorf("%d failures: no error recorded", test.failures)
+		}
+		received := payloads()
+		if len(received) != int(test.attempts) {
+			t.Errorf("%d failures: %d requests, want %d", test.failures, len(received), test.attempts)
+		}
+		for i, payload := range received {
+			if payload.ID != created.ID || payload.Path != "/srv/a.pdf" || payload.SavedSearch.Name != "pdfs*" || payload.Attempt != int32(i+1) {
+				t.Errorf("%d failures: request %d posted %+v", test.failures, i, payload)
+			}
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempts, want := range map[int32]time.Duration{
+		0:  10 * time.Second,
+		1:  20 * time.Second,
+		3:  80 * time.Second,
+		9:  maxRetryDelay,
+		40: maxRetryDelay,
+	} {
+		if got := retryDelay(attempts); got != want {
+			t.Errorf("retryDelay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
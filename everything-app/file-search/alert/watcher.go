@@ -0,0 +1,227 @@
+// Package alert notifies the owners of watched saved searches when the
+// indexer creates or modifies a file matching them. The Watcher records a
+// notification for each match, or a gap notification when changes were
+// missed, and the Notifier delivers the notifications to a webhook, retrying
+// the failed deliveries.
+package alert
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"sync"
+	"time"
+	"training/db/filequery"
+	db "training/db/sqlc"
+	"training/file-index/pb"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// refreshInterval is how long the watched searches are cached, a search
+	// saved or changed is watched after at most this delay
+	refreshInterval = 30 * time.Second
+	// maxReconnectDelay bounds the delay between two connections to the
+	// change stream of the indexer
+	maxReconnectDelay = time.Minute
+)
+
+// GapChangeType is the change type of the notifications recorded when the
+// indexer dropped changes before the watcher received them, they have no
+// path
+const GapChangeType = "GAP"
+
+// watchedSearch is a watched saved search with its parsed query
+type watchedSearch struct {
+	id    int32
+	match func(file *filequery.File) bool
+}
+
+// Watcher follows the changes of the indexer and records a notification for
+// each watched search matching a created, modified or renamed file
+type Watcher struct {
+	store  db.Store
+	client pb.FileIndexClient
+
+	mutex    sync.Mutex
+	searches []watchedSearch
+	loadedAt time.Time
+}
+
+// NewWatcher returns a Watcher of the changes streamed by client
+func NewWatcher(store db.Store, client pb.FileIndexClient) *Watcher {
+	return &Watcher{
+		store:  store,
+		client: client,
+	}
+}
+
+// Run follows the changes until ctx is done. The stream resumes after the
+// last handled change, whose resume token is saved in the store so a
+// restarted watcher resumes after it too. When the indexer no longer has the
+// changes after the token, a gap notification is recorded for each watched
+// search and the new changes are watched.
+func (watcher *Watcher) Run(ctx context.Context) error {
+	token, err := watcher.store.GetWatchResumeToken(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	delay := time.Second
+	for {
+		received, err := watcher.follow(ctx, &token)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		switch code := status.Code(err); {
+		case token != "" && (code == codes.OutOfRange || code == codes.InvalidArgument):
+			log.Warn().Err(err).Msg("changes were dropped by the indexer, watching the new ones")
+			if err := watcher.recordGap(ctx); err != nil {
+				log.Error().Err(err).Msg("cannot record the dropped changes")
+			} else {
+				token = ""
+			}
+		default:
+			log.Error().Err(err).Msg("cannot watch the changes of the indexer")
+		}
+		if received {
+			delay = time.Second
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay = min(2*delay, maxReconnectDelay)
+	}
+}
+
+// follow handles the changes of one stream after token, which is updated to
+// and saved as the last handled change. It reports whether any change was
+// received.
+func (watcher *Watcher) follow(ctx context.Context, token *string) (bool, error) {
+	stream, err := watcher.client.WatchChanges(ctx, &pb.WatchChangesRequest{ResumeToken: *token})
+	if err != nil {
+		return false, err
+	}
+	received := false
+	for {
+		change, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return received, errors.New("change stream closed by the indexer")
+		}
+		if err != nil {
+			return received, err
+		}
+		received = true
+		if err := watcher.Handle(ctx, change); err != nil {
+			return received, err
+		}
+		*token = change.GetResumeToken()
+		if err := watcher.saveToken(ctx, *token); err != nil {
+			return received, err
+		}
+	}
+}
+
+// saveToken saves the resume token of the last handled change
+func (watcher *Watcher) saveToken(ctx context.Context, token string) error {
+	return watcher.store.SetWatchResumeToken(ctx, db.SetWatchResumeTokenParams{
+		Token:     token,
+		UpdatedAt: time.Now(),
+	})
+}
+
+// recordGap records a notification without path for each watched search,
+// telling its owner that changes may have been missed, and forgets the
+// saved resume token
+func (watcher *Watcher) recordGap(ctx context.Context) error {
+	searches, err := watcher.watchedSearches(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, search := range searches {
+		_, err := watcher.store.CreateNotification(ctx, db.CreateNotificationParams{
+			SavedSearchID: search.id,
+			ChangeType:    GapChangeType,
+			CreatedAt:     now,
+		})
+		if err != nil && !db.IsForeignKeyViolation(err) {
+			return err
+		}
+	}
+	return watcher.saveToken(ctx, "")
+}
+
+// Handle records the notifications of a change, deletions are not notified
+func (watcher *Watcher) Handle(ctx context.Context, change *pb.FileChange) error {
+	switch change.GetType() {
+	case pb.ChangeType_CREATED, pb.ChangeType_MODIFIED, pb.ChangeType_RENAMED:
+	default:
+		return nil
+	}
+	searches, err := watcher.watchedSearches(ctx)
+	if err != nil {
+		return err
+	}
+
+	fileAttr := change.GetFile()
+	file := &filequery.File{
+		Name:       fileAttr.GetName(),
+		Extension:  fileAttr.GetType(),
+		Path:       fileAttr.GetPath(),
+		Size:       fileAttr.GetSize(),
+		CreatedAt:  fileAttr.GetCreatedAt().AsTime(),
+		ModifiedAt: fileAttr.GetModifiedAt().AsTime(),
+		AccessedAt: fileAttr.GetAccessedAt().AsTime(),
+		Metadata:   fileAttr.GetMetadata(),
+	}
+	for _, search := range searches {
+		if !search.match(file) {
+			continue
+		}
+		_, err := watcher.store.CreateNotification(ctx, db.CreateNotificationParams{
+			SavedSearchID: search.id,
+			Path:          file.Path,
+			ChangeType:    change.GetType().String(),
+			CreatedAt:     time.Now(),
+		})
+		// The search may have been deleted since it was loaded
+		if err != nil && !db.IsForeignKeyViolation(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchedSearches returns the watched searches, loaded again when they are
+// older than refreshInterval. Named dates like today are resolved on load.
+func (watcher *Watcher) watchedSearches(ctx context.Context) ([]watchedSearch, error) {
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+
+	if time.Since(watcher.loadedAt) < refreshInterval {
+		return watcher.searches, nil
+	}
+	saved, err := watcher.store.ListWatchedSearches(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	searches := make([]watchedSearch, 0, len(saved))
+	for _, search := range saved {
+		query, err := filequery.Parse(search.Query, now)
+		if err != nil {
+			log.Error().Err(err).Int32("search", search.ID).Msg("cannot parse watched search")
+			continue
+		}
+		searches = append(searches, watchedSearch{id: search.ID, match: filequery.Matcher(query)})
+	}
+	watcher.searches = searches
+	watcher.loadedAt = now
+	return searches, nil
+}
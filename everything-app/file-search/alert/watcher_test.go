@@ -0,0 +1,254 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+	"training/db/memdb"
+	db "training/db/sqlc"
+	"training/file-index/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newAlertTestStore returns a memdb store with the saved searches of a user,
+// the searches named with a "*" suffix are watched
+func newAlertTestStore(t *testing.T, queries map[string]string) (*memdb.Store, map[int32]string) {
+	t.Helper()
+	ctx := context.Background()
+	store := memdb.NewStore()
+	if _, err := store.CreateUser(ctx, db.CreateUserParams{Username: "alice", Email: "alice@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	names := make(map[int32]string)
+	for name, query := range queries {
+		search, err := store.CreateSavedSearch(ctx, db.CreateSavedSearchParams{
+			Owner:     "alice",
+			Name:      name,
+			Query:     query,
+			Watch:     name[len(name)-1] == '*',
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		names[search.ID] = name
+	}
+	return store, names
+}
+
+// notified returns the "name change_type path" of the notifications of the
+// searches, sorted
+func notified(t *testing.T, store db.Store, names map[int32]string) []string {
+	t.Helper()
+	var result []string
+	for id, name := range names {
+		notifications, err := store.ListNotifications(context.Background(), db.ListNotificationsParams{SavedSearchID: id, Limit: 100})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, notification := range notifications {
+			result = append(result, name+" "+notification.ChangeType+" "+notification.Path)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+var alertQueries = map[string]string{
+	"pdfs*":     "ext:pdf",
+	"reports*":  "report path:/srv/share",
+	"big*":      "size:>1mb",
+	"authored*": `meta.author:"Jane Doe"`,
+	"old*":      "modified:<2024",
+	"all pdfs":  "ext:pdf",
+}
+
+func fileChange(changeType pb.ChangeType, path, name, extension string, size int64, modifiedAt time.Time) *pb.FileChange {
+	return &pb.FileChange{
+		Type: changeType,
+		File: &pb.FileAttr{
+			Path:       path,
+			Name:       name,
+			Type:       extension,
+			Size:       size,
+			CreatedAt:  timestamppb.New(modifiedAt),
+			ModifiedAt: timestamppb.New(modifiedAt),
+			AccessedAt: timestamppb.New(modifiedAt),
+		},
+	}
+}
+
+func TestWatcherHandle(t *testing.T) {
+	modifiedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	authored := fileChange(pb.ChangeType_MODIFIED, "/home/notes.txt", "notes.txt", ".txt", 10, modifiedAt)
+	authored.File.Metadata = map[string]string{"author": "Jane Doe"}
+
+	for _, test := range []struct {
+		name   string
+		change *pb.FileChange
+		want   []string
+	}{
+		{
+			// The unwatched search is not notified
+			"created",
+			fileChange(pb.ChangeType_CREATED, "/srv/share/report.PDF", "report.PDF", ".PDF", 100, modifiedAt),
+			[]string{"pdfs* CREATED /srv/share/report.PDF", "reports* CREATED /srv/share/report.PDF"},
+		},
+		{
+			"modified",
+			fileChange(pb.ChangeType_MODIFIED, "/srv/video.mp4", "video.mp4", ".mp4", 2<<20, modifiedAt.AddDate(-1, 0, 0)),
+			[]string{"big* MODIFIED /srv/video.mp4", "old* MODIFIED /srv/video.mp4"},
+		},
+		{
+			"renamed",
+			fileChange(pb.ChangeType_RENAMED, "/srv/share/Report-2024.txt", "Report-2024.txt", ".txt", 10, modifiedAt),
+			[]string{"reports* RENAMED /srv/share/Report-2024.txt"},
+		},
+		{"metadata", authored, []string{"authored* MODIFIED /home/notes.txt"}},
+		{
+			"outside path",
+			fileChange(pb.ChangeType_CREATED, "/home/report.txt", "report.txt", ".txt", 10, modifiedAt),
+			nil,
+		},
+		{
+			"deleted",
+			fileChange(pb.ChangeType_DELETED, "/srv/share/report.pdf", "report.pdf", ".pdf", 100, modifiedAt),
+			nil,
+		},
+	} {
+		store, names := newAlertTestStore(t, alertQueries)
+		watcher := NewWatcher(store, nil)
+		if err := watcher.Handle(context.Background(), test.change); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got := notified(t, store, names); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: notifications %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+// watchStream is a change stream sending changes and then err, or blocking
+// until its context is done if err is nil
+type watchStream struct {
+	grpc.ClientStream
+	ctx     context.Context
+	changes []*pb.FileChange
+	err     error
+	blocked chan struct{}
+}
+
+func (stream *watchStream) Recv() (*pb.FileChange, error) {
+	if len(stream.changes) > 0 {
+		change := stream.changes[0]
+		stream.changes = stream.changes[1:]
+		return change, nil
+	}
+	if stream.err != nil {
+		return nil, stream.err
+	}
+	close(stream.blocked)
+	<-stream.ctx.Done()
+	return nil, stream.ctx.Err()
+}
+
+// watchClient returns the streams of streams in order and records the resume
+// tokens of the requests
+type watchClient struct {
+	pb.FileIndexClient
+	streams []*watchStream
+	tokens  []string
+}
+
+func (client *watchClient) WatchChanges(ctx context.Context, in *pb.WatchChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[pb.FileChange], error) {
+	client.tokens = append(client.tokens, in.GetResumeToken())
+	if len(client.streams) == 0 {
+		return nil, errors.New("no more streams")
+	}
+	stream := client.streams[0]
+	client.streams = client.streams[1:]
+	if stream.changes == nil && stream.err != nil {
+		return nil, stream.err
+	}
+	stream.ctx = ctx
+	return stream, nil
+}
+
+// TestWatcherRun resumes after a saved token the indexer no longer has, and
+// checks the gap is notified and the new token is saved
+func TestWatcherRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store, names := newAlertTestStore(t, map[string]string{"pdfs*": "ext:pdf", "all pdfs": "ext:pdf"})
+	if err := store.SetWatchResumeToken(ctx, db.SetWatchResumeTokenParams{Token: "expired", UpdatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	created := fileChange(pb.ChangeType_CREATED, "/srv/a.pdf", "a.pdf", ".pdf", 10, time.Now())
+	created.ResumeToken = "token-1"
+	blocked := make(chan struct{})
+	client := &watchClient{streams: []*watchStream{
+		{err: status.Error(codes.OutOfRange, "resume token is not in the retained change history")},
+		{changes: []*pb.FileChange{created}, blocked: blocked},
+	}}
+	done := make(chan error)
+	go func() { done <- NewWatcher(store, client).Run(ctx) }()
+
+	select {
+	case <-blocked:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the watcher did not reconnect")
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run returned %v", err)
+	}
+
+	if want := []string{"expired", ""}; !reflect.DeepEqual(client.tokens, want) {
+		t.Errorf("requested tokens %q, want %q", client.tokens, want)
+	}
+	if token, err := store.GetWatchResumeToken(context.Background()); err != nil || token != "token-1" {
+		t.Errorf("saved token %q, %v, want token-1", token, err)
+	}
+	want := []string{"pdfs* CREATED /srv/a.pdf", "pdfs* " + GapChangeType + " "}
+	if got := notified(t, store, names); !reflect.DeepEqual(got, want) {
+		t.Errorf("notifications %q, want %q", got, want)
+	}
+}
+
+// TestWatcherRunEOF keeps the token of the last change when the stream
+// closes
+func TestWatcherRunEOF(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store, _ := newAlertTestStore(t, nil)
+
+	created := fileChange(pb.ChangeType_CREATED, "/srv/a.pdf", "a.pdf", ".pdf", 10, time.Now())
+	created.ResumeToken = "token-1"
+	blocked := make(chan struct{})
+	client := &watchClient{streams: []*watchStream{
+		{changes: []*pb.FileChange{created}, err: io.EOF},
+		{changes: []*pb.FileChange{}, blocked: blocked},
+	}}
+	done := make(chan error)
+	go func() { done <- NewWatcher(store, client).Run(ctx) }()
+
+	select {
+	case <-blocked:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the watcher did not reconnect")
+	}
+	cancel()
+	<-done
+	if want := []string{"", "token-1"}; !reflect.DeepEqual(client.tokens, want) {
+		t.Errorf("requested tokens %q, want %q", client.tokens, want)
+	}
+}
@@ -63,16 +63,109 @@ type loginUserResponse struct {
 }
 
 type newDataFile struct {
-	FilePath string `json:"filepath"`
-	CheckSum string `json:"checksum"`
+	FilePath   string    `json:"filepath"`
+	Name       string    `json:"name"`
+	Extension  string    `json:"extension"`
+	Size       int64     `json:"size"`
+	CreatedAt  time.Time `json:"created_at"`
+	ModifiedAt time.Time `json:"modified_at"`
+	AccessedAt time.Time `json:"accessed_at"`
+	Attributes string    `json:"attributes"`
+	// Metadata holds the properties extracted from documents and media, such
+	// as author, camera or artist
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	CheckSum   string            `json:"checksum,omitempty"`
+	Score      float32           `json:"score"`
+	Similarity float32           `json:"similarity"`
+	Snippet    string            `json:"snippet,omitempty"`
 }
 type Metadata struct {
-	Total  int `json:"total"`
-	Offset int `json:"offset"`
-	Limit  int `json:"limit"`
+	Total      int  `json:"total"`
+	TotalExact bool `json:"total_exact"`
+	// Offset is left out of the pages of a cursor
+	Offset     *int   `json:"offset,omitempty"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 type newFileResponse struct {
 	Status int           `json:"status"`
 	Data   []newDataFile `json:"data"`
 	Meta   Metadata      `json:"meta"`
 }
+type facetCount struct {
+	Value string `json:"value"`
+	Files int64  `json:"files"`
+	Size  int64  `json:"size"`
+}
+type fileStats struct {
+	Files              int64                   `json:"files"`
+	Size               int64                   `json:"size"`
+	Extensions         []facetCount            `json:"extensions"`
+	Directories        []facetCount            `json:"directories"`
+	Sizes              []facetCount            `json:"sizes"`
+	Months             []facetCount            `json:"months"`
+	LargestFiles       []db.GetLargestFilesRow `json:"largest_files"`
+	LargestDirectories []facetCount            `json:"largest_directories"`
+}
+type fileStatsResponse struct {
+	Status int       `json:"status"`
+	Data   fileStats `json:"data"`
+}
+type duplicateFile struct {
+	Filepath   string    `json:"filepath"`
+	Name       string    `json:"name"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+type duplicateGroup struct {
+	ContentHash string          `json:"content_hash"`
+	Size        int64           `json:"size"`
+	WastedBytes int64           `json:"wasted_bytes"`
+	Files       []duplicateFile `json:"files"`
+}
+type duplicateTotals struct {
+	Groups      int64 `json:"groups"`
+	Files       int64 `json:"files"`
+	WastedBytes int64 `json:"wasted_bytes"`
+}
+type duplicateFilesResponse struct {
+	Status int              `json:"status"`
+	Data   []duplicateGroup `json:"data"`
+	Meta   duplicateTotals  `json:"meta"`
+}
+type directoryDigest struct {
+	Path       string    `json:"path"`
+	Digest     string    `json:"digest"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+type directoryDigestResponse struct {
+	Status int             `json:"status"`
+	Data   directoryDigest `json:"data"`
+}
+type directoryChange struct {
+	Path       string `json:"path"`
+	ChangeType string `json:"change_type"`
+	Directory  bool   `json:"directory"`
+}
+type directoryDiff struct {
+	OldDigest string            `json:"old_digest"`
+	NewDigest string            `json:"new_digest"`
+	Changes   []directoryChange `json:"changes"`
+}
+type directoryDiffResponse struct {
+	Status int           `json:"status"`
+	Data   directoryDiff `json:"data"`
+}
+
+type savedSearchRequest struct {
+	Name  string `json:"name" binding:"required,max=100"`
+	Query string `json:"query" binding:"required"`
+	Watch bool   `json:"watch"`
+}
+type savedSearchesResponse struct {
+	Status int              `json:"status"`
+	Data   []db.SavedSearch `json:"data"`
+}
+type notificationsResponse struct {
+	Status int               `json:"status"`
+	Data   []db.Notification `json:"data"`
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+	db "training/db/sqlc"
+)
+
+// fileCursor is the position of the last file of a page of a file search.
+// It is sent to clients as an opaque next_cursor.
+type fileCursor struct {
+	SortBy     string    `json:"s"`
+	SortOrder  string    `json:"o"`
+	Path       string    `json:"p"`
+	Name       string    `json:"n,omitempty"`
+	Size       int64     `json:"z,omitempty"`
+	Time       time.Time `json:"t,omitempty"`
+	Rank       float32   `json:"r,omitempty"`
+	Similarity float32   `json:"m,omitempty"`
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// newFileCursor returns the cursor after row for a search sorted by sortBy
+func newFileCursor(row db.GetFilesRow, sortBy, sortOrder string) fileCursor {
+	cursor := fileCursor{
+		SortBy:    sortBy,
+		SortOrder: sortOrder,
+		Path:      row.Path,
+	}
+	switch sortBy {
+	case db.SortByName:
+		cursor.Name = row.Name
+	case db.SortBySize:
+		cursor.Size = row.Size
+	case db.SortByModifiedAt:
+		cursor.Time = row.ModifiedAt
+	case db.SortByCreatedAt:
+		cursor.Time = row.CreatedAt
+	case db.SortByRelevance:
+		cursor.Rank = row.Rank
+		cursor.Similarity = row.Similarity
+	}
+	return cursor
+}
+
+func (cursor fileCursor) encode() string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeFileCursor decodes a cursor, it has to come from a search with the
+// same sort
+func decodeFileCursor(value, sortBy, sortOrder string) (fileCursor, error) {
+	var cursor fileCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, errInvalidCursor
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Path == "" {
+		return cursor, errInvalidCursor
+	}
+	if cursor.SortBy != sortBy || cursor.SortOrder != sortOrder {
+		return cursor, errors.New("cursor is for another sort")
+	}
+	return cursor, nil
+}
+
+// apply makes arg return the files after the cursor
+func (cursor fileCursor) apply(arg *db.GetFilesParams) {
+	arg.AfterPath = cursor.Path
+	arg.AfterText = cursor.Name
+	arg.AfterSize = cursor.Size
+	arg.AfterTime = cursor.Time
+	arg.AfterRank = cursor.Rank
+	arg.AfterSimilarity = cursor.Similarity
+}
+
+// applyQuery makes arg return the files after the cursor
+func (cursor fileCursor) applyQuery(arg *db.QueryFilesParams) {
+	arg.AfterPath = cursor.Path
+	arg.AfterText = cursor.Name
+	arg.AfterSize = cursor.Size
+	arg.AfterTime = cursor.Time
+}
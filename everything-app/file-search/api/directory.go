@@ -0,0 +1,99 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"training/file-index/pb"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// @Summary Directory digest
+// @Description Merkle digest of the files below an indexed directory, it changes whenever a file below the directory changes. Keep it to list the changes later with /api/v1/directories/diff. The indexer computes the digests when directory_digests is set in its crawl config.
+// @Tags directories
+// @Accept  json
+// @Produce  json
+// @Param path query string true "Absolute path of the directory"
+// @Success 200 {object} directoryDigestResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/directories/digest [get]
+func (server *Server) getDirectoryDigest(ctx *gin.Context) {
+	res, err := server.fileSearcherClient.GetDirectoryDigest(ctx, &pb.GetDirectoryDigestRequest{Path: ctx.Query("path")})
+	if err != nil {
+		ctx.JSON(indexerErrorStatus(err), errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, directoryDigestResponse{
+		Status: http.StatusOK,
+		Data: directoryDigest{
+			Path:       res.GetPath(),
+			Digest:     res.GetDigest(),
+			ComputedAt: res.GetComputedAt().AsTime(),
+		},
+	})
+}
+
+// @Summary Directory changes
+// @Description Files and directories that differ between two digests of a directory: CREATED and DELETED files and subtrees, MODIFIED files. Without new_digest the old digest is compared with the current digest of path. Digests of versions that left the index are kept for a month.
+// @Tags directories
+// @Accept  json
+// @Produce  json
+// @Param path query string false "Absolute path of the directory, the paths of the changes are relative without it"
+// @Param old_digest query string true "Previous digest"
+// @Param new_digest query string false "Digest to compare with, the current digest of path by default"
+// @Param max_depth query int false "Directory levels to compare, a changed directory below is MODIFIED. 0 means unlimited" default(0)
+// @Success 200 {object} directoryDiffResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/directories/diff [get]
+func (server *Server) getDirectoryDiff(ctx *gin.Context) {
+	maxDepth, err := strconv.Atoi(ctx.DefaultQuery("max_depth", "0"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	res, err := server.fileSearcherClient.DiffDirectoryDigests(ctx, &pb.DiffDirectoryDigestsRequest{
+		Path:      ctx.Query("path"),
+		OldDigest: ctx.Query("old_digest"),
+		NewDigest: ctx.Query("new_digest"),
+		MaxDepth:  int32(maxDepth),
+	})
+	if err != nil {
+		ctx.JSON(indexerErrorStatus(err), errorResponse(err))
+		return
+	}
+	diff := directoryDiff{
+		OldDigest: res.GetOldDigest(),
+		NewDigest: res.GetNewDigest(),
+		Changes:   make([]directoryChange, len(res.GetChanges())),
+	}
+	for i, change := range res.GetChanges() {
+		diff.Changes[i] = directoryChange{
+			Path:       change.GetPath(),
+			ChangeType: change.GetType().String(),
+			Directory:  change.GetDirectory(),
+		}
+	}
+	ctx.JSON(http.StatusOK, directoryDiffResponse{Status: http.StatusOK, Data: diff})
+}
+
+// indexerErrorStatus returns the HTTP status of an error returned by the indexer
+func indexerErrorStatus(err error) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.FailedPrecondition, codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.Canceled, codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -1,24 +1,29 @@
 package api
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
+	"training/db/filequery"
 	db "training/db/sqlc"
 	"training/file-index/pb"
-	"training/file-search/util"
 
 	"github.com/gin-gonic/gin"
 )
 
 // @Summary Search files
-// @Description Search files
+// @Description Search files with the q query or with the filter parameters, q can not be combined with them.
+// @Description The metadata extracted from documents and media is filtered with meta.<key> parameters matching
+// @Description the whole value, such as meta.author=Jane or meta.camera=Canon EOS 5D, or with meta.<key>: in q.
 // @Tags files
 // @Accept  json
 // @Produce  json
+// @Param q query string false "Query like: ext:docx size:>10mb modified:lastweek path:/srv/share \"quarterly report\" !tmp"
 // @Param name query string false "File name"
+// @Param match query string false "Name match mode" Enums(substring, exact, regex, fuzzy) default(substring)
+// @Param threshold query number false "Minimum similarity of a fuzzy match, 0 to 1"
 // @Param extension query string false "File extension"
 // @Param size_min query int false "Minimum file size"
 // @Param size_max query int false "Maximum file size"
@@ -28,13 +33,217 @@ import (
 // @Param modified_before query string false "Modified before"
 // @Param accessed_after query string false "Accessed after"
 // @Param accessed_before query string false "Accessed before"
-// @Param content query string false "Content"
-// @Param offset query int false "Offset"
+// @Param content query string false "Content search: words, \"phrases\", prefix*, OR, -excluded and (groups)"
+// @Param sort_by query string false "Sort key, files with the same key are sorted by path" Enums(relevance, name, size, modified_at, created_at, path) default(relevance)
+// @Param order query string false "Sort order, descending by default for relevance" Enums(asc, desc)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param offset query int false "Offset, can not be combined with cursor"
 // @Param limit query int false "Limit"
+// @Param include query string false "Comma separated extra fields" Enums(checksum)
 // @Success 200 {object} newFileResponse
 // @Failure 400 {object} ErrorResponse
 // @Router /api/v1/files [get]
 func (server *Server) getFileSearcher(ctx *gin.Context) {
+	includeChecksum := false
+	for _, include := range strings.Split(ctx.Query("include"), ",") {
+		switch include {
+		case "":
+		case "checksum":
+			includeChecksum = true
+		default:
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("unknown include %q", include)))
+			return
+		}
+	}
+	sortBy := ctx.DefaultQuery("sort_by", db.SortByRelevance)
+	switch sortBy {
+	case db.SortByName, db.SortBySize, db.SortByModifiedAt, db.SortByCreatedAt, db.SortByPath, db.SortByRelevance:
+	default:
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("unknown sort_by %q", sortBy)))
+		return
+	}
+	defaultOrder := db.SortAsc
+	if sortBy == db.SortByRelevance {
+		defaultOrder = db.SortDesc
+	}
+	order := ctx.DefaultQuery("order", defaultOrder)
+	if order != db.SortAsc && order != db.SortDesc {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("order must be asc or desc")))
+		return
+	}
+	page := filePage{SortBy: sortBy, SortOrder: order}
+	offset, _ := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "0"))
+	page.Offset, page.Limit = int32(offset), int32(limit)
+	if value := ctx.Query("cursor"); value != "" {
+		// The cursor is the position, an offset would skip files on every page
+		if offset != 0 {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("cursor can not be combined with offset")))
+			return
+		}
+		cursor, err := decodeFileCursor(value, sortBy, order)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		page.Cursor = &cursor
+	}
+
+	// Call the database to retrieve the files
+	var rows []db.GetFilesRow
+	var total int64
+	var err error
+	if q, ok := ctx.GetQuery("q"); ok {
+		rows, total, err = server.queryFiles(ctx, q, page)
+	} else {
+		rows, total, err = server.filterFiles(ctx, page)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	datas := make([]newDataFile, len(rows))
+	// Keep the order of the search, the best matches come first
+	for i, row := range rows {
+		datas[i] = newDataFile{
+			FilePath:   row.Path,
+			Name:       row.Name,
+			Extension:  row.Extension,
+			Size:       row.Size,
+			CreatedAt:  row.CreatedAt,
+			ModifiedAt: row.ModifiedAt,
+			AccessedAt: row.AccessedAt,
+			Attributes: row.Attributes,
+			Metadata:   db.MetadataValues(row.Metadata),
+			Score:      row.Rank,
+			Similarity: row.Similarity,
+			Snippet:    row.Snippet,
+		}
+	}
+	// Checksums are computed by the indexer on demand
+	if includeChecksum && len(rows) > 0 {
+		req := &pb.CreateFileChecksumRequest{
+			Filepath: make([]string, len(rows)),
+		}
+		for i, row := range rows {
+			req.Filepath[i] = row.Path
+		}
+		responses, err := server.fileSearcherClient.GetCheckSumFiles(ctx, req)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		for i := range datas {
+			datas[i].CheckSum = responses.Checksums[datas[i].FilePath]
+		}
+	}
+
+	response := newFileResponse{
+		Status: http.StatusOK,
+		Data:   datas,
+		Meta: Metadata{
+			Total:      int(min(total, maxExactTotal)),
+			TotalExact: total <= maxExactTotal,
+			Limit:      limit,
+		},
+	}
+	if page.Cursor == nil {
+		response.Meta.Offset = &offset
+	}
+	// A full page may be followed by another one
+	if limit > 0 && len(rows) == limit {
+		response.Meta.NextCursor = newFileCursor(rows[len(rows)-1], sortBy, order).encode()
+	}
+	// Return the search results
+	ctx.JSON(http.StatusOK, response)
+}
+
+// filePage is the sort and the page of a file search
+type filePage struct {
+	SortBy    string
+	SortOrder string
+	Cursor    *fileCursor
+	Offset    int32
+	Limit     int32
+}
+
+// filterParams are the query parameters of the search by filters
+var filterParams = []string{
+	"name", "match", "threshold", "extension", "size_min", "size_max",
+	"created_after", "created_before", "modified_after", "modified_before",
+	"accessed_after", "accessed_before", "content",
+}
+
+// metaParamPrefix starts the names of the metadata filter parameters, the
+// rest of the name is the metadata key
+const metaParamPrefix = "meta."
+
+// parseMetaParams returns the metadata filter of the meta.<key> parameters
+func parseMetaParams(ctx *gin.Context) (string, error) {
+	values := make(map[string]string)
+	for param, value := range ctx.Request.URL.Query() {
+		key, ok := strings.CutPrefix(param, metaParamPrefix)
+		if !ok {
+			continue
+		}
+		if key == "" || value[0] == "" {
+			return "", fmt.Errorf("%s needs a key and a value", param)
+		}
+		values[key] = value[0]
+	}
+	return db.MetadataFilter(values), nil
+}
+
+// parseFileQuery parses q, which can not be combined with the filter
+// parameters
+func parseFileQuery(ctx *gin.Context, q string) (filequery.Node, error) {
+	for _, param := range filterParams {
+		if _, ok := ctx.GetQuery(param); ok {
+			return nil, fmt.Errorf("q can not be combined with %s", param)
+		}
+	}
+	for param := range ctx.Request.URL.Query() {
+		if strings.HasPrefix(param, metaParamPrefix) {
+			return nil, fmt.Errorf("q can not be combined with %s", param)
+		}
+	}
+	query, err := filequery.Parse(q, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("invalid q: %w", err)
+	}
+	return query, nil
+}
+
+// queryFiles searches the files matching q and counts them
+func (server *Server) queryFiles(ctx *gin.Context, q string, page filePage) ([]db.GetFilesRow, int64, error) {
+	query, err := parseFileQuery(ctx, q)
+	if err != nil {
+		return nil, 0, err
+	}
+	arg := db.QueryFilesParams{
+		Query:      query,
+		SortBy:     page.SortBy,
+		SortOrder:  page.SortOrder,
+		PageOffset: page.Offset,
+		PageLimit:  page.Limit,
+	}
+	if page.Cursor != nil {
+		page.Cursor.applyQuery(&arg)
+	}
+	rows, err := server.store.QueryFiles(ctx, arg)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := server.store.CountQueryFiles(ctx, query, maxExactTotal+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+// parseFileFilter parses the filter parameters into the filters of a
+// GetFiles search
+func (server *Server) parseFileFilter(ctx *gin.Context) (db.GetFilesParams, error) {
 	// Retrieve query parameters with defaults
 	name := ctx.DefaultQuery("name", "")
 	extension := ctx.DefaultQuery("extension", "")
@@ -50,91 +259,109 @@ func (server *Server) getFileSearcher(ctx *gin.Context) {
 	// Parse timestamps as Unix times
 	createdAfterTime, err := parseUnixTimestamp(createdAfter)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		return db.GetFilesParams{}, err
 	}
 	createdBeforeTime, err := parseUnixTimestamp(createdBefore)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		return db.GetFilesParams{}, err
 	}
 	modifiedAfterTime, err := parseUnixTimestamp(modifiedAfter)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		return db.GetFilesParams{}, err
 	}
 	modifiedBeforeTime, err := parseUnixTimestamp(modifiedBefore)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		return db.GetFilesParams{}, err
 	}
 	accessedAfterTime, err := parseUnixTimestamp(accessedAfter)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		return db.GetFilesParams{}, err
 	}
 	accessedBeforeTime, err := parseUnixTimestamp(accessedBefore)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		return db.GetFilesParams{}, err
 	}
-	fmt.Println(createdAfterTime, createdBeforeTime, modifiedAfterTime, modifiedBeforeTime, accessedAfterTime, accessedBeforeTime)
 	content := ctx.DefaultQuery("content", "")
-	offset, _ := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
-	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "0"))
-	// Prepare the database parameters
-	arg := db.GetFilesParams{
-		Column1:      util.NullableString(name),
-		Extension:    extension,
-		Size:         int64(sizeMin),
-		Size_2:       int64(sizeMax),
-		CreatedAt:    createdAfterTime,
-		CreatedAt_2:  createdBeforeTime,
-		ModifiedAt:   modifiedAfterTime,
-		ModifiedAt_2: modifiedBeforeTime,
-		AccessedAt:   accessedAfterTime,
-		AccessedAt_2: accessedBeforeTime,
-		Column11:     util.NullableString(content),
-		Offset:       int32(offset),
-		Column13:     int32(limit),
+	match := ctx.DefaultQuery("match", db.MatchSubstring)
+	switch match {
+	case db.MatchSubstring, db.MatchExact, db.MatchRegex, db.MatchFuzzy:
+	default:
+		return db.GetFilesParams{}, fmt.Errorf("unknown match mode %q", match)
 	}
-	// Call the database to retrieve the files
-	paths, err := server.store.GetFiles(ctx, arg)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+	threshold := server.config.SimilarityThreshold
+	if value, ok := ctx.GetQuery("threshold"); ok {
+		parsed, err := strconv.ParseFloat(value, 32)
+		if err != nil || parsed < 0 || parsed > 1 {
+			return db.GetFilesParams{}, fmt.Errorf("threshold must be a number between 0 and 1")
+		}
+		threshold = float32(parsed)
 	}
-	req := &pb.CreateFileChecksumRequest{
-		Filepath: paths,
+	metadata, err := parseMetaParams(ctx)
+	if err != nil {
+		return db.GetFilesParams{}, err
 	}
+	// Prepare the database parameters
+	return db.GetFilesParams{
+		Name:           name,
+		NameMatch:      match,
+		Threshold:      threshold,
+		Extension:      extension,
+		SizeMin:        int64(sizeMin),
+		SizeMax:        int64(sizeMax),
+		CreatedAfter:   createdAfterTime,
+		CreatedBefore:  createdBeforeTime,
+		ModifiedAfter:  modifiedAfterTime,
+		ModifiedBefore: modifiedBeforeTime,
+		AccessedAfter:  accessedAfterTime,
+		AccessedBefore: accessedBeforeTime,
+		Content:        content,
+		Metadata:       metadata,
+	}, nil
+}
 
-	var datas []newDataFile
-	context := context.Background()
-	responses, err := server.fileSearcherClient.GetCheckSumFiles(context, req)
+// filterFiles searches the files matching the filter parameters and counts
+// them
+func (server *Server) filterFiles(ctx *gin.Context, page filePage) ([]db.GetFilesRow, int64, error) {
+	arg, err := server.parseFileFilter(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		return nil, 0, err
 	}
-	for key, value := range responses.Checksums {
-		datas = append(datas, newDataFile{
-			FilePath: key,
-			CheckSum: value,
-		})
+	arg.SortBy, arg.SortOrder = page.SortBy, page.SortOrder
+	arg.PageOffset, arg.PageLimit = page.Offset, page.Limit
+	if page.Cursor != nil {
+		page.Cursor.apply(&arg)
 	}
-
-	response := newFileResponse{
-		Status: http.StatusOK,
-		Data:   datas,
-		Meta: Metadata{
-			Total:  len(paths),
-			Offset: offset,
-			Limit:  limit,
-		},
+	rows, err := server.store.GetFiles(ctx, arg)
+	if err != nil {
+		return nil, 0, err
 	}
-	// Return the search results
-	ctx.JSON(http.StatusOK, response)
+	total, err := server.store.CountFiles(ctx, db.CountFilesParams{
+		Name:           arg.Name,
+		NameMatch:      arg.NameMatch,
+		Threshold:      arg.Threshold,
+		Extension:      arg.Extension,
+		SizeMin:        arg.SizeMin,
+		SizeMax:        arg.SizeMax,
+		CreatedAfter:   arg.CreatedAfter,
+		CreatedBefore:  arg.CreatedBefore,
+		ModifiedAfter:  arg.ModifiedAfter,
+		ModifiedBefore: arg.ModifiedBefore,
+		AccessedAfter:  arg.AccessedAfter,
+		AccessedBefore: arg.AccessedBefore,
+		Content:        arg.Content,
+		Metadata:       arg.Metadata,
+		CountLimit:     maxExactTotal + 1,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
 }
 
+// maxExactTotal is the number of files counted for the total of a search,
+// a larger total is reported as maxExactTotal and not exact
+const maxExactTotal = 100000
+
 // Helper function to parse Unix timestamp
 func parseUnixTimestamp(timestamp string) (time.Time, error) {
 	unixTime, err := strconv.ParseInt(timestamp, 10, 64)
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	db "training/db/sqlc"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultDuplicateGroups and maxDuplicateGroups bound the number of
+	// groups of duplicate files returned at once
+	defaultDuplicateGroups = 100
+	maxDuplicateGroups     = 1000
+)
+
+// @Summary Duplicate files
+// @Description Groups of indexed files with the same content, the groups wasting the most bytes first. A group wastes the size of all of its files but one. The indexer hashes the files sharing their size with another file after each crawl, so a new file may be missing until then.
+// @Tags files
+// @Accept  json
+// @Produce  json
+// @Param root query string false "Only group the files below this absolute path"
+// @Param min_size query int false "Only group the files of at least this many bytes" default(1)
+// @Param limit query int false "Number of groups, up to 1000" default(100)
+// @Success 200 {object} duplicateFilesResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/files/duplicates [get]
+func (server *Server) getDuplicateFiles(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultDuplicateGroups)))
+	if err != nil || limit < 1 || limit > maxDuplicateGroups {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("limit must be a number between 1 and %d", maxDuplicateGroups)))
+		return
+	}
+	minSize, err := strconv.ParseInt(ctx.DefaultQuery("min_size", "1"), 10, 64)
+	if err != nil || minSize < 1 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("min_size must be a positive number")))
+		return
+	}
+	prefix, err := rootPrefix(ctx.Query("root"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	rows, err := server.store.GetDuplicateFiles(ctx, db.GetDuplicateFilesParams{
+		Prefix:     prefix,
+		MinSize:    minSize,
+		GroupLimit: int32(limit),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	totals, err := server.store.GetDuplicateTotals(ctx, db.GetDuplicateTotalsParams{
+		MinSize: minSize,
+		Prefix:  prefix,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, duplicateFilesResponse{
+		Status: http.StatusOK,
+		Data:   newDuplicateGroups(rows),
+		Meta: duplicateTotals{
+			Groups:      totals.GroupCount,
+			Files:       totals.FileCount,
+			WastedBytes: totals.Wasted,
+		},
+	})
+}
+
+// rootPrefix returns the prefix of the paths below root, every path starts
+// with the prefix of an empty root
+func rootPrefix(root string) (string, error) {
+	if root == "" {
+		return "", nil
+	}
+	if !filepath.IsAbs(root) {
+		return "", fmt.Errorf("root %q is not an absolute path", root)
+	}
+	root = filepath.Clean(root)
+	if root == string(filepath.Separator) {
+		return root, nil
+	}
+	return root + string(filepath.Separator), nil
+}
+
+// newDuplicateGroups splits the rows of GetDuplicateFiles, ordered by group,
+// into groups
+func newDuplicateGroups(rows []db.GetDuplicateFilesRow) []duplicateGroup {
+	groups := []duplicateGroup{}
+	for _, row := range rows {
+		if n := len(groups); n == 0 || groups[n-1].ContentHash != row.ContentHash || groups[n-1].Size != row.Size {
+			groups = append(groups, duplicateGroup{
+				ContentHash: row.ContentHash,
+				Size:        row.Size,
+				WastedBytes: row.Wasted,
+				Files:       []duplicateFile{},
+			})
+		}
+		group := &groups[len(groups)-1]
+		group.Files = append(group.Files, duplicateFile{
+			Filepath:   row.Path,
+			Name:       row.Name,
+			ModifiedAt: row.ModifiedAt,
+		})
+	}
+	return groups
+}
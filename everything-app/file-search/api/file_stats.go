@@ -0,0 +1,170 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"training/db/filequery"
+	db "training/db/sqlc"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxStatsTop is the largest number of largest files and directories
+const maxStatsTop = 1000
+
+// @Summary File statistics
+// @Description Facets of the files matching the q query or the filter parameters of /api/v1/files: the number and the total size of the files by extension, by top-level directory, by named size and by modification month in UTC, and the largest files and directories. A directory holds the size of the files it directly contains.
+// @Tags files
+// @Accept  json
+// @Produce  json
+// @Param q query string false "Query like: ext:docx size:>10mb modified:lastweek path:/srv/share \"quarterly report\" !tmp"
+// @Param name query string false "File name"
+// @Param match query string false "Name match mode" Enums(substring, exact, regex, fuzzy) default(substring)
+// @Param threshold query number false "Minimum similarity of a fuzzy match, 0 to 1"
+// @Param extension query string false "File extension"
+// @Param size_min query int false "Minimum file size"
+// @Param size_max query int false "Maximum file size"
+// @Param created_after query string false "Created after"
+// @Param created_before query string false "Created before"
+// @Param modified_after query string false "Modified after"
+// @Param modified_before query string false "Modified before"
+// @Param accessed_after query string false "Accessed after"
+// @Param accessed_before query string false "Accessed before"
+// @Param content query string false "Content search: words, \"phrases\", prefix*, OR, -excluded and (groups)"
+// @Param top query int false "Number of largest files and directories, up to 1000" default(10)
+// @Success 200 {object} fileStatsResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/files/stats [get]
+func (server *Server) getFileStats(ctx *gin.Context) {
+	top, err := strconv.Atoi(ctx.DefaultQuery("top", "10"))
+	if err != nil || top < 1 || top > maxStatsTop {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("top must be a number between 1 and %d", maxStatsTop)))
+		return
+	}
+
+	var facets []db.GetFileFacetsRow
+	var largest []db.GetLargestFilesRow
+	if q, ok := ctx.GetQuery("q"); ok {
+		facets, largest, err = server.queryFileStats(ctx, q, int32(top))
+	} else {
+		facets, largest, err = server.filterFileStats(ctx, int32(top))
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, fileStatsResponse{
+		Status: http.StatusOK,
+		Data:   newFileStats(facets, largest),
+	})
+}
+
+// queryFileStats returns the facets and the largest files of the files
+// matching q
+func (server *Server) queryFileStats(ctx *gin.Context, q string, top int32) ([]db.GetFileFacetsRow, []db.GetLargestFilesRow, error) {
+	query, err := parseFileQuery(ctx, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	facets, err := server.store.QueryFileFacets(ctx, query, top)
+	if err != nil {
+		return nil, nil, err
+	}
+	largest, err := server.store.QueryLargestFiles(ctx, query, top)
+	if err != nil {
+		return nil, nil, err
+	}
+	return facets, largest, nil
+}
+
+// filterFileStats returns the facets and the largest files of the files
+// matching the filter parameters
+func (server *Server) filterFileStats(ctx *gin.Context, top int32) ([]db.GetFileFacetsRow, []db.GetLargestFilesRow, error) {
+	arg, err := server.parseFileFilter(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	facets, err := server.store.GetFileFacets(ctx, db.GetFileFacetsParams{
+		Name:           arg.Name,
+		NameMatch:      arg.NameMatch,
+		Threshold:      arg.Threshold,
+		Extension:      arg.Extension,
+		SizeMin:        arg.SizeMin,
+		SizeMax:        arg.SizeMax,
+		CreatedAfter:   arg.CreatedAfter,
+		CreatedBefore:  arg.CreatedBefore,
+		ModifiedAfter:  arg.ModifiedAfter,
+		ModifiedBefore: arg.ModifiedBefore,
+		AccessedAfter:  arg.AccessedAfter,
+		AccessedBefore: arg.AccessedBefore,
+		Content:        arg.Content,
+		Metadata:       arg.Metadata,
+		TopLimit:       top,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	largest, err := server.store.GetLargestFiles(ctx, db.GetLargestFilesParams{
+		Name:           arg.Name,
+		NameMatch:      arg.NameMatch,
+		Threshold:      arg.Threshold,
+		Extension:      arg.Extension,
+		SizeMin:        arg.SizeMin,
+		SizeMax:        arg.SizeMax,
+		CreatedAfter:   arg.CreatedAfter,
+		CreatedBefore:  arg.CreatedBefore,
+		ModifiedAfter:  arg.ModifiedAfter,
+		ModifiedBefore: arg.ModifiedBefore,
+		AccessedAfter:  arg.AccessedAfter,
+		AccessedBefore: arg.AccessedBefore,
+		Content:        arg.Content,
+		Metadata:       arg.Metadata,
+		TopLimit:       top,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return facets, largest, nil
+}
+
+// newFileStats splits the facets by name. Extensions and directories are
+// ordered by size descending, sizes from empty to gigantic and months in
+// chronological order.
+func newFileStats(facets []db.GetFileFacetsRow, largest []db.GetLargestFilesRow) fileStats {
+	stats := fileStats{
+		Extensions:         []facetCount{},
+		Directories:        []facetCount{},
+		Sizes:              []facetCount{},
+		Months:             []facetCount{},
+		LargestFiles:       largest,
+		LargestDirectories: []facetCount{},
+	}
+	for _, facet := range facets {
+		count := facetCount{Value: facet.Value, Files: facet.Files, Size: facet.Size}
+		switch facet.Facet {
+		case db.FacetExtension:
+			// Every file has one extension
+			stats.Files += facet.Files
+			stats.Size += facet.Size
+			stats.Extensions = append(stats.Extensions, count)
+		case db.FacetDirectory:
+			stats.Directories = append(stats.Directories, count)
+		case db.FacetSize:
+			stats.Sizes = append(stats.Sizes, count)
+		case db.FacetMonth:
+			stats.Months = append(stats.Months, count)
+		case db.FacetLargestDirectory:
+			stats.LargestDirectories = append(stats.LargestDirectories, count)
+		}
+	}
+	slices.SortFunc(stats.Sizes, func(a, b facetCount) int {
+		return slices.Index(filequery.SizeNames, a.Value) - slices.Index(filequery.SizeNames, b.Value)
+	})
+	slices.SortFunc(stats.Months, func(a, b facetCount) int {
+		return strings.Compare(a.Value, b.Value)
+	})
+	return stats
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+	"training/db/memdb"
+	db "training/db/sqlc"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newFileTestServer returns a server of a memdb store holding files with
+// duplicate names, sizes and times, so the pages split ties
+func newFileTestServer(t *testing.T) *Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	store := memdb.NewStore()
+	modifiedAt := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 11; i++ {
+		name := fmt.Sprintf("report-%d.txt", i%4)
+		_, err := store.InsertFile(context.Background(), db.InsertFileParams{
+			Name:       name,
+			Extension:  ".txt",
+			Size:       int64(100 * (i % 3)),
+			Path:       fmt.Sprintf("/srv/%02d/%s", i, name),
+			CreatedAt:  modifiedAt,
+			ModifiedAt: modifiedAt.Add(time.Duration(i%2) * time.Hour),
+			AccessedAt: modifiedAt,
+			Metadata:   json.RawMessage("{}"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &Server{store: store}
+}
+
+// searchFiles calls the file search with query and decodes its response
+func searchFiles(t *testing.T, server *Server, query url.Values) (int, newFileResponse, map[string]any) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/api/v1/files?"+query.Encode(), nil)
+	server.getFileSearcher(ctx)
+
+	var response newFileResponse
+	var meta struct {
+		Meta map[string]any `json:"meta"`
+	}
+	if recorder.Code == http.StatusOK {
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &meta); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return recorder.Code, response, meta.Meta
+}
+
+func filePaths(files []newDataFile) []string {
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.FilePath
+	}
+	return paths
+}
+
+// TestGetFileSearcherCursor walks the pages of searches by cursor and checks
+// that they hold every file of the search once, in order
+func TestGetFileSearcherCursor(t *testing.T) {
+	server := newFileTestServer(t)
+	for _, test := range []url.Values{
+		{"sort_by": {db.SortByName}},
+		{"sort_by": {db.SortBySize}, "order": {db.SortDesc}},
+		{"sort_by": {db.SortByModifiedAt}},
+		{"sort_by": {db.SortByPath}, "order": {db.SortDesc}},
+		{"sort_by": {db.SortByRelevance}, "name": {"report"}},
+		{"sort_by": {db.SortBySize}, "q": {"ext:txt"}},
+	} {
+		status, all, _ := searchFiles(t, server, test)
+		if status != http.StatusOK || len(all.Data) != 11 {
+			t.Fatalf("search %v returned %d with %d files", test, status, len(all.Data))
+		}
+		want := filePaths(all.Data)
+
+		var got []string
+		query := url.Values{"limit": {"4"}}
+		for key, value := range test {
+			query[key] = value
+		}
+		for pages := 0; ; pages++ {
+			if pages > 3 {
+				t.Fatalf("search %v has more than 3 pages of 4 files", test)
+			}
+			status, page, meta := searchFiles(t, server, query)
+			if status != http.StatusOK {
+				t.Fatalf("page %d of %v returned %d", pages, test, status)
+			}
+			if _, ok := meta["offset"]; ok && query.Has("cursor") {
+				t.Errorf("page %d of %v has an offset", pages, test)
+			}
+			got = append(got, filePaths(page.Data)...)
+			if page.Meta.NextCursor == "" {
+				break
+			}
+			query.Set("cursor", page.Meta.NextCursor)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("pages of %v = %v, want %v", test, got, want)
+		}
+	}
+}
+
+func TestGetFileSearcherCursorOffset(t *testing.T) {
+	server := newFileTestServer(t)
+	_, page, meta := searchFiles(t, server, url.Values{"limit": {"4"}, "offset": {"2"}})
+	if offset, ok := meta["offset"]; !ok || offset != 2.0 {
+		t.Errorf("page of an offset has offset %v", offset)
+	}
+
+	// A client keeping its offset while following next_cursor would skip
+	// files on every page
+	status, _, _ := searchFiles(t, server, url.Values{"limit": {"4"}, "offset": {"2"}, "cursor": {page.Meta.NextCursor}})
+	if status != http.StatusBadRequest {
+		t.Errorf("search with a cursor and an offset returned %d, want %d", status, http.StatusBadRequest)
+	}
+	status, _, _ = searchFiles(t, server, url.Values{"limit": {"4"}, "offset": {"0"}, "cursor": {page.Meta.NextCursor}})
+	if status != http.StatusOK {
+		t.Errorf("search with a cursor and offset 0 returned %d, want %d", status, http.StatusOK)
+	}
+	status, _, _ = searchFiles(t, server, url.Values{"limit": {"4"}, "sort_by": {db.SortByName}, "cursor": {page.Meta.NextCursor}})
+	if status != http.StatusBadRequest {
+		t.Errorf("search with the cursor of another sort returned %d, want %d", status, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"training/db/filequery"
+	db "training/db/sqlc"
+	"training/file-search/alert"
+	"training/file-search/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// startAlerts watches the saved searches marked watch and delivers their
+// notifications to the webhook if one is configured
+func (server *Server) startAlerts(ctx context.Context) {
+	go alert.NewWatcher(server.store, server.fileSearcherClient).Run(ctx)
+	if server.config.AlertWebhookURL != "" {
+		go alert.NewNotifier(server.store, server.config.AlertWebhookURL, server.config.AlertMaxAttempts).Run(ctx)
+	}
+}
+
+// @Summary Save a search
+// @Description Save a named q query of /api/v1/files, a watched search notifies the webhook of the files created or modified that match it
+// @Tags searches
+// @Accept  json
+// @Produce  json
+// @Param input body savedSearchRequest true "Search"
+// @Success 200 {object} db.SavedSearch
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/searches [post]
+func (server *Server) createSavedSearch(ctx *gin.Context) {
+	var req savedSearchRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := validateSavedQuery(req.Query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	search, err := server.store.CreateSavedSearch(ctx, db.CreateSavedSearchParams{
+		Owner:     payload.Username,
+		Name:      req.Name,
+		Query:     req.Query,
+		Watch:     req.Watch,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		ctx.JSON(savedSearchErrorStatus(err), errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, search)
+}
+
+// @Summary Get saved searches
+// @Description Get the saved searches of the user ordered by name
+// @Tags searches
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} savedSearchesResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/searches [get]
+func (server *Server) getSavedSearches(ctx *gin.Context) {
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	searches, err := server.store.ListSavedSearches(ctx, payload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, savedSearchesResponse{
+		Status: http.StatusOK,
+		Data:   searches,
+	})
+}
+
+// @Summary Get saved search by ID
+// @Description Get a saved search of the user
+// @Tags searches
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Search ID"
+// @Success 200 {object} db.SavedSearch
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/searches/{id} [get]
+func (server *Server) getSavedSearch(ctx *gin.Context) {
+	search, ok := server.ownSavedSearch(ctx)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, search)
+}
+
+// @Summary Update saved search
+// @Description Replace the name, query and watch of a saved search of the user
+// @Tags searches
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Search ID"
+// @Param input body savedSearchRequest true "Search"
+// @Success 200 {object} db.SavedSearch
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/searches/{id} [put]
+func (server *Server) updateSavedSearch(ctx *gin.Context) {
+	var req savedSearchRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := validateSavedQuery(req.Query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	search, ok := server.ownSavedSearch(ctx)
+	if !ok {
+		return
+	}
+
+	search, err := server.store.UpdateSavedSearch(ctx, db.UpdateSavedSearchParams{
+		ID:        search.ID,
+		Name:      req.Name,
+		Query:     req.Query,
+		Watch:     req.Watch,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		ctx.JSON(savedSearchErrorStatus(err), errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, search)
+}
+
+// @Summary Delete saved search
+// @Description Delete a saved search of the user and its notifications
+// @Tags searches
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Search ID"
+// @Success 200 {object} db.SavedSearch
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/searches/{id} [delete]
+func (server *Server) deleteSavedSearch(ctx *gin.Context) {
+	search, ok := server.ownSavedSearch(ctx)
+	if !ok {
+		return
+	}
+	if _, err := server.store.DeleteSavedSearch(ctx, search.ID); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, search)
+}
+
+// @Summary Get notifications of a saved search
+// @Description Get the newest notifications of a watched search of the user with their delivery state
+// @Tags searches
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Search ID"
+// @Param limit query int false "Limit" default(50)
+// @Success 200 {object} notificationsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/searches/{id}/notifications [get]
+func (server *Server) getNotifications(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+	if err != nil || limit < 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("limit must be a positive number")))
+		return
+	}
+	search, ok := server.ownSavedSearch(ctx)
+	if !ok {
+		return
+	}
+	notifications, err := server.store.ListNotifications(ctx, db.ListNotificationsParams{
+		SavedSearchID: search.ID,
+		Limit:         int32(limit),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, notificationsResponse{
+		Status: http.StatusOK,
+		Data:   notifications,
+	})
+}
+
+// errSavedSearchNotFound is returned for the searches of other users too
+var errSavedSearchNotFound = errors.New("saved search not found")
+
+// ownSavedSearch returns the search of the id parameter if it belongs to the
+// user, otherwise it writes the error response
+func (server *Server) ownSavedSearch(ctx *gin.Context) (db.SavedSearch, bool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.SavedSearch{}, false
+	}
+	search, err := server.store.GetSavedSearch(ctx, int32(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, errorResponse(errSavedSearchNotFound))
+		return db.SavedSearch{}, false
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.SavedSearch{}, false
+	}
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if search.Owner != payload.Username {
+		ctx.JSON(http.StatusNotFound, errorResponse(errSavedSearchNotFound))
+		return db.SavedSearch{}, false
+	}
+	return search, true
+}
+
+// validateSavedQuery checks the q query of a saved search
+func validateSavedQuery(query string) error {
+	if _, err := filequery.Parse(query, time.Now()); err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+	return nil
+}
+
+// savedSearchErrorStatus returns the status of a failed write of a search,
+// names are unique per user and only users of the users table own searches
+func savedSearchErrorStatus(err error) int {
+	switch {
+	case db.IsUniqueViolation(err):
+		return http.StatusForbidden
+	case db.IsForeignKeyViolation(err):
+		return http.StatusForbidden
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
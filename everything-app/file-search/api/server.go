@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
@@ -64,6 +65,21 @@ func loadTLSCredentials() (credentials.TransportCredentials, error) {
 
 // NewServer creates a new HTTP server and set up routing.
 func NewServer(config util.Config, store db.Store) (*Server, error) {
+	tlsCredential, err := loadTLSCredentials()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	conn, err := grpc.Dial(config.GRPCServerAddress, grpc.WithTransportCredentials(tlsCredential))
+	if err != nil {
+		log.Fatal("cannot dial server: ", err)
+	}
+
+	return NewServerWithClient(config, store, pb.NewFileIndexClient(conn))
+}
+
+// NewServerWithClient creates a new HTTP server using fileSearcherClient to reach the indexer.
+func NewServerWithClient(config util.Config, store db.Store, fileSearcherClient pb.FileIndexClient) (*Server, error) {
 	tokenMaker, err := token.NewJWTMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
@@ -79,23 +95,13 @@ func NewServer(config util.Config, store db.Store) (*Server, error) {
 	}
 
 	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
-		enforcer:   enforcer,
-	}
-
-	tlsCredential, err := loadTLSCredentials()
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	conn, err := grpc.Dial(config.GRPCServerAddress, grpc.WithTransportCredentials(tlsCredential))
-	if err != nil {
-		log.Fatal("cannot dial server: ", err)
+		config:             config,
+		store:              store,
+		tokenMaker:         tokenMaker,
+		enforcer:           enforcer,
+		fileSearcherClient: fileSearcherClient,
 	}
 
-	server.fileSearcherClient = pb.NewFileIndexClient(conn)
 	fmt.Println(server.fileSearcherClient)
 	server.setupRouter()
 	return server, nil
@@ -132,11 +138,21 @@ func (server *Server) setupRouter() {
 	{
 		// Use Authorize middleware to check if user has permission to access the route
 		authRoutes.GET("/files", server.getFileSearcher)
+		authRoutes.GET("/files/stats", server.getFileStats)
+		authRoutes.GET("/files/duplicates", server.getDuplicateFiles)
+		authRoutes.GET("/directories/digest", server.getDirectoryDigest)
+		authRoutes.GET("/directories/diff", server.getDirectoryDiff)
 		authRoutes.GET("/users", server.getUsers)
 		authRoutes.POST("/users", server.createUser)
 		authRoutes.PATCH("/users", server.updateUser)
 		authRoutes.DELETE("/users/:id", server.deleteUser)
 		authRoutes.GET("/users/:id", server.getUserById)
+		authRoutes.GET("/searches", server.getSavedSearches)
+		authRoutes.POST("/searches", server.createSavedSearch)
+		authRoutes.GET("/searches/:id", server.getSavedSearch)
+		authRoutes.PUT("/searches/:id", server.updateSavedSearch)
+		authRoutes.DELETE("/searches/:id", server.deleteSavedSearch)
+		authRoutes.GET("/searches/:id/notifications", server.getNotifications)
 	}
 
 	docs.SwaggerInfo.Host = server.config.HTTPServerAddress
@@ -144,8 +160,9 @@ func (server *Server) setupRouter() {
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 }
 
-// Start runs the HTTP server on a specific address.
+// Start runs the alerts of the watched searches and the HTTP server on a specific address.
 func (server *Server) Start(address string) error {
+	server.startAlerts(context.Background())
 	address = strings.Split(address, ":")[1]
 	return server.router.Run(":" + address)
 }
@@ -9,7 +9,6 @@ import (
 	"training/file-search/util"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
 )
 
 // @Summary Create a new user
@@ -48,12 +47,9 @@ func (server *Server) createUser(ctx *gin.Context) {
 
 	user, err := server.store.CreateUser(ctx, arg)
 	if err != nil {
-		if pgErr, ok := err.(*pq.Error); ok {
-			switch pgErr.Code.Name() {
-			case "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return
-			}
+		if db.IsUniqueViolation(err) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
 		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
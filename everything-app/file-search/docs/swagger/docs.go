@@ -15,9 +15,128 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/v1/directories/diff": {
+            "get": {
+                "description": "Files and directories that differ between two digests of a directory: CREATED and DELETED files and subtrees, MODIFIED files. Without new_digest the old digest is compared with the current digest of path. Digests of versions that left the index are kept for a month.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "directories"
+                ],
+                "summary": "Directory changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Absolute path of the directory, the paths of the changes are relative without it",
+                        "name": "path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Previous digest",
+                        "name": "old_digest",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Digest to compare with, the current digest of path by default",
+                        "name": "new_digest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Directory levels to compare, a changed directory below is MODIFIED. 0 means unlimited",
+                        "name": "max_depth",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.directoryDiffResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/directories/digest": {
+            "get": {
+                "description": "Merkle digest of the files below an indexed directory, it changes whenever a file below the directory changes. Keep it to list the changes later with /api/v1/directories/diff. The indexer computes the digests when directory_digests is set in its crawl config.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "directories"
+                ],
+                "summary": "Directory digest",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Absolute path of the directory",
+                        "name": "path",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.directoryDigestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/files": {
             "get": {
-                "description": "Search files",
+                "description": "Search files with the q query or with the filter parameters, q can not be combined with them.\nThe metadata extracted from documents and media is filtered with meta.\u003ckey\u003e parameters matching\nthe whole value, such as meta.author=Jane or meta.camera=Canon EOS 5D, or with meta.\u003ckey\u003e: in q.",
                 "consumes": [
                     "application/json"
                 ],
@@ -29,12 +148,37 @@ const docTemplate = `{
                 ],
                 "summary": "Search files",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Query like: ext:docx size:\u003e10mb modified:lastweek path:/srv/share \\",
+                        "name": "q",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "File name",
                         "name": "name",
                         "in": "query"
                     },
+                    {
+                        "enum": [
+                            "substring",
+                            "exact",
+                            "regex",
+                            "fuzzy"
+                        ],
+                        "type": "string",
+                        "default": "substring",
+                        "description": "Name match mode",
+                        "name": "match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum similarity of a fuzzy match, 0 to 1",
+                        "name": "threshold",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "File extension",
@@ -91,13 +235,44 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Content",
+                        "description": "Content search: words, \\",
                         "name": "content",
                         "in": "query"
                     },
+                    {
+                        "enum": [
+                            "relevance",
+                            "name",
+                            "size",
+                            "modified_at",
+                            "created_at",
+                            "path"
+                        ],
+                        "type": "string",
+                        "default": "relevance",
+                        "description": "Sort key, files with the same key are sorted by path",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, descending by default for relevance",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Offset",
+                        "description": "Offset, can not be combined with cursor",
                         "name": "offset",
                         "in": "query"
                     },
@@ -106,6 +281,15 @@ const docTemplate = `{
                         "description": "Limit",
                         "name": "limit",
                         "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "checksum"
+                        ],
+                        "type": "string",
+                        "description": "Comma separated extra fields",
+                        "name": "include",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -124,9 +308,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/users": {
+        "/api/v1/files/duplicates": {
             "get": {
-                "description": "Get users",
+                "description": "Groups of indexed files with the same content, the groups wasting the most bytes first. A group wastes the size of all of its files but one. The indexer hashes the files sharing their size with another file after each crawl, so a new file may be missing until then.",
                 "consumes": [
                     "application/json"
                 ],
@@ -134,44 +318,157 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "files"
                 ],
-                "summary": "Get users",
+                "summary": "Duplicate files",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Search term",
-                        "name": "search",
+                        "description": "Only group the files below this absolute path",
+                        "name": "root",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Limit",
+                        "default": 1,
+                        "description": "Only group the files of at least this many bytes",
+                        "name": "min_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Number of groups, up to 1000",
                         "name": "limit",
                         "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.duplicateFilesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/files/stats": {
+            "get": {
+                "description": "Facets of the files matching the q query or the filter parameters of /api/v1/files: the number and the total size of the files by extension, by top-level directory, by named size and by modification month in UTC, and the largest files and directories. A directory holds the size of the files it directly contains.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "File statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Query like: ext:docx size:\u003e10mb modified:lastweek path:/srv/share \\",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "File name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "substring",
+                            "exact",
+                            "regex",
+                            "fuzzy"
+                        ],
+                        "type": "string",
+                        "default": "substring",
+                        "description": "Name match mode",
+                        "name": "match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum similarity of a fuzzy match, 0 to 1",
+                        "name": "threshold",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "File extension",
+                        "name": "extension",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Offset",
-                        "name": "offset",
+                        "description": "Minimum file size",
+                        "name": "size_min",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "State",
-                        "name": "state",
+                        "description": "Maximum file size",
+                        "name": "size_max",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Order by",
-                        "name": "orderby",
+                        "description": "Created after",
+                        "name": "created_after",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Order",
-                        "name": "order",
+                        "description": "Created before",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Modified after",
+                        "name": "modified_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Modified before",
+                        "name": "modified_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Accessed after",
+                        "name": "accessed_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Accessed before",
+                        "name": "accessed_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content search: words, \\",
+                        "name": "content",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of largest files and directories, up to 1000",
+                        "name": "top",
                         "in": "query"
                     }
                 ],
@@ -179,7 +476,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.Response"
+                            "$ref": "#/definitions/api.fileStatsResponse"
                         }
                     },
                     "400": {
@@ -189,9 +486,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a new user",
+            }
+        },
+        "/api/v1/searches": {
+            "get": {
+                "description": "Get the saved searches of the user ordered by name",
                 "consumes": [
                     "application/json"
                 ],
@@ -199,25 +498,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
-                ],
-                "summary": "Create a new user",
-                "parameters": [
-                    {
-                        "description": "User info",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.createUserRequest"
-                        }
-                    }
+                    "searches"
                 ],
+                "summary": "Get saved searches",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.userResponse"
+                            "$ref": "#/definitions/api.savedSearchesResponse"
                         }
                     },
                     "400": {
@@ -228,8 +516,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "patch": {
-                "description": "Update user",
+            "post": {
+                "description": "Save a named q query of /api/v1/files, a watched search notifies the webhook of the files created or modified that match it",
                 "consumes": [
                     "application/json"
                 ],
@@ -237,17 +525,360 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "searches"
                 ],
-                "summary": "Update user",
+                "summary": "Save a search",
                 "parameters": [
                     {
-                        "description": "User info",
+                        "description": "Search",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.createUserRequest"
+                            "$ref": "#/definitions/api.savedSearchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.SavedSearch"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/searches/{id}": {
+            "get": {
+                "description": "Get a saved search of the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "searches"
+                ],
+                "summary": "Get saved search by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Search ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.SavedSearch"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the name, query and watch of a saved search of the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "searches"
+                ],
+                "summary": "Update saved search",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Search ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Search",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.savedSearchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.SavedSearch"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a saved search of the user and its notifications",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "searches"
+                ],
+                "summary": "Delete saved search",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Search ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.SavedSearch"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/searches/{id}/notifications": {
+            "get": {
+                "description": "Get the newest notifications of a watched search of the user with their delivery state",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "searches"
+                ],
+                "summary": "Get notifications of a saved search",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Search ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.notificationsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users": {
+            "get": {
+                "description": "Get users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search term",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "State",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order by",
+                        "name": "orderby",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create a new user",
+                "parameters": [
+                    {
+                        "description": "User info",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.createUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.userResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update user",
+                "parameters": [
+                    {
+                        "description": "User info",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.createUserRequest"
                         }
                     }
                 ],
@@ -386,103 +1017,313 @@ const docTemplate = `{
         "api.DataObject": {
             "type": "object",
             "properties": {
-                "access_token": {
+                "access_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.Meta": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "metadata": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.Metadata": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_exact": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.Response": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.User"
+                    }
+                },
+                "meta": {
+                    "$ref": "#/definitions/api.Meta"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.createUserRequest": {
+            "type": "object",
+            "required": [
+                "avatar",
+                "email",
+                "full_name",
+                "password",
+                "role",
+                "username"
+            ],
+            "properties": {
+                "avatar": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 6
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "operator"
+                    ]
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.directoryChange": {
+            "type": "object",
+            "properties": {
+                "change_type": {
+                    "type": "string"
+                },
+                "directory": {
+                    "type": "boolean"
+                },
+                "path": {
                     "type": "string"
                 }
             }
         },
-        "api.ErrorResponse": {
+        "api.directoryDiff": {
             "type": "object",
             "properties": {
-                "error": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.directoryChange"
+                    }
+                },
+                "new_digest": {
+                    "type": "string"
+                },
+                "old_digest": {
                     "type": "string"
                 }
             }
         },
-        "api.Meta": {
+        "api.directoryDiffResponse": {
             "type": "object",
             "properties": {
-                "limit": {
-                    "type": "integer"
+                "data": {
+                    "$ref": "#/definitions/api.directoryDiff"
                 },
-                "metadata": {
+                "status": {
                     "type": "integer"
+                }
+            }
+        },
+        "api.directoryDigest": {
+            "type": "object",
+            "properties": {
+                "computed_at": {
+                    "type": "string"
                 },
-                "offset": {
-                    "type": "integer"
+                "digest": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
                 }
             }
         },
-        "api.Metadata": {
+        "api.directoryDigestResponse": {
             "type": "object",
             "properties": {
-                "limit": {
-                    "type": "integer"
+                "data": {
+                    "$ref": "#/definitions/api.directoryDigest"
                 },
-                "offset": {
+                "status": {
                     "type": "integer"
+                }
+            }
+        },
+        "api.duplicateFile": {
+            "type": "object",
+            "properties": {
+                "filepath": {
+                    "type": "string"
                 },
-                "total": {
-                    "type": "integer"
+                "modified_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
                 }
             }
         },
-        "api.Response": {
+        "api.duplicateFilesResponse": {
             "type": "object",
             "properties": {
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/db.User"
+                        "$ref": "#/definitions/api.duplicateGroup"
                     }
                 },
                 "meta": {
-                    "$ref": "#/definitions/api.Meta"
+                    "$ref": "#/definitions/api.duplicateTotals"
                 },
                 "status": {
                     "type": "integer"
                 }
             }
         },
-        "api.createUserRequest": {
+        "api.duplicateGroup": {
             "type": "object",
-            "required": [
-                "avatar",
-                "email",
-                "full_name",
-                "password",
-                "role",
-                "username"
-            ],
             "properties": {
-                "avatar": {
+                "content_hash": {
                     "type": "string"
                 },
-                "email": {
-                    "type": "string"
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.duplicateFile"
+                    }
                 },
-                "full_name": {
-                    "type": "string"
+                "size": {
+                    "type": "integer"
                 },
-                "password": {
-                    "type": "string",
-                    "minLength": 6
+                "wasted_bytes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.duplicateTotals": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "integer"
                 },
-                "phone": {
-                    "type": "string"
+                "groups": {
+                    "type": "integer"
                 },
-                "role": {
-                    "type": "string",
-                    "enum": [
-                        "admin",
-                        "operator"
-                    ]
+                "wasted_bytes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.facetCount": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "integer"
                 },
-                "username": {
+                "size": {
+                    "type": "integer"
+                },
+                "value": {
                     "type": "string"
                 }
             }
         },
+        "api.fileStats": {
+            "type": "object",
+            "properties": {
+                "directories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.facetCount"
+                    }
+                },
+                "extensions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.facetCount"
+                    }
+                },
+                "files": {
+                    "type": "integer"
+                },
+                "largest_directories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.facetCount"
+                    }
+                },
+                "largest_files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.GetLargestFilesRow"
+                    }
+                },
+                "months": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.facetCount"
+                    }
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "sizes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.facetCount"
+                    }
+                }
+            }
+        },
+        "api.fileStatsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/api.fileStats"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
         "api.loginUserRequest": {
             "type": "object",
             "required": [
@@ -521,11 +1362,48 @@ const docTemplate = `{
         "api.newDataFile": {
             "type": "object",
             "properties": {
+                "accessed_at": {
+                    "type": "string"
+                },
+                "attributes": {
+                    "type": "string"
+                },
                 "checksum": {
                     "type": "string"
                 },
+                "created_at": {
+                    "type": "string"
+                },
+                "extension": {
+                    "type": "string"
+                },
                 "filepath": {
                     "type": "string"
+                },
+                "metadata": {
+                    "description": "Metadata holds the properties extracted from documents and media, such\nas author, camera or artist",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "modified_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "similarity": {
+                    "type": "number"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "snippet": {
+                    "type": "string"
                 }
             }
         },
@@ -546,6 +1424,53 @@ const docTemplate = `{
                 }
             }
         },
+        "api.notificationsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.Notification"
+                    }
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.savedSearchRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "query"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "query": {
+                    "type": "string"
+                },
+                "watch": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.savedSearchesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.SavedSearch"
+                    }
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
         "api.userResponse": {
             "type": "object",
             "properties": {
@@ -566,6 +1491,84 @@ const docTemplate = `{
                 }
             }
         },
+        "db.GetLargestFilesRow": {
+            "type": "object",
+            "properties": {
+                "extension": {
+                    "type": "string"
+                },
+                "modified_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "db.Notification": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "change_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "saved_search_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "db.SavedSearch": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "watch": {
+                    "type": "boolean"
+                }
+            }
+        },
         "db.User": {
             "type": "object",
             "properties": {
@@ -1,7 +1,13 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"time"
+	"training/db/memdb"
+	"training/db/sqlite"
+	"training/file-index/pb"
+	"training/file-index/service"
 	"training/file-search/api"
 	"training/file-search/util"
 
@@ -16,13 +22,26 @@ func main() {
 		log.Fatal().Msg("cannot load config")
 	}
 
-	conn, err := sql.Open(config.DBDriver, config.DBSource)
-	if err != nil {
-		log.Fatal().Msg("cannot connect to db")
+	switch config.DBDriver {
+	case "memory":
+		runSingleBinary(config)
+	case sqlite.DriverName:
+		// DB_SOURCE is the path of the database file
+		conn, err := sqlite.Open(config.DBSource)
+		if err != nil {
+			log.Fatal().Err(err).Msg("cannot open sqlite db")
+		}
+		defer conn.Close()
+		runGinServer(config, sqlite.NewStore(conn))
+	default:
+		conn, err := sql.Open(config.DBDriver, config.DBSource)
+		if err != nil {
+			log.Fatal().Msg("cannot connect to db")
+		}
+		defer conn.Close()
+		store := db.NewStore(conn)
+		runGinServer(config, store)
 	}
-	defer conn.Close()
-	store := db.NewStore(conn)
-	runGinServer(config, store)
 }
 
 func runGinServer(config util.Config, store db.Store) {
@@ -30,7 +49,60 @@ func runGinServer(config util.Config, store db.Store) {
 	if err != nil {
 		log.Fatal().Msg("cannot create server")
 	}
-	err = server.Start(config.HTTPServerAddress)
+	startGinServer(config, server)
+}
+
+// runSingleBinary keeps files and users in memory and runs the indexer in
+// this process, so the search API works without a database or a file-index server
+func runSingleBinary(config util.Config) {
+	store := memdb.NewStore()
+	fileSearcherClient, stop, err := runIndexer(config, store)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot start indexer")
+	}
+	defer stop()
+
+	server, err := api.NewServerWithClient(config, store, fileSearcherClient)
+	if err != nil {
+		log.Fatal().Msg("cannot create server")
+	}
+	startGinServer(config, server)
+}
+
+func runIndexer(config util.Config, store *memdb.Store) (pb.FileIndexClient, func(), error) {
+	crawlConfig := service.DefaultCrawlConfig()
+	if config.CrawlConfig != "" {
+		var err error
+		crawlConfig, err = service.LoadCrawlConfig(config.CrawlConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	indexer, err := service.NewIndexer(service.NewInMemoryFileStore(store), crawlConfig, service.IndexModeWatch, 15*time.Minute)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		if err := indexer.Run(ctx); err != nil {
+			log.Error().Err(err).Msg("indexer stopped")
+		}
+	}()
+
+	fileSearcherClient, stopClient, err := service.NewLocalClient(service.NewFileDiscoveryServer(indexer))
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	stop := func() {
+		stopClient()
+		cancel()
+	}
+	return fileSearcherClient, stop, nil
+}
+
+func startGinServer(config util.Config, server *api.Server) {
+	err := server.Start(config.HTTPServerAddress)
 	if err != nil {
 		log.Fatal().Msg("cannot start server")
 	}
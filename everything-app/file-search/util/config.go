@@ -17,6 +17,17 @@ type Config struct {
 	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
 	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
 	AdminUser            string        `mapstructure:"ADMIN_USER"`
+	// CrawlConfig is the crawl config file of the indexer embedded when
+	// DBDriver is memory, the drive folders are indexed if it is empty
+	CrawlConfig string `mapstructure:"CRAWL_CONFIG"`
+	// SimilarityThreshold is the default minimum similarity of a fuzzy
+	// filename search
+	SimilarityThreshold float32 `mapstructure:"SIMILARITY_THRESHOLD"`
+	// AlertWebhookURL receives the notifications of the watched searches,
+	// they are only recorded if it is empty
+	AlertWebhookURL string `mapstructure:"ALERT_WEBHOOK_URL"`
+	// AlertMaxAttempts is the number of attempts to deliver a notification
+	AlertMaxAttempts int32 `mapstructure:"ALERT_MAX_ATTEMPTS"`
 }
 
 // LoadConfig reads configuration from file or environment variables.
@@ -25,6 +36,8 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.SetConfigName("app")
 	viper.SetConfigType("env")
 
+	viper.SetDefault("SIMILARITY_THRESHOLD", 0.3)
+	viper.SetDefault("ALERT_MAX_ATTEMPTS", 8)
 	viper.AutomaticEnv()
 
 	err = viper.ReadInConfig()